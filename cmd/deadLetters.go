@@ -0,0 +1,136 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"os"
+	"strconv"
+	"text/tabwriter"
+
+	"github.com/goccy/go-json"
+	"github.com/saleh-ghazimoradi/Cartopher/config"
+	"github.com/saleh-ghazimoradi/Cartopher/infra/postgresql"
+	"github.com/saleh-ghazimoradi/Cartopher/internal/logger"
+	"github.com/saleh-ghazimoradi/Cartopher/internal/repository"
+	"github.com/saleh-ghazimoradi/Cartopher/internal/service"
+
+	"github.com/spf13/cobra"
+)
+
+// deadLettersCmd represents the deadLetters command
+var deadLettersCmd = &cobra.Command{
+	Use:   "deadLetters",
+	Short: "Inspect and replay messages the notifier gave up on",
+}
+
+var deadLettersListCmd = &cobra.Command{
+	Use:   "list",
+	Short: "List dead letters, newest first",
+	Args:  cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		all, _ := cmd.Flags().GetBool("all")
+		limit, _ := cmd.Flags().GetInt("limit")
+
+		deadLetters, err := newDeadLetterService().GetDeadLetters(context.Background(), all, limit)
+		if err != nil {
+			log.Fatalf("failed to list dead letters: %v", err)
+		}
+
+		w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+		fmt.Fprintln(w, "ID\tEVENT\tATTEMPTS\tCREATED\tREPLAYED\tLAST ERROR")
+		for _, deadLetter := range deadLetters {
+			replayed := "-"
+			if deadLetter.ReplayedAt != nil {
+				replayed = deadLetter.ReplayedAt.Format("2006-01-02 15:04:05")
+			}
+			fmt.Fprintf(w, "%d\t%s\t%d\t%s\t%s\t%s\n", deadLetter.Id, deadLetter.EventType, deadLetter.Attempts, deadLetter.CreatedAt.Format("2006-01-02 15:04:05"), replayed, deadLetter.LastError)
+		}
+		_ = w.Flush()
+	},
+}
+
+var deadLettersShowCmd = &cobra.Command{
+	Use:   "show <id>",
+	Short: "Print a dead letter with its payload",
+	Args:  cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		id, err := strconv.ParseUint(args[0], 10, 32)
+		if err != nil {
+			log.Fatalf("invalid dead letter id: %v", err)
+		}
+
+		deadLetter, err := newDeadLetterService().GetDeadLetter(context.Background(), uint(id))
+		if err != nil {
+			log.Fatalf("failed to get dead letter: %v", err)
+		}
+
+		data, err := json.MarshalIndent(deadLetter, "", "  ")
+		if err != nil {
+			log.Fatalf("failed to encode dead letter: %v", err)
+		}
+		fmt.Println(string(data))
+	},
+}
+
+var deadLettersReplayCmd = &cobra.Command{
+	Use:   "replay <id>...",
+	Short: "Hand dead letters back to the notifier with a fresh retry budget",
+	Args:  cobra.MinimumNArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		deadLetterService := newDeadLetterService()
+
+		for _, arg := range args {
+			id, err := strconv.ParseUint(arg, 10, 32)
+			if err != nil {
+				log.Fatalf("invalid dead letter id %q: %v", arg, err)
+			}
+
+			if err := deadLetterService.ReplayDeadLetter(context.Background(), uint(id)); err != nil {
+				log.Fatalf("failed to replay dead letter %d: %v", id, err)
+			}
+			fmt.Printf("dead letter %d queued for replay\n", id)
+		}
+	},
+}
+
+func newDeadLetterService() service.DeadLetterService {
+	cfg, err := config.GetInstance()
+	if err != nil {
+		log.Fatalf("failed to get config: %v", err)
+	}
+
+	zlog := logger.NewLogger(cfg)
+
+	postDB := postgresql.NewPostgresql(
+		postgresql.WithHost(cfg.Postgresql.Host),
+		postgresql.WithPort(cfg.Postgresql.Port),
+		postgresql.WithUser(cfg.Postgresql.User),
+		postgresql.WithPassword(cfg.Postgresql.Password),
+		postgresql.WithName(cfg.Postgresql.Name),
+		postgresql.WithMaxOpenConn(cfg.Postgresql.MaxOpenConn),
+		postgresql.WithMaxIdleConn(cfg.Postgresql.MaxIdleConn),
+		postgresql.WithMaxIdleTime(cfg.Postgresql.MaxIdleTime),
+		postgresql.WithSSLMode(cfg.Postgresql.SSLMode),
+		postgresql.WithTimeout(cfg.Postgresql.Timeout),
+		postgresql.WithLogger(&zlog),
+	)
+
+	gormDB, _, err := postDB.Connect()
+	if err != nil {
+		log.Fatalf("failed to connect to database: %v", err)
+	}
+
+	outboxRepository := repository.NewOutboxRepository(gormDB, gormDB)
+	deadLetterRepository := repository.NewDeadLetterRepository(gormDB, gormDB)
+
+	return service.NewDeadLetterService(cfg, outboxRepository, deadLetterRepository, gormDB)
+}
+
+func init() {
+	deadLettersListCmd.Flags().Bool("all", false, "Include dead letters that were already replayed")
+	deadLettersListCmd.Flags().Int("limit", 50, "Maximum number of dead letters to list")
+
+	deadLettersCmd.AddCommand(deadLettersListCmd, deadLettersShowCmd, deadLettersReplayCmd)
+	rootCmd.AddCommand(deadLettersCmd)
+}
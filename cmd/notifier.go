@@ -4,18 +4,17 @@ import (
 	"context"
 	"fmt"
 	"log"
-	"os"
 	"os/signal"
 	"syscall"
 
-	"github.com/ThreeDotsLabs/watermill"
-	"github.com/ThreeDotsLabs/watermill-aws/sqs"
 	"github.com/ThreeDotsLabs/watermill/message"
-	"github.com/goccy/go-json"
 	"github.com/saleh-ghazimoradi/Cartopher/config"
-	"github.com/saleh-ghazimoradi/Cartopher/internal/domain"
+	"github.com/saleh-ghazimoradi/Cartopher/infra/events"
+	"github.com/saleh-ghazimoradi/Cartopher/infra/postgresql"
+	"github.com/saleh-ghazimoradi/Cartopher/internal/dto"
+	"github.com/saleh-ghazimoradi/Cartopher/internal/logger"
+	"github.com/saleh-ghazimoradi/Cartopher/internal/repository"
 	"github.com/saleh-ghazimoradi/Cartopher/internal/service"
-	"github.com/saleh-ghazimoradi/Cartopher/pkg/uploadProvider"
 
 	"github.com/spf13/cobra"
 )
@@ -36,68 +35,135 @@ var notifierCmd = &cobra.Command{
 			log.Fatalf("failed to get config: %v", err)
 		}
 
+		if events.InProcess(cfg) {
+			log.Fatalf("the %s transport runs the notifier inside the run command", events.TransportGoChannel)
+		}
+
 		emailNotifier := service.NewEmailNotifier(cfg)
 
-		awsCfg, err := uploadProvider.CreateAWSConfig(ctx, cfg.AWS.S3Endpoint, cfg.AWS.Region)
+		zlog := logger.NewLogger(cfg)
+
+		postDB := postgresql.NewPostgresql(
+			postgresql.WithHost(cfg.Postgresql.Host),
+			postgresql.WithPort(cfg.Postgresql.Port),
+			postgresql.WithUser(cfg.Postgresql.User),
+			postgresql.WithPassword(cfg.Postgresql.Password),
+			postgresql.WithName(cfg.Postgresql.Name),
+			postgresql.WithMaxOpenConn(cfg.Postgresql.MaxOpenConn),
+			postgresql.WithMaxIdleConn(cfg.Postgresql.MaxIdleConn),
+			postgresql.WithMaxIdleTime(cfg.Postgresql.MaxIdleTime),
+			postgresql.WithSSLMode(cfg.Postgresql.SSLMode),
+			postgresql.WithTimeout(cfg.Postgresql.Timeout),
+			postgresql.WithLogger(&zlog),
+		)
+
+		// The database holds the retries and dead letters, and the postgres
+		// transport also reads its messages from it.
+		gormDB, sqlDB, err := postDB.Connect()
 		if err != nil {
-			log.Fatalf("failed to create aws config: %v", err)
+			log.Fatalf("failed to connect to database: %v", err)
 		}
 
-		logger := watermill.NewStdLogger(false, false)
-
-		subscriber, err := sqs.NewSubscriber(sqs.SubscriberConfig{
-			AWSConfig: awsCfg,
-		}, logger)
+		outboxRepository := repository.NewOutboxRepository(gormDB, gormDB)
+		deadLetterRepository := repository.NewDeadLetterRepository(gormDB, gormDB)
+		deadLetterService := service.NewDeadLetterService(cfg, outboxRepository, deadLetterRepository, gormDB)
 
+		subscriber, err := events.NewSubscriber(ctx, cfg, sqlDB)
 		if err != nil {
-			log.Fatalf("failed to create aws sqs subscriber: %v", err)
+			log.Fatalf("failed to create event subscriber: %v", err)
 		}
 
-		messages, err := subscriber.Subscribe(ctx, cfg.AWS.EventQueueName)
+		sigCtx, stop := signal.NotifyContext(ctx, syscall.SIGINT, syscall.SIGTERM)
+		defer stop()
+
+		messages, err := subscriber.Subscribe(sigCtx, events.Topic(cfg))
 		if err != nil {
 			subscriber.Close()
 			log.Fatalf("failed to subscribe to queue: %v", err)
 		}
 
-		sigChan := make(chan os.Signal, 1)
-		signal.Notify(sigChan, syscall.SIGINT, syscall.SIGTERM)
+		runNotifier(sigCtx, messages, emailNotifier, deadLetterService)
+		subscriber.Close()
+	},
+}
 
-		log.Println("Notification service started. Waiting for messages...")
+// runNotifier handles messages until ctx is done or the subscription ends.
+// A message that fails is acked once its retry or dead letter is recorded;
+// it is only nacked back to the transport if even that fails.
+func runNotifier(ctx context.Context, messages <-chan *message.Message, emailNotifier service.Notifier, deadLetterService service.DeadLetterService) {
+	log.Println("Notification service started. Waiting for messages...")
 
-		for {
-			select {
-			case msg := <-messages:
-				if err := processMessage(msg, emailNotifier); err != nil {
-					log.Printf("failed to process message: %v", err)
+	for {
+		select {
+		case msg, ok := <-messages:
+			if !ok {
+				return
+			}
+			if err := processMessage(msg, emailNotifier); err != nil {
+				log.Printf("failed to process message %s (attempt %s): %v", msg.UUID, msg.Metadata.Get(service.MetadataAttempt), err)
+
+				if err := deadLetterService.HandleFailure(ctx, &dto.FailedMessage{
+					EventType: msg.Metadata.Get("event_type"),
+					Payload:   msg.Payload,
+					Metadata:  msg.Metadata,
+					Error:     err.Error(),
+				}); err != nil {
+					log.Printf("failed to schedule retry of message %s: %v", msg.UUID, err)
 					msg.Nack()
-				} else {
-					msg.Ack()
+					continue
 				}
-			case <-sigChan:
-				log.Println("Notification service shutting down...")
-				subscriber.Close()
-				return
 			}
+			msg.Ack()
+		case <-ctx.Done():
+			log.Println("Notification service shutting down...")
+			return
 		}
-	},
+	}
 }
 
 func processMessage(msg *message.Message, emailNotifier service.Notifier) error {
-	eventType := msg.Metadata.Get("event_type")
+	if target := msg.Metadata.Get(service.MetadataTarget); target != "" && target != service.NotifierTarget {
+		return nil
+	}
 
-	switch eventType {
-	case service.UserLoggedIn:
-		return handleUserLoggedIn(msg, emailNotifier)
+	envelope, err := events.DecodeEnvelope(msg.Payload, msg.Metadata.Get("event_type"))
+	if err != nil {
+		return err
+	}
+
+	switch envelope.Type {
+	case events.UserLoggedIn:
+		return handleUserLoggedIn(envelope, emailNotifier)
+	case events.EmailVerificationRequested:
+		return handleEmailVerificationRequested(envelope, emailNotifier)
+	case events.PasswordResetRequested:
+		return handlePasswordResetRequested(envelope, emailNotifier)
+	case events.RefreshTokenReused:
+		return handleRefreshTokenReused(envelope, emailNotifier)
+	case events.AccountLocked:
+		return handleAccountLocked(envelope, emailNotifier)
+	case events.ProductLowStock:
+		return handleProductLowStock(envelope, emailNotifier)
+	case events.OrderCreated:
+		return handleOrderEvent(envelope, emailNotifier.SendOrderConfirmation)
+	case events.OrderShipped:
+		return handleOrderEvent(envelope, emailNotifier.SendOrderShipped)
+	case events.OrderDelivered:
+		return handleOrderEvent(envelope, emailNotifier.SendOrderDelivered)
+	case events.OrderCancelled:
+		return handleOrderEvent(envelope, emailNotifier.SendOrderCancelled)
+	case events.OrderConfirmed, events.ShipmentDelivered, events.CartUpdated:
+		// Only used for live updates.
+		return nil
 	default:
-		log.Printf("Unknown event type: %s", eventType)
+		log.Printf("Unknown event type: %s", envelope.Type)
 		return nil
 	}
 }
 
-func handleUserLoggedIn(msg *message.Message, emailNotifier service.Notifier) error {
-	var user domain.User
-
-	if err := json.Unmarshal(msg.Payload, &user); err != nil {
+func handleUserLoggedIn(envelope *events.Envelope, emailNotifier service.Notifier) error {
+	user, err := events.DecodeUserLoggedIn(envelope)
+	if err != nil {
 		return err
 	}
 
@@ -111,6 +177,72 @@ func handleUserLoggedIn(msg *message.Message, emailNotifier service.Notifier) er
 	return emailNotifier.SendLoginNotification(user.Email, userName)
 }
 
+func handleEmailVerificationRequested(envelope *events.Envelope, emailNotifier service.Notifier) error {
+	verification, err := events.DecodeEmailVerificationRequested(envelope)
+	if err != nil {
+		return err
+	}
+
+	log.Printf("Sending email verification to: %s", verification.Email)
+
+	return emailNotifier.SendEmailVerification(verification)
+}
+
+func handlePasswordResetRequested(envelope *events.Envelope, emailNotifier service.Notifier) error {
+	reset, err := events.DecodePasswordResetRequested(envelope)
+	if err != nil {
+		return err
+	}
+
+	log.Printf("Sending password reset to: %s", reset.Email)
+
+	return emailNotifier.SendPasswordReset(reset)
+}
+
+func handleRefreshTokenReused(envelope *events.Envelope, emailNotifier service.Notifier) error {
+	reuse, err := events.DecodeRefreshTokenReused(envelope)
+	if err != nil {
+		return err
+	}
+
+	log.Printf("Sending refresh token reuse alert to: %s", reuse.Email)
+
+	return emailNotifier.SendRefreshTokenReuseAlert(reuse)
+}
+
+func handleAccountLocked(envelope *events.Envelope, emailNotifier service.Notifier) error {
+	lockout, err := events.DecodeAccountLocked(envelope)
+	if err != nil {
+		return err
+	}
+
+	log.Printf("Sending account lockout notice to: %s", lockout.Email)
+
+	return emailNotifier.SendAccountLocked(lockout)
+}
+
+func handleProductLowStock(envelope *events.Envelope, emailNotifier service.Notifier) error {
+	product, err := events.DecodeProductLowStock(envelope)
+	if err != nil {
+		return err
+	}
+
+	log.Printf("Sending low stock alert for: %s", product.SKU)
+
+	return emailNotifier.SendLowStockAlert(product)
+}
+
+func handleOrderEvent(envelope *events.Envelope, send func(order *events.OrderV1) error) error {
+	order, err := events.DecodeOrder(envelope)
+	if err != nil {
+		return err
+	}
+
+	log.Printf("Sending %s notification for order %d to: %s", envelope.Type, order.OrderId, order.Email)
+
+	return send(order)
+}
+
 func init() {
 	rootCmd.AddCommand(notifierCmd)
 }
@@ -0,0 +1,137 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"github.com/saleh-ghazimoradi/Cartopher/config"
+	"github.com/saleh-ghazimoradi/Cartopher/infra/cache"
+	"github.com/saleh-ghazimoradi/Cartopher/infra/events"
+	"github.com/saleh-ghazimoradi/Cartopher/infra/postgresql"
+	"github.com/saleh-ghazimoradi/Cartopher/internal/logger"
+	"github.com/saleh-ghazimoradi/Cartopher/internal/repository"
+	"github.com/saleh-ghazimoradi/Cartopher/internal/service"
+
+	"github.com/rs/zerolog"
+	"github.com/spf13/cobra"
+)
+
+const defaultOutboxPollInterval = time.Second
+
+// relayCmd represents the relay command
+var relayCmd = &cobra.Command{
+	Use:   "relay",
+	Short: "It forwards events from the outbox table to the message broker",
+	Run: func(cmd *cobra.Command, args []string) {
+		fmt.Println("relay called")
+
+		cfg, err := config.GetInstance()
+		if err != nil {
+			log.Fatalf("failed to get config: %v", err)
+		}
+
+		log := logger.NewLogger(cfg)
+
+		postDB := postgresql.NewPostgresql(
+			postgresql.WithHost(cfg.Postgresql.Host),
+			postgresql.WithPort(cfg.Postgresql.Port),
+			postgresql.WithUser(cfg.Postgresql.User),
+			postgresql.WithPassword(cfg.Postgresql.Password),
+			postgresql.WithName(cfg.Postgresql.Name),
+			postgresql.WithMaxOpenConn(cfg.Postgresql.MaxOpenConn),
+			postgresql.WithMaxIdleConn(cfg.Postgresql.MaxIdleConn),
+			postgresql.WithMaxIdleTime(cfg.Postgresql.MaxIdleTime),
+			postgresql.WithSSLMode(cfg.Postgresql.SSLMode),
+			postgresql.WithTimeout(cfg.Postgresql.Timeout),
+			postgresql.WithLogger(&log),
+		)
+
+		gormDB, sqlDB, err := postDB.Connect()
+		if err != nil {
+			log.Fatal().Err(err).Msg("Error connecting to database")
+		}
+
+		if events.InProcess(cfg) {
+			log.Fatal().Msgf("the %s transport runs the relay inside the run command", events.TransportGoChannel)
+		}
+
+		ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+		defer stop()
+
+		eventPublisher, err := events.NewWatermillEventPublisher(ctx, cfg, sqlDB)
+		if err != nil {
+			log.Fatal().Err(err).Msg("Error creating event publisher")
+		}
+		defer eventPublisher.Close()
+
+		// Live updates are best effort; relay without them if Redis is down.
+		var broadcaster events.Broadcaster
+		redisClient, err := cache.NewRedis(
+			cache.WithHost(cfg.Redis.Host),
+			cache.WithPort(cfg.Redis.Port),
+			cache.WithPassword(cfg.Redis.Password),
+			cache.WithDB(cfg.Redis.DB),
+		).Connect(ctx)
+		if err != nil {
+			log.Warn().Err(err).Msg("Error connecting to redis, live updates are disabled")
+		} else {
+			defer redisClient.Close()
+			broadcaster = events.NewRedisBroadcaster(redisClient)
+		}
+
+		outboxRepository := repository.NewOutboxRepository(gormDB, gormDB)
+		webhookRepository := repository.NewWebhookRepository(gormDB, gormDB)
+		outboxService := service.NewOutboxService(cfg, eventPublisher, broadcaster, outboxRepository, webhookRepository, gormDB)
+
+		runRelay(ctx, log, cfg, outboxService)
+	},
+}
+
+// runRelay forwards outbox messages until ctx is done.
+func runRelay(ctx context.Context, log zerolog.Logger, cfg *config.Config, outboxService service.OutboxService) {
+	pollInterval := cfg.Outbox.PollInterval
+	if pollInterval <= 0 {
+		pollInterval = defaultOutboxPollInterval
+	}
+
+	ticker := time.NewTicker(pollInterval)
+	defer ticker.Stop()
+
+	log.Info().Dur("poll_interval", pollInterval).Msg("outbox relay started")
+
+	for {
+		// Keep relaying while there is a backlog, then wait for the next tick.
+		for {
+			sent, err := outboxService.RelayPending(ctx)
+			if err != nil {
+				log.Error().Err(err).Msg("failed to relay outbox messages")
+				break
+			}
+			if sent == 0 {
+				break
+			}
+			log.Debug().Int("sent", sent).Msg("relayed outbox messages")
+		}
+
+		if cfg.Outbox.Retention > 0 {
+			if _, err := outboxService.DeleteSent(ctx, cfg.Outbox.Retention); err != nil {
+				log.Error().Err(err).Msg("failed to delete sent outbox messages")
+			}
+		}
+
+		select {
+		case <-ticker.C:
+		case <-ctx.Done():
+			log.Info().Msg("outbox relay shutting down")
+			return
+		}
+	}
+}
+
+func init() {
+	rootCmd.AddCommand(relayCmd)
+}
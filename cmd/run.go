@@ -25,6 +25,7 @@ import (
 	"github.com/saleh-ghazimoradi/Cartopher/internal/logger"
 	"github.com/saleh-ghazimoradi/Cartopher/internal/server"
 
+	"github.com/saleh-ghazimoradi/Cartopher/utils"
 	"github.com/spf13/cobra"
 )
 
@@ -53,6 +54,7 @@ var runCmd = &cobra.Command{
 
 		redisClient, err := redis.Connect(context.Background())
 		cacheService := cache.NewCache(redisClient)
+		tokenVersions := cache.NewTokenVersions(redisClient)
 
 		limiter := redis_rate.NewLimiter(redisClient)
 		rateLimiter := helper.NewRateLimiter(limiter)
@@ -71,8 +73,6 @@ var runCmd = &cobra.Command{
 			postgresql.WithLogger(&log),
 		)
 
-		ctx := context.Background()
-
 		gormDB, _, err := postDB.Connect()
 		if err != nil {
 			log.Fatal().Err(err).Msg("Error connecting to database")
@@ -85,12 +85,21 @@ var runCmd = &cobra.Command{
 			uploadProviders = uploadProvider.NewLocalUploadProvider(cfg.Upload.Path)
 		}
 
-		middleware := middlewares.NewMiddlewares(cfg, rateLimiter)
-		authenticationMiddleware := middlewares.NewAuthentication(cfg)
-		eventPublisher, err := events.NewWatermillEventPublisher(ctx, cfg)
+		// Invoices are only handed out by the authenticated invoice handler, so
+		// they are kept out of the public upload directory. Objects in S3 are
+		// private already.
+		invoiceProvider := uploadProviders
+		if cfg.Upload.UploadProviders != "s3" {
+			invoiceProvider = uploadProvider.NewLocalUploadProvider(cfg.Invoice.Path)
+		}
+
+		keys, err := utils.LoadKeySet(cfg)
 		if err != nil {
-			log.Error().Err(err).Msg("Error creating event publisher")
+			log.Fatal().Err(err).Msg("Error loading JWT keys")
 		}
+
+		middleware := middlewares.NewMiddlewares(cfg, rateLimiter)
+		authenticationMiddleware := middlewares.NewAuthentication(cfg, keys, tokenVersions)
 		healthHandler := handlers.NewHealthHandler()
 		healthRoutes := routes.NewHealthRoutes(healthHandler)
 
@@ -98,13 +107,62 @@ var runCmd = &cobra.Command{
 		cartRepository := repository.NewCartRepository(gormDB, gormDB)
 		productRepository := repository.NewProductRepository(gormDB, gormDB)
 		orderRepository := repository.NewOrderRepository(gormDB, gormDB)
-
-		authService := service.NewAuthService(cfg, eventPublisher, userRepository, cartRepository)
-		userService := service.NewUserService(userRepository)
-		productService := service.NewProductService(productRepository, cacheService)
+		inventoryRepository := repository.NewInventoryRepository(gormDB, gormDB)
+		shipmentRepository := repository.NewShipmentRepository(gormDB, gormDB)
+		returnRepository := repository.NewReturnRepository(gormDB, gormDB)
+		outboxRepository := repository.NewOutboxRepository(gormDB, gormDB)
+		webhookRepository := repository.NewWebhookRepository(gormDB, gormDB)
+		roleRepository := repository.NewRoleRepository(gormDB, gormDB)
+		auditRepository := repository.NewAuditRepository(gormDB, gormDB)
+
+		authService := service.NewAuthService(cfg, keys, userRepository, cartRepository, outboxRepository, roleRepository, tokenVersions, rateLimiter, cacheService, gormDB)
+		userService := service.NewUserService(userRepository, tokenVersions, gormDB)
+		productService := service.NewProductService(outboxRepository, productRepository, inventoryRepository, cacheService, gormDB)
 		uploadService := service.NewUploadService(uploadProviders)
-		cartService := service.NewCartService(cartRepository, productRepository)
-		orderService := service.NewOrderService(orderRepository, cartRepository, productRepository, gormDB)
+		cartService := service.NewCartService(outboxRepository, cartRepository, productRepository, gormDB)
+		orderService := service.NewOrderService(cfg, userRepository, outboxRepository, orderRepository, cartRepository, productRepository, inventoryRepository, cacheService, gormDB)
+		inventoryService := service.NewInventoryService(outboxRepository, inventoryRepository, productRepository, cacheService, gormDB)
+		shipmentService := service.NewShipmentService(outboxRepository, shipmentRepository, orderRepository, gormDB)
+		invoiceService := service.NewInvoiceService(cfg, orderRepository, invoiceProvider)
+		templateService := service.NewTemplateService(cfg)
+		returnService := service.NewReturnService(outboxRepository, returnRepository, orderRepository, inventoryRepository, cacheService, gormDB)
+		webhookService := service.NewWebhookService(cfg, webhookRepository, gormDB)
+		roleService := service.NewRoleService(roleRepository, userRepository, tokenVersions)
+		adminUserService := service.NewAdminUserService(authService, userRepository, roleRepository, auditRepository, tokenVersions, gormDB)
+		broadcaster := events.NewRedisBroadcaster(redisClient)
+		realtimeService := service.NewRealtimeService(broadcaster, orderService, cartService)
+
+		if events.InProcess(cfg) {
+			// The Go channel transport only reaches subscribers in this
+			// process, so the relay and the notifier run alongside the API,
+			// and so does the webhook dispatcher to keep local setups to a
+			// single process.
+			ctx := context.Background()
+
+			eventPublisher, err := events.NewWatermillEventPublisher(ctx, cfg, nil)
+			if err != nil {
+				log.Fatal().Err(err).Msg("Error creating event publisher")
+			}
+
+			subscriber, err := events.NewSubscriber(ctx, cfg, nil)
+			if err != nil {
+				log.Fatal().Err(err).Msg("Error creating event subscriber")
+			}
+
+			// Subscribe before the relay starts, the Go channel drops
+			// messages that have no subscriber yet.
+			messages, err := subscriber.Subscribe(ctx, events.Topic(cfg))
+			if err != nil {
+				log.Fatal().Err(err).Msg("Error subscribing to events")
+			}
+
+			outboxService := service.NewOutboxService(cfg, eventPublisher, broadcaster, outboxRepository, webhookRepository, gormDB)
+			deadLetterService := service.NewDeadLetterService(cfg, outboxRepository, repository.NewDeadLetterRepository(gormDB, gormDB), gormDB)
+
+			go runNotifier(ctx, messages, service.NewEmailNotifier(cfg), deadLetterService)
+			go runRelay(ctx, log, cfg, outboxService)
+			go runWebhooks(ctx, log, cfg, webhookService)
+		}
 
 		graphqlResolver := resolver.NewResolver(
 			resolver.WithAuthService(authService),
@@ -112,9 +170,15 @@ var runCmd = &cobra.Command{
 			resolver.WithProductService(productService),
 			resolver.WithOrderService(orderService),
 			resolver.WithCartService(cartService),
+			resolver.WithInventoryService(inventoryService),
+			resolver.WithShipmentService(shipmentService),
+			resolver.WithReturnService(returnService),
+			resolver.WithInvoiceService(invoiceService),
+			resolver.WithRealtimeService(realtimeService),
+			resolver.WithAdminUserService(adminUserService),
 		)
 
-		graphqlServer := server.NewGraphql(graphqlResolver)
+		graphqlServer := server.NewGraphql(graphqlResolver, authenticationMiddleware.WebsocketInit)
 		graphqlHandler := handlers.NewGraphQLHandler(graphqlServer.Connect())
 		graphqlRoutes := routes.NewGraphQLRoutes(graphqlHandler, authenticationMiddleware)
 
@@ -122,13 +186,29 @@ var runCmd = &cobra.Command{
 		userHandler := handlers.NewUserHandler(userService)
 		productHandler := handlers.NewProductHandler(productService, uploadService)
 		cartHandler := handlers.NewCartHandler(cartService)
-		orderHandler := handlers.NewOrderHandler(orderService)
-
-		authRoutes := routes.NewAuthRoutes(authHandler)
+		orderHandler := handlers.NewOrderHandler(orderService, realtimeService)
+		inventoryHandler := handlers.NewInventoryHandler(inventoryService)
+		shipmentHandler := handlers.NewShipmentHandler(shipmentService)
+		returnHandler := handlers.NewReturnHandler(returnService)
+		invoiceHandler := handlers.NewInvoiceHandler(invoiceService)
+		templateHandler := handlers.NewTemplateHandler(templateService)
+		webhookHandler := handlers.NewWebhookHandler(webhookService)
+		roleHandler := handlers.NewRoleHandler(roleService)
+		adminUserHandler := handlers.NewAdminUserHandler(adminUserService)
+
+		authRoutes := routes.NewAuthRoutes(authHandler, authenticationMiddleware)
 		userRoutes := routes.NewUserRoutes(userHandler, authenticationMiddleware)
 		productRoutes := routes.NewProductRoutes(productHandler, authenticationMiddleware)
 		cartRoutes := routes.NewCartRoutes(cartHandler, authenticationMiddleware)
 		orderRoutes := routes.NewOrderRoutes(orderHandler, authenticationMiddleware)
+		inventoryRoutes := routes.NewInventoryRoutes(inventoryHandler, authenticationMiddleware)
+		shipmentRoutes := routes.NewShipmentRoutes(shipmentHandler, authenticationMiddleware)
+		returnRoutes := routes.NewReturnRoutes(returnHandler, authenticationMiddleware)
+		invoiceRoutes := routes.NewInvoiceRoutes(invoiceHandler, authenticationMiddleware)
+		templateRoutes := routes.NewTemplateRoutes(templateHandler, authenticationMiddleware)
+		webhookRoutes := routes.NewWebhookRoutes(webhookHandler, authenticationMiddleware)
+		roleRoutes := routes.NewRoleRoutes(roleHandler, authenticationMiddleware)
+		adminUserRoutes := routes.NewAdminUserRoutes(adminUserHandler, authenticationMiddleware)
 		registerRoutes := routes.NewRegister(
 			routes.WithHealthRoute(healthRoutes),
 			routes.WithAuthRoute(authRoutes),
@@ -136,6 +216,14 @@ var runCmd = &cobra.Command{
 			routes.WithProductRoute(productRoutes),
 			routes.WithCartRoute(cartRoutes),
 			routes.WithOrderRoute(orderRoutes),
+			routes.WithInventoryRoute(inventoryRoutes),
+			routes.WithShipmentRoute(shipmentRoutes),
+			routes.WithReturnRoute(returnRoutes),
+			routes.WithInvoiceRoute(invoiceRoutes),
+			routes.WithTemplateRoute(templateRoutes),
+			routes.WithWebhookRoute(webhookRoutes),
+			routes.WithRoleRoute(roleRoutes),
+			routes.WithAdminUserRoute(adminUserRoutes),
 			routes.WithMiddlewares(middleware),
 			routes.WithGraphqlRoute(graphqlRoutes),
 		)
@@ -0,0 +1,108 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"github.com/saleh-ghazimoradi/Cartopher/config"
+	"github.com/saleh-ghazimoradi/Cartopher/infra/events"
+	"github.com/saleh-ghazimoradi/Cartopher/infra/postgresql"
+	"github.com/saleh-ghazimoradi/Cartopher/internal/logger"
+	"github.com/saleh-ghazimoradi/Cartopher/internal/repository"
+	"github.com/saleh-ghazimoradi/Cartopher/internal/service"
+
+	"github.com/rs/zerolog"
+	"github.com/spf13/cobra"
+)
+
+const defaultWebhooksPollInterval = 5 * time.Second
+
+// webhooksCmd represents the webhooks command
+var webhooksCmd = &cobra.Command{
+	Use:   "webhooks",
+	Short: "It delivers events to the merchant webhook endpoints",
+	Run: func(cmd *cobra.Command, args []string) {
+		fmt.Println("webhooks called")
+
+		cfg, err := config.GetInstance()
+		if err != nil {
+			log.Fatalf("failed to get config: %v", err)
+		}
+
+		log := logger.NewLogger(cfg)
+
+		if events.InProcess(cfg) {
+			log.Fatal().Msgf("the %s transport runs the webhook dispatcher inside the run command", events.TransportGoChannel)
+		}
+
+		postDB := postgresql.NewPostgresql(
+			postgresql.WithHost(cfg.Postgresql.Host),
+			postgresql.WithPort(cfg.Postgresql.Port),
+			postgresql.WithUser(cfg.Postgresql.User),
+			postgresql.WithPassword(cfg.Postgresql.Password),
+			postgresql.WithName(cfg.Postgresql.Name),
+			postgresql.WithMaxOpenConn(cfg.Postgresql.MaxOpenConn),
+			postgresql.WithMaxIdleConn(cfg.Postgresql.MaxIdleConn),
+			postgresql.WithMaxIdleTime(cfg.Postgresql.MaxIdleTime),
+			postgresql.WithSSLMode(cfg.Postgresql.SSLMode),
+			postgresql.WithTimeout(cfg.Postgresql.Timeout),
+			postgresql.WithLogger(&log),
+		)
+
+		gormDB, _, err := postDB.Connect()
+		if err != nil {
+			log.Fatal().Err(err).Msg("Error connecting to database")
+		}
+
+		ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+		defer stop()
+
+		webhookRepository := repository.NewWebhookRepository(gormDB, gormDB)
+		webhookService := service.NewWebhookService(cfg, webhookRepository, gormDB)
+
+		runWebhooks(ctx, log, cfg, webhookService)
+	},
+}
+
+// runWebhooks delivers due webhooks until ctx is done.
+func runWebhooks(ctx context.Context, log zerolog.Logger, cfg *config.Config, webhookService service.WebhookService) {
+	pollInterval := cfg.Webhooks.PollInterval
+	if pollInterval <= 0 {
+		pollInterval = defaultWebhooksPollInterval
+	}
+
+	ticker := time.NewTicker(pollInterval)
+	defer ticker.Stop()
+
+	log.Info().Dur("poll_interval", pollInterval).Msg("webhook dispatcher started")
+
+	for {
+		// Keep delivering while there is a backlog, then wait for the next tick.
+		for {
+			delivered, err := webhookService.DeliverPending(ctx)
+			if err != nil {
+				log.Error().Err(err).Msg("failed to deliver webhooks")
+				break
+			}
+			if delivered == 0 {
+				break
+			}
+			log.Debug().Int("delivered", delivered).Msg("delivered webhooks")
+		}
+
+		select {
+		case <-ticker.C:
+		case <-ctx.Done():
+			log.Info().Msg("webhook dispatcher shutting down")
+			return
+		}
+	}
+}
+
+func init() {
+	rootCmd.AddCommand(webhooksCmd)
+}
@@ -17,10 +17,18 @@ type Config struct {
 	Server     Server
 	Postgresql Postgresql
 	JWT        JWT
+	Auth       Auth
 	AWS        AWS
 	Upload     Upload
 	SMTP       SMTP
 	Redis      Redis
+	Fulfilment Fulfilment
+	Notifier   Notifier
+	Invoice    Invoice
+	Outbox     Outbox
+	Events     Events
+	Webhooks   Webhooks
+	OIDC       OIDC
 }
 
 type Server struct {
@@ -55,10 +63,28 @@ type Postgresql struct {
 
 type JWT struct {
 	Secret              string        `env:"JWT_SECRET"`
+	KeyFiles            []string      `env:"JWT_KEY_FILES" envSeparator:","`
+	Issuer              string        `env:"JWT_ISSUER"`
+	Audience            string        `env:"JWT_AUDIENCE"`
 	ExpiresIn           time.Duration `env:"JWT_EXPIRES_IN"`
 	RefreshTokenExpires time.Duration `env:"JWT_REFRESH_TOKEN_EXPIRES"`
 }
 
+type Auth struct {
+	RequireVerifiedEmail       string        `env:"AUTH_REQUIRE_VERIFIED_EMAIL"`
+	VerificationTokenExpires   time.Duration `env:"AUTH_VERIFICATION_TOKEN_EXPIRES"`
+	VerificationResendInterval time.Duration `env:"AUTH_VERIFICATION_RESEND_INTERVAL"`
+	VerificationURL            string        `env:"AUTH_VERIFICATION_URL"`
+	PasswordResetTokenExpires  time.Duration `env:"AUTH_PASSWORD_RESET_TOKEN_EXPIRES"`
+	PasswordResetURL           string        `env:"AUTH_PASSWORD_RESET_URL"`
+	TwoFactorIssuer            string        `env:"AUTH_TWO_FACTOR_ISSUER"`
+	TwoFactorChallengeExpires  time.Duration `env:"AUTH_TWO_FACTOR_CHALLENGE_EXPIRES"`
+	RequireAdminTwoFactor      bool          `env:"AUTH_REQUIRE_ADMIN_TWO_FACTOR"`
+	LoginMaxAttempts           int           `env:"AUTH_LOGIN_MAX_ATTEMPTS"`
+	LoginIPMaxAttempts         int           `env:"AUTH_LOGIN_IP_MAX_ATTEMPTS"`
+	LoginLockout               time.Duration `env:"AUTH_LOGIN_LOCKOUT"`
+}
+
 type AWS struct {
 	Region          string `env:"AWS_REGION"`
 	AccessKeyId     string `env:"AWS_ACCESS_KEY_ID"`
@@ -82,6 +108,69 @@ type SMTP struct {
 	From     string `env:"SMTP_FROM"`
 }
 
+type Fulfilment struct {
+	Strategy string `env:"FULFILMENT_STRATEGY"`
+}
+
+// Notifier configures notification emails. Locale is the one language all
+// of them are rendered in; emails are not localised per recipient.
+type Notifier struct {
+	AdminEmails  []string      `env:"NOTIFIER_ADMIN_EMAILS" envSeparator:","`
+	Locale       string        `env:"NOTIFIER_LOCALE"`
+	TemplatesDir string        `env:"NOTIFIER_TEMPLATES_DIR"`
+	MaxAttempts  int           `env:"NOTIFIER_MAX_ATTEMPTS"`
+	RetryBackoff time.Duration `env:"NOTIFIER_RETRY_BACKOFF"`
+	MaxBackoff   time.Duration `env:"NOTIFIER_MAX_BACKOFF"`
+}
+
+// Invoice configures invoice documents. Path is the directory invoices are
+// stored in when uploads are kept locally; unlike UPLOAD_PATH it must not be
+// served publicly.
+type Invoice struct {
+	Prefix         string `env:"INVOICE_PREFIX"`
+	CompanyName    string `env:"INVOICE_COMPANY_NAME"`
+	CompanyAddress string `env:"INVOICE_COMPANY_ADDRESS"`
+	Path           string `env:"INVOICE_PATH"`
+}
+
+type Outbox struct {
+	PollInterval time.Duration `env:"OUTBOX_POLL_INTERVAL"`
+	BatchSize    int           `env:"OUTBOX_BATCH_SIZE"`
+	MaxBackoff   time.Duration `env:"OUTBOX_MAX_BACKOFF"`
+	Retention    time.Duration `env:"OUTBOX_RETENTION"`
+}
+
+type Events struct {
+	Transport string `env:"EVENTS_TRANSPORT"`
+	Topic     string `env:"EVENTS_TOPIC"`
+}
+
+type Webhooks struct {
+	PollInterval time.Duration `env:"WEBHOOKS_POLL_INTERVAL"`
+	BatchSize    int           `env:"WEBHOOKS_BATCH_SIZE"`
+	Timeout      time.Duration `env:"WEBHOOKS_TIMEOUT"`
+	MaxAttempts  int           `env:"WEBHOOKS_MAX_ATTEMPTS"`
+	RetryBackoff time.Duration `env:"WEBHOOKS_RETRY_BACKOFF"`
+	MaxBackoff   time.Duration `env:"WEBHOOKS_MAX_BACKOFF"`
+}
+
+// OIDC configures login through OpenID Connect providers. Each provider
+// is configured with OIDC_PROVIDERS_<n>_ variables, numbered from 0, e.g.
+// OIDC_PROVIDERS_0_NAME=google and OIDC_PROVIDERS_0_ISSUER.
+type OIDC struct {
+	StateExpires time.Duration  `env:"OIDC_STATE_EXPIRES"`
+	Providers    []OIDCProvider `envPrefix:"OIDC_PROVIDERS"`
+}
+
+type OIDCProvider struct {
+	Name         string   `env:"NAME"`
+	Issuer       string   `env:"ISSUER"`
+	ClientId     string   `env:"CLIENT_ID"`
+	ClientSecret string   `env:"CLIENT_SECRET"`
+	RedirectURL  string   `env:"REDIRECT_URL"`
+	Scopes       []string `env:"SCOPES" envSeparator:","`
+}
+
 func GetInstance() (*Config, error) {
 	once.Do(func() {
 		instance = &Config{}
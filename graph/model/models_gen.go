@@ -6,6 +6,20 @@ import (
 	"github.com/saleh-ghazimoradi/Cartopher/internal/dto"
 )
 
+type AuditLogConnection struct {
+	Edges    []*AuditLogEdge `json:"edges"`
+	PageInfo *PageInfo       `json:"pageInfo"`
+}
+
+type AuditLogDetail struct {
+	Key   string `json:"key"`
+	Value string `json:"value"`
+}
+
+type AuditLogEdge struct {
+	Node *dto.AuditLogResponse `json:"node"`
+}
+
 type Mutation struct {
 }
 
@@ -36,3 +50,15 @@ type ProductEdge struct {
 
 type Query struct {
 }
+
+type Subscription struct {
+}
+
+type UserConnection struct {
+	Edges    []*UserEdge `json:"edges"`
+	PageInfo *PageInfo   `json:"pageInfo"`
+}
+
+type UserEdge struct {
+	Node *dto.UserResponse `json:"node"`
+}
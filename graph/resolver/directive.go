@@ -0,0 +1,25 @@
+package resolver
+
+import (
+	"context"
+	"errors"
+
+	"github.com/99designs/gqlgen/graphql"
+	"github.com/saleh-ghazimoradi/Cartopher/internal/domain"
+)
+
+var ErrForbidden = errors.New("forbidden")
+
+// HasPermission implements the @hasPermission directive, resolving the
+// field only when the token of the request grants permission.
+func HasPermission(ctx context.Context, obj any, next graphql.Resolver, permission string) (any, error) {
+	if _, err := GetUserIdFromContext(ctx); err != nil {
+		return nil, err
+	}
+
+	if !HasPermissionFromContext(ctx, domain.Permission(permission)) {
+		return nil, ErrForbidden
+	}
+
+	return next(ctx)
+}
@@ -3,11 +3,10 @@ package resolver
 import (
 	"context"
 	"errors"
-	"github.com/saleh-ghazimoradi/Cartopher/utils"
-)
+	"slices"
 
-const (
-	adminRole = "admin"
+	"github.com/saleh-ghazimoradi/Cartopher/internal/domain"
+	"github.com/saleh-ghazimoradi/Cartopher/utils"
 )
 
 var (
@@ -64,11 +63,9 @@ func getPagingNumbers(page, limit *int) (int, int) {
 	return p, l
 }
 
-func IsAdminFromContext(ctx context.Context) bool {
-	role, err := GetUserRoleFromContext(ctx)
-	if err != nil {
-		return false
-	}
-
-	return role == adminRole
+// HasPermissionFromContext reports whether the token of the request grants
+// permission.
+func HasPermissionFromContext(ctx context.Context, permission domain.Permission) bool {
+	permissions, _ := ctx.Value(utils.UserPermissionsKey).([]string)
+	return slices.Contains(permissions, string(permission))
 }
@@ -6,11 +6,17 @@ import (
 )
 
 type Resolver struct {
-	authService    service.AuthService
-	userService    service.UserService
-	cartService    service.CartService
-	orderService   service.OrderService
-	productService service.ProductService
+	authService      service.AuthService
+	userService      service.UserService
+	cartService      service.CartService
+	orderService     service.OrderService
+	productService   service.ProductService
+	inventoryService service.InventoryService
+	shipmentService  service.ShipmentService
+	returnService    service.ReturnService
+	invoiceService   service.InvoiceService
+	realtimeService  service.RealtimeService
+	adminUserService service.AdminUserService
 }
 
 type Options func(*Resolver)
@@ -45,11 +51,47 @@ func WithProductService(productService service.ProductService) Options {
 	}
 }
 
+func WithInventoryService(inventoryService service.InventoryService) Options {
+	return func(r *Resolver) {
+		r.inventoryService = inventoryService
+	}
+}
+
+func WithShipmentService(shipmentService service.ShipmentService) Options {
+	return func(r *Resolver) {
+		r.shipmentService = shipmentService
+	}
+}
+
+func WithReturnService(returnService service.ReturnService) Options {
+	return func(r *Resolver) {
+		r.returnService = returnService
+	}
+}
+
+func WithInvoiceService(invoiceService service.InvoiceService) Options {
+	return func(r *Resolver) {
+		r.invoiceService = invoiceService
+	}
+}
+
+func WithRealtimeService(realtimeService service.RealtimeService) Options {
+	return func(r *Resolver) {
+		r.realtimeService = realtimeService
+	}
+}
+
 func (r *Resolver) parseId(id string) (uint, error) {
 	parsed, err := strconv.ParseUint(id, 10, 32)
 	return uint(parsed), err
 }
 
+func WithAdminUserService(adminUserService service.AdminUserService) Options {
+	return func(r *Resolver) {
+		r.adminUserService = adminUserService
+	}
+}
+
 func NewResolver(options ...Options) *Resolver {
 	resolver := &Resolver{}
 	for _, option := range options {
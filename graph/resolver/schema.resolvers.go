@@ -45,6 +45,107 @@ func (r *mutationResolver) Logout(ctx context.Context, input dto.RefreshTokenReq
 	return true, nil
 }
 
+// VerifyEmail is the resolver for the verifyEmail field.
+func (r *mutationResolver) VerifyEmail(ctx context.Context, input dto.VerifyEmailRequest) (*dto.UserResponse, error) {
+	user, err := r.authService.VerifyEmail(ctx, &input)
+	if err != nil {
+		return nil, fmt.Errorf("verify email failed: %w", err)
+	}
+	return user, nil
+}
+
+// ResendVerification is the resolver for the resendVerification field.
+func (r *mutationResolver) ResendVerification(ctx context.Context, input dto.ResendVerificationRequest) (bool, error) {
+	if err := r.authService.ResendVerification(ctx, &input); err != nil {
+		return false, fmt.Errorf("resend verification failed: %w", err)
+	}
+	return true, nil
+}
+
+// ForgotPassword is the resolver for the forgotPassword field.
+func (r *mutationResolver) ForgotPassword(ctx context.Context, input dto.ForgotPasswordRequest) (bool, error) {
+	if err := r.authService.ForgotPassword(ctx, &input); err != nil {
+		return false, fmt.Errorf("forgot password failed: %w", err)
+	}
+	return true, nil
+}
+
+// ResetPassword is the resolver for the resetPassword field.
+func (r *mutationResolver) ResetPassword(ctx context.Context, input dto.ResetPasswordRequest) (bool, error) {
+	if err := r.authService.ResetPassword(ctx, &input); err != nil {
+		return false, fmt.Errorf("reset password failed: %w", err)
+	}
+	return true, nil
+}
+
+// VerifyTwoFactor is the resolver for the verifyTwoFactor field.
+func (r *mutationResolver) VerifyTwoFactor(ctx context.Context, input dto.TwoFactorLoginRequest) (*dto.AuthResponse, error) {
+	response, err := r.authService.VerifyTwoFactor(ctx, &input)
+	if err != nil {
+		return nil, fmt.Errorf("two-factor verification failed: %w", err)
+	}
+	return response, nil
+}
+
+// AuthorizeOidc is the resolver for the authorizeOIDC field.
+func (r *mutationResolver) AuthorizeOidc(ctx context.Context, provider string) (*dto.OIDCAuthorizationResponse, error) {
+	authorization, err := r.authService.AuthorizeOIDC(ctx, provider)
+	if err != nil {
+		return nil, fmt.Errorf("authorize failed: %w", err)
+	}
+	return authorization, nil
+}
+
+// LoginWithOidc is the resolver for the loginWithOIDC field.
+func (r *mutationResolver) LoginWithOidc(ctx context.Context, provider string, input dto.OIDCLoginRequest) (*dto.AuthResponse, error) {
+	response, err := r.authService.LoginWithOIDC(ctx, provider, &input)
+	if err != nil {
+		return nil, fmt.Errorf("login failed: %w", err)
+	}
+	return response, nil
+}
+
+// EnrolTwoFactor is the resolver for the enrolTwoFactor field.
+func (r *mutationResolver) EnrolTwoFactor(ctx context.Context) (*dto.TwoFactorEnrolmentResponse, error) {
+	userId, err := GetUserIdFromContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	enrolment, err := r.authService.EnrolTwoFactor(ctx, userId)
+	if err != nil {
+		return nil, fmt.Errorf("failed to start two-factor enrolment: %w", err)
+	}
+	return enrolment, nil
+}
+
+// ConfirmTwoFactor is the resolver for the confirmTwoFactor field.
+func (r *mutationResolver) ConfirmTwoFactor(ctx context.Context, input dto.TwoFactorCodeRequest) (*dto.RecoveryCodesResponse, error) {
+	userId, err := GetUserIdFromContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	recoveryCodes, err := r.authService.ConfirmTwoFactor(ctx, userId, &input)
+	if err != nil {
+		return nil, fmt.Errorf("failed to confirm two-factor enrolment: %w", err)
+	}
+	return recoveryCodes, nil
+}
+
+// DisableTwoFactor is the resolver for the disableTwoFactor field.
+func (r *mutationResolver) DisableTwoFactor(ctx context.Context, input dto.TwoFactorCodeRequest) (bool, error) {
+	userId, err := GetUserIdFromContext(ctx)
+	if err != nil {
+		return false, err
+	}
+
+	if err := r.authService.DisableTwoFactor(ctx, userId, &input); err != nil {
+		return false, fmt.Errorf("failed to disable two-factor authentication: %w", err)
+	}
+	return true, nil
+}
+
 // UpdateProfile is the resolver for the updateProfile field.
 func (r *mutationResolver) UpdateProfile(ctx context.Context, input dto.UpdateProfileRequest) (*dto.UserResponse, error) {
 	userId, err := GetUserIdFromContext(ctx)
@@ -59,12 +160,21 @@ func (r *mutationResolver) UpdateProfile(ctx context.Context, input dto.UpdatePr
 	return user, nil
 }
 
-// CreateCategory is the resolver for the createCategory field.
-func (r *mutationResolver) CreateCategory(ctx context.Context, input dto.CreateCategoryRequest) (*dto.CategoryResponse, error) {
-	if !IsAdminFromContext(ctx) {
-		return nil, ErrUnauthorized
+// ChangePassword is the resolver for the changePassword field.
+func (r *mutationResolver) ChangePassword(ctx context.Context, input dto.ChangePasswordRequest) (bool, error) {
+	userId, err := GetUserIdFromContext(ctx)
+	if err != nil {
+		return false, err
 	}
 
+	if err := r.userService.ChangePassword(ctx, userId, &input); err != nil {
+		return false, fmt.Errorf("failed to change password: %w", err)
+	}
+	return true, nil
+}
+
+// CreateCategory is the resolver for the createCategory field.
+func (r *mutationResolver) CreateCategory(ctx context.Context, input dto.CreateCategoryRequest) (*dto.CategoryResponse, error) {
 	category, err := r.productService.CreateCategory(ctx, &input)
 	if err != nil {
 		return nil, fmt.Errorf("failed to create category: %w", err)
@@ -75,10 +185,6 @@ func (r *mutationResolver) CreateCategory(ctx context.Context, input dto.CreateC
 
 // UpdateCategory is the resolver for the updateCategory field.
 func (r *mutationResolver) UpdateCategory(ctx context.Context, id string, input dto.UpdateCategoryRequest) (*dto.CategoryResponse, error) {
-	if !IsAdminFromContext(ctx) {
-		return nil, ErrUnauthorized
-	}
-
 	categoryId, err := r.parseId(id)
 	if err != nil {
 		return nil, fmt.Errorf("failed to parse category id: %w", err)
@@ -94,10 +200,6 @@ func (r *mutationResolver) UpdateCategory(ctx context.Context, id string, input
 
 // DeleteCategory is the resolver for the deleteCategory field.
 func (r *mutationResolver) DeleteCategory(ctx context.Context, id string) (bool, error) {
-	if !IsAdminFromContext(ctx) {
-		return false, ErrUnauthorized
-	}
-
 	categoryId, err := r.parseId(id)
 	if err != nil {
 		return false, fmt.Errorf("failed to parse category id: %w", err)
@@ -112,10 +214,6 @@ func (r *mutationResolver) DeleteCategory(ctx context.Context, id string) (bool,
 
 // CreateProduct is the resolver for the createProduct field.
 func (r *mutationResolver) CreateProduct(ctx context.Context, input dto.CreateProductRequest) (*dto.ProductResponse, error) {
-	if !IsAdminFromContext(ctx) {
-		return nil, ErrUnauthorized
-	}
-
 	product, err := r.productService.CreateProduct(ctx, &input)
 	if err != nil {
 		return nil, fmt.Errorf("failed to create product: %w", err)
@@ -126,10 +224,6 @@ func (r *mutationResolver) CreateProduct(ctx context.Context, input dto.CreatePr
 
 // UpdateProduct is the resolver for the updateProduct field.
 func (r *mutationResolver) UpdateProduct(ctx context.Context, id string, input dto.UpdateProductRequest) (*dto.ProductResponse, error) {
-	if !IsAdminFromContext(ctx) {
-		return nil, ErrUnauthorized
-	}
-
 	productId, err := r.parseId(id)
 	if err != nil {
 		return nil, fmt.Errorf("failed to parse product id: %w", err)
@@ -145,10 +239,6 @@ func (r *mutationResolver) UpdateProduct(ctx context.Context, id string, input d
 
 // DeleteProduct is the resolver for the deleteProduct field.
 func (r *mutationResolver) DeleteProduct(ctx context.Context, id string) (bool, error) {
-	if !IsAdminFromContext(ctx) {
-		return false, ErrUnauthorized
-	}
-
 	productId, err := r.parseId(id)
 	if err != nil {
 		return false, fmt.Errorf("failed to parse product id: %w", err)
@@ -216,13 +306,13 @@ func (r *mutationResolver) RemoveFromCart(ctx context.Context, id string) (bool,
 }
 
 // CreateOrder is the resolver for the createOrder field.
-func (r *mutationResolver) CreateOrder(ctx context.Context) (*dto.OrderResponse, error) {
+func (r *mutationResolver) CreateOrder(ctx context.Context, input *dto.CreateOrderRequest) (*dto.OrderResponse, error) {
 	userId, err := GetUserIdFromContext(ctx)
 	if err != nil {
 		return nil, ErrUnauthorized
 	}
 
-	order, err := r.orderService.CreateOrder(ctx, userId)
+	order, err := r.orderService.CreateOrder(ctx, userId, input)
 	if err != nil {
 		return nil, fmt.Errorf("failed to create order: %w", err)
 	}
@@ -230,6 +320,352 @@ func (r *mutationResolver) CreateOrder(ctx context.Context) (*dto.OrderResponse,
 	return order, nil
 }
 
+// ConfirmOrder is the resolver for the confirmOrder field.
+func (r *mutationResolver) ConfirmOrder(ctx context.Context, id string) (*dto.OrderResponse, error) {
+	orderId, err := r.parseId(id)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse order id: %w", err)
+	}
+
+	order, err := r.orderService.ConfirmOrder(ctx, orderId)
+	if err != nil {
+		return nil, fmt.Errorf("failed to confirm order: %w", err)
+	}
+
+	return order, nil
+}
+
+// CancelOrder is the resolver for the cancelOrder field.
+func (r *mutationResolver) CancelOrder(ctx context.Context, id string) (*dto.OrderResponse, error) {
+	userId, err := GetUserIdFromContext(ctx)
+	if err != nil {
+		return nil, ErrUnauthorized
+	}
+
+	orderId, err := r.parseId(id)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse order id: %w", err)
+	}
+
+	order, err := r.orderService.CancelOrder(ctx, userId, orderId)
+	if err != nil {
+		return nil, fmt.Errorf("failed to cancel order: %w", err)
+	}
+
+	return order, nil
+}
+
+// RegenerateInvoice is the resolver for the regenerateInvoice field.
+func (r *mutationResolver) RegenerateInvoice(ctx context.Context, orderID string) (*dto.InvoiceResponse, error) {
+	orderId, err := r.parseId(orderID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse order id: %w", err)
+	}
+
+	invoice, err := r.invoiceService.RegenerateInvoice(ctx, orderId)
+	if err != nil {
+		return nil, fmt.Errorf("failed to regenerate invoice: %w", err)
+	}
+
+	return invoice, nil
+}
+
+// CreateWarehouse is the resolver for the createWarehouse field.
+func (r *mutationResolver) CreateWarehouse(ctx context.Context, input dto.CreateWarehouseRequest) (*dto.WarehouseResponse, error) {
+	warehouse, err := r.inventoryService.CreateWarehouse(ctx, &input)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create warehouse: %w", err)
+	}
+
+	return warehouse, nil
+}
+
+// UpdateWarehouse is the resolver for the updateWarehouse field.
+func (r *mutationResolver) UpdateWarehouse(ctx context.Context, id string, input dto.UpdateWarehouseRequest) (*dto.WarehouseResponse, error) {
+	warehouseId, err := r.parseId(id)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse warehouse id: %w", err)
+	}
+
+	warehouse, err := r.inventoryService.UpdateWarehouse(ctx, warehouseId, &input)
+	if err != nil {
+		return nil, fmt.Errorf("failed to update warehouse: %w", err)
+	}
+
+	return warehouse, nil
+}
+
+// DeleteWarehouse is the resolver for the deleteWarehouse field.
+func (r *mutationResolver) DeleteWarehouse(ctx context.Context, id string) (bool, error) {
+	warehouseId, err := r.parseId(id)
+	if err != nil {
+		return false, fmt.Errorf("failed to parse warehouse id: %w", err)
+	}
+
+	if err := r.inventoryService.DeleteWarehouse(ctx, warehouseId); err != nil {
+		return false, fmt.Errorf("failed to delete warehouse: %w", err)
+	}
+
+	return true, nil
+}
+
+// SetStockLevel is the resolver for the setStockLevel field.
+func (r *mutationResolver) SetStockLevel(ctx context.Context, productID string, input dto.SetStockLevelRequest) ([]*dto.StockLevelResponse, error) {
+	productId, err := r.parseId(productID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse product id: %w", err)
+	}
+
+	stock, err := r.inventoryService.SetStockLevel(ctx, productId, &input)
+	if err != nil {
+		return nil, fmt.Errorf("failed to set stock level: %w", err)
+	}
+
+	return stock, nil
+}
+
+// CreateShipment is the resolver for the createShipment field.
+func (r *mutationResolver) CreateShipment(ctx context.Context, orderID string, input dto.CreateShipmentRequest) (*dto.ShipmentResponse, error) {
+	orderId, err := r.parseId(orderID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse order id: %w", err)
+	}
+
+	shipment, err := r.shipmentService.CreateShipment(ctx, orderId, &input)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create shipment: %w", err)
+	}
+
+	return shipment, nil
+}
+
+// MarkShipmentDelivered is the resolver for the markShipmentDelivered field.
+func (r *mutationResolver) MarkShipmentDelivered(ctx context.Context, id string) (*dto.ShipmentResponse, error) {
+	shipmentId, err := r.parseId(id)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse shipment id: %w", err)
+	}
+
+	shipment, err := r.shipmentService.MarkDelivered(ctx, shipmentId)
+	if err != nil {
+		return nil, fmt.Errorf("failed to mark shipment delivered: %w", err)
+	}
+
+	return shipment, nil
+}
+
+// RequestReturn is the resolver for the requestReturn field.
+func (r *mutationResolver) RequestReturn(ctx context.Context, orderID string, input dto.CreateReturnRequest) (*dto.ReturnResponse, error) {
+	userId, err := GetUserIdFromContext(ctx)
+	if err != nil {
+		return nil, ErrUnauthorized
+	}
+
+	orderId, err := r.parseId(orderID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse order id: %w", err)
+	}
+
+	returnRequest, err := r.returnService.CreateReturn(ctx, userId, orderId, &input)
+	if err != nil {
+		return nil, fmt.Errorf("failed to request return: %w", err)
+	}
+
+	return returnRequest, nil
+}
+
+// ApproveReturn is the resolver for the approveReturn field.
+func (r *mutationResolver) ApproveReturn(ctx context.Context, id string, note *string) (*dto.ReturnResponse, error) {
+	returnId, err := r.parseId(id)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse return id: %w", err)
+	}
+
+	req := &dto.ReviewReturnRequest{}
+	if note != nil {
+		req.Note = *note
+	}
+
+	returnRequest, err := r.returnService.ApproveReturn(ctx, returnId, req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to approve return: %w", err)
+	}
+
+	return returnRequest, nil
+}
+
+// RejectReturn is the resolver for the rejectReturn field.
+func (r *mutationResolver) RejectReturn(ctx context.Context, id string, note *string) (*dto.ReturnResponse, error) {
+	returnId, err := r.parseId(id)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse return id: %w", err)
+	}
+
+	req := &dto.ReviewReturnRequest{}
+	if note != nil {
+		req.Note = *note
+	}
+
+	returnRequest, err := r.returnService.RejectReturn(ctx, returnId, req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to reject return: %w", err)
+	}
+
+	return returnRequest, nil
+}
+
+// ReceiveReturn is the resolver for the receiveReturn field.
+func (r *mutationResolver) ReceiveReturn(ctx context.Context, id string) (*dto.ReturnResponse, error) {
+	returnId, err := r.parseId(id)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse return id: %w", err)
+	}
+
+	returnRequest, err := r.returnService.ReceiveReturn(ctx, returnId)
+	if err != nil {
+		return nil, fmt.Errorf("failed to receive return: %w", err)
+	}
+
+	return returnRequest, nil
+}
+
+// RefundReturn is the resolver for the refundReturn field.
+func (r *mutationResolver) RefundReturn(ctx context.Context, id string, reference string) (*dto.ReturnResponse, error) {
+	returnId, err := r.parseId(id)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse return id: %w", err)
+	}
+
+	returnRequest, err := r.returnService.RefundReturn(ctx, returnId, &dto.RefundReturnRequest{Reference: reference})
+	if err != nil {
+		return nil, fmt.Errorf("failed to refund return: %w", err)
+	}
+
+	return returnRequest, nil
+}
+
+// DeactivateUser is the resolver for the deactivateUser field.
+func (r *mutationResolver) DeactivateUser(ctx context.Context, id string, reason *string) (*dto.UserResponse, error) {
+	actorId, err := GetUserIdFromContext(ctx)
+	if err != nil {
+		return nil, ErrUnauthorized
+	}
+
+	userId, err := r.parseId(id)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse user id: %w", err)
+	}
+
+	req := &dto.DeactivateUserRequest{}
+	if reason != nil {
+		req.Reason = *reason
+	}
+
+	user, err := r.adminUserService.DeactivateUser(ctx, actorId, userId, req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to deactivate user: %w", err)
+	}
+
+	return user, nil
+}
+
+// ReactivateUser is the resolver for the reactivateUser field.
+func (r *mutationResolver) ReactivateUser(ctx context.Context, id string) (*dto.UserResponse, error) {
+	actorId, err := GetUserIdFromContext(ctx)
+	if err != nil {
+		return nil, ErrUnauthorized
+	}
+
+	userId, err := r.parseId(id)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse user id: %w", err)
+	}
+
+	user, err := r.adminUserService.ReactivateUser(ctx, actorId, userId)
+	if err != nil {
+		return nil, fmt.Errorf("failed to reactivate user: %w", err)
+	}
+
+	return user, nil
+}
+
+// ChangeUserRole is the resolver for the changeUserRole field.
+func (r *mutationResolver) ChangeUserRole(ctx context.Context, id string, role string) (*dto.UserResponse, error) {
+	actorId, err := GetUserIdFromContext(ctx)
+	if err != nil {
+		return nil, ErrUnauthorized
+	}
+
+	userId, err := r.parseId(id)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse user id: %w", err)
+	}
+
+	user, err := r.adminUserService.ChangeRole(ctx, actorId, userId, &dto.AssignRoleRequest{Role: role})
+	if err != nil {
+		return nil, fmt.Errorf("failed to change role: %w", err)
+	}
+
+	return user, nil
+}
+
+// DeleteUser is the resolver for the deleteUser field.
+func (r *mutationResolver) DeleteUser(ctx context.Context, id string) (bool, error) {
+	actorId, err := GetUserIdFromContext(ctx)
+	if err != nil {
+		return false, ErrUnauthorized
+	}
+
+	userId, err := r.parseId(id)
+	if err != nil {
+		return false, fmt.Errorf("failed to parse user id: %w", err)
+	}
+
+	if err := r.adminUserService.DeleteUser(ctx, actorId, userId); err != nil {
+		return false, fmt.Errorf("failed to delete user: %w", err)
+	}
+
+	return true, nil
+}
+
+// RevokeUserSessions is the resolver for the revokeUserSessions field.
+func (r *mutationResolver) RevokeUserSessions(ctx context.Context, id string) (bool, error) {
+	actorId, err := GetUserIdFromContext(ctx)
+	if err != nil {
+		return false, ErrUnauthorized
+	}
+
+	userId, err := r.parseId(id)
+	if err != nil {
+		return false, fmt.Errorf("failed to parse user id: %w", err)
+	}
+
+	if err := r.adminUserService.RevokeSessions(ctx, actorId, userId); err != nil {
+		return false, fmt.Errorf("failed to revoke sessions: %w", err)
+	}
+
+	return true, nil
+}
+
+// UnlockUser is the resolver for the unlockUser field.
+func (r *mutationResolver) UnlockUser(ctx context.Context, id string) (bool, error) {
+	actorId, err := GetUserIdFromContext(ctx)
+	if err != nil {
+		return false, ErrUnauthorized
+	}
+
+	userId, err := r.parseId(id)
+	if err != nil {
+		return false, fmt.Errorf("failed to parse user id: %w", err)
+	}
+
+	if err := r.adminUserService.UnlockUser(ctx, actorId, userId); err != nil {
+		return false, fmt.Errorf("failed to unlock user: %w", err)
+	}
+
+	return true, nil
+}
+
 // Me is the resolver for the me field.
 func (r *queryResolver) Me(ctx context.Context) (*dto.UserResponse, error) {
 	userId, err := GetUserIdFromContext(ctx)
@@ -245,6 +681,11 @@ func (r *queryResolver) Me(ctx context.Context) (*dto.UserResponse, error) {
 	return user, nil
 }
 
+// OidcProviders is the resolver for the oidcProviders field.
+func (r *queryResolver) OidcProviders(ctx context.Context) ([]string, error) {
+	return r.authService.OIDCProviders(), nil
+}
+
 // Products is the resolver for the products field.
 func (r *queryResolver) Products(ctx context.Context, page *int, limit *int) (*model.ProductConnection, error) {
 	p, l := getPagingNumbers(page, limit)
@@ -369,11 +810,213 @@ func (r *queryResolver) Order(ctx context.Context, id string) (*dto.OrderRespons
 	return order, nil
 }
 
+// Warehouses is the resolver for the warehouses field.
+func (r *queryResolver) Warehouses(ctx context.Context) ([]*dto.WarehouseResponse, error) {
+	warehouses, err := r.inventoryService.GetWarehouses(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch warehouses: %w", err)
+	}
+
+	return warehouses, nil
+}
+
+// ProductStock is the resolver for the productStock field.
+func (r *queryResolver) ProductStock(ctx context.Context, productID string) ([]*dto.StockLevelResponse, error) {
+	productId, err := r.parseId(productID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse product id: %w", err)
+	}
+
+	stock, err := r.inventoryService.GetProductStock(ctx, productId)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch product stock: %w", err)
+	}
+
+	return stock, nil
+}
+
+// LowStockProducts is the resolver for the lowStockProducts field.
+func (r *queryResolver) LowStockProducts(ctx context.Context) ([]*dto.LowStockProductResponse, error) {
+	products, err := r.inventoryService.GetLowStockProducts(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch low-stock products: %w", err)
+	}
+
+	return products, nil
+}
+
+// MyReturns is the resolver for the myReturns field.
+func (r *queryResolver) MyReturns(ctx context.Context) ([]*dto.ReturnResponse, error) {
+	userId, err := GetUserIdFromContext(ctx)
+	if err != nil {
+		return nil, ErrUnauthorized
+	}
+
+	returns, err := r.returnService.GetReturns(ctx, userId)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch returns: %w", err)
+	}
+
+	return returns, nil
+}
+
+// ReturnRequest is the resolver for the returnRequest field.
+func (r *queryResolver) ReturnRequest(ctx context.Context, id string) (*dto.ReturnResponse, error) {
+	userId, err := GetUserIdFromContext(ctx)
+	if err != nil {
+		return nil, ErrUnauthorized
+	}
+
+	returnId, err := r.parseId(id)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse return id: %w", err)
+	}
+
+	returnRequest, err := r.returnService.GetReturn(ctx, userId, returnId)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch return: %w", err)
+	}
+
+	return returnRequest, nil
+}
+
+// Returns is the resolver for the returns field.
+func (r *queryResolver) Returns(ctx context.Context, status *string) ([]*dto.ReturnResponse, error) {
+	var filter string
+	if status != nil {
+		filter = *status
+	}
+
+	returns, err := r.returnService.GetReturnsByStatus(ctx, filter)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch returns: %w", err)
+	}
+
+	return returns, nil
+}
+
+// Users is the resolver for the users field.
+func (r *queryResolver) Users(ctx context.Context, filter *dto.GetUsersRequest, page *int, limit *int) (*model.UserConnection, error) {
+	req := &dto.GetUsersRequest{}
+	if filter != nil {
+		req = filter
+	}
+	req.Page, req.Limit = getPagingNumbers(page, limit)
+
+	users, meta, err := r.adminUserService.GetUsers(ctx, req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch users: %w", err)
+	}
+
+	edges := make([]*model.UserEdge, len(users))
+	for i, user := range users {
+		edges[i] = &model.UserEdge{
+			Node: user,
+		}
+	}
+
+	return &model.UserConnection{
+		Edges: edges,
+		PageInfo: &model.PageInfo{
+			Page:       meta.Page,
+			Limit:      meta.Limit,
+			Total:      int(meta.Total),
+			TotalPages: meta.TotalPage,
+		},
+	}, nil
+}
+
+// User is the resolver for the user field.
+func (r *queryResolver) User(ctx context.Context, id string) (*dto.UserResponse, error) {
+	userId, err := r.parseId(id)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse user id: %w", err)
+	}
+
+	user, err := r.adminUserService.GetUser(ctx, userId)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch user: %w", err)
+	}
+
+	return user, nil
+}
+
+// UserAuditLog is the resolver for the userAuditLog field.
+func (r *queryResolver) UserAuditLog(ctx context.Context, id string, page *int, limit *int) (*model.AuditLogConnection, error) {
+	userId, err := r.parseId(id)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse user id: %w", err)
+	}
+
+	p, l := getPagingNumbers(page, limit)
+
+	logs, meta, err := r.adminUserService.GetAuditLog(ctx, userId, p, l)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch audit log: %w", err)
+	}
+
+	edges := make([]*model.AuditLogEdge, len(logs))
+	for i, log := range logs {
+		edges[i] = &model.AuditLogEdge{
+			Node: log,
+		}
+	}
+
+	return &model.AuditLogConnection{
+		Edges: edges,
+		PageInfo: &model.PageInfo{
+			Page:       meta.Page,
+			Limit:      meta.Limit,
+			Total:      int(meta.Total),
+			TotalPages: meta.TotalPage,
+		},
+	}, nil
+}
+
+// OrderUpdated is the resolver for the orderUpdated field.
+func (r *subscriptionResolver) OrderUpdated(ctx context.Context, id string) (<-chan *dto.OrderResponse, error) {
+	userId, err := GetUserIdFromContext(ctx)
+	if err != nil {
+		return nil, ErrUnauthorized
+	}
+
+	orderId, err := r.parseId(id)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse order id: %w", err)
+	}
+
+	updates, err := r.realtimeService.OrderUpdates(ctx, userId, orderId)
+	if err != nil {
+		return nil, fmt.Errorf("failed to subscribe to order: %w", err)
+	}
+
+	return updates, nil
+}
+
+// CartUpdated is the resolver for the cartUpdated field.
+func (r *subscriptionResolver) CartUpdated(ctx context.Context) (<-chan *dto.CartResponse, error) {
+	userId, err := GetUserIdFromContext(ctx)
+	if err != nil {
+		return nil, ErrUnauthorized
+	}
+
+	updates, err := r.realtimeService.CartUpdates(ctx, userId)
+	if err != nil {
+		return nil, fmt.Errorf("failed to subscribe to cart: %w", err)
+	}
+
+	return updates, nil
+}
+
 // Mutation returns graph.MutationResolver implementation.
 func (r *Resolver) Mutation() graph.MutationResolver { return &mutationResolver{r} }
 
 // Query returns graph.QueryResolver implementation.
 func (r *Resolver) Query() graph.QueryResolver { return &queryResolver{r} }
 
+// Subscription returns graph.SubscriptionResolver implementation.
+func (r *Resolver) Subscription() graph.SubscriptionResolver { return &subscriptionResolver{r} }
+
 type mutationResolver struct{ *Resolver }
 type queryResolver struct{ *Resolver }
+type subscriptionResolver struct{ *Resolver }
@@ -3,11 +3,44 @@ package resolver
 import (
 	"context"
 	"fmt"
+	"maps"
+	"slices"
 
 	"github.com/saleh-ghazimoradi/Cartopher/graph"
+	"github.com/saleh-ghazimoradi/Cartopher/graph/model"
 	"github.com/saleh-ghazimoradi/Cartopher/internal/dto"
 )
 
+// ID is the resolver for the id field.
+func (r *auditLogResolver) ID(ctx context.Context, obj *dto.AuditLogResponse) (string, error) {
+	return fmt.Sprintf("%d", obj.Id), nil
+}
+
+// ActorID is the resolver for the actor_id field.
+func (r *auditLogResolver) ActorID(ctx context.Context, obj *dto.AuditLogResponse) (string, error) {
+	return fmt.Sprintf("%d", obj.ActorId), nil
+}
+
+// UserID is the resolver for the user_id field.
+func (r *auditLogResolver) UserID(ctx context.Context, obj *dto.AuditLogResponse) (string, error) {
+	return fmt.Sprintf("%d", obj.UserId), nil
+}
+
+// Details is the resolver for the details field.
+func (r *auditLogResolver) Details(ctx context.Context, obj *dto.AuditLogResponse) ([]*model.AuditLogDetail, error) {
+	keys := slices.Sorted(maps.Keys(obj.Details))
+
+	details := make([]*model.AuditLogDetail, len(keys))
+	for i, key := range keys {
+		details[i] = &model.AuditLogDetail{
+			Key:   key,
+			Value: obj.Details[key],
+		}
+	}
+
+	return details, nil
+}
+
 // ID is the resolver for the id field.
 func (r *cartResolver) ID(ctx context.Context, obj *dto.CartResponse) (string, error) {
 	return fmt.Sprintf("%d", obj.Id), nil
@@ -28,6 +61,16 @@ func (r *categoryResolver) ID(ctx context.Context, obj *dto.CategoryResponse) (s
 	return fmt.Sprintf("%d", obj.Id), nil
 }
 
+// OrderID is the resolver for the order_id field.
+func (r *invoiceResolver) OrderID(ctx context.Context, obj *dto.InvoiceResponse) (string, error) {
+	return fmt.Sprintf("%d", obj.OrderId), nil
+}
+
+// ProductID is the resolver for the product_id field.
+func (r *lowStockProductResolver) ProductID(ctx context.Context, obj *dto.LowStockProductResponse) (string, error) {
+	return fmt.Sprintf("%d", obj.ProductId), nil
+}
+
 // ID is the resolver for the id field.
 func (r *orderResolver) ID(ctx context.Context, obj *dto.OrderResponse) (string, error) {
 	return fmt.Sprintf("%d", obj.Id), nil
@@ -43,6 +86,11 @@ func (r *orderItemResolver) ID(ctx context.Context, obj *dto.OrderItemResponse)
 	return fmt.Sprintf("%d", obj.Id), nil
 }
 
+// WarehouseID is the resolver for the warehouse_id field.
+func (r *orderItemResolver) WarehouseID(ctx context.Context, obj *dto.OrderItemResponse) (string, error) {
+	return fmt.Sprintf("%d", obj.WarehouseId), nil
+}
+
 // ID is the resolver for the id field.
 func (r *productResolver) ID(ctx context.Context, obj *dto.ProductResponse) (string, error) {
 	return fmt.Sprintf("%d", obj.Id), nil
@@ -58,11 +106,64 @@ func (r *productImageResolver) ID(ctx context.Context, obj *dto.ProductImageResp
 	return fmt.Sprintf("%d", obj.Id), nil
 }
 
+// ID is the resolver for the id field.
+func (r *returnResolver) ID(ctx context.Context, obj *dto.ReturnResponse) (string, error) {
+	return fmt.Sprintf("%d", obj.Id), nil
+}
+
+// OrderID is the resolver for the order_id field.
+func (r *returnResolver) OrderID(ctx context.Context, obj *dto.ReturnResponse) (string, error) {
+	return fmt.Sprintf("%d", obj.OrderId), nil
+}
+
+// UserID is the resolver for the user_id field.
+func (r *returnResolver) UserID(ctx context.Context, obj *dto.ReturnResponse) (string, error) {
+	return fmt.Sprintf("%d", obj.UserId), nil
+}
+
+// OrderItemID is the resolver for the order_item_id field.
+func (r *returnItemResolver) OrderItemID(ctx context.Context, obj *dto.ReturnItemResponse) (string, error) {
+	return fmt.Sprintf("%d", obj.OrderItemId), nil
+}
+
+// ProductID is the resolver for the product_id field.
+func (r *returnItemResolver) ProductID(ctx context.Context, obj *dto.ReturnItemResponse) (string, error) {
+	return fmt.Sprintf("%d", obj.ProductId), nil
+}
+
+// ID is the resolver for the id field.
+func (r *shipmentResolver) ID(ctx context.Context, obj *dto.ShipmentResponse) (string, error) {
+	return fmt.Sprintf("%d", obj.Id), nil
+}
+
+// OrderID is the resolver for the order_id field.
+func (r *shipmentResolver) OrderID(ctx context.Context, obj *dto.ShipmentResponse) (string, error) {
+	return fmt.Sprintf("%d", obj.OrderId), nil
+}
+
+// OrderItemID is the resolver for the order_item_id field.
+func (r *shipmentItemResolver) OrderItemID(ctx context.Context, obj *dto.ShipmentItemResponse) (string, error) {
+	return fmt.Sprintf("%d", obj.OrderItemId), nil
+}
+
+// WarehouseID is the resolver for the warehouse_id field.
+func (r *stockLevelResolver) WarehouseID(ctx context.Context, obj *dto.StockLevelResponse) (string, error) {
+	return fmt.Sprintf("%d", obj.WarehouseId), nil
+}
+
 // ID is the resolver for the id field.
 func (r *userResolver) ID(ctx context.Context, obj *dto.UserResponse) (string, error) {
 	return fmt.Sprintf("%d", obj.Id), nil
 }
 
+// ID is the resolver for the id field.
+func (r *warehouseResolver) ID(ctx context.Context, obj *dto.WarehouseResponse) (string, error) {
+	return fmt.Sprintf("%d", obj.Id), nil
+}
+
+// AuditLog returns graph.AuditLogResolver implementation.
+func (r *Resolver) AuditLog() graph.AuditLogResolver { return &auditLogResolver{r} }
+
 // Cart returns graph.CartResolver implementation.
 func (r *Resolver) Cart() graph.CartResolver { return &cartResolver{r} }
 
@@ -72,6 +173,14 @@ func (r *Resolver) CartItem() graph.CartItemResolver { return &cartItemResolver{
 // Category returns graph.CategoryResolver implementation.
 func (r *Resolver) Category() graph.CategoryResolver { return &categoryResolver{r} }
 
+// Invoice returns graph.InvoiceResolver implementation.
+func (r *Resolver) Invoice() graph.InvoiceResolver { return &invoiceResolver{r} }
+
+// LowStockProduct returns graph.LowStockProductResolver implementation.
+func (r *Resolver) LowStockProduct() graph.LowStockProductResolver {
+	return &lowStockProductResolver{r}
+}
+
 // Order returns graph.OrderResolver implementation.
 func (r *Resolver) Order() graph.OrderResolver { return &orderResolver{r} }
 
@@ -84,14 +193,41 @@ func (r *Resolver) Product() graph.ProductResolver { return &productResolver{r}
 // ProductImage returns graph.ProductImageResolver implementation.
 func (r *Resolver) ProductImage() graph.ProductImageResolver { return &productImageResolver{r} }
 
+// Return returns graph.ReturnResolver implementation.
+func (r *Resolver) Return() graph.ReturnResolver { return &returnResolver{r} }
+
+// ReturnItem returns graph.ReturnItemResolver implementation.
+func (r *Resolver) ReturnItem() graph.ReturnItemResolver { return &returnItemResolver{r} }
+
+// Shipment returns graph.ShipmentResolver implementation.
+func (r *Resolver) Shipment() graph.ShipmentResolver { return &shipmentResolver{r} }
+
+// ShipmentItem returns graph.ShipmentItemResolver implementation.
+func (r *Resolver) ShipmentItem() graph.ShipmentItemResolver { return &shipmentItemResolver{r} }
+
+// StockLevel returns graph.StockLevelResolver implementation.
+func (r *Resolver) StockLevel() graph.StockLevelResolver { return &stockLevelResolver{r} }
+
 // User returns graph.UserResolver implementation.
 func (r *Resolver) User() graph.UserResolver { return &userResolver{r} }
 
+// Warehouse returns graph.WarehouseResolver implementation.
+func (r *Resolver) Warehouse() graph.WarehouseResolver { return &warehouseResolver{r} }
+
+type auditLogResolver struct{ *Resolver }
 type cartResolver struct{ *Resolver }
 type cartItemResolver struct{ *Resolver }
 type categoryResolver struct{ *Resolver }
+type invoiceResolver struct{ *Resolver }
+type lowStockProductResolver struct{ *Resolver }
 type orderResolver struct{ *Resolver }
 type orderItemResolver struct{ *Resolver }
 type productResolver struct{ *Resolver }
 type productImageResolver struct{ *Resolver }
+type returnResolver struct{ *Resolver }
+type returnItemResolver struct{ *Resolver }
+type shipmentResolver struct{ *Resolver }
+type shipmentItemResolver struct{ *Resolver }
+type stockLevelResolver struct{ *Resolver }
 type userResolver struct{ *Resolver }
+type warehouseResolver struct{ *Resolver }
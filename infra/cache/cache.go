@@ -11,6 +11,9 @@ import (
 type Cache interface {
 	Get(ctx context.Context, key string, dest any) (bool, error)
 	Set(ctx context.Context, key string, value any, ttl time.Duration) error
+	SetNX(ctx context.Context, key string, value any, ttl time.Duration) (bool, error)
+	// Take gets a value and deletes it at once, so only one caller gets it.
+	Take(ctx context.Context, key string, dest any) (bool, error)
 	Delete(ctx context.Context, keys ...string) error
 	DeleteByPrefix(ctx context.Context, prefix string) error
 }
@@ -41,6 +44,29 @@ func (c *cache) Set(ctx context.Context, key string, value any, ttl time.Duratio
 	return c.client.Set(ctx, key, data, ttl).Err()
 }
 
+// SetNX sets key unless it exists. It reports whether key was set.
+func (c *cache) SetNX(ctx context.Context, key string, value any, ttl time.Duration) (bool, error) {
+	data, err := json.Marshal(value)
+	if err != nil {
+		return false, err
+	}
+
+	return c.client.SetNX(ctx, key, data, ttl).Result()
+}
+
+func (c *cache) Take(ctx context.Context, key string, dest any) (bool, error) {
+	val, err := c.client.GetDel(ctx, key).Result()
+	if errors.Is(err, redis.Nil) {
+		return false, nil
+	}
+
+	if err != nil {
+		return false, err
+	}
+
+	return true, json.Unmarshal([]byte(val), dest)
+}
+
 func (c *cache) Delete(ctx context.Context, keys ...string) error {
 	return c.client.Del(ctx, keys...).Err()
 }
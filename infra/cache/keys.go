@@ -3,11 +3,20 @@ package cache
 import "fmt"
 
 const (
-	productListPrefix = "product:list:"
+	productByIdPrefix  = "product:id:"
+	productListPrefix  = "product:list:"
+	tokenVersionPrefix = "user:token_version:"
+
+	twoFactorChallengePrefix = "2fa:challenge:"
+	oidcStatePrefix          = "oidc:state:"
 )
 
 func ProductById(id uint) string {
-	return fmt.Sprintf("product:id:%d", id)
+	return fmt.Sprintf("%s%d", productByIdPrefix, id)
+}
+
+func ProductByIdPrefix() string {
+	return productByIdPrefix
 }
 
 func ProductList(page, limit int) string {
@@ -17,3 +26,16 @@ func ProductList(page, limit int) string {
 func ProductListPrefix() string {
 	return productListPrefix
 }
+
+func UserTokenVersion(userId uint) string {
+	return fmt.Sprintf("%s%d", tokenVersionPrefix, userId)
+}
+
+// TwoFactorChallenge is set once the challenge token with id was used.
+func TwoFactorChallenge(id string) string {
+	return twoFactorChallengePrefix + id
+}
+
+func OIDCState(state string) string {
+	return oidcStatePrefix + state
+}
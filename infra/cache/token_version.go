@@ -0,0 +1,46 @@
+package cache
+
+import (
+	"context"
+	"errors"
+	"strconv"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// TokenVersions keeps a version per user that access tokens are issued
+// with. Bumping it revokes every access token the user holds at once,
+// instead of when they expire.
+type TokenVersions interface {
+	Get(ctx context.Context, userId uint) (int64, error)
+	Bump(ctx context.Context, userId uint) error
+}
+
+type tokenVersions struct {
+	client *redis.Client
+}
+
+// Get returns the current version, 0 for a user whose tokens were never
+// revoked.
+func (t *tokenVersions) Get(ctx context.Context, userId uint) (int64, error) {
+	val, err := t.client.Get(ctx, UserTokenVersion(userId)).Result()
+	if errors.Is(err, redis.Nil) {
+		return 0, nil
+	}
+
+	if err != nil {
+		return 0, err
+	}
+
+	return strconv.ParseInt(val, 10, 64)
+}
+
+func (t *tokenVersions) Bump(ctx context.Context, userId uint) error {
+	return t.client.Incr(ctx, UserTokenVersion(userId)).Err()
+}
+
+func NewTokenVersions(client *redis.Client) TokenVersions {
+	return &tokenVersions{
+		client: client,
+	}
+}
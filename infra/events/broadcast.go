@@ -0,0 +1,125 @@
+package events
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// Broadcaster fans events out to every API instance, so clients get live
+// updates whichever instance they are connected to. Delivery is best
+// effort: a subscriber that is not connected when an event is broadcast
+// misses it, unless it catches up with Replay.
+type Broadcaster interface {
+	Broadcast(ctx context.Context, channel string, payload []byte) error
+	// Subscribe returns the payloads broadcast on channel until ctx is done,
+	// then closes the returned channel.
+	Subscribe(ctx context.Context, channel string) (<-chan []byte, error)
+	// Replay returns the recent payloads broadcast on channel after the
+	// event with lastEventId, oldest first. All recent payloads are
+	// returned when the event is no longer known.
+	Replay(ctx context.Context, channel, lastEventId string) ([][]byte, error)
+}
+
+const (
+	// historySize and historyTTL bound how far back Replay can go.
+	historySize = 100
+	historyTTL  = 24 * time.Hour
+)
+
+type RedisBroadcaster struct {
+	client *redis.Client
+}
+
+func (r *RedisBroadcaster) Broadcast(ctx context.Context, channel string, payload []byte) error {
+	history := historyKey(channel)
+	_, err := r.client.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+		pipe.RPush(ctx, history, payload)
+		pipe.LTrim(ctx, history, -historySize, -1)
+		pipe.Expire(ctx, history, historyTTL)
+		pipe.Publish(ctx, channel, payload)
+		return nil
+	})
+	return err
+}
+
+func (r *RedisBroadcaster) Subscribe(ctx context.Context, channel string) (<-chan []byte, error) {
+	pubsub := r.client.Subscribe(ctx, channel)
+
+	// Wait for the subscription to be confirmed so nothing broadcast after
+	// Subscribe returns is missed.
+	if _, err := pubsub.Receive(ctx); err != nil {
+		_ = pubsub.Close()
+		return nil, fmt.Errorf("failed to subscribe to %s: %w", channel, err)
+	}
+
+	payloads := make(chan []byte)
+	go func() {
+		defer close(payloads)
+		defer pubsub.Close()
+
+		messages := pubsub.Channel()
+		for {
+			select {
+			case msg, ok := <-messages:
+				if !ok {
+					return
+				}
+				select {
+				case payloads <- []byte(msg.Payload):
+				case <-ctx.Done():
+					return
+				}
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+
+	return payloads, nil
+}
+
+func (r *RedisBroadcaster) Replay(ctx context.Context, channel, lastEventId string) ([][]byte, error) {
+	history, err := r.client.LRange(ctx, historyKey(channel), 0, -1).Result()
+	if err != nil {
+		return nil, fmt.Errorf("failed to read history of %s: %w", channel, err)
+	}
+
+	payloads := make([][]byte, 0, len(history))
+	for _, payload := range history {
+		var envelope struct {
+			Id string `json:"id"`
+		}
+		if err := json.Unmarshal([]byte(payload), &envelope); err == nil && envelope.Id == lastEventId {
+			// Everything up to here was already seen.
+			payloads = payloads[:0]
+			continue
+		}
+		payloads = append(payloads, []byte(payload))
+	}
+
+	return payloads, nil
+}
+
+func NewRedisBroadcaster(client *redis.Client) *RedisBroadcaster {
+	return &RedisBroadcaster{
+		client: client,
+	}
+}
+
+// OrderChannel is the channel events about an order are broadcast on.
+func OrderChannel(orderId uint) string {
+	return fmt.Sprintf("cartopher:events:orders:%d", orderId)
+}
+
+// CartChannel is the channel events about a user's cart are broadcast on.
+func CartChannel(userId uint) string {
+	return fmt.Sprintf("cartopher:events:carts:%d", userId)
+}
+
+func historyKey(channel string) string {
+	return channel + ":history"
+}
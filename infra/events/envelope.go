@@ -0,0 +1,93 @@
+package events
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/goccy/go-json"
+	"github.com/google/uuid"
+)
+
+// ErrUnsupportedVersion is returned when a consumer does not know the
+// schema version of an event, typically because the producer was deployed
+// first. The message is left to the retry and dead letter handling so it can
+// be replayed once the consumer catches up.
+var ErrUnsupportedVersion = errors.New("unsupported event version")
+
+type correlationIdKey struct{}
+
+// Envelope wraps every event payload. Id identifies the event across
+// retries and replays, so consumers can deduplicate on it; Version is the
+// schema version of Data.
+type Envelope struct {
+	Id            string          `json:"id"`
+	Type          string          `json:"type"`
+	Version       int             `json:"version"`
+	OccurredAt    time.Time       `json:"occurred_at"`
+	CorrelationId string          `json:"correlation_id,omitempty"`
+	Data          json.RawMessage `json:"data"`
+}
+
+// NewEnvelope wraps payload as an event of eventType, taking the
+// correlation id from ctx.
+func NewEnvelope(ctx context.Context, eventType string, payload Payload) (*Envelope, error) {
+	data, err := json.Marshal(payload)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode %s event: %w", eventType, err)
+	}
+
+	return &Envelope{
+		Id:            uuid.NewString(),
+		Type:          eventType,
+		Version:       payload.SchemaVersion(),
+		OccurredAt:    time.Now().UTC(),
+		CorrelationId: CorrelationId(ctx),
+		Data:          data,
+	}, nil
+}
+
+// DecodeEnvelope reads a message body. Bodies written before events had an
+// envelope are returned as version 0 of eventType with the body as data.
+func DecodeEnvelope(body []byte, eventType string) (*Envelope, error) {
+	var header struct {
+		Type    string          `json:"type"`
+		Version int             `json:"version"`
+		Data    json.RawMessage `json:"data"`
+	}
+	if err := json.Unmarshal(body, &header); err != nil || header.Type == "" || header.Version == 0 || header.Data == nil {
+		return &Envelope{Type: eventType, Data: body}, nil
+	}
+
+	var envelope Envelope
+	if err := json.Unmarshal(body, &envelope); err != nil {
+		return nil, fmt.Errorf("failed to decode event: %w", err)
+	}
+
+	return &envelope, nil
+}
+
+// WithCorrelationId returns a copy of ctx carrying the id that events
+// created under it are correlated by.
+func WithCorrelationId(ctx context.Context, id string) context.Context {
+	return context.WithValue(ctx, correlationIdKey{}, id)
+}
+
+// CorrelationId returns the correlation id carried by ctx, if any.
+func CorrelationId(ctx context.Context) string {
+	id, _ := ctx.Value(correlationIdKey{}).(string)
+	return id
+}
+
+func decode[T any](envelope *Envelope) (*T, error) {
+	var payload T
+	if err := json.Unmarshal(envelope.Data, &payload); err != nil {
+		return nil, fmt.Errorf("failed to decode %s v%d event: %w", envelope.Type, envelope.Version, err)
+	}
+	return &payload, nil
+}
+
+func unsupportedVersion(envelope *Envelope) error {
+	return fmt.Errorf("%w: %s v%d", ErrUnsupportedVersion, envelope.Type, envelope.Version)
+}
@@ -0,0 +1,308 @@
+package events
+
+import "time"
+
+const (
+	UserLoggedIn      = "USER_LOGGED_IN"
+	ProductLowStock   = "PRODUCT_LOW_STOCK"
+	ReturnRequested   = "RETURN_REQUESTED"
+	ReturnApproved    = "RETURN_APPROVED"
+	ReturnRejected    = "RETURN_REJECTED"
+	ReturnReceived    = "RETURN_RECEIVED"
+	ReturnRefunded    = "RETURN_REFUNDED"
+	OrderCreated      = "ORDER_CREATED"
+	OrderConfirmed    = "ORDER_CONFIRMED"
+	OrderShipped      = "ORDER_SHIPPED"
+	ShipmentDelivered = "SHIPMENT_DELIVERED"
+	OrderDelivered    = "ORDER_DELIVERED"
+	OrderCancelled    = "ORDER_CANCELLED"
+	CartUpdated       = "CART_UPDATED"
+
+	// These carry secret tokens or the client details of a security
+	// incident, so they only go to the notifier and are not listed in Types.
+	EmailVerificationRequested = "EMAIL_VERIFICATION_REQUESTED"
+	PasswordResetRequested     = "PASSWORD_RESET_REQUESTED"
+	RefreshTokenReused         = "REFRESH_TOKEN_REUSED"
+	AccountLocked              = "ACCOUNT_LOCKED"
+)
+
+// Types returns every event type, e.g. to validate webhook subscriptions.
+func Types() []string {
+	return []string{
+		UserLoggedIn,
+		ProductLowStock,
+		ReturnRequested,
+		ReturnApproved,
+		ReturnRejected,
+		ReturnReceived,
+		ReturnRefunded,
+		OrderCreated,
+		OrderConfirmed,
+		OrderShipped,
+		ShipmentDelivered,
+		OrderDelivered,
+		OrderCancelled,
+		CartUpdated,
+	}
+}
+
+// Payload is an event body with an explicit schema. A change that is not
+// backwards compatible gets a new type with the next version, and consumers
+// keep decoding the old one until no producer writes it anymore.
+type Payload interface {
+	SchemaVersion() int
+}
+
+// UserLoggedInV1 is the payload of USER_LOGGED_IN.
+type UserLoggedInV1 struct {
+	UserId    uint   `json:"user_id"`
+	Email     string `json:"email"`
+	FirstName string `json:"first_name"`
+	LastName  string `json:"last_name"`
+}
+
+func (UserLoggedInV1) SchemaVersion() int { return 1 }
+
+// userLoggedInV0 is the part of the user record USER_LOGGED_IN carried
+// before it was versioned.
+type userLoggedInV0 struct {
+	Id        uint   `json:"id"`
+	Email     string `json:"email"`
+	FirstName string `json:"first_name"`
+	LastName  string `json:"last_name"`
+}
+
+// ProductLowStockV1 is the payload of PRODUCT_LOW_STOCK.
+type ProductLowStockV1 struct {
+	ProductId uint   `json:"product_id"`
+	Name      string `json:"name"`
+	SKU       string `json:"sku"`
+	Stock     int    `json:"stock"`
+	Threshold int    `json:"threshold"`
+}
+
+func (ProductLowStockV1) SchemaVersion() int { return 1 }
+
+// OrderV1 is the payload of the order lifecycle events. It carries what
+// the notifier needs to email the customer without reading the database.
+type OrderV1 struct {
+	OrderId       uint             `json:"order_id"`
+	UserId        uint             `json:"user_id"`
+	Email         string           `json:"email"`
+	CustomerName  string           `json:"customer_name"`
+	Status        string           `json:"status"`
+	TotalAmount   float64          `json:"total_amount"`
+	InvoiceNumber string           `json:"invoice_number"`
+	Items         []OrderItemV1    `json:"items"`
+	Shipment      *OrderShipmentV1 `json:"shipment,omitempty"`
+}
+
+type OrderItemV1 struct {
+	SKU      string  `json:"sku"`
+	Name     string  `json:"name"`
+	Quantity int     `json:"quantity"`
+	Price    float64 `json:"price"`
+}
+
+type OrderShipmentV1 struct {
+	Id             uint          `json:"id"`
+	Carrier        string        `json:"carrier"`
+	TrackingNumber string        `json:"tracking_number"`
+	Items          []OrderItemV1 `json:"items"`
+}
+
+func (OrderV1) SchemaVersion() int { return 1 }
+
+// ReturnV1 is the payload of the return workflow events.
+type ReturnV1 struct {
+	ReturnId        uint           `json:"return_id"`
+	OrderId         uint           `json:"order_id"`
+	UserId          uint           `json:"user_id"`
+	Status          string         `json:"status"`
+	RefundAmount    float64        `json:"refund_amount"`
+	RefundReference string         `json:"refund_reference,omitempty"`
+	Items           []ReturnItemV1 `json:"items"`
+}
+
+type ReturnItemV1 struct {
+	OrderItemId uint   `json:"order_item_id"`
+	Quantity    int    `json:"quantity"`
+	Reason      string `json:"reason"`
+}
+
+func (ReturnV1) SchemaVersion() int { return 1 }
+
+// CartV1 is the payload of CART_UPDATED. It only identifies the cart;
+// consumers that need its contents read them from the API.
+type CartV1 struct {
+	CartId uint `json:"cart_id"`
+	UserId uint `json:"user_id"`
+}
+
+func (CartV1) SchemaVersion() int { return 1 }
+
+// RefreshTokenReusedV1 is the payload of REFRESH_TOKEN_REUSED, published
+// when a rotated refresh token is presented again and its session is
+// revoked. The client is the one that presented the token.
+type RefreshTokenReusedV1 struct {
+	UserId    uint   `json:"user_id"`
+	Email     string `json:"email"`
+	FirstName string `json:"first_name"`
+	LastName  string `json:"last_name"`
+	SessionId uint   `json:"session_id"`
+	UserAgent string `json:"user_agent"`
+	IPAddress string `json:"ip_address"`
+}
+
+func (RefreshTokenReusedV1) SchemaVersion() int { return 1 }
+
+// AccountLockedV1 is the payload of ACCOUNT_LOCKED, published when failed
+// logins lock an account. The client is the one whose attempt locked it.
+type AccountLockedV1 struct {
+	UserId      uint      `json:"user_id"`
+	Email       string    `json:"email"`
+	FirstName   string    `json:"first_name"`
+	LastName    string    `json:"last_name"`
+	UserAgent   string    `json:"user_agent"`
+	IPAddress   string    `json:"ip_address"`
+	LockedUntil time.Time `json:"locked_until"`
+}
+
+func (AccountLockedV1) SchemaVersion() int { return 1 }
+
+// EmailVerificationRequestedV1 is the payload of
+// EMAIL_VERIFICATION_REQUESTED.
+type EmailVerificationRequestedV1 struct {
+	UserId    uint      `json:"user_id"`
+	Email     string    `json:"email"`
+	FirstName string    `json:"first_name"`
+	LastName  string    `json:"last_name"`
+	Token     string    `json:"token"`
+	URL       string    `json:"url,omitempty"`
+	ExpiresAt time.Time `json:"expires_at"`
+}
+
+func (EmailVerificationRequestedV1) SchemaVersion() int { return 1 }
+
+// PasswordResetRequestedV1 is the payload of PASSWORD_RESET_REQUESTED.
+type PasswordResetRequestedV1 struct {
+	UserId    uint      `json:"user_id"`
+	Email     string    `json:"email"`
+	FirstName string    `json:"first_name"`
+	LastName  string    `json:"last_name"`
+	Token     string    `json:"token"`
+	URL       string    `json:"url,omitempty"`
+	ExpiresAt time.Time `json:"expires_at"`
+}
+
+func (PasswordResetRequestedV1) SchemaVersion() int { return 1 }
+
+// DecodeUserLoggedIn returns the USER_LOGGED_IN payload in its current
+// version.
+func DecodeUserLoggedIn(envelope *Envelope) (*UserLoggedInV1, error) {
+	switch envelope.Version {
+	case 0:
+		user, err := decode[userLoggedInV0](envelope)
+		if err != nil {
+			return nil, err
+		}
+		return &UserLoggedInV1{
+			UserId:    user.Id,
+			Email:     user.Email,
+			FirstName: user.FirstName,
+			LastName:  user.LastName,
+		}, nil
+	case 1:
+		return decode[UserLoggedInV1](envelope)
+	default:
+		return nil, unsupportedVersion(envelope)
+	}
+}
+
+// DecodeProductLowStock returns the PRODUCT_LOW_STOCK payload in its
+// current version. Version 0 had the same fields.
+func DecodeProductLowStock(envelope *Envelope) (*ProductLowStockV1, error) {
+	switch envelope.Version {
+	case 0, 1:
+		return decode[ProductLowStockV1](envelope)
+	default:
+		return nil, unsupportedVersion(envelope)
+	}
+}
+
+// DecodeOrder returns the payload of an order lifecycle event in its
+// current version. Version 0 had the same fields.
+func DecodeOrder(envelope *Envelope) (*OrderV1, error) {
+	switch envelope.Version {
+	case 0, 1:
+		return decode[OrderV1](envelope)
+	default:
+		return nil, unsupportedVersion(envelope)
+	}
+}
+
+// DecodeReturn returns the payload of a return workflow event in its
+// current version. Unversioned return events carried the API response and
+// are not understood.
+func DecodeReturn(envelope *Envelope) (*ReturnV1, error) {
+	switch envelope.Version {
+	case 1:
+		return decode[ReturnV1](envelope)
+	default:
+		return nil, unsupportedVersion(envelope)
+	}
+}
+
+// DecodeCart returns the CART_UPDATED payload in its current version.
+func DecodeCart(envelope *Envelope) (*CartV1, error) {
+	switch envelope.Version {
+	case 1:
+		return decode[CartV1](envelope)
+	default:
+		return nil, unsupportedVersion(envelope)
+	}
+}
+
+// DecodeEmailVerificationRequested returns the
+// EMAIL_VERIFICATION_REQUESTED payload in its current version.
+func DecodeEmailVerificationRequested(envelope *Envelope) (*EmailVerificationRequestedV1, error) {
+	switch envelope.Version {
+	case 1:
+		return decode[EmailVerificationRequestedV1](envelope)
+	default:
+		return nil, unsupportedVersion(envelope)
+	}
+}
+
+// DecodePasswordResetRequested returns the PASSWORD_RESET_REQUESTED
+// payload in its current version.
+func DecodePasswordResetRequested(envelope *Envelope) (*PasswordResetRequestedV1, error) {
+	switch envelope.Version {
+	case 1:
+		return decode[PasswordResetRequestedV1](envelope)
+	default:
+		return nil, unsupportedVersion(envelope)
+	}
+}
+
+// DecodeRefreshTokenReused returns the REFRESH_TOKEN_REUSED payload in its
+// current version.
+func DecodeRefreshTokenReused(envelope *Envelope) (*RefreshTokenReusedV1, error) {
+	switch envelope.Version {
+	case 1:
+		return decode[RefreshTokenReusedV1](envelope)
+	default:
+		return nil, unsupportedVersion(envelope)
+	}
+}
+
+// DecodeAccountLocked returns the ACCOUNT_LOCKED payload in its current
+// version.
+func DecodeAccountLocked(envelope *Envelope) (*AccountLockedV1, error) {
+	switch envelope.Version {
+	case 1:
+		return decode[AccountLockedV1](envelope)
+	default:
+		return nil, unsupportedVersion(envelope)
+	}
+}
@@ -0,0 +1,147 @@
+package events
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"sync"
+
+	"github.com/ThreeDotsLabs/watermill"
+	"github.com/ThreeDotsLabs/watermill-aws/sqs"
+	watermillSQL "github.com/ThreeDotsLabs/watermill-sql/v3/pkg/sql"
+	"github.com/ThreeDotsLabs/watermill/message"
+	"github.com/ThreeDotsLabs/watermill/pubsub/gochannel"
+	"github.com/saleh-ghazimoradi/Cartopher/config"
+	"github.com/saleh-ghazimoradi/Cartopher/pkg/uploadProvider"
+)
+
+const (
+	TransportSQS       = "sqs"
+	TransportGoChannel = "gochannel"
+	TransportPostgres  = "postgres"
+
+	defaultTopic         = "cartopher-events"
+	notifierConsumerName = "notifier"
+)
+
+var (
+	goChannel     *gochannel.GoChannel
+	goChannelOnce sync.Once
+)
+
+// Transport returns the configured transport, defaulting to SQS.
+func Transport(cfg *config.Config) string {
+	if cfg.Events.Transport == "" {
+		return TransportSQS
+	}
+	return cfg.Events.Transport
+}
+
+// InProcess reports whether publisher and subscriber have to live in the
+// same process, which is the case for the Go channel transport.
+func InProcess(cfg *config.Config) bool {
+	return Transport(cfg) == TransportGoChannel
+}
+
+// Topic returns the topic events are published to. The SQS queue name is
+// kept as the topic so existing deployments keep working.
+func Topic(cfg *config.Config) string {
+	switch {
+	case cfg.Events.Topic != "":
+		return cfg.Events.Topic
+	case cfg.AWS.EventQueueName != "":
+		return cfg.AWS.EventQueueName
+	default:
+		return defaultTopic
+	}
+}
+
+// NewSubscriber creates a subscriber for the notifier on the configured
+// transport. db is only used by the postgres transport and may be nil
+// otherwise.
+func NewSubscriber(ctx context.Context, cfg *config.Config, db *sql.DB) (message.Subscriber, error) {
+	logger := watermill.NewStdLogger(false, false)
+
+	switch Transport(cfg) {
+	case TransportSQS:
+		awsConfig, err := uploadProvider.CreateAWSConfig(ctx, cfg.AWS.S3Endpoint, cfg.AWS.Region)
+		if err != nil {
+			return nil, fmt.Errorf("failed to create aws config: %w", err)
+		}
+
+		subscriber, err := sqs.NewSubscriber(sqs.SubscriberConfig{
+			AWSConfig: awsConfig,
+		}, logger)
+		if err != nil {
+			return nil, fmt.Errorf("failed to create sqs subscriber: %w", err)
+		}
+		return subscriber, nil
+	case TransportGoChannel:
+		return sharedGoChannel(logger), nil
+	case TransportPostgres:
+		if db == nil {
+			return nil, errors.New("postgres transport requires a database connection")
+		}
+
+		subscriber, err := watermillSQL.NewSubscriber(db, watermillSQL.SubscriberConfig{
+			ConsumerGroup:    notifierConsumerName,
+			SchemaAdapter:    watermillSQL.DefaultPostgreSQLSchema{},
+			OffsetsAdapter:   watermillSQL.DefaultPostgreSQLOffsetsAdapter{},
+			InitializeSchema: true,
+		}, logger)
+		if err != nil {
+			return nil, fmt.Errorf("failed to create postgres subscriber: %w", err)
+		}
+		return subscriber, nil
+	default:
+		return nil, fmt.Errorf("unknown event transport: %s", cfg.Events.Transport)
+	}
+}
+
+func newPublisher(ctx context.Context, cfg *config.Config, db *sql.DB, logger watermill.LoggerAdapter) (message.Publisher, error) {
+	switch Transport(cfg) {
+	case TransportSQS:
+		awsConfig, err := uploadProvider.CreateAWSConfig(ctx, cfg.AWS.S3Endpoint, cfg.AWS.Region)
+		if err != nil {
+			return nil, fmt.Errorf("failed to create aws config: %w", err)
+		}
+
+		publisher, err := sqs.NewPublisher(sqs.PublisherConfig{
+			AWSConfig: awsConfig,
+			Marshaler: nil,
+		}, logger)
+		if err != nil {
+			return nil, fmt.Errorf("failed to create sqs publisher: %w", err)
+		}
+		return publisher, nil
+	case TransportGoChannel:
+		return sharedGoChannel(logger), nil
+	case TransportPostgres:
+		if db == nil {
+			return nil, errors.New("postgres transport requires a database connection")
+		}
+
+		publisher, err := watermillSQL.NewPublisher(db, watermillSQL.PublisherConfig{
+			SchemaAdapter:        watermillSQL.DefaultPostgreSQLSchema{},
+			AutoInitializeSchema: true,
+		}, logger)
+		if err != nil {
+			return nil, fmt.Errorf("failed to create postgres publisher: %w", err)
+		}
+		return publisher, nil
+	default:
+		return nil, fmt.Errorf("unknown event transport: %s", cfg.Events.Transport)
+	}
+}
+
+// sharedGoChannel returns the process-wide Go channel pub/sub. Publisher and
+// subscriber must share one instance for messages to reach the notifier.
+func sharedGoChannel(logger watermill.LoggerAdapter) *gochannel.GoChannel {
+	goChannelOnce.Do(func() {
+		goChannel = gochannel.NewGoChannel(gochannel.Config{
+			OutputChannelBuffer: 64,
+		}, logger)
+	})
+	return goChannel
+}
@@ -2,15 +2,13 @@ package events
 
 import (
 	"context"
-	"fmt"
+	"database/sql"
 
 	"github.com/ThreeDotsLabs/watermill"
-	"github.com/ThreeDotsLabs/watermill-aws/sqs"
 	"github.com/ThreeDotsLabs/watermill/message"
 	_ "github.com/aws/smithy-go/endpoints"
 	"github.com/goccy/go-json"
 	"github.com/saleh-ghazimoradi/Cartopher/config"
-	"github.com/saleh-ghazimoradi/Cartopher/pkg/uploadProvider"
 )
 
 type WatermillEventPublisher struct {
@@ -39,26 +37,16 @@ func (w *WatermillEventPublisher) Close() error {
 	return w.publisher.Close()
 }
 
-func NewWatermillEventPublisher(ctx context.Context, cfg *config.Config) (*WatermillEventPublisher, error) {
-	logger := watermill.NewStdLogger(false, false)
-
-	awsConfig, err := uploadProvider.CreateAWSConfig(ctx, cfg.AWS.S3Endpoint, cfg.AWS.Region)
-	if err != nil {
-		return nil, fmt.Errorf("failed to create aws config: %w", err)
-	}
-
-	publisherConfig := sqs.PublisherConfig{
-		AWSConfig: awsConfig,
-		Marshaler: nil,
-	}
-
-	publisher, err := sqs.NewPublisher(publisherConfig, logger)
+// NewWatermillEventPublisher creates a publisher on the configured transport.
+// db is only used by the postgres transport and may be nil otherwise.
+func NewWatermillEventPublisher(ctx context.Context, cfg *config.Config, db *sql.DB) (*WatermillEventPublisher, error) {
+	publisher, err := newPublisher(ctx, cfg, db, watermill.NewStdLogger(false, false))
 	if err != nil {
-		return nil, fmt.Errorf("failed to create sqs publisher: %w", err)
+		return nil, err
 	}
 
 	return &WatermillEventPublisher{
 		publisher: publisher,
-		queueName: cfg.AWS.EventQueueName,
+		queueName: Topic(cfg),
 	}, nil
 }
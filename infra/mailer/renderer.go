@@ -0,0 +1,226 @@
+package mailer
+
+import (
+	"bytes"
+	"embed"
+	"errors"
+	"fmt"
+	htmltemplate "html/template"
+	"io/fs"
+	"os"
+	"path"
+	"sort"
+	"strings"
+	texttemplate "text/template"
+)
+
+//go:embed templates
+var defaultTemplates embed.FS
+
+var ErrTemplateNotFound = errors.New("email template not found")
+
+// Renderer renders the email of an event from a template directory named
+// after it. Every directory holds a subject.txt, body.txt and body.html, and
+// any of them may have localised variants such as subject.de.txt. The
+// embedded defaults only translate subjects; translated bodies can be added
+// in the override directory, whose files take precedence over the defaults.
+type Renderer struct {
+	sources       []fs.FS
+	defaultLocale string
+}
+
+// Render renders the named template for the given locale, falling back to
+// the base language and then to the default locale.
+func (r *Renderer) Render(name, locale string, data any) (*Message, error) {
+	if !r.exists(name) {
+		return nil, fmt.Errorf("%w: %s", ErrTemplateNotFound, name)
+	}
+
+	locales := r.locales(locale)
+
+	subject, err := r.renderText(path.Join(name, "subject"), "", locales, data)
+	if err != nil {
+		return nil, err
+	}
+
+	text, err := r.renderText(path.Join(name, "body"), "layout.txt", locales, data)
+	if err != nil {
+		return nil, err
+	}
+
+	html, err := r.renderHTML(path.Join(name, "body"), "layout.html", locales, data)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Message{
+		Subject: strings.Join(strings.Fields(subject), " "),
+		Text:    text,
+		HTML:    html,
+	}, nil
+}
+
+// Templates returns the names of all available templates.
+func (r *Renderer) Templates() []string {
+	seen := make(map[string]struct{})
+	for _, source := range r.sources {
+		entries, err := fs.ReadDir(source, ".")
+		if err != nil {
+			continue
+		}
+		for _, entry := range entries {
+			if entry.IsDir() {
+				seen[entry.Name()] = struct{}{}
+			}
+		}
+	}
+
+	names := make([]string, 0, len(seen))
+	for name := range seen {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+func (r *Renderer) exists(name string) bool {
+	if name == "" || strings.ContainsAny(name, "/\\.") {
+		return false
+	}
+	for _, source := range r.sources {
+		if info, err := fs.Stat(source, name); err == nil && info.IsDir() {
+			return true
+		}
+	}
+	return false
+}
+
+func (r *Renderer) renderText(base, layout string, locales []string, data any) (string, error) {
+	content, err := r.read(base, ".txt", locales)
+	if err != nil {
+		return "", err
+	}
+
+	tmpl := texttemplate.New("content").Funcs(funcs)
+	if _, err := tmpl.Parse(content); err != nil {
+		return "", fmt.Errorf("failed to parse %s: %w", base, err)
+	}
+
+	root := tmpl
+	if layout != "" {
+		layoutContent, err := r.readFile(layout)
+		if err != nil {
+			return "", err
+		}
+		if root, err = tmpl.New("layout").Parse(layoutContent); err != nil {
+			return "", fmt.Errorf("failed to parse %s: %w", layout, err)
+		}
+	}
+
+	var buf bytes.Buffer
+	if err := root.Execute(&buf, data); err != nil {
+		return "", fmt.Errorf("failed to render %s: %w", base, err)
+	}
+	return buf.String(), nil
+}
+
+func (r *Renderer) renderHTML(base, layout string, locales []string, data any) (string, error) {
+	content, err := r.read(base, ".html", locales)
+	if err != nil {
+		return "", err
+	}
+
+	layoutContent, err := r.readFile(layout)
+	if err != nil {
+		return "", err
+	}
+
+	tmpl := htmltemplate.New("content").Funcs(htmltemplate.FuncMap(funcs))
+	if _, err := tmpl.Parse(content); err != nil {
+		return "", fmt.Errorf("failed to parse %s: %w", base, err)
+	}
+
+	root, err := tmpl.New("layout").Parse(layoutContent)
+	if err != nil {
+		return "", fmt.Errorf("failed to parse %s: %w", layout, err)
+	}
+
+	var buf bytes.Buffer
+	if err := root.Execute(&buf, data); err != nil {
+		return "", fmt.Errorf("failed to render %s: %w", base, err)
+	}
+	return buf.String(), nil
+}
+
+// read returns the most specific localised variant of base+ext.
+func (r *Renderer) read(base, ext string, locales []string) (string, error) {
+	for _, locale := range locales {
+		name := base + ext
+		if locale != "" {
+			name = base + "." + locale + ext
+		}
+		content, err := r.readFile(name)
+		if err == nil {
+			return content, nil
+		}
+		if !errors.Is(err, fs.ErrNotExist) {
+			return "", err
+		}
+	}
+	return "", fmt.Errorf("%w: %s%s", ErrTemplateNotFound, base, ext)
+}
+
+func (r *Renderer) readFile(name string) (string, error) {
+	for _, source := range r.sources {
+		content, err := fs.ReadFile(source, name)
+		if err == nil {
+			return string(content), nil
+		}
+		if !errors.Is(err, fs.ErrNotExist) {
+			return "", err
+		}
+	}
+	return "", fmt.Errorf("%s: %w", name, fs.ErrNotExist)
+}
+
+// locales lists the variants to try, most specific first. The empty string
+// stands for the unlocalised file.
+func (r *Renderer) locales(locale string) []string {
+	var locales []string
+	for _, l := range []string{locale, r.defaultLocale} {
+		l = strings.ToLower(strings.ReplaceAll(strings.TrimSpace(l), "_", "-"))
+		if l == "" {
+			continue
+		}
+		locales = append(locales, l)
+		if lang, _, ok := strings.Cut(l, "-"); ok {
+			locales = append(locales, lang)
+		}
+	}
+	return append(locales, "")
+}
+
+var funcs = texttemplate.FuncMap{
+	"money": func(amount float64) string {
+		return fmt.Sprintf("%.2f", amount)
+	},
+	"subtotal": func(quantity int, price float64) string {
+		return fmt.Sprintf("%.2f", float64(quantity)*price)
+	},
+}
+
+// NewRenderer creates a renderer over the embedded templates. When dir is
+// set, templates found there override the embedded ones file by file.
+func NewRenderer(dir, defaultLocale string) *Renderer {
+	embedded, _ := fs.Sub(defaultTemplates, "templates")
+
+	var sources []fs.FS
+	if dir != "" {
+		sources = append(sources, os.DirFS(dir))
+	}
+
+	return &Renderer{
+		sources:       append(sources, embedded),
+		defaultLocale: defaultLocale,
+	}
+}
@@ -0,0 +1 @@
+DROP TABLE IF EXISTS warehouses;
\ No newline at end of file
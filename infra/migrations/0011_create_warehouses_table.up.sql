@@ -0,0 +1,21 @@
+CREATE TABLE IF NOT EXISTS warehouses (
+    id BIGSERIAL PRIMARY KEY,
+    name VARCHAR(255) NOT NULL,
+    code VARCHAR(50) UNIQUE NOT NULL,
+    priority INTEGER NOT NULL DEFAULT 0,
+    country VARCHAR(2),
+    city VARCHAR(100),
+    postal_code VARCHAR(20),
+    latitude DOUBLE PRECISION,
+    longitude DOUBLE PRECISION,
+    is_active BOOLEAN DEFAULT true,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    deleted_at TIMESTAMP WITH TIME ZONE
+);
+
+CREATE INDEX idx_warehouses_is_active ON warehouses(is_active);
+CREATE INDEX idx_warehouses_deleted_at ON warehouses(deleted_at);
+
+-- Existing stock is moved into this warehouse by the next migration
+INSERT INTO warehouses (name, code, priority) VALUES ('Default', 'DEFAULT', 0);
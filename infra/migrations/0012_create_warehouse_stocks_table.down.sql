@@ -0,0 +1,7 @@
+ALTER TABLE products ADD COLUMN IF NOT EXISTS stock INTEGER DEFAULT 0;
+
+UPDATE products p SET stock = COALESCE((
+    SELECT SUM(ws.quantity) FROM warehouse_stocks ws WHERE ws.product_id = p.id
+), 0);
+
+DROP TABLE IF EXISTS warehouse_stocks;
@@ -0,0 +1,18 @@
+CREATE TABLE IF NOT EXISTS warehouse_stocks (
+    id BIGSERIAL PRIMARY KEY,
+    warehouse_id INTEGER NOT NULL REFERENCES warehouses(id) ON DELETE CASCADE,
+    product_id INTEGER NOT NULL REFERENCES products(id) ON DELETE CASCADE,
+    quantity INTEGER NOT NULL DEFAULT 0 CHECK ( quantity >= 0 ),
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    UNIQUE(warehouse_id, product_id)
+);
+
+CREATE INDEX idx_warehouse_stocks_product_id ON warehouse_stocks(product_id);
+
+INSERT INTO warehouse_stocks (warehouse_id, product_id, quantity)
+SELECT w.id, p.id, COALESCE(p.stock, 0)
+FROM products p, warehouses w
+WHERE w.code = 'DEFAULT';
+
+ALTER TABLE products DROP COLUMN IF EXISTS stock;
@@ -0,0 +1,12 @@
+ALTER TABLE order_items DROP COLUMN IF EXISTS warehouse_id;
+
+ALTER TABLE orders
+    DROP COLUMN IF EXISTS shipping_name,
+    DROP COLUMN IF EXISTS shipping_line1,
+    DROP COLUMN IF EXISTS shipping_line2,
+    DROP COLUMN IF EXISTS shipping_city,
+    DROP COLUMN IF EXISTS shipping_state,
+    DROP COLUMN IF EXISTS shipping_postal_code,
+    DROP COLUMN IF EXISTS shipping_country,
+    DROP COLUMN IF EXISTS shipping_latitude,
+    DROP COLUMN IF EXISTS shipping_longitude;
@@ -0,0 +1,18 @@
+ALTER TABLE orders
+    ADD COLUMN shipping_name VARCHAR(255),
+    ADD COLUMN shipping_line1 VARCHAR(255),
+    ADD COLUMN shipping_line2 VARCHAR(255),
+    ADD COLUMN shipping_city VARCHAR(100),
+    ADD COLUMN shipping_state VARCHAR(100),
+    ADD COLUMN shipping_postal_code VARCHAR(20),
+    ADD COLUMN shipping_country VARCHAR(2),
+    ADD COLUMN shipping_latitude DOUBLE PRECISION,
+    ADD COLUMN shipping_longitude DOUBLE PRECISION;
+
+ALTER TABLE order_items ADD COLUMN warehouse_id INTEGER REFERENCES warehouses(id);
+
+UPDATE order_items SET warehouse_id = (SELECT id FROM warehouses WHERE code = 'DEFAULT');
+
+ALTER TABLE order_items ALTER COLUMN warehouse_id SET NOT NULL;
+
+CREATE INDEX idx_order_items_warehouse_id ON order_items(warehouse_id);
@@ -0,0 +1,3 @@
+ALTER TABLE products DROP COLUMN IF EXISTS low_stock_threshold;
+
+ALTER TABLE categories DROP COLUMN IF EXISTS low_stock_threshold;
@@ -0,0 +1,5 @@
+-- 0 disables low-stock alerts for the category
+ALTER TABLE categories ADD COLUMN low_stock_threshold INTEGER NOT NULL DEFAULT 0 CHECK ( low_stock_threshold >= 0 );
+
+-- NULL means the product falls back to its category threshold
+ALTER TABLE products ADD COLUMN low_stock_threshold INTEGER CHECK ( low_stock_threshold >= 0 );
@@ -0,0 +1,11 @@
+UPDATE orders SET status = 'confirmed' WHERE status = 'partially_shipped';
+
+ALTER TYPE order_status RENAME TO order_status_old;
+
+CREATE TYPE order_status AS ENUM ('pending', 'confirmed', 'shipped', 'delivered', 'cancelled');
+
+ALTER TABLE orders ALTER COLUMN status DROP DEFAULT;
+ALTER TABLE orders ALTER COLUMN status TYPE order_status USING status::text::order_status;
+ALTER TABLE orders ALTER COLUMN status SET DEFAULT 'pending';
+
+DROP TYPE order_status_old;
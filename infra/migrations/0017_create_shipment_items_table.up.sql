@@ -0,0 +1,10 @@
+CREATE TABLE IF NOT EXISTS shipment_items (
+    id BIGSERIAL PRIMARY KEY,
+    shipment_id INTEGER NOT NULL REFERENCES shipments(id) ON DELETE CASCADE,
+    order_item_id INTEGER NOT NULL REFERENCES order_items(id) ON DELETE CASCADE,
+    quantity INTEGER NOT NULL CHECK ( quantity > 0 ),
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    UNIQUE (shipment_id, order_item_id)
+);
+
+CREATE INDEX idx_shipment_items_order_item_id ON shipment_items(order_item_id);
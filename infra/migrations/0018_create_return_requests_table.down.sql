@@ -0,0 +1,2 @@
+DROP TABLE IF EXISTS return_requests;
+DROP TYPE IF EXISTS return_status;
@@ -0,0 +1,21 @@
+CREATE TYPE return_status AS ENUM ('requested', 'approved', 'rejected', 'received', 'refunded');
+
+CREATE TABLE IF NOT EXISTS return_requests (
+    id BIGSERIAL PRIMARY KEY,
+    order_id INTEGER NOT NULL REFERENCES orders(id) ON DELETE CASCADE,
+    user_id INTEGER NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    status return_status NOT NULL DEFAULT 'requested',
+    comment TEXT,
+    admin_note TEXT,
+    refund_amount DECIMAL(10,2) NOT NULL DEFAULT 0,
+    refund_reference VARCHAR(255),
+    reviewed_at TIMESTAMP WITH TIME ZONE,
+    received_at TIMESTAMP WITH TIME ZONE,
+    refunded_at TIMESTAMP WITH TIME ZONE,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX idx_return_requests_order_id ON return_requests(order_id);
+CREATE INDEX idx_return_requests_user_id ON return_requests(user_id);
+CREATE INDEX idx_return_requests_status ON return_requests(status);
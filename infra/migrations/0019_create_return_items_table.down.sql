@@ -0,0 +1,2 @@
+DROP TABLE IF EXISTS return_items;
+DROP TYPE IF EXISTS return_reason;
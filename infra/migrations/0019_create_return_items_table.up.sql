@@ -0,0 +1,13 @@
+CREATE TYPE return_reason AS ENUM ('damaged', 'defective', 'wrong_item', 'not_as_described', 'no_longer_needed', 'other');
+
+CREATE TABLE IF NOT EXISTS return_items (
+    id BIGSERIAL PRIMARY KEY,
+    return_request_id INTEGER NOT NULL REFERENCES return_requests(id) ON DELETE CASCADE,
+    order_item_id INTEGER NOT NULL REFERENCES order_items(id) ON DELETE CASCADE,
+    quantity INTEGER NOT NULL CHECK ( quantity > 0 ),
+    reason return_reason NOT NULL,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    UNIQUE (return_request_id, order_item_id)
+);
+
+CREATE INDEX idx_return_items_order_item_id ON return_items(order_item_id);
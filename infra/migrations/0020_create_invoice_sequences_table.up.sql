@@ -0,0 +1,9 @@
+-- A counter row rather than a SEQUENCE: sequences skip numbers on rollback,
+-- while invoice numbers must be gap-free. The row lock taken by the UPDATE
+-- serialises issuing until the confirming transaction commits.
+CREATE TABLE IF NOT EXISTS invoice_sequences (
+    id SMALLINT PRIMARY KEY,
+    last_number BIGINT NOT NULL DEFAULT 0
+);
+
+INSERT INTO invoice_sequences (id, last_number) VALUES (1, 0);
@@ -0,0 +1,4 @@
+ALTER TABLE orders
+    DROP COLUMN IF EXISTS invoice_number,
+    DROP COLUMN IF EXISTS invoiced_at,
+    DROP COLUMN IF EXISTS invoice_path;
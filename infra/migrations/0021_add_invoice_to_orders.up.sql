@@ -0,0 +1,4 @@
+ALTER TABLE orders
+    ADD COLUMN invoice_number VARCHAR(50) UNIQUE,
+    ADD COLUMN invoiced_at TIMESTAMP WITH TIME ZONE,
+    ADD COLUMN invoice_path VARCHAR(255);
@@ -0,0 +1,15 @@
+-- Events are written here in the same transaction as the change that caused
+-- them and forwarded to the message broker by the relay command.
+CREATE TABLE IF NOT EXISTS outbox_messages (
+    id BIGSERIAL PRIMARY KEY,
+    event_type VARCHAR(100) NOT NULL,
+    payload JSONB NOT NULL,
+    attempts INTEGER NOT NULL DEFAULT 0,
+    last_error TEXT,
+    available_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    sent_at TIMESTAMP WITH TIME ZONE,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX idx_outbox_messages_pending ON outbox_messages(available_at, id) WHERE sent_at IS NULL;
+CREATE INDEX idx_outbox_messages_sent_at ON outbox_messages(sent_at) WHERE sent_at IS NOT NULL;
@@ -0,0 +1,3 @@
+DROP TABLE IF EXISTS dead_letters;
+
+ALTER TABLE outbox_messages DROP COLUMN IF EXISTS metadata;
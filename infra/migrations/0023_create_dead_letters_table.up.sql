@@ -0,0 +1,16 @@
+ALTER TABLE outbox_messages ADD COLUMN metadata JSONB;
+
+-- Messages the notifier gave up on after exhausting its retries. They are
+-- kept for inspection and can be replayed through the outbox.
+CREATE TABLE IF NOT EXISTS dead_letters (
+    id BIGSERIAL PRIMARY KEY,
+    event_type VARCHAR(100) NOT NULL,
+    payload JSONB NOT NULL,
+    metadata JSONB,
+    attempts INTEGER NOT NULL,
+    last_error TEXT,
+    replayed_at TIMESTAMP WITH TIME ZONE,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX idx_dead_letters_created_at ON dead_letters(created_at);
@@ -0,0 +1,4 @@
+ALTER TABLE refresh_tokens
+    ADD COLUMN user_agent VARCHAR(512),
+    ADD COLUMN ip_address VARCHAR(45),
+    ADD COLUMN last_used_at TIMESTAMP WITH TIME ZONE;
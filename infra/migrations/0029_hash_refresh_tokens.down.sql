@@ -0,0 +1,10 @@
+DROP TABLE IF EXISTS rotated_refresh_tokens;
+
+-- Raw tokens cannot be recovered from their hashes, so every session ends.
+DELETE FROM refresh_tokens;
+
+ALTER TABLE refresh_tokens
+    DROP COLUMN IF EXISTS token_hash,
+    ADD COLUMN token VARCHAR(500) UNIQUE NOT NULL;
+
+CREATE INDEX idx_refresh_tokens_token ON refresh_tokens(token);
@@ -0,0 +1,20 @@
+-- Refresh tokens are stored as SHA-256 hashes. Each row is a token family:
+-- one session whose token is rotated on every refresh. The hashes of
+-- rotated tokens are kept to detect their reuse.
+ALTER TABLE refresh_tokens ADD COLUMN token_hash VARCHAR(64);
+
+UPDATE refresh_tokens SET token_hash = encode(sha256(convert_to(token, 'UTF8')), 'hex');
+
+ALTER TABLE refresh_tokens
+    ALTER COLUMN token_hash SET NOT NULL,
+    ADD CONSTRAINT refresh_tokens_token_hash_key UNIQUE (token_hash),
+    DROP COLUMN token;
+
+CREATE TABLE IF NOT EXISTS rotated_refresh_tokens (
+    id BIGSERIAL PRIMARY KEY,
+    refresh_token_id BIGINT NOT NULL REFERENCES refresh_tokens(id) ON DELETE CASCADE,
+    token_hash VARCHAR(64) NOT NULL UNIQUE,
+    rotated_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX idx_rotated_refresh_tokens_refresh_token_id ON rotated_refresh_tokens(refresh_token_id);
@@ -0,0 +1,30 @@
+-- Permissions are defined in code; a role grants a set of them. The
+-- customer and admin roles are built in: customers have no permissions and
+-- admins have every permission, whatever is stored here.
+CREATE TABLE IF NOT EXISTS roles (
+    id BIGSERIAL PRIMARY KEY,
+    name VARCHAR(50) UNIQUE NOT NULL,
+    description TEXT NOT NULL DEFAULT '',
+    permissions JSONB NOT NULL DEFAULT '[]',
+    is_system BOOLEAN NOT NULL DEFAULT false,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP
+);
+
+INSERT INTO roles (name, description, is_system) VALUES
+    ('customer', 'Shops on the storefront', true),
+    ('admin', 'Has every permission', true);
+
+INSERT INTO roles (name, description, permissions) VALUES
+    ('catalog_manager', 'Maintains products, categories and stock', '["catalog:write", "inventory:manage"]'),
+    ('support_agent', 'Handles orders, shipments and returns', '["orders:manage", "returns:manage"]');
+
+UPDATE users SET role = 'customer' WHERE role IS NULL;
+
+ALTER TABLE users ALTER COLUMN role DROP DEFAULT;
+ALTER TABLE users ALTER COLUMN role TYPE VARCHAR(50) USING role::text;
+ALTER TABLE users ALTER COLUMN role SET DEFAULT 'customer';
+ALTER TABLE users ALTER COLUMN role SET NOT NULL;
+ALTER TABLE users ADD CONSTRAINT fk_users_role FOREIGN KEY (role) REFERENCES roles(name);
+
+DROP TYPE IF EXISTS user_role;
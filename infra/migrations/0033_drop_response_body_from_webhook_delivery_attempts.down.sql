@@ -0,0 +1,2 @@
+ALTER TABLE webhook_delivery_attempts
+    ADD COLUMN response_body TEXT;
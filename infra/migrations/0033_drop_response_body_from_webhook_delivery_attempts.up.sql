@@ -0,0 +1,3 @@
+-- Endpoints may answer with anything, so their responses are not kept.
+ALTER TABLE webhook_delivery_attempts
+    DROP COLUMN IF EXISTS response_body;
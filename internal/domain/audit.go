@@ -0,0 +1,27 @@
+package domain
+
+import "time"
+
+type AuditAction string
+
+const (
+	AuditActionUserDeactivated     AuditAction = "user.deactivated"
+	AuditActionUserReactivated     AuditAction = "user.reactivated"
+	AuditActionUserRoleChanged     AuditAction = "user.role_changed"
+	AuditActionUserDeleted         AuditAction = "user.deleted"
+	AuditActionUserSessionsRevoked AuditAction = "user.sessions_revoked"
+	AuditActionUserUnlocked        AuditAction = "user.unlocked"
+)
+
+// AuditLog records an action a staff member, the actor, took on the
+// account of a user, with the client it came from.
+type AuditLog struct {
+	Id        uint              `json:"id" gorm:"primaryKey"`
+	ActorId   uint              `json:"actor_id" gorm:"not null"`
+	UserId    uint              `json:"user_id" gorm:"not null"`
+	Action    AuditAction       `json:"action" gorm:"not null"`
+	Details   map[string]string `json:"details" gorm:"serializer:json;type:jsonb;not null"`
+	IPAddress string            `json:"ip_address"`
+	UserAgent string            `json:"user_agent"`
+	CreatedAt time.Time         `json:"created_at"`
+}
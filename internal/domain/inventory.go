@@ -0,0 +1,58 @@
+package domain
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// DefaultWarehouseCode is the code of the warehouse created with the
+// inventory. Stock set on a product without naming a warehouse is held
+// there.
+const DefaultWarehouseCode = "DEFAULT"
+
+type Warehouse struct {
+	Id         uint           `json:"id" gorm:"primaryKey"`
+	Name       string         `json:"name" gorm:"not null"`
+	Code       string         `json:"code" gorm:"uniqueIndex;not null"`
+	Priority   int            `json:"priority" gorm:"default:0"`
+	Country    string         `json:"country"`
+	City       string         `json:"city"`
+	PostalCode string         `json:"postal_code"`
+	Latitude   *float64       `json:"latitude"`
+	Longitude  *float64       `json:"longitude"`
+	IsActive   bool           `json:"is_active" gorm:"default:true"`
+	CreatedAt  time.Time      `json:"created_at"`
+	UpdatedAt  time.Time      `json:"updated_at"`
+	DeletedAt  gorm.DeletedAt `json:"-" gorm:"index"`
+
+	Stocks []WarehouseStock `json:"-"`
+}
+
+type WarehouseStock struct {
+	Id          uint      `json:"id" gorm:"primaryKey"`
+	WarehouseId uint      `json:"warehouse_id" gorm:"not null"`
+	ProductId   uint      `json:"product_id" gorm:"not null"`
+	Quantity    int       `json:"quantity" gorm:"not null;default:0"`
+	CreatedAt   time.Time `json:"created_at"`
+	UpdatedAt   time.Time `json:"updated_at"`
+
+	Warehouse Warehouse `json:"warehouse"`
+	Product   Product   `json:"-"`
+}
+
+type LowStockProduct struct {
+	ProductId uint   `json:"product_id"`
+	Name      string `json:"name"`
+	SKU       string `json:"sku"`
+	Stock     int    `json:"stock"`
+	Threshold int    `json:"threshold"`
+}
+
+type FulfilmentStrategy string
+
+const (
+	FulfilmentStrategyPriority     FulfilmentStrategy = "priority"
+	FulfilmentStrategyNearest      FulfilmentStrategy = "nearest"
+	FulfilmentStrategyFewestSplits FulfilmentStrategy = "fewest_splits"
+)
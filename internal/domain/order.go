@@ -7,39 +7,143 @@ import (
 )
 
 type Order struct {
-	Id          uint           `json:"id" gorm:"primaryKey"`
-	UserId      uint           `json:"user_id" gorm:"not null"`
-	Status      OrderStatus    `json:"status" gorm:"default:pending"`
-	TotalAmount float64        `json:"total_amount" gorm:"not null"`
-	CreatedAt   time.Time      `json:"created_at"`
-	UpdatedAt   time.Time      `json:"updated_at"`
-	DeletedAt   gorm.DeletedAt `json:"-" gorm:"index"`
+	Id              uint           `json:"id" gorm:"primaryKey"`
+	UserId          uint           `json:"user_id" gorm:"not null"`
+	Status          OrderStatus    `json:"status" gorm:"default:pending"`
+	TotalAmount     float64        `json:"total_amount" gorm:"not null"`
+	ShippingAddress Address        `json:"shipping_address" gorm:"embedded;embeddedPrefix:shipping_"`
+	InvoiceNumber   *string        `json:"invoice_number" gorm:"uniqueIndex"`
+	InvoicedAt      *time.Time     `json:"invoiced_at"`
+	InvoicePath     string         `json:"-"`
+	CreatedAt       time.Time      `json:"created_at"`
+	UpdatedAt       time.Time      `json:"updated_at"`
+	DeletedAt       gorm.DeletedAt `json:"-" gorm:"index"`
 
 	User       User        `json:"user"`
 	OrderItems []OrderItem `json:"order_items"`
+	Shipments  []Shipment  `json:"shipments"`
+}
+
+// ShippedQuantities returns how many units of each order item, keyed by its
+// id, have been shipped so far. Shipments.Items must be preloaded.
+func (o *Order) ShippedQuantities() map[uint]int {
+	shipped := make(map[uint]int)
+	for i := range o.Shipments {
+		for _, item := range o.Shipments[i].Items {
+			shipped[item.OrderItemId] += item.Quantity
+		}
+	}
+	return shipped
+}
+
+// DeliveredQuantities returns how many units of each order item, keyed by its
+// id, have reached the customer. Shipments.Items must be preloaded.
+func (o *Order) DeliveredQuantities() map[uint]int {
+	delivered := make(map[uint]int)
+	for i := range o.Shipments {
+		if o.Shipments[i].Status != ShipmentStatusDelivered {
+			continue
+		}
+		for _, item := range o.Shipments[i].Items {
+			delivered[item.OrderItemId] += item.Quantity
+		}
+	}
+	return delivered
+}
+
+// FulfilmentStatus derives the order status from its shipments. Cancelled
+// orders and orders with nothing shipped yet keep their current status.
+// OrderItems and Shipments.Items must be preloaded.
+func (o *Order) FulfilmentStatus() OrderStatus {
+	if o.Status == OrderStatusCancelled || len(o.Shipments) == 0 {
+		return o.Status
+	}
+
+	shipped := o.ShippedQuantities()
+	for _, item := range o.OrderItems {
+		if shipped[item.Id] < item.Quantity {
+			return OrderStatusPartiallyShipped
+		}
+	}
+
+	for i := range o.Shipments {
+		if o.Shipments[i].Status != ShipmentStatusDelivered {
+			return OrderStatusShipped
+		}
+	}
+
+	return OrderStatusDelivered
 }
 
 type OrderStatus string
 
 const (
-	OrderStatusPending   OrderStatus = "pending"
-	OrderStatusConfirmed OrderStatus = "confirmed"
-	OrderStatusShipped   OrderStatus = "shipped"
-	OrderStatusDelivered OrderStatus = "delivered"
-	OrderStatusCancelled OrderStatus = "cancelled"
+	OrderStatusPending          OrderStatus = "pending"
+	OrderStatusConfirmed        OrderStatus = "confirmed"
+	OrderStatusPartiallyShipped OrderStatus = "partially_shipped"
+	OrderStatusShipped          OrderStatus = "shipped"
+	OrderStatusDelivered        OrderStatus = "delivered"
+	OrderStatusCancelled        OrderStatus = "cancelled"
 )
 
+type Address struct {
+	Name       string   `json:"name"`
+	Line1      string   `json:"line1"`
+	Line2      string   `json:"line2"`
+	City       string   `json:"city"`
+	State      string   `json:"state"`
+	PostalCode string   `json:"postal_code"`
+	Country    string   `json:"country"`
+	Latitude   *float64 `json:"latitude"`
+	Longitude  *float64 `json:"longitude"`
+}
+
 type OrderItem struct {
-	Id        uint           `json:"id" gorm:"primaryKey"`
-	OrderId   uint           `json:"order_id" gorm:"not null"`
-	ProductId uint           `json:"product_id" gorm:"not null"`
-	Quantity  int            `json:"quantity" gorm:"not null"`
-	Price     float64        `json:"price" gorm:"not null"`
-	CreatedAt time.Time      `json:"created_at"`
-	DeletedAt gorm.DeletedAt `json:"-" gorm:"index"`
+	Id          uint           `json:"id" gorm:"primaryKey"`
+	OrderId     uint           `json:"order_id" gorm:"not null"`
+	ProductId   uint           `json:"product_id" gorm:"not null"`
+	WarehouseId uint           `json:"warehouse_id" gorm:"not null"`
+	Quantity    int            `json:"quantity" gorm:"not null"`
+	Price       float64        `json:"price" gorm:"not null"`
+	CreatedAt   time.Time      `json:"created_at"`
+	DeletedAt   gorm.DeletedAt `json:"-" gorm:"index"`
 
-	Order   Order   `json:"-"`
-	Product Product `json:"product"`
+	Order     Order     `json:"-"`
+	Product   Product   `json:"product"`
+	Warehouse Warehouse `json:"warehouse"`
+}
+
+type Shipment struct {
+	Id             uint           `json:"id" gorm:"primaryKey"`
+	OrderId        uint           `json:"order_id" gorm:"not null"`
+	Carrier        string         `json:"carrier" gorm:"not null"`
+	TrackingNumber string         `json:"tracking_number" gorm:"not null"`
+	Status         ShipmentStatus `json:"status" gorm:"default:shipped"`
+	ShippedAt      time.Time      `json:"shipped_at"`
+	DeliveredAt    *time.Time     `json:"delivered_at"`
+	CreatedAt      time.Time      `json:"created_at"`
+	UpdatedAt      time.Time      `json:"updated_at"`
+
+	Order Order          `json:"-"`
+	Items []ShipmentItem `json:"items"`
+}
+
+type ShipmentStatus string
+
+const (
+	ShipmentStatusShipped   ShipmentStatus = "shipped"
+	ShipmentStatusDelivered ShipmentStatus = "delivered"
+)
+
+type ShipmentItem struct {
+	Id          uint      `json:"id" gorm:"primaryKey"`
+	ShipmentId  uint      `json:"shipment_id" gorm:"not null"`
+	OrderItemId uint      `json:"order_item_id" gorm:"not null"`
+	Quantity    int       `json:"quantity" gorm:"not null"`
+	CreatedAt   time.Time `json:"created_at"`
+
+	Shipment  Shipment  `json:"-"`
+	OrderItem OrderItem `json:"-"`
 }
 
 type Cart struct {
@@ -0,0 +1,29 @@
+package domain
+
+import "time"
+
+// OutboxMessage is an event waiting to be relayed to the message broker.
+type OutboxMessage struct {
+	Id          uint              `json:"id" gorm:"primaryKey"`
+	EventType   string            `json:"event_type" gorm:"not null"`
+	Payload     []byte            `json:"payload" gorm:"type:jsonb;not null"`
+	Metadata    map[string]string `json:"metadata" gorm:"serializer:json;type:jsonb"`
+	Attempts    int               `json:"attempts" gorm:"not null;default:0"`
+	LastError   string            `json:"last_error"`
+	AvailableAt time.Time         `json:"available_at" gorm:"not null"`
+	SentAt      *time.Time        `json:"sent_at"`
+	CreatedAt   time.Time         `json:"created_at"`
+}
+
+// DeadLetter is a message the notifier could not handle within its retry
+// budget.
+type DeadLetter struct {
+	Id         uint              `json:"id" gorm:"primaryKey"`
+	EventType  string            `json:"event_type" gorm:"not null"`
+	Payload    []byte            `json:"payload" gorm:"type:jsonb;not null"`
+	Metadata   map[string]string `json:"metadata" gorm:"serializer:json;type:jsonb"`
+	Attempts   int               `json:"attempts" gorm:"not null"`
+	LastError  string            `json:"last_error"`
+	ReplayedAt *time.Time        `json:"replayed_at"`
+	CreatedAt  time.Time         `json:"created_at"`
+}
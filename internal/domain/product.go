@@ -7,34 +7,58 @@ import (
 )
 
 type Category struct {
-	Id          uint           `json:"id" gorm:"primaryKey"`
-	Name        string         `json:"name" gorm:"not null"`
-	Description string         `json:"description"`
-	IsActive    bool           `json:"is_active" gorm:"default:true"`
-	CreatedAt   time.Time      `json:"created_at"`
-	UpdatedAt   time.Time      `json:"updated_at"`
-	DeletedAt   gorm.DeletedAt `json:"-" gorm:"index"`
+	Id                uint           `json:"id" gorm:"primaryKey"`
+	Name              string         `json:"name" gorm:"not null"`
+	Description       string         `json:"description"`
+	IsActive          bool           `json:"is_active" gorm:"default:true"`
+	LowStockThreshold int            `json:"low_stock_threshold" gorm:"not null;default:0"`
+	CreatedAt         time.Time      `json:"created_at"`
+	UpdatedAt         time.Time      `json:"updated_at"`
+	DeletedAt         gorm.DeletedAt `json:"-" gorm:"index"`
 
 	Products []Product `json:"-"`
 }
 
 type Product struct {
-	Id          uint           `json:"id" gorm:"primaryKey"`
-	CategoryId  uint           `json:"category_id" gorm:"not null"`
-	Name        string         `json:"name" gorm:"not null"`
-	Description string         `json:"description"`
-	Price       float64        `json:"price" gorm:"not null"`
-	Stock       int            `json:"stock" gorm:"default:0"`
-	SKU         string         `json:"sku" gorm:"uniqueIndex;not null"`
-	IsActive    bool           `json:"is_active" gorm:"default:true"`
-	CreatedAt   time.Time      `json:"created_at"`
-	UpdatedAt   time.Time      `json:"updated_at"`
-	DeletedAt   gorm.DeletedAt `json:"-" gorm:"index"`
-
-	Category   Category       `json:"Category"`
-	Images     []ProductImage `json:"images"`
-	OrderItems []OrderItem    `json:"-"`
-	CartItems  []CartItem     `json:"-"`
+	Id                uint           `json:"id" gorm:"primaryKey"`
+	CategoryId        uint           `json:"category_id" gorm:"not null"`
+	Name              string         `json:"name" gorm:"not null"`
+	Description       string         `json:"description"`
+	Price             float64        `json:"price" gorm:"not null"`
+	SKU               string         `json:"sku" gorm:"uniqueIndex;not null"`
+	IsActive          bool           `json:"is_active" gorm:"default:true"`
+	LowStockThreshold *int           `json:"low_stock_threshold"`
+	CreatedAt         time.Time      `json:"created_at"`
+	UpdatedAt         time.Time      `json:"updated_at"`
+	DeletedAt         gorm.DeletedAt `json:"-" gorm:"index"`
+
+	Category   Category         `json:"Category"`
+	Images     []ProductImage   `json:"images"`
+	Stocks     []WarehouseStock `json:"stocks"`
+	OrderItems []OrderItem      `json:"-"`
+	CartItems  []CartItem       `json:"-"`
+}
+
+// TotalStock sums the stock held for the product across active warehouses.
+// Stocks.Warehouse must be preloaded.
+func (p *Product) TotalStock() int {
+	var total int
+	for i := range p.Stocks {
+		if p.Stocks[i].Warehouse.IsActive {
+			total += p.Stocks[i].Quantity
+		}
+	}
+	return total
+}
+
+// StockThreshold returns the product's own low-stock threshold, falling back
+// to its category's. A threshold of zero disables low-stock alerts. Category
+// must be preloaded.
+func (p *Product) StockThreshold() int {
+	if p.LowStockThreshold != nil {
+		return *p.LowStockThreshold
+	}
+	return p.Category.LowStockThreshold
 }
 
 type ProductImage struct {
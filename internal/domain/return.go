@@ -0,0 +1,76 @@
+package domain
+
+import "time"
+
+type ReturnRequest struct {
+	Id              uint         `json:"id" gorm:"primaryKey"`
+	OrderId         uint         `json:"order_id" gorm:"not null"`
+	UserId          uint         `json:"user_id" gorm:"not null"`
+	Status          ReturnStatus `json:"status" gorm:"default:requested"`
+	Comment         string       `json:"comment"`
+	AdminNote       string       `json:"admin_note"`
+	RefundAmount    float64      `json:"refund_amount" gorm:"not null;default:0"`
+	RefundReference string       `json:"refund_reference"`
+	ReviewedAt      *time.Time   `json:"reviewed_at"`
+	ReceivedAt      *time.Time   `json:"received_at"`
+	RefundedAt      *time.Time   `json:"refunded_at"`
+	CreatedAt       time.Time    `json:"created_at"`
+	UpdatedAt       time.Time    `json:"updated_at"`
+
+	Order Order        `json:"-"`
+	User  User         `json:"-"`
+	Items []ReturnItem `json:"items"`
+}
+
+type ReturnStatus string
+
+const (
+	ReturnStatusRequested ReturnStatus = "requested"
+	ReturnStatusApproved  ReturnStatus = "approved"
+	ReturnStatusRejected  ReturnStatus = "rejected"
+	ReturnStatusReceived  ReturnStatus = "received"
+	ReturnStatusRefunded  ReturnStatus = "refunded"
+)
+
+func (s ReturnStatus) IsValid() bool {
+	switch s {
+	case ReturnStatusRequested, ReturnStatusApproved, ReturnStatusRejected,
+		ReturnStatusReceived, ReturnStatusRefunded:
+		return true
+	default:
+		return false
+	}
+}
+
+type ReturnItem struct {
+	Id              uint         `json:"id" gorm:"primaryKey"`
+	ReturnRequestId uint         `json:"return_request_id" gorm:"not null"`
+	OrderItemId     uint         `json:"order_item_id" gorm:"not null"`
+	Quantity        int          `json:"quantity" gorm:"not null"`
+	Reason          ReturnReason `json:"reason" gorm:"not null"`
+	CreatedAt       time.Time    `json:"created_at"`
+
+	ReturnRequest ReturnRequest `json:"-"`
+	OrderItem     OrderItem     `json:"order_item"`
+}
+
+type ReturnReason string
+
+const (
+	ReturnReasonDamaged        ReturnReason = "damaged"
+	ReturnReasonDefective      ReturnReason = "defective"
+	ReturnReasonWrongItem      ReturnReason = "wrong_item"
+	ReturnReasonNotAsDescribed ReturnReason = "not_as_described"
+	ReturnReasonNoLongerNeeded ReturnReason = "no_longer_needed"
+	ReturnReasonOther          ReturnReason = "other"
+)
+
+func (r ReturnReason) IsValid() bool {
+	switch r {
+	case ReturnReasonDamaged, ReturnReasonDefective, ReturnReasonWrongItem,
+		ReturnReasonNotAsDescribed, ReturnReasonNoLongerNeeded, ReturnReasonOther:
+		return true
+	default:
+		return false
+	}
+}
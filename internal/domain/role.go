@@ -0,0 +1,55 @@
+package domain
+
+import "time"
+
+// Permission allows an operation on the back office. Permissions are
+// defined in code and granted through roles.
+type Permission string
+
+const (
+	PermissionCatalogWrite    Permission = "catalog:write"
+	PermissionInventoryManage Permission = "inventory:manage"
+	PermissionOrdersManage    Permission = "orders:manage"
+	PermissionOrdersRefund    Permission = "orders:refund"
+	PermissionReturnsManage   Permission = "returns:manage"
+	PermissionUsersManage     Permission = "users:manage"
+	PermissionRolesManage     Permission = "roles:manage"
+	PermissionWebhooksManage  Permission = "webhooks:manage"
+	PermissionTemplatesRead   Permission = "templates:read"
+)
+
+// Permissions returns every permission, e.g. to validate roles.
+func Permissions() []Permission {
+	return []Permission{
+		PermissionCatalogWrite,
+		PermissionInventoryManage,
+		PermissionOrdersManage,
+		PermissionOrdersRefund,
+		PermissionReturnsManage,
+		PermissionUsersManage,
+		PermissionRolesManage,
+		PermissionWebhooksManage,
+		PermissionTemplatesRead,
+	}
+}
+
+// Role is a named set of permissions assigned to users. System roles, the
+// customer and admin roles, cannot be changed or deleted.
+type Role struct {
+	Id          uint         `json:"id" gorm:"primaryKey"`
+	Name        UserRole     `json:"name" gorm:"uniqueIndex;not null"`
+	Description string       `json:"description"`
+	Permissions []Permission `json:"permissions" gorm:"serializer:json;type:jsonb;not null"`
+	IsSystem    bool         `json:"is_system" gorm:"not null;default:false"`
+	CreatedAt   time.Time    `json:"created_at"`
+	UpdatedAt   time.Time    `json:"updated_at"`
+}
+
+// GrantedPermissions returns the permissions the role grants; the admin
+// role grants all of them.
+func (r *Role) GrantedPermissions() []Permission {
+	if r.Name == UserRoleAdmin {
+		return Permissions()
+	}
+	return r.Permissions
+}
@@ -7,22 +7,28 @@ import (
 )
 
 type User struct {
-	Id            uint           `json:"id" gorm:"primaryKey"`
-	Email         string         `json:"email" gorm:"uniqueIndex;not null"`
-	Password      string         `json:"-" gorm:"not null"`
-	FirstName     string         `json:"first_name" gorm:"not null"`
-	LastName      string         `json:"last_name" gorm:"not null"`
-	Phone         string         `json:"phone"`
-	IsActive      bool           `json:"is_active" gorm:"default:true"`
-	Role          UserRole       `json:"role" gorm:"default:customer"`
-	CreatedAt     time.Time      `json:"created_at"`
-	UpdatedAt     time.Time      `json:"updated_at"`
-	DeletedAt     gorm.DeletedAt `json:"deleted_at" gorm:"index"`
-	RefreshTokens []RefreshToken `json:"-"`
-	Orders        []Order        `json:"-"`
-	Cart          Cart           `json:"-"`
+	Id                 uint           `json:"id" gorm:"primaryKey"`
+	Email              string         `json:"email" gorm:"uniqueIndex;not null"`
+	Password           string         `json:"-" gorm:"not null"`
+	FirstName          string         `json:"first_name" gorm:"not null"`
+	LastName           string         `json:"last_name" gorm:"not null"`
+	Phone              string         `json:"phone"`
+	IsActive           bool           `json:"is_active" gorm:"default:true"`
+	Role               UserRole       `json:"role" gorm:"default:customer"`
+	EmailVerifiedAt    *time.Time     `json:"email_verified_at"`
+	VerificationSentAt *time.Time     `json:"-"`
+	TOTPSecret         string         `json:"-"`
+	TOTPEnabledAt      *time.Time     `json:"-"`
+	TOTPLastStep       *int64         `json:"-"`
+	CreatedAt          time.Time      `json:"created_at"`
+	UpdatedAt          time.Time      `json:"updated_at"`
+	DeletedAt          gorm.DeletedAt `json:"deleted_at" gorm:"index"`
+	RefreshTokens      []RefreshToken `json:"-"`
+	Orders             []Order        `json:"-"`
+	Cart               Cart           `json:"-"`
 }
 
+// UserRole is the name of the Role of a user.
 type UserRole string
 
 const (
@@ -30,13 +36,70 @@ const (
 	UserRoleAdmin    UserRole = "admin"
 )
 
+// EmailVerificationRequirement is what an unverified user is kept from
+// doing.
+type EmailVerificationRequirement string
+
+const (
+	EmailVerificationOptional    EmailVerificationRequirement = "none"
+	EmailVerificationForLogin    EmailVerificationRequirement = "login"
+	EmailVerificationForCheckout EmailVerificationRequirement = "checkout"
+)
+
+// RefreshToken is a token family: a session of a user on one device. The
+// token is rotated on every refresh while the session keeps its id, and
+// only the hash of the current token is stored.
 type RefreshToken struct {
-	Id        uint           `json:"id" gorm:"primaryKey"`
-	UserId    uint           `json:"user_id" gorm:"not null"`
-	Token     string         `json:"token" gorm:"uniqueIndex;not null"`
-	ExpiresAt time.Time      `json:"expires_at" gorm:"not null"`
-	CreatedAt time.Time      `json:"created_at"`
-	DeletedAt gorm.DeletedAt `json:"-" gorm:"index"`
+	Id         uint           `json:"id" gorm:"primaryKey"`
+	UserId     uint           `json:"user_id" gorm:"not null"`
+	TokenHash  string         `json:"-" gorm:"uniqueIndex;not null"`
+	UserAgent  string         `json:"user_agent"`
+	IPAddress  string         `json:"ip_address"`
+	ExpiresAt  time.Time      `json:"expires_at" gorm:"not null"`
+	LastUsedAt *time.Time     `json:"last_used_at"`
+	CreatedAt  time.Time      `json:"created_at"`
+	DeletedAt  gorm.DeletedAt `json:"-" gorm:"index"`
 
 	User User `json:"-"`
 }
+
+// RotatedRefreshToken is a token of a family that was replaced by a
+// refresh. Presenting it again means it leaked.
+type RotatedRefreshToken struct {
+	Id             uint      `json:"id" gorm:"primaryKey"`
+	RefreshTokenId uint      `json:"refresh_token_id" gorm:"not null"`
+	TokenHash      string    `json:"-" gorm:"uniqueIndex;not null"`
+	RotatedAt      time.Time `json:"rotated_at" gorm:"not null"`
+}
+
+// PasswordResetToken is a single-use password reset link. Only the hash of
+// the token is stored.
+type PasswordResetToken struct {
+	Id        uint       `json:"id" gorm:"primaryKey"`
+	UserId    uint       `json:"user_id" gorm:"not null"`
+	TokenHash string     `json:"-" gorm:"uniqueIndex;not null"`
+	ExpiresAt time.Time  `json:"expires_at" gorm:"not null"`
+	UsedAt    *time.Time `json:"used_at"`
+	CreatedAt time.Time  `json:"created_at"`
+}
+
+// UserIdentity links a user to their account at an OpenID Connect
+// provider, so they can log in through it.
+type UserIdentity struct {
+	Id        uint      `json:"id" gorm:"primaryKey"`
+	UserId    uint      `json:"user_id" gorm:"not null"`
+	Provider  string    `json:"provider" gorm:"not null"`
+	Subject   string    `json:"subject" gorm:"not null"`
+	Email     string    `json:"email"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// RecoveryCode is a single-use code that replaces a TOTP code when the
+// user lost their authenticator. Only the hash of the code is stored.
+type RecoveryCode struct {
+	Id        uint       `json:"id" gorm:"primaryKey"`
+	UserId    uint       `json:"user_id" gorm:"not null"`
+	CodeHash  string     `json:"-" gorm:"not null"`
+	UsedAt    *time.Time `json:"used_at"`
+	CreatedAt time.Time  `json:"created_at"`
+}
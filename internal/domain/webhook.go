@@ -0,0 +1,55 @@
+package domain
+
+import "time"
+
+// WebhookEndpoint is a merchant URL that receives the events it subscribes
+// to, signed with its secret.
+type WebhookEndpoint struct {
+	Id          uint      `json:"id" gorm:"primaryKey"`
+	URL         string    `json:"url" gorm:"not null"`
+	Description string    `json:"description"`
+	Secret      string    `json:"-" gorm:"not null"`
+	EventTypes  []string  `json:"event_types" gorm:"serializer:json;type:jsonb;not null"`
+	IsActive    bool      `json:"is_active" gorm:"not null;default:true"`
+	CreatedAt   time.Time `json:"created_at"`
+	UpdatedAt   time.Time `json:"updated_at"`
+}
+
+type WebhookDeliveryStatus string
+
+const (
+	WebhookDeliveryStatusPending   WebhookDeliveryStatus = "pending"
+	WebhookDeliveryStatusSucceeded WebhookDeliveryStatus = "succeeded"
+	WebhookDeliveryStatusFailed    WebhookDeliveryStatus = "failed"
+)
+
+// WebhookDelivery is one event sent to one endpoint.
+type WebhookDelivery struct {
+	Id             uint                  `json:"id" gorm:"primaryKey"`
+	EndpointId     uint                  `json:"endpoint_id" gorm:"not null"`
+	EventId        string                `json:"event_id" gorm:"not null"`
+	EventType      string                `json:"event_type" gorm:"not null"`
+	Payload        []byte                `json:"payload" gorm:"type:jsonb;not null"`
+	Status         WebhookDeliveryStatus `json:"status" gorm:"not null;default:pending"`
+	Attempts       int                   `json:"attempts" gorm:"not null;default:0"`
+	LastStatusCode *int                  `json:"last_status_code"`
+	LastError      string                `json:"last_error"`
+	NextAttemptAt  time.Time             `json:"next_attempt_at" gorm:"not null"`
+	DeliveredAt    *time.Time            `json:"delivered_at"`
+	CreatedAt      time.Time             `json:"created_at"`
+	UpdatedAt      time.Time             `json:"updated_at"`
+
+	Endpoint WebhookEndpoint          `json:"-"`
+	History  []WebhookDeliveryAttempt `json:"history" gorm:"foreignKey:DeliveryId"`
+}
+
+// WebhookDeliveryAttempt records the outcome of one HTTP request of a
+// delivery.
+type WebhookDeliveryAttempt struct {
+	Id         uint      `json:"id" gorm:"primaryKey"`
+	DeliveryId uint      `json:"delivery_id" gorm:"not null"`
+	StatusCode *int      `json:"status_code"`
+	Error      string    `json:"error"`
+	DurationMs int       `json:"duration_ms" gorm:"not null"`
+	CreatedAt  time.Time `json:"created_at"`
+}
@@ -0,0 +1,61 @@
+package dto
+
+import "time"
+
+type CreateWarehouseRequest struct {
+	Name       string   `json:"name" binding:"required"`
+	Code       string   `json:"code" binding:"required"`
+	Priority   int      `json:"priority"`
+	Country    string   `json:"country" binding:"omitempty,len=2"`
+	City       string   `json:"city"`
+	PostalCode string   `json:"postal_code"`
+	Latitude   *float64 `json:"latitude" binding:"omitempty,min=-90,max=90"`
+	Longitude  *float64 `json:"longitude" binding:"omitempty,min=-180,max=180"`
+}
+
+type UpdateWarehouseRequest struct {
+	Name       string   `json:"name" binding:"required"`
+	Priority   int      `json:"priority"`
+	Country    string   `json:"country" binding:"omitempty,len=2"`
+	City       string   `json:"city"`
+	PostalCode string   `json:"postal_code"`
+	Latitude   *float64 `json:"latitude" binding:"omitempty,min=-90,max=90"`
+	Longitude  *float64 `json:"longitude" binding:"omitempty,min=-180,max=180"`
+	IsActive   *bool    `json:"is_active"`
+}
+
+type WarehouseResponse struct {
+	Id         uint      `json:"id"`
+	Name       string    `json:"name"`
+	Code       string    `json:"code"`
+	Priority   int       `json:"priority"`
+	Country    string    `json:"country"`
+	City       string    `json:"city"`
+	PostalCode string    `json:"postal_code"`
+	Latitude   *float64  `json:"latitude"`
+	Longitude  *float64  `json:"longitude"`
+	IsActive   bool      `json:"is_active"`
+	CreatedAt  time.Time `json:"created_at"`
+	UpdatedAt  time.Time `json:"updated_at"`
+}
+
+type SetStockLevelRequest struct {
+	WarehouseId uint `json:"warehouse_id" binding:"required"`
+	Quantity    int  `json:"quantity" binding:"min=0"`
+}
+
+type StockLevelResponse struct {
+	WarehouseId   uint      `json:"warehouse_id"`
+	WarehouseCode string    `json:"warehouse_code"`
+	WarehouseName string    `json:"warehouse_name"`
+	Quantity      int       `json:"quantity"`
+	UpdatedAt     time.Time `json:"updated_at"`
+}
+
+type LowStockProductResponse struct {
+	ProductId uint   `json:"product_id"`
+	Name      string `json:"name"`
+	SKU       string `json:"sku"`
+	Stock     int    `json:"stock"`
+	Threshold int    `json:"threshold"`
+}
@@ -1,7 +1,40 @@
 package dto
 
+import (
+	"encoding/json"
+	"time"
+)
+
 type Email struct {
 	To      string
 	Subject string
 	Body    string
+	HTML    string
+}
+
+type EmailPreviewResponse struct {
+	Template string `json:"template"`
+	Locale   string `json:"locale"`
+	Subject  string `json:"subject"`
+	Text     string `json:"text"`
+	HTML     string `json:"html"`
+}
+
+// FailedMessage is a message the notifier could not handle.
+type FailedMessage struct {
+	EventType string
+	Payload   []byte
+	Metadata  map[string]string
+	Error     string
+}
+
+type DeadLetterResponse struct {
+	Id         uint              `json:"id"`
+	EventType  string            `json:"event_type"`
+	Payload    json.RawMessage   `json:"payload"`
+	Metadata   map[string]string `json:"metadata"`
+	Attempts   int               `json:"attempts"`
+	LastError  string            `json:"last_error"`
+	ReplayedAt *time.Time        `json:"replayed_at"`
+	CreatedAt  time.Time         `json:"created_at"`
 }
@@ -29,20 +29,135 @@ type CartItemResponse struct {
 	UpdatedAt time.Time       `json:"updated_at"`
 }
 
+type AddressRequest struct {
+	Name       string   `json:"name" binding:"required"`
+	Line1      string   `json:"line1" binding:"required"`
+	Line2      string   `json:"line2"`
+	City       string   `json:"city" binding:"required"`
+	State      string   `json:"state"`
+	PostalCode string   `json:"postal_code" binding:"required"`
+	Country    string   `json:"country" binding:"required,len=2"`
+	Latitude   *float64 `json:"latitude" binding:"omitempty,min=-90,max=90"`
+	Longitude  *float64 `json:"longitude" binding:"omitempty,min=-180,max=180"`
+}
+
+type AddressResponse struct {
+	Name       string   `json:"name"`
+	Line1      string   `json:"line1"`
+	Line2      string   `json:"line2"`
+	City       string   `json:"city"`
+	State      string   `json:"state"`
+	PostalCode string   `json:"postal_code"`
+	Country    string   `json:"country"`
+	Latitude   *float64 `json:"latitude"`
+	Longitude  *float64 `json:"longitude"`
+}
+
+type CreateOrderRequest struct {
+	ShippingAddress *AddressRequest `json:"shipping_address"`
+}
+
 type OrderResponse struct {
-	Id          uint                `json:"id"`
-	UserId      uint                `json:"user_id"`
-	Status      string              `json:"status"`
-	TotalAmount float64             `json:"total_amount"`
-	OrderItems  []OrderItemResponse `json:"order_items"`
-	CreatedAt   time.Time           `json:"created_at"`
-	UpdatedAt   time.Time           `json:"updated_at"`
+	Id              uint                `json:"id"`
+	UserId          uint                `json:"user_id"`
+	Status          string              `json:"status"`
+	TotalAmount     float64             `json:"total_amount"`
+	ShippingAddress *AddressResponse    `json:"shipping_address"`
+	InvoiceNumber   string              `json:"invoice_number"`
+	InvoicedAt      *time.Time          `json:"invoiced_at"`
+	OrderItems      []OrderItemResponse `json:"order_items"`
+	Shipments       []ShipmentResponse  `json:"shipments"`
+	CreatedAt       time.Time           `json:"created_at"`
+	UpdatedAt       time.Time           `json:"updated_at"`
 }
 
 type OrderItemResponse struct {
-	Id        uint            `json:"id"`
-	Product   ProductResponse `json:"product"`
-	Quantity  int             `json:"quantity"`
-	Price     float64         `json:"price"`
-	CreatedAt time.Time       `json:"created_at"`
+	Id            uint            `json:"id"`
+	Product       ProductResponse `json:"product"`
+	WarehouseId   uint            `json:"warehouse_id"`
+	WarehouseCode string          `json:"warehouse_code"`
+	Quantity      int             `json:"quantity"`
+	Price         float64         `json:"price"`
+	CreatedAt     time.Time       `json:"created_at"`
+}
+
+type CreateShipmentRequest struct {
+	Carrier        string                `json:"carrier" binding:"required"`
+	TrackingNumber string                `json:"tracking_number" binding:"required"`
+	Items          []ShipmentItemRequest `json:"items" binding:"required,min=1,dive"`
+}
+
+type ShipmentItemRequest struct {
+	OrderItemId uint `json:"order_item_id" binding:"required"`
+	Quantity    int  `json:"quantity" binding:"required,min=1"`
+}
+
+type ShipmentResponse struct {
+	Id             uint                   `json:"id"`
+	OrderId        uint                   `json:"order_id"`
+	Carrier        string                 `json:"carrier"`
+	TrackingNumber string                 `json:"tracking_number"`
+	Status         string                 `json:"status"`
+	Items          []ShipmentItemResponse `json:"items"`
+	ShippedAt      time.Time              `json:"shipped_at"`
+	DeliveredAt    *time.Time             `json:"delivered_at"`
+}
+
+type ShipmentItemResponse struct {
+	OrderItemId uint `json:"order_item_id"`
+	Quantity    int  `json:"quantity"`
+}
+
+type CreateReturnRequest struct {
+	Comment string              `json:"comment"`
+	Items   []ReturnItemRequest `json:"items" binding:"required,min=1,dive"`
+}
+
+type ReturnItemRequest struct {
+	OrderItemId uint   `json:"order_item_id" binding:"required"`
+	Quantity    int    `json:"quantity" binding:"required,min=1"`
+	Reason      string `json:"reason" binding:"required,oneof=damaged defective wrong_item not_as_described no_longer_needed other"`
+}
+
+type ReviewReturnRequest struct {
+	Note string `json:"note"`
+}
+
+type RefundReturnRequest struct {
+	Reference string `json:"reference" binding:"required"`
+}
+
+type ReturnResponse struct {
+	Id              uint                 `json:"id"`
+	OrderId         uint                 `json:"order_id"`
+	UserId          uint                 `json:"user_id"`
+	Status          string               `json:"status"`
+	Comment         string               `json:"comment"`
+	AdminNote       string               `json:"admin_note"`
+	RefundAmount    float64              `json:"refund_amount"`
+	RefundReference string               `json:"refund_reference"`
+	Items           []ReturnItemResponse `json:"items"`
+	ReviewedAt      *time.Time           `json:"reviewed_at"`
+	ReceivedAt      *time.Time           `json:"received_at"`
+	RefundedAt      *time.Time           `json:"refunded_at"`
+	CreatedAt       time.Time            `json:"created_at"`
+	UpdatedAt       time.Time            `json:"updated_at"`
+}
+
+type ReturnItemResponse struct {
+	OrderItemId uint   `json:"order_item_id"`
+	ProductId   uint   `json:"product_id"`
+	Quantity    int    `json:"quantity"`
+	Reason      string `json:"reason"`
+}
+
+type InvoiceResponse struct {
+	OrderId       uint      `json:"order_id"`
+	InvoiceNumber string    `json:"invoice_number"`
+	InvoicedAt    time.Time `json:"invoiced_at"`
+}
+
+type DocumentResponse struct {
+	Filename string
+	Content  []byte
 }
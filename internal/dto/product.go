@@ -3,56 +3,63 @@ package dto
 import "time"
 
 type CreateCategoryRequest struct {
-	Name        string `json:"name" binding:"required"`
-	Description string `json:"description"`
+	Name              string `json:"name" binding:"required"`
+	Description       string `json:"description"`
+	LowStockThreshold int    `json:"low_stock_threshold" binding:"min=0"`
 }
 
 type UpdateCategoryRequest struct {
-	Name        string `json:"name" binding:"required"`
-	Description string `json:"description"`
-	IsActive    *bool  `json:"is_active"`
+	Name              string `json:"name" binding:"required"`
+	Description       string `json:"description"`
+	IsActive          *bool  `json:"is_active"`
+	LowStockThreshold *int   `json:"low_stock_threshold" binding:"omitempty,min=0"`
 }
 
 type CategoryResponse struct {
-	Id          uint      `json:"id"`
-	Name        string    `json:"name"`
-	Description string    `json:"description"`
-	IsActive    bool      `json:"is_active"`
-	CreatedAt   time.Time `json:"created_at"`
-	UpdatedAt   time.Time `json:"updated_at"`
+	Id                uint      `json:"id"`
+	Name              string    `json:"name"`
+	Description       string    `json:"description"`
+	IsActive          bool      `json:"is_active"`
+	LowStockThreshold int       `json:"low_stock_threshold"`
+	CreatedAt         time.Time `json:"created_at"`
+	UpdatedAt         time.Time `json:"updated_at"`
 }
 
 type CreateProductRequest struct {
-	CategoryId  uint    `json:"category_id" binding:"required"`
-	Name        string  `json:"name" binding:"required"`
-	Description string  `json:"description"`
-	Price       float64 `json:"price" binding:"required,gt=0"`
-	Stock       int     `json:"stock" binding:"min=0"`
-	SKU         string  `json:"sku" binding:"required"`
+	CategoryId        uint    `json:"category_id" binding:"required"`
+	Name              string  `json:"name" binding:"required"`
+	Description       string  `json:"description"`
+	Price             float64 `json:"price" binding:"required,gt=0"`
+	SKU               string  `json:"sku" binding:"required"`
+	Stock             *int    `json:"stock" binding:"omitempty,min=0"`
+	LowStockThreshold *int    `json:"low_stock_threshold" binding:"omitempty,min=0"`
 }
 
 type UpdateProductRequest struct {
-	CategoryId  uint    `json:"category_id" binding:"required"`
-	Name        string  `json:"name" binding:"required"`
-	Description string  `json:"description"`
-	Price       float64 `json:"price" binding:"required,gt=0"`
-	Stock       int     `json:"stock" binding:"min=0"`
-	IsActive    *bool   `json:"is_active"`
+	CategoryId        uint    `json:"category_id" binding:"required"`
+	Name              string  `json:"name" binding:"required"`
+	Description       string  `json:"description"`
+	Price             float64 `json:"price" binding:"required,gt=0"`
+	IsActive          *bool   `json:"is_active"`
+	Stock             *int    `json:"stock" binding:"omitempty,min=0"`
+	LowStockThreshold *int    `json:"low_stock_threshold" binding:"omitempty,min=0"`
 }
 
 type ProductResponse struct {
-	Id          uint                   `json:"id"`
-	CategoryId  uint                   `json:"category_id"`
-	Name        string                 `json:"name"`
-	Description string                 `json:"description"`
-	Price       float64                `json:"price"`
-	Stock       int                    `json:"stock"`
-	SKU         string                 `json:"sku"`
-	IsActive    bool                   `json:"is_active"`
-	Category    CategoryResponse       `json:"category"`
-	Images      []ProductImageResponse `json:"images"`
-	CreatedAt   time.Time              `json:"created_at"`
-	UpdatedAt   time.Time              `json:"updated_at"`
+	Id                uint                   `json:"id"`
+	CategoryId        uint                   `json:"category_id"`
+	Name              string                 `json:"name"`
+	Description       string                 `json:"description"`
+	Price             float64                `json:"price"`
+	Stock             int                    `json:"stock"`
+	InStock           bool                   `json:"in_stock"`
+	SKU               string                 `json:"sku"`
+	IsActive          bool                   `json:"is_active"`
+	LowStockThreshold *int                   `json:"low_stock_threshold"`
+	Category          CategoryResponse       `json:"category"`
+	Images            []ProductImageResponse `json:"images"`
+	CreatedAt         time.Time              `json:"created_at"`
+	UpdatedAt         time.Time              `json:"updated_at"`
 }
 
 type ProductImageResponse struct {
@@ -0,0 +1,28 @@
+package dto
+
+import "time"
+
+// GetUsersRequest filters the users listed to staff. Query matches the
+// email address and name.
+type GetUsersRequest struct {
+	Query    string `form:"q"`
+	Role     string `form:"role"`
+	IsActive *bool  `form:"is_active"`
+	Page     int    `form:"page"`
+	Limit    int    `form:"limit"`
+}
+
+type DeactivateUserRequest struct {
+	Reason string `json:"reason" binding:"max=500"`
+}
+
+type AuditLogResponse struct {
+	Id        uint              `json:"id"`
+	ActorId   uint              `json:"actor_id"`
+	UserId    uint              `json:"user_id"`
+	Action    string            `json:"action"`
+	Details   map[string]string `json:"details"`
+	IPAddress string            `json:"ip_address"`
+	UserAgent string            `json:"user_agent"`
+	CreatedAt time.Time         `json:"created_at"`
+}
@@ -0,0 +1,55 @@
+package dto
+
+import (
+	"encoding/json"
+	"time"
+)
+
+type CreateWebhookEndpointRequest struct {
+	URL         string   `json:"url" binding:"required,url"`
+	Description string   `json:"description"`
+	EventTypes  []string `json:"event_types" binding:"required,min=1"`
+}
+
+type UpdateWebhookEndpointRequest struct {
+	URL         string   `json:"url" binding:"required,url"`
+	Description string   `json:"description"`
+	EventTypes  []string `json:"event_types" binding:"required,min=1"`
+	IsActive    *bool    `json:"is_active"`
+}
+
+type WebhookEndpointResponse struct {
+	Id          uint     `json:"id"`
+	URL         string   `json:"url"`
+	Description string   `json:"description"`
+	EventTypes  []string `json:"event_types"`
+	IsActive    bool     `json:"is_active"`
+	// Secret is only returned when the endpoint is created.
+	Secret    string    `json:"secret,omitempty"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+type WebhookDeliveryResponse struct {
+	Id             uint                             `json:"id"`
+	EndpointId     uint                             `json:"endpoint_id"`
+	EventId        string                           `json:"event_id"`
+	EventType      string                           `json:"event_type"`
+	Status         string                           `json:"status"`
+	Attempts       int                              `json:"attempts"`
+	LastStatusCode *int                             `json:"last_status_code"`
+	LastError      string                           `json:"last_error"`
+	NextAttemptAt  time.Time                        `json:"next_attempt_at"`
+	DeliveredAt    *time.Time                       `json:"delivered_at"`
+	CreatedAt      time.Time                        `json:"created_at"`
+	Payload        json.RawMessage                  `json:"payload,omitempty"`
+	History        []WebhookDeliveryAttemptResponse `json:"history,omitempty"`
+}
+
+type WebhookDeliveryAttemptResponse struct {
+	Id         uint      `json:"id"`
+	StatusCode *int      `json:"status_code"`
+	Error      string    `json:"error"`
+	DurationMs int       `json:"duration_ms"`
+	CreatedAt  time.Time `json:"created_at"`
+}
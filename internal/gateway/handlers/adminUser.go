@@ -0,0 +1,326 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/saleh-ghazimoradi/Cartopher/internal/dto"
+	"github.com/saleh-ghazimoradi/Cartopher/internal/helper"
+	"github.com/saleh-ghazimoradi/Cartopher/internal/repository"
+	"github.com/saleh-ghazimoradi/Cartopher/internal/service"
+)
+
+type AdminUserHandler struct {
+	adminUserService service.AdminUserService
+}
+
+// GetUsers docs
+// @Summary Get users
+// @Description Retrieve a paginated list of users, newest first, optionally searched by email or name and filtered by role and status (requires users:manage)
+// @Tags Admin Users
+// @Produce json
+// @Security BearerAuth
+// @Param q query string false "Email or name to search for"
+// @Param role query string false "Filter by role"
+// @Param is_active query bool false "Filter by status"
+// @Param page query int false "Page number" default(1)
+// @Param limit query int false "Items per page" default(20)
+// @Success 200 {object} helper.PaginatedResponse{data=[]dto.UserResponse} "Users retrieved successfully"
+// @Failure 400 {object} helper.Response "Invalid filter"
+// @Failure 401 {object} helper.Response "Unauthorized"
+// @Failure 403 {object} helper.Response "Permission required"
+// @Router /admin/users [get]
+func (a *AdminUserHandler) GetUsers(ctx *gin.Context) {
+	payload := &dto.GetUsersRequest{}
+	if err := ctx.ShouldBindQuery(payload); err != nil {
+		helper.BadRequestResponse(ctx, "invalid filter given", err)
+		return
+	}
+
+	users, meta, err := a.adminUserService.GetUsers(ctx, payload)
+	if err != nil {
+		helper.InternalServerError(ctx, "error while getting users", err)
+		return
+	}
+
+	helper.PaginatedSuccessResponse(ctx, "users successfully retrieved", users, *meta)
+}
+
+// GetUser docs
+// @Summary Get user by ID
+// @Description Retrieve a user (requires users:manage)
+// @Tags Admin Users
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "User ID"
+// @Success 200 {object} helper.Response{data=dto.UserResponse} "User retrieved successfully"
+// @Failure 400 {object} helper.Response "Invalid user ID"
+// @Failure 401 {object} helper.Response "Unauthorized"
+// @Failure 403 {object} helper.Response "Permission required"
+// @Failure 404 {object} helper.Response "User not found"
+// @Router /admin/users/{id} [get]
+func (a *AdminUserHandler) GetUser(ctx *gin.Context) {
+	id, err := strconv.ParseUint(ctx.Param("id"), 10, 32)
+	if err != nil {
+		helper.BadRequestResponse(ctx, "invalid user id", err)
+		return
+	}
+
+	user, err := a.adminUserService.GetUser(ctx, uint(id))
+	if err != nil {
+		helper.NotFoundResponse(ctx, "user not found")
+		return
+	}
+
+	helper.SuccessResponse(ctx, "user successfully retrieved", user)
+}
+
+// GetAuditLog docs
+// @Summary Get the audit log of a user
+// @Description Retrieve the paginated log of what staff did to a user's account, newest first (requires users:manage)
+// @Tags Admin Users
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "User ID"
+// @Param page query int false "Page number" default(1)
+// @Param limit query int false "Items per page" default(20)
+// @Success 200 {object} helper.PaginatedResponse{data=[]dto.AuditLogResponse} "Audit log retrieved successfully"
+// @Failure 400 {object} helper.Response "Invalid user ID"
+// @Failure 401 {object} helper.Response "Unauthorized"
+// @Failure 403 {object} helper.Response "Permission required"
+// @Failure 404 {object} helper.Response "User not found"
+// @Router /admin/users/{id}/audit-log [get]
+func (a *AdminUserHandler) GetAuditLog(ctx *gin.Context) {
+	id, err := strconv.ParseUint(ctx.Param("id"), 10, 32)
+	if err != nil {
+		helper.BadRequestResponse(ctx, "invalid user id", err)
+		return
+	}
+
+	page, _ := strconv.Atoi(ctx.DefaultQuery("page", "1"))
+	limit, _ := strconv.Atoi(ctx.DefaultQuery("limit", "20"))
+
+	logs, meta, err := a.adminUserService.GetAuditLog(ctx, uint(id), page, limit)
+	if err != nil {
+		adminUserError(ctx, "error while getting audit log", err)
+		return
+	}
+
+	helper.PaginatedSuccessResponse(ctx, "audit log successfully retrieved", logs, *meta)
+}
+
+// DeactivateUser docs
+// @Summary Deactivate a user
+// @Description Keep a user from logging in and log them out on every device (requires users:manage)
+// @Tags Admin Users
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "User ID"
+// @Param request body dto.DeactivateUserRequest false "Reason"
+// @Success 200 {object} helper.Response{data=dto.UserResponse} "User deactivated successfully"
+// @Failure 400 {object} helper.Response "Invalid request data"
+// @Failure 401 {object} helper.Response "Unauthorized"
+// @Failure 403 {object} helper.Response "Permission required"
+// @Failure 404 {object} helper.Response "User not found"
+// @Failure 409 {object} helper.Response "User already deactivated"
+// @Router /admin/users/{id}/deactivate [put]
+func (a *AdminUserHandler) DeactivateUser(ctx *gin.Context) {
+	id, err := strconv.ParseUint(ctx.Param("id"), 10, 32)
+	if err != nil {
+		helper.BadRequestResponse(ctx, "invalid user id", err)
+		return
+	}
+
+	payload := &dto.DeactivateUserRequest{}
+	if ctx.Request.ContentLength > 0 {
+		if err := ctx.ShouldBindJSON(payload); err != nil {
+			helper.BadRequestResponse(ctx, "invalid payload given", err)
+			return
+		}
+	}
+
+	user, err := a.adminUserService.DeactivateUser(ctx, ctx.GetUint("user_id"), uint(id), payload)
+	if err != nil {
+		adminUserError(ctx, "error while deactivating user", err)
+		return
+	}
+
+	helper.SuccessResponse(ctx, "user successfully deactivated", user)
+}
+
+// ReactivateUser docs
+// @Summary Reactivate a user
+// @Description Let a deactivated user log in again (requires users:manage)
+// @Tags Admin Users
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "User ID"
+// @Success 200 {object} helper.Response{data=dto.UserResponse} "User reactivated successfully"
+// @Failure 400 {object} helper.Response "Invalid user ID"
+// @Failure 401 {object} helper.Response "Unauthorized"
+// @Failure 403 {object} helper.Response "Permission required"
+// @Failure 404 {object} helper.Response "User not found"
+// @Failure 409 {object} helper.Response "User already active"
+// @Router /admin/users/{id}/reactivate [put]
+func (a *AdminUserHandler) ReactivateUser(ctx *gin.Context) {
+	id, err := strconv.ParseUint(ctx.Param("id"), 10, 32)
+	if err != nil {
+		helper.BadRequestResponse(ctx, "invalid user id", err)
+		return
+	}
+
+	user, err := a.adminUserService.ReactivateUser(ctx, ctx.GetUint("user_id"), uint(id))
+	if err != nil {
+		adminUserError(ctx, "error while reactivating user", err)
+		return
+	}
+
+	helper.SuccessResponse(ctx, "user successfully reactivated", user)
+}
+
+// ChangeRole docs
+// @Summary Change the role of a user
+// @Description Give a user another role, revoking their access tokens. Only roles whose permissions the caller has can be given (requires users:manage)
+// @Tags Admin Users
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "User ID"
+// @Param request body dto.AssignRoleRequest true "Role"
+// @Success 200 {object} helper.Response{data=dto.UserResponse} "Role changed successfully"
+// @Failure 400 {object} helper.Response "Invalid request data"
+// @Failure 401 {object} helper.Response "Unauthorized"
+// @Failure 403 {object} helper.Response "Permission required"
+// @Failure 404 {object} helper.Response "User not found"
+// @Router /admin/users/{id}/role [put]
+func (a *AdminUserHandler) ChangeRole(ctx *gin.Context) {
+	id, err := strconv.ParseUint(ctx.Param("id"), 10, 32)
+	if err != nil {
+		helper.BadRequestResponse(ctx, "invalid user id", err)
+		return
+	}
+
+	var payload *dto.AssignRoleRequest
+	if err := ctx.ShouldBindJSON(&payload); err != nil {
+		helper.BadRequestResponse(ctx, "invalid payload given", err)
+		return
+	}
+
+	user, err := a.adminUserService.ChangeRole(ctx, ctx.GetUint("user_id"), uint(id), payload)
+	if err != nil {
+		adminUserError(ctx, "error while changing role", err)
+		return
+	}
+
+	helper.SuccessResponse(ctx, "role successfully changed", user)
+}
+
+// DeleteUser docs
+// @Summary Delete a user
+// @Description Soft-delete a user and log them out on every device; their orders are kept (requires users:manage)
+// @Tags Admin Users
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "User ID"
+// @Success 200 {object} helper.Response "User deleted successfully"
+// @Failure 400 {object} helper.Response "Invalid user ID"
+// @Failure 401 {object} helper.Response "Unauthorized"
+// @Failure 403 {object} helper.Response "Permission required"
+// @Failure 404 {object} helper.Response "User not found"
+// @Router /admin/users/{id} [delete]
+func (a *AdminUserHandler) DeleteUser(ctx *gin.Context) {
+	id, err := strconv.ParseUint(ctx.Param("id"), 10, 32)
+	if err != nil {
+		helper.BadRequestResponse(ctx, "invalid user id", err)
+		return
+	}
+
+	if err := a.adminUserService.DeleteUser(ctx, ctx.GetUint("user_id"), uint(id)); err != nil {
+		adminUserError(ctx, "error while deleting user", err)
+		return
+	}
+
+	helper.SuccessResponse(ctx, "user successfully deleted", nil)
+}
+
+// RevokeSessions docs
+// @Summary Revoke all sessions of a user
+// @Description Log a user out on every device (requires users:manage)
+// @Tags Admin Users
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "User ID"
+// @Success 200 {object} helper.Response "Sessions revoked successfully"
+// @Failure 400 {object} helper.Response "Invalid user ID"
+// @Failure 401 {object} helper.Response "Unauthorized"
+// @Failure 403 {object} helper.Response "Permission required"
+// @Failure 404 {object} helper.Response "User not found"
+// @Router /admin/users/{id}/sessions [delete]
+func (a *AdminUserHandler) RevokeSessions(ctx *gin.Context) {
+	id, err := strconv.ParseUint(ctx.Param("id"), 10, 32)
+	if err != nil {
+		helper.BadRequestResponse(ctx, "invalid user id", err)
+		return
+	}
+
+	if err := a.adminUserService.RevokeSessions(ctx, ctx.GetUint("user_id"), uint(id)); err != nil {
+		adminUserError(ctx, "error while revoking sessions", err)
+		return
+	}
+
+	helper.SuccessResponse(ctx, "sessions successfully revoked", nil)
+}
+
+// UnlockUser docs
+// @Summary Unlock a user's login
+// @Description Lift the lockout of a user after too many failed logins (requires users:manage)
+// @Tags Admin Users
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "User ID"
+// @Success 200 {object} helper.Response "User unlocked successfully"
+// @Failure 400 {object} helper.Response "Invalid user ID"
+// @Failure 401 {object} helper.Response "Unauthorized"
+// @Failure 403 {object} helper.Response "Permission required"
+// @Failure 404 {object} helper.Response "User not found"
+// @Router /admin/users/{id}/unlock [post]
+func (a *AdminUserHandler) UnlockUser(ctx *gin.Context) {
+	id, err := strconv.ParseUint(ctx.Param("id"), 10, 32)
+	if err != nil {
+		helper.BadRequestResponse(ctx, "invalid user id", err)
+		return
+	}
+
+	if err := a.adminUserService.UnlockUser(ctx, ctx.GetUint("user_id"), uint(id)); err != nil {
+		adminUserError(ctx, "error while unlocking user", err)
+		return
+	}
+
+	helper.SuccessResponse(ctx, "user successfully unlocked", nil)
+}
+
+// adminUserError answers a failed action on a user with the status its
+// error calls for.
+func adminUserError(ctx *gin.Context, message string, err error) {
+	switch {
+	case errors.Is(err, repository.ErrNotFound):
+		helper.NotFoundResponse(ctx, "user not found")
+	case errors.Is(err, service.ErrInvalidRole):
+		helper.BadRequestResponse(ctx, message, err)
+	case errors.Is(err, service.ErrSelfManagement), errors.Is(err, service.ErrInsufficientRole):
+		helper.ForbiddenResponse(ctx, err.Error())
+	case errors.Is(err, service.ErrUserAlreadyInactive), errors.Is(err, service.ErrUserAlreadyActive):
+		helper.ErrorResponse(ctx, http.StatusConflict, message, err)
+	default:
+		helper.InternalServerError(ctx, message, err)
+	}
+}
+
+func NewAdminUserHandler(adminUserService service.AdminUserService) *AdminUserHandler {
+	return &AdminUserHandler{
+		adminUserService: adminUserService,
+	}
+}
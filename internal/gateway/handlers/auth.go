@@ -1,6 +1,11 @@
 package handlers
 
 import (
+	"errors"
+	"net/http"
+	"strconv"
+	"time"
+
 	"github.com/gin-gonic/gin"
 	"github.com/saleh-ghazimoradi/Cartopher/internal/dto"
 	"github.com/saleh-ghazimoradi/Cartopher/internal/helper"
@@ -44,8 +49,10 @@ func (a *AuthHandler) Register(ctx *gin.Context) {
 // @Accept       json
 // @Produce      json
 // @Param        request body dto.LoginRequest true "User login credentials"
-// @Success      200 {object} helper.Response{data=dto.AuthResponse} "Login successfully"
+// @Success      200 {object} helper.Response{data=dto.AuthResponse} "Login successfully, or a two-factor challenge when two_factor_required is set"
 // @Failure      401 {object} helper.Response "Invalid credentials"
+// @Failure      403 {object} helper.Response "Email address is not verified"
+// @Failure      429 {object} helper.Response "Too many failed login attempts"
 // @Router       /auth/login [post]
 func (a *AuthHandler) Login(ctx *gin.Context) {
 	var payload dto.LoginRequest
@@ -56,6 +63,13 @@ func (a *AuthHandler) Login(ctx *gin.Context) {
 
 	loginResponse, err := a.authService.Login(ctx, &payload)
 	if err != nil {
+		if loginLocked(ctx, err) {
+			return
+		}
+		if errors.Is(err, service.ErrEmailNotVerified) {
+			helper.ForbiddenResponse(ctx, "Email address is not verified")
+			return
+		}
 		helper.InternalServerError(ctx, "Failed to login", err)
 		return
 	}
@@ -114,6 +128,417 @@ func (a *AuthHandler) Logout(ctx *gin.Context) {
 	helper.SuccessResponse(ctx, "user logged out successfully", nil)
 }
 
+// VerifyEmail docs
+// @Summary Verify email address
+// @Description Mark the email address a verification token was sent to as verified
+// @Tags Authentication
+// @Accept json
+// @Produce json
+// @Param request body dto.VerifyEmailRequest true "Verification token"
+// @Success 200 {object} helper.Response{data=dto.UserResponse} "Email verified successfully"
+// @Failure 400 {object} helper.Response "Invalid or expired verification token"
+// @Router /auth/verify-email [post]
+func (a *AuthHandler) VerifyEmail(ctx *gin.Context) {
+	var payload dto.VerifyEmailRequest
+	if err := ctx.ShouldBindJSON(&payload); err != nil {
+		helper.BadRequestResponse(ctx, "Invalid request data", err)
+		return
+	}
+
+	user, err := a.authService.VerifyEmail(ctx, &payload)
+	if err != nil {
+		helper.BadRequestResponse(ctx, "Failed to verify email", err)
+		return
+	}
+
+	helper.SuccessResponse(ctx, "email verified successfully", user)
+}
+
+// ResendVerification docs
+// @Summary Resend verification email
+// @Description Send a new verification link to an unverified email address
+// @Tags Authentication
+// @Accept json
+// @Produce json
+// @Param request body dto.ResendVerificationRequest true "Email address"
+// @Success 200 {object} helper.Response "Verification email sent if the address needs one"
+// @Failure 400 {object} helper.Response "Invalid request data"
+// @Router /auth/resend-verification [post]
+func (a *AuthHandler) ResendVerification(ctx *gin.Context) {
+	var payload dto.ResendVerificationRequest
+	if err := ctx.ShouldBindJSON(&payload); err != nil {
+		helper.BadRequestResponse(ctx, "Invalid request data", err)
+		return
+	}
+
+	if err := a.authService.ResendVerification(ctx, &payload); err != nil {
+		helper.InternalServerError(ctx, "Failed to resend verification email", err)
+		return
+	}
+
+	helper.SuccessResponse(ctx, "verification email sent if the address needs one", nil)
+}
+
+// ForgotPassword docs
+// @Summary Request a password reset
+// @Description Email a single-use password reset link to the address if it has an account
+// @Tags Authentication
+// @Accept json
+// @Produce json
+// @Param request body dto.ForgotPasswordRequest true "Email address"
+// @Success 200 {object} helper.Response "Reset link sent if the address has an account"
+// @Failure 400 {object} helper.Response "Invalid request data"
+// @Failure 429 {object} helper.Response "Too many password reset requests"
+// @Router /auth/forgot-password [post]
+func (a *AuthHandler) ForgotPassword(ctx *gin.Context) {
+	var payload dto.ForgotPasswordRequest
+	if err := ctx.ShouldBindJSON(&payload); err != nil {
+		helper.BadRequestResponse(ctx, "Invalid request data", err)
+		return
+	}
+
+	if err := a.authService.ForgotPassword(ctx, &payload); err != nil {
+		if errors.Is(err, service.ErrPasswordResetThrottled) {
+			helper.TooManyRequestsResponse(ctx, err.Error())
+			return
+		}
+		helper.InternalServerError(ctx, "Failed to request password reset", err)
+		return
+	}
+
+	helper.SuccessResponse(ctx, "reset link sent if the address has an account", nil)
+}
+
+// ResetPassword docs
+// @Summary Reset password
+// @Description Set a new password with a reset token and sign out of every session
+// @Tags Authentication
+// @Accept json
+// @Produce json
+// @Param request body dto.ResetPasswordRequest true "Reset token and new password"
+// @Success 200 {object} helper.Response "Password reset successfully"
+// @Failure 400 {object} helper.Response "Invalid or expired reset token"
+// @Router /auth/reset-password [post]
+func (a *AuthHandler) ResetPassword(ctx *gin.Context) {
+	var payload dto.ResetPasswordRequest
+	if err := ctx.ShouldBindJSON(&payload); err != nil {
+		helper.BadRequestResponse(ctx, "Invalid request data", err)
+		return
+	}
+
+	if err := a.authService.ResetPassword(ctx, &payload); err != nil {
+		helper.BadRequestResponse(ctx, "Failed to reset password", err)
+		return
+	}
+
+	helper.SuccessResponse(ctx, "password reset successfully", nil)
+}
+
+// VerifyTwoFactor docs
+// @Summary Complete a two-factor login
+// @Description Exchange the challenge token from login and a TOTP or recovery code for tokens
+// @Tags Authentication
+// @Accept json
+// @Produce json
+// @Param request body dto.TwoFactorLoginRequest true "Challenge token and code"
+// @Success 200 {object} helper.Response{data=dto.AuthResponse} "Login successfully"
+// @Failure 400 {object} helper.Response "Invalid request data"
+// @Failure 401 {object} helper.Response "Invalid challenge token or code"
+// @Failure 429 {object} helper.Response "Too many failed login or two-factor attempts"
+// @Router /auth/2fa/verify [post]
+func (a *AuthHandler) VerifyTwoFactor(ctx *gin.Context) {
+	var payload dto.TwoFactorLoginRequest
+	if err := ctx.ShouldBindJSON(&payload); err != nil {
+		helper.BadRequestResponse(ctx, "Invalid request data", err)
+		return
+	}
+
+	loginResponse, err := a.authService.VerifyTwoFactor(ctx, &payload)
+	if err != nil {
+		if loginLocked(ctx, err) {
+			return
+		}
+		if errors.Is(err, service.ErrTwoFactorThrottled) {
+			helper.TooManyRequestsResponse(ctx, err.Error())
+			return
+		}
+		helper.ErrorResponse(ctx, http.StatusUnauthorized, "Failed to verify two-factor code", err)
+		return
+	}
+
+	helper.SuccessResponse(ctx, "user logged in successfully", loginResponse)
+}
+
+// EnrolTwoFactor docs
+// @Summary Start two-factor enrolment
+// @Description Generate a TOTP secret with its otpauth URI and QR code; it takes effect once confirmed
+// @Tags Authentication
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {object} helper.Response{data=dto.TwoFactorEnrolmentResponse} "Enrolment started"
+// @Failure 400 {object} helper.Response "Two-factor authentication is already enabled"
+// @Failure 401 {object} helper.Response "Unauthorized"
+// @Router /auth/2fa/enrol [post]
+func (a *AuthHandler) EnrolTwoFactor(ctx *gin.Context) {
+	userId := ctx.GetUint("user_id")
+
+	enrolment, err := a.authService.EnrolTwoFactor(ctx, userId)
+	if err != nil {
+		helper.BadRequestResponse(ctx, "Failed to start two-factor enrolment", err)
+		return
+	}
+
+	helper.SuccessResponse(ctx, "two-factor enrolment started", enrolment)
+}
+
+// ConfirmTwoFactor docs
+// @Summary Confirm two-factor enrolment
+// @Description Enable two-factor authentication with a code from the authenticator and get recovery codes, which are only shown once
+// @Tags Authentication
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param request body dto.TwoFactorCodeRequest true "TOTP code"
+// @Success 200 {object} helper.Response{data=dto.RecoveryCodesResponse} "Two-factor authentication enabled"
+// @Failure 400 {object} helper.Response "Invalid code or no enrolment started"
+// @Failure 401 {object} helper.Response "Unauthorized"
+// @Failure 429 {object} helper.Response "Too many two-factor attempts"
+// @Router /auth/2fa/confirm [post]
+func (a *AuthHandler) ConfirmTwoFactor(ctx *gin.Context) {
+	userId := ctx.GetUint("user_id")
+
+	var payload dto.TwoFactorCodeRequest
+	if err := ctx.ShouldBindJSON(&payload); err != nil {
+		helper.BadRequestResponse(ctx, "Invalid request data", err)
+		return
+	}
+
+	recoveryCodes, err := a.authService.ConfirmTwoFactor(ctx, userId, &payload)
+	if err != nil {
+		if errors.Is(err, service.ErrTwoFactorThrottled) {
+			helper.TooManyRequestsResponse(ctx, err.Error())
+			return
+		}
+		helper.BadRequestResponse(ctx, "Failed to confirm two-factor enrolment", err)
+		return
+	}
+
+	helper.SuccessResponse(ctx, "two-factor authentication enabled", recoveryCodes)
+}
+
+// DisableTwoFactor docs
+// @Summary Disable two-factor authentication
+// @Description Turn two-factor authentication off with a current TOTP or recovery code
+// @Tags Authentication
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param request body dto.TwoFactorCodeRequest true "TOTP or recovery code"
+// @Success 200 {object} helper.Response "Two-factor authentication disabled"
+// @Failure 400 {object} helper.Response "Invalid code or two-factor authentication is required"
+// @Failure 401 {object} helper.Response "Unauthorized"
+// @Failure 429 {object} helper.Response "Too many two-factor attempts"
+// @Router /auth/2fa/disable [post]
+func (a *AuthHandler) DisableTwoFactor(ctx *gin.Context) {
+	userId := ctx.GetUint("user_id")
+
+	var payload dto.TwoFactorCodeRequest
+	if err := ctx.ShouldBindJSON(&payload); err != nil {
+		helper.BadRequestResponse(ctx, "Invalid request data", err)
+		return
+	}
+
+	if err := a.authService.DisableTwoFactor(ctx, userId, &payload); err != nil {
+		if errors.Is(err, service.ErrTwoFactorThrottled) {
+			helper.TooManyRequestsResponse(ctx, err.Error())
+			return
+		}
+		helper.BadRequestResponse(ctx, "Failed to disable two-factor authentication", err)
+		return
+	}
+
+	helper.SuccessResponse(ctx, "two-factor authentication disabled", nil)
+}
+
+// GetSessions docs
+// @Summary List sessions
+// @Description List the devices the current user is logged in on
+// @Tags Authentication
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {object} helper.Response{data=[]dto.SessionResponse} "Sessions retrieved successfully"
+// @Failure 401 {object} helper.Response "Unauthorized"
+// @Failure 500 {object} helper.Response "Internal server error"
+// @Router /auth/sessions [get]
+func (a *AuthHandler) GetSessions(ctx *gin.Context) {
+	userId := ctx.GetUint("user_id")
+
+	sessions, err := a.authService.GetSessions(ctx, userId)
+	if err != nil {
+		helper.InternalServerError(ctx, "Failed to get sessions", err)
+		return
+	}
+
+	helper.SuccessResponse(ctx, "sessions retrieved successfully", sessions)
+}
+
+// RevokeSession docs
+// @Summary Revoke a session
+// @Description Log the current user out on one device
+// @Tags Authentication
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "Session ID"
+// @Success 200 {object} helper.Response "Session revoked successfully"
+// @Failure 400 {object} helper.Response "Invalid session ID"
+// @Failure 401 {object} helper.Response "Unauthorized"
+// @Failure 404 {object} helper.Response "Session not found"
+// @Router /auth/sessions/{id} [delete]
+func (a *AuthHandler) RevokeSession(ctx *gin.Context) {
+	userId := ctx.GetUint("user_id")
+
+	id, err := strconv.ParseUint(ctx.Param("id"), 10, 32)
+	if err != nil {
+		helper.BadRequestResponse(ctx, "Invalid session id", err)
+		return
+	}
+
+	if err := a.authService.RevokeSession(ctx, userId, uint(id)); err != nil {
+		helper.NotFoundResponse(ctx, "session not found")
+		return
+	}
+
+	helper.SuccessResponse(ctx, "session revoked successfully", nil)
+}
+
+// RevokeAllSessions docs
+// @Summary Log out everywhere
+// @Description Log the current user out on every device
+// @Tags Authentication
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {object} helper.Response "Sessions revoked successfully"
+// @Failure 401 {object} helper.Response "Unauthorized"
+// @Failure 500 {object} helper.Response "Internal server error"
+// @Router /auth/sessions [delete]
+func (a *AuthHandler) RevokeAllSessions(ctx *gin.Context) {
+	userId := ctx.GetUint("user_id")
+
+	if err := a.authService.RevokeAllSessions(ctx, userId); err != nil {
+		helper.InternalServerError(ctx, "Failed to revoke sessions", err)
+		return
+	}
+
+	helper.SuccessResponse(ctx, "sessions revoked successfully", nil)
+}
+
+// JWKS docs
+// @Summary JSON Web Key Set
+// @Description Public keys access tokens are verified with, as a standard JWK Set; empty when tokens are signed with a shared secret
+// @Tags Authentication
+// @Produce json
+// @Success 200 {object} utils.JWKS "Key set"
+// @Router /.well-known/jwks.json [get]
+func (a *AuthHandler) JWKS(ctx *gin.Context) {
+	// Verifiers cache the keys; a rotated key is published well before it
+	// signs.
+	ctx.Header("Cache-Control", "public, max-age=300")
+	ctx.JSON(http.StatusOK, a.authService.JWKS())
+}
+
+// GetOIDCProviders docs
+// @Summary Get identity providers
+// @Description List the OpenID Connect providers users can log in with
+// @Tags Authentication
+// @Produce json
+// @Success 200 {object} helper.Response{data=[]string} "Providers retrieved successfully"
+// @Router /auth/oidc/providers [get]
+func (a *AuthHandler) GetOIDCProviders(ctx *gin.Context) {
+	helper.SuccessResponse(ctx, "providers retrieved successfully", a.authService.OIDCProviders())
+}
+
+// AuthorizeOIDC docs
+// @Summary Start an identity provider login
+// @Description Get the URL to send the user to for logging in with an OpenID Connect provider; the provider redirects back with a code and the state
+// @Tags Authentication
+// @Produce json
+// @Param provider path string true "Provider name"
+// @Success 200 {object} helper.Response{data=dto.OIDCAuthorizationResponse} "Login started"
+// @Failure 404 {object} helper.Response "Unknown provider"
+// @Router /auth/oidc/{provider}/authorize [get]
+func (a *AuthHandler) AuthorizeOIDC(ctx *gin.Context) {
+	authorization, err := a.authService.AuthorizeOIDC(ctx, ctx.Param("provider"))
+	if err != nil {
+		oidcError(ctx, "Failed to start login", err)
+		return
+	}
+
+	helper.SuccessResponse(ctx, "login started successfully", authorization)
+}
+
+// LoginWithOIDC docs
+// @Summary Complete an identity provider login
+// @Description Exchange the code and state an OpenID Connect provider redirected back with for tokens. Verified customer accounts are linked by their email address, and new users are registered on their first login
+// @Tags Authentication
+// @Accept json
+// @Produce json
+// @Param provider path string true "Provider name"
+// @Param request body dto.OIDCLoginRequest true "Code and state"
+// @Success 200 {object} helper.Response{data=dto.AuthResponse} "Login successfully, or a two-factor challenge when two_factor_required is set"
+// @Failure 400 {object} helper.Response "Invalid request data or state"
+// @Failure 401 {object} helper.Response "Provider login failed"
+// @Failure 403 {object} helper.Response "Email address is not verified by the provider"
+// @Failure 404 {object} helper.Response "Unknown provider"
+// @Failure 409 {object} helper.Response "The account with the email address cannot be linked"
+// @Router /auth/oidc/{provider}/callback [post]
+func (a *AuthHandler) LoginWithOIDC(ctx *gin.Context) {
+	var payload dto.OIDCLoginRequest
+	if err := ctx.ShouldBindJSON(&payload); err != nil {
+		helper.BadRequestResponse(ctx, "Invalid request data", err)
+		return
+	}
+
+	loginResponse, err := a.authService.LoginWithOIDC(ctx, ctx.Param("provider"), &payload)
+	if err != nil {
+		oidcError(ctx, "Failed to login", err)
+		return
+	}
+
+	helper.SuccessResponse(ctx, "user logged in successfully", loginResponse)
+}
+
+// oidcError answers a failed identity provider login with the status its
+// error calls for.
+func oidcError(ctx *gin.Context, message string, err error) {
+	switch {
+	case errors.Is(err, service.ErrUnknownOIDCProvider):
+		helper.NotFoundResponse(ctx, err.Error())
+	case errors.Is(err, service.ErrInvalidOIDCState):
+		helper.BadRequestResponse(ctx, message, err)
+	case errors.Is(err, service.ErrOIDCEmailNotVerified):
+		helper.ForbiddenResponse(ctx, err.Error())
+	case errors.Is(err, service.ErrOIDCAccountNotLinkable):
+		helper.ErrorResponse(ctx, http.StatusConflict, message, err)
+	case errors.Is(err, service.ErrOIDCLoginFailed):
+		helper.ErrorResponse(ctx, http.StatusUnauthorized, message, err)
+	default:
+		helper.InternalServerError(ctx, message, err)
+	}
+}
+
+// loginLocked answers a login refused by a lockout, telling the client when
+// to retry like RateLimitMiddleware does.
+func loginLocked(ctx *gin.Context, err error) bool {
+	var locked *service.LoginLockedError
+	if !errors.As(err, &locked) {
+		return false
+	}
+
+	ctx.Header("RateLimit-RetryAfter", strconv.Itoa(int(locked.RetryAfter.Round(time.Second)/time.Second)))
+	helper.TooManyRequestsResponse(ctx, err.Error())
+	return true
+}
+
 func NewAuthHandler(authService service.AuthService) *AuthHandler {
 	return &AuthHandler{
 		authService: authService,
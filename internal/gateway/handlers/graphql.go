@@ -4,6 +4,8 @@ import (
 	"github.com/99designs/gqlgen/graphql/handler"
 	"github.com/99designs/gqlgen/graphql/playground"
 	"github.com/gin-gonic/gin"
+	"github.com/gorilla/websocket"
+	"github.com/saleh-ghazimoradi/Cartopher/internal/helper"
 )
 
 type GraphQLHandler struct {
@@ -16,6 +18,19 @@ func (g *GraphQLHandler) GraphqlHandler() gin.HandlerFunc {
 	}
 }
 
+// SubscriptionHandler serves GraphQL subscriptions. It only accepts
+// websocket upgrades, which are authenticated on connection init rather than
+// by header.
+func (g *GraphQLHandler) SubscriptionHandler() gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		if !websocket.IsWebSocketUpgrade(ctx.Request) {
+			helper.BadRequestResponse(ctx, "websocket upgrade required", nil)
+			return
+		}
+		g.handler.ServeHTTP(ctx.Writer, ctx.Request)
+	}
+}
+
 func (g *GraphQLHandler) PlayGround() gin.HandlerFunc {
 	h := playground.Handler("GraphQL playground", "/graphql/")
 	return func(ctx *gin.Context) {
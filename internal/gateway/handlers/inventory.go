@@ -0,0 +1,215 @@
+package handlers
+
+import (
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/saleh-ghazimoradi/Cartopher/internal/dto"
+	"github.com/saleh-ghazimoradi/Cartopher/internal/helper"
+	"github.com/saleh-ghazimoradi/Cartopher/internal/service"
+)
+
+type InventoryHandler struct {
+	inventoryService service.InventoryService
+}
+
+// CreateWarehouse docs
+// @Summary Create a warehouse
+// @Description Create a new fulfilment location (Admin only)
+// @Tags Inventory
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param request body dto.CreateWarehouseRequest true "Warehouse data"
+// @Success 201 {object} helper.Response{data=dto.WarehouseResponse} "Warehouse created successfully"
+// @Failure 400 {object} helper.Response "Invalid request data"
+// @Failure 401 {object} helper.Response "Unauthorized"
+// @Failure 403 {object} helper.Response "Admin access required"
+// @Router /warehouses [post]
+func (i *InventoryHandler) CreateWarehouse(ctx *gin.Context) {
+	var payload *dto.CreateWarehouseRequest
+	if err := ctx.ShouldBindJSON(&payload); err != nil {
+		helper.BadRequestResponse(ctx, "Invalid payload given", err)
+		return
+	}
+
+	warehouse, err := i.inventoryService.CreateWarehouse(ctx, payload)
+	if err != nil {
+		helper.InternalServerError(ctx, "Error creating warehouse", err)
+		return
+	}
+
+	helper.CreatedResponse(ctx, "Warehouse successfully created", warehouse)
+}
+
+// GetWarehouses docs
+// @Summary Get all warehouses
+// @Description Retrieve all warehouses ordered by priority (Admin only)
+// @Tags Inventory
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {object} helper.Response{data=[]dto.WarehouseResponse} "Warehouses retrieved successfully"
+// @Failure 401 {object} helper.Response "Unauthorized"
+// @Failure 403 {object} helper.Response "Admin access required"
+// @Router /warehouses [get]
+func (i *InventoryHandler) GetWarehouses(ctx *gin.Context) {
+	warehouses, err := i.inventoryService.GetWarehouses(ctx)
+	if err != nil {
+		helper.InternalServerError(ctx, "Error getting warehouses", err)
+		return
+	}
+
+	helper.SuccessResponse(ctx, "Warehouses successfully retrieved", warehouses)
+}
+
+// UpdateWarehouse docs
+// @Summary Update a warehouse
+// @Description Update an existing warehouse (Admin only)
+// @Tags Inventory
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "Warehouse ID"
+// @Param request body dto.UpdateWarehouseRequest true "Warehouse update data"
+// @Success 200 {object} helper.Response{data=dto.WarehouseResponse} "Warehouse updated successfully"
+// @Failure 400 {object} helper.Response "Invalid request data"
+// @Failure 401 {object} helper.Response "Unauthorized"
+// @Failure 403 {object} helper.Response "Admin access required"
+// @Router /warehouses/{id} [put]
+func (i *InventoryHandler) UpdateWarehouse(ctx *gin.Context) {
+	id, err := strconv.ParseUint(ctx.Param("id"), 10, 32)
+	if err != nil {
+		helper.BadRequestResponse(ctx, "Invalid id given", err)
+		return
+	}
+
+	var payload *dto.UpdateWarehouseRequest
+	if err := ctx.ShouldBindJSON(&payload); err != nil {
+		helper.BadRequestResponse(ctx, "Invalid payload given", err)
+		return
+	}
+
+	warehouse, err := i.inventoryService.UpdateWarehouse(ctx, uint(id), payload)
+	if err != nil {
+		helper.InternalServerError(ctx, "Error updating warehouse", err)
+		return
+	}
+
+	helper.SuccessResponse(ctx, "Warehouse successfully updated", warehouse)
+}
+
+// DeleteWarehouse docs
+// @Summary Delete a warehouse
+// @Description Delete a warehouse (Admin only)
+// @Tags Inventory
+// @Security BearerAuth
+// @Param id path int true "Warehouse ID"
+// @Success 200 {object} helper.Response "Warehouse deleted successfully"
+// @Failure 400 {object} helper.Response "Invalid warehouse ID"
+// @Failure 401 {object} helper.Response "Unauthorized"
+// @Failure 403 {object} helper.Response "Admin access required"
+// @Router /warehouses/{id} [delete]
+func (i *InventoryHandler) DeleteWarehouse(ctx *gin.Context) {
+	id, err := strconv.ParseUint(ctx.Param("id"), 10, 32)
+	if err != nil {
+		helper.BadRequestResponse(ctx, "Invalid id given", err)
+		return
+	}
+
+	if err := i.inventoryService.DeleteWarehouse(ctx, uint(id)); err != nil {
+		helper.InternalServerError(ctx, "Error deleting warehouse", err)
+		return
+	}
+
+	helper.SuccessResponse(ctx, "Warehouse successfully deleted", nil)
+}
+
+// GetProductStock docs
+// @Summary Get product stock levels
+// @Description Retrieve the stock held for a product in each warehouse (Admin only)
+// @Tags Inventory
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "Product ID"
+// @Success 200 {object} helper.Response{data=[]dto.StockLevelResponse} "Stock levels retrieved successfully"
+// @Failure 400 {object} helper.Response "Invalid product ID"
+// @Failure 401 {object} helper.Response "Unauthorized"
+// @Failure 403 {object} helper.Response "Admin access required"
+// @Router /products/{id}/stock [get]
+func (i *InventoryHandler) GetProductStock(ctx *gin.Context) {
+	id, err := strconv.ParseUint(ctx.Param("id"), 10, 32)
+	if err != nil {
+		helper.BadRequestResponse(ctx, "Invalid id given", err)
+		return
+	}
+
+	stock, err := i.inventoryService.GetProductStock(ctx, uint(id))
+	if err != nil {
+		helper.InternalServerError(ctx, "Error getting product stock", err)
+		return
+	}
+
+	helper.SuccessResponse(ctx, "Product stock successfully retrieved", stock)
+}
+
+// SetStockLevel docs
+// @Summary Set product stock level
+// @Description Set the stock held for a product in a warehouse (Admin only)
+// @Tags Inventory
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "Product ID"
+// @Param request body dto.SetStockLevelRequest true "Stock level"
+// @Success 200 {object} helper.Response{data=[]dto.StockLevelResponse} "Stock level updated successfully"
+// @Failure 400 {object} helper.Response "Invalid request data"
+// @Failure 401 {object} helper.Response "Unauthorized"
+// @Failure 403 {object} helper.Response "Admin access required"
+// @Router /products/{id}/stock [put]
+func (i *InventoryHandler) SetStockLevel(ctx *gin.Context) {
+	id, err := strconv.ParseUint(ctx.Param("id"), 10, 32)
+	if err != nil {
+		helper.BadRequestResponse(ctx, "Invalid id given", err)
+		return
+	}
+
+	var payload *dto.SetStockLevelRequest
+	if err := ctx.ShouldBindJSON(&payload); err != nil {
+		helper.BadRequestResponse(ctx, "Invalid payload given", err)
+		return
+	}
+
+	stock, err := i.inventoryService.SetStockLevel(ctx, uint(id), payload)
+	if err != nil {
+		helper.InternalServerError(ctx, "Error setting stock level", err)
+		return
+	}
+
+	helper.SuccessResponse(ctx, "Stock level successfully updated", stock)
+}
+
+// GetLowStockProducts docs
+// @Summary Get low-stock report
+// @Description List every active product whose stock is at or below its low-stock threshold (Admin only)
+// @Tags Inventory
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {object} helper.Response{data=[]dto.LowStockProductResponse} "Low-stock products retrieved successfully"
+// @Failure 401 {object} helper.Response "Unauthorized"
+// @Failure 403 {object} helper.Response "Admin access required"
+// @Router /reports/low-stock [get]
+func (i *InventoryHandler) GetLowStockProducts(ctx *gin.Context) {
+	products, err := i.inventoryService.GetLowStockProducts(ctx)
+	if err != nil {
+		helper.InternalServerError(ctx, "Error getting low-stock products", err)
+		return
+	}
+
+	helper.SuccessResponse(ctx, "Low-stock products successfully retrieved", products)
+}
+
+func NewInventoryHandler(inventoryService service.InventoryService) *InventoryHandler {
+	return &InventoryHandler{
+		inventoryService: inventoryService,
+	}
+}
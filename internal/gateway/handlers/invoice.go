@@ -0,0 +1,136 @@
+package handlers
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/saleh-ghazimoradi/Cartopher/internal/dto"
+	"github.com/saleh-ghazimoradi/Cartopher/internal/helper"
+	"github.com/saleh-ghazimoradi/Cartopher/internal/repository"
+	"github.com/saleh-ghazimoradi/Cartopher/internal/service"
+)
+
+type InvoiceHandler struct {
+	invoiceService service.InvoiceService
+}
+
+// GetInvoice docs
+// @Summary Download an invoice
+// @Description Download the PDF invoice of one of the current user's confirmed orders
+// @Tags Invoices
+// @Produce application/pdf
+// @Security BearerAuth
+// @Param id path int true "Order ID"
+// @Success 200 {file} file "Invoice PDF"
+// @Failure 400 {object} helper.Response "Invalid order ID"
+// @Failure 401 {object} helper.Response "Unauthorized"
+// @Failure 404 {object} helper.Response "Invoice not found"
+// @Failure 500 {object} helper.Response "Internal server error"
+// @Router /orders/{id}/invoice [get]
+func (i *InvoiceHandler) GetInvoice(ctx *gin.Context) {
+	userId := ctx.GetUint("user_id")
+
+	id, err := strconv.ParseUint(ctx.Param("id"), 10, 32)
+	if err != nil {
+		helper.BadRequestResponse(ctx, "invalid order id", err)
+		return
+	}
+
+	invoice, err := i.invoiceService.GetInvoice(ctx, userId, uint(id))
+	if err != nil {
+		if errors.Is(err, repository.ErrNotFound) || errors.Is(err, service.ErrNotInvoiced) {
+			helper.NotFoundResponse(ctx, "invoice not found")
+			return
+		}
+		helper.InternalServerError(ctx, "Error getting invoice", err)
+		return
+	}
+
+	i.sendDocument(ctx, invoice)
+}
+
+// RegenerateInvoice docs
+// @Summary Regenerate an invoice
+// @Description Re-render and store an order's invoice PDF, keeping its number (Admin only)
+// @Tags Invoices
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "Order ID"
+// @Success 200 {object} helper.Response{data=dto.InvoiceResponse} "Invoice regenerated successfully"
+// @Failure 400 {object} helper.Response "Invalid order ID"
+// @Failure 401 {object} helper.Response "Unauthorized"
+// @Failure 403 {object} helper.Response "Admin access required"
+// @Failure 404 {object} helper.Response "Order not found"
+// @Failure 409 {object} helper.Response "Order not invoiced yet"
+// @Failure 500 {object} helper.Response "Internal server error"
+// @Router /orders/{id}/invoice/regenerate [post]
+func (i *InvoiceHandler) RegenerateInvoice(ctx *gin.Context) {
+	id, err := strconv.ParseUint(ctx.Param("id"), 10, 32)
+	if err != nil {
+		helper.BadRequestResponse(ctx, "Invalid id given", err)
+		return
+	}
+
+	invoice, err := i.invoiceService.RegenerateInvoice(ctx, uint(id))
+	if err != nil {
+		switch {
+		case errors.Is(err, repository.ErrNotFound):
+			helper.NotFoundResponse(ctx, "order not found")
+		case errors.Is(err, service.ErrNotInvoiced):
+			helper.ErrorResponse(ctx, http.StatusConflict, "Error regenerating invoice", err)
+		default:
+			helper.InternalServerError(ctx, "Error regenerating invoice", err)
+		}
+		return
+	}
+
+	helper.SuccessResponse(ctx, "Invoice successfully regenerated", invoice)
+}
+
+// GetPackingSlip docs
+// @Summary Download a packing slip
+// @Description Download the PDF packing slip of an order, grouped by warehouse (Admin only)
+// @Tags Invoices
+// @Produce application/pdf
+// @Security BearerAuth
+// @Param id path int true "Order ID"
+// @Success 200 {file} file "Packing slip PDF"
+// @Failure 400 {object} helper.Response "Invalid order ID"
+// @Failure 401 {object} helper.Response "Unauthorized"
+// @Failure 403 {object} helper.Response "Admin access required"
+// @Failure 404 {object} helper.Response "Order not found"
+// @Failure 500 {object} helper.Response "Internal server error"
+// @Router /orders/{id}/packing-slip [get]
+func (i *InvoiceHandler) GetPackingSlip(ctx *gin.Context) {
+	id, err := strconv.ParseUint(ctx.Param("id"), 10, 32)
+	if err != nil {
+		helper.BadRequestResponse(ctx, "Invalid id given", err)
+		return
+	}
+
+	slip, err := i.invoiceService.GetPackingSlip(ctx, uint(id))
+	if err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			helper.NotFoundResponse(ctx, "order not found")
+			return
+		}
+		helper.InternalServerError(ctx, "Error generating packing slip", err)
+		return
+	}
+
+	i.sendDocument(ctx, slip)
+}
+
+func (i *InvoiceHandler) sendDocument(ctx *gin.Context, document *dto.DocumentResponse) {
+	ctx.Header("Content-Disposition", fmt.Sprintf(`attachment; filename="%s"`, document.Filename))
+	ctx.Data(http.StatusOK, "application/pdf", document.Content)
+}
+
+func NewInvoiceHandler(invoiceService service.InvoiceService) *InvoiceHandler {
+	return &InvoiceHandler{
+		invoiceService: invoiceService,
+	}
+}
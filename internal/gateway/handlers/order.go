@@ -1,32 +1,58 @@
 package handlers
 
 import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
 	"strconv"
+	"time"
 
 	"github.com/gin-gonic/gin"
+	"github.com/saleh-ghazimoradi/Cartopher/internal/dto"
 	"github.com/saleh-ghazimoradi/Cartopher/internal/helper"
 	"github.com/saleh-ghazimoradi/Cartopher/internal/service"
 )
 
+// orderEventsHeartbeat keeps idle event streams from being closed by
+// proxies along the way.
+const orderEventsHeartbeat = 15 * time.Second
+
 type OrderHandler struct {
-	orderService service.OrderService
+	orderService    service.OrderService
+	realtimeService service.RealtimeService
 }
 
 // CreateOrder dcs
 // @Summary Create an order
-// @Description Create an order from the current user's cart
+// @Description Create an order from the current user's cart, allocating each line to a warehouse
 // @Tags Orders
+// @Accept json
 // @Produce json
 // @Security BearerAuth
+// @Param request body dto.CreateOrderRequest false "Shipping address"
 // @Success 201 {object} helper.Response{data=dto.OrderResponse} "Order created successfully"
 // @Failure 400 {object} helper.Response "Cart is empty or insufficient stock"
 // @Failure 401 {object} helper.Response "Unauthorized"
+// @Failure 403 {object} helper.Response "Email address is not verified"
 // @Router /orders [post]
 func (o *OrderHandler) CreateOrder(ctx *gin.Context) {
 	userId := ctx.GetUint("user_id")
 
-	order, err := o.orderService.CreateOrder(ctx, userId)
+	var payload dto.CreateOrderRequest
+	if ctx.Request.ContentLength != 0 {
+		if err := ctx.ShouldBindJSON(&payload); err != nil {
+			helper.BadRequestResponse(ctx, "invalid payload given", err)
+			return
+		}
+	}
+
+	order, err := o.orderService.CreateOrder(ctx, userId, &payload)
 	if err != nil {
+		if errors.Is(err, service.ErrEmailNotVerified) {
+			helper.ForbiddenResponse(ctx, "verify your email address before checking out")
+			return
+		}
 		helper.InternalServerError(ctx, "error while creating order", err)
 		return
 	}
@@ -90,8 +116,132 @@ func (o *OrderHandler) GetOrders(ctx *gin.Context) {
 	helper.PaginatedSuccessResponse(ctx, "Order retrieved successfully", orders, *meta)
 }
 
-func NewOrderHandler(orderService service.OrderService) *OrderHandler {
+// ConfirmOrder docs
+// @Summary Confirm an order
+// @Description Confirm a pending order and issue its invoice number (Admin only)
+// @Tags Orders
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "Order ID"
+// @Success 200 {object} helper.Response{data=dto.OrderResponse} "Order confirmed successfully"
+// @Failure 400 {object} helper.Response "Invalid order ID"
+// @Failure 401 {object} helper.Response "Unauthorized"
+// @Failure 403 {object} helper.Response "Admin access required"
+// @Router /orders/{id}/confirm [put]
+func (o *OrderHandler) ConfirmOrder(ctx *gin.Context) {
+	id, err := strconv.ParseUint(ctx.Param("id"), 10, 32)
+	if err != nil {
+		helper.BadRequestResponse(ctx, "invalid order id", err)
+		return
+	}
+
+	order, err := o.orderService.ConfirmOrder(ctx, uint(id))
+	if err != nil {
+		helper.InternalServerError(ctx, "error while confirming order", err)
+		return
+	}
+
+	helper.SuccessResponse(ctx, "order successfully confirmed", order)
+}
+
+// CancelOrder docs
+// @Summary Cancel an order
+// @Description Cancel one of the current user's orders before it has shipped
+// @Tags Orders
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "Order ID"
+// @Success 200 {object} helper.Response{data=dto.OrderResponse} "Order cancelled successfully"
+// @Failure 400 {object} helper.Response "Invalid order ID"
+// @Failure 401 {object} helper.Response "Unauthorized"
+// @Failure 500 {object} helper.Response "Internal server error"
+// @Router /orders/{id}/cancel [put]
+func (o *OrderHandler) CancelOrder(ctx *gin.Context) {
+	userId := ctx.GetUint("user_id")
+
+	id, err := strconv.ParseUint(ctx.Param("id"), 10, 32)
+	if err != nil {
+		helper.BadRequestResponse(ctx, "invalid order id", err)
+		return
+	}
+
+	order, err := o.orderService.CancelOrder(ctx, userId, uint(id))
+	if err != nil {
+		helper.InternalServerError(ctx, "error while cancelling order", err)
+		return
+	}
+
+	helper.SuccessResponse(ctx, "order successfully cancelled", order)
+}
+
+// OrderEvents docs
+// @Summary Stream order events
+// @Description Stream status changes and shipment updates of one of the current user's orders as Server-Sent Events. Each event carries the event envelope as data and its id as the event id; reconnect with Last-Event-ID to receive the events missed in between
+// @Tags Orders
+// @Produce text/event-stream
+// @Security BearerAuth
+// @Param id path int true "Order ID"
+// @Param Last-Event-ID header string false "Id of the last event received"
+// @Success 200 {string} string "Event stream"
+// @Failure 400 {object} helper.Response "Invalid order ID"
+// @Failure 401 {object} helper.Response "Unauthorized"
+// @Failure 404 {object} helper.Response "Order not found"
+// @Router /orders/{id}/events [get]
+func (o *OrderHandler) OrderEvents(ctx *gin.Context) {
+	userId := ctx.GetUint("user_id")
+
+	id, err := strconv.ParseUint(ctx.Param("id"), 10, 32)
+	if err != nil {
+		helper.BadRequestResponse(ctx, "invalid order id", err)
+		return
+	}
+
+	envelopes, err := o.realtimeService.OrderEvents(ctx, userId, uint(id), ctx.GetHeader("Last-Event-ID"))
+	if err != nil {
+		helper.NotFoundResponse(ctx, "order not found")
+		return
+	}
+
+	// The stream outlives the server write timeout.
+	_ = http.NewResponseController(ctx.Writer).SetWriteDeadline(time.Time{})
+
+	ctx.Header("Content-Type", "text/event-stream")
+	ctx.Header("Cache-Control", "no-cache")
+	ctx.Header("Connection", "keep-alive")
+	ctx.Header("X-Accel-Buffering", "no")
+	ctx.Status(http.StatusOK)
+	ctx.Writer.Flush()
+
+	heartbeat := time.NewTicker(orderEventsHeartbeat)
+	defer heartbeat.Stop()
+
+	for {
+		select {
+		case envelope, ok := <-envelopes:
+			if !ok {
+				return
+			}
+			data, err := json.Marshal(envelope)
+			if err != nil {
+				continue
+			}
+			if _, err := fmt.Fprintf(ctx.Writer, "id: %s\nevent: %s\ndata: %s\n\n", envelope.Id, envelope.Type, data); err != nil {
+				return
+			}
+		case <-heartbeat.C:
+			if _, err := fmt.Fprint(ctx.Writer, ": heartbeat\n\n"); err != nil {
+				return
+			}
+		case <-ctx.Request.Context().Done():
+			return
+		}
+		ctx.Writer.Flush()
+	}
+}
+
+func NewOrderHandler(orderService service.OrderService, realtimeService service.RealtimeService) *OrderHandler {
 	return &OrderHandler{
-		orderService: orderService,
+		orderService:    orderService,
+		realtimeService: realtimeService,
 	}
 }
@@ -1,6 +1,7 @@
 package handlers
 
 import (
+	"errors"
 	"fmt"
 	"strconv"
 
@@ -146,6 +147,10 @@ func (p *ProductHandler) CreateProduct(ctx *gin.Context) {
 
 	product, err := p.productService.CreateProduct(ctx, payload)
 	if err != nil {
+		if errors.Is(err, service.ErrNoDefaultWarehouse) {
+			helper.BadRequestResponse(ctx, "Error creating product", err)
+			return
+		}
 		helper.InternalServerError(ctx, "Error creating product", err)
 		return
 	}
@@ -231,6 +236,10 @@ func (p *ProductHandler) UpdateProduct(ctx *gin.Context) {
 
 	updatedProduct, err := p.productService.UpdateProduct(ctx, uint(id), payload)
 	if err != nil {
+		if errors.Is(err, service.ErrNoDefaultWarehouse) {
+			helper.BadRequestResponse(ctx, "Error updating product", err)
+			return
+		}
 		helper.InternalServerError(ctx, "Error updating product", err)
 		return
 	}
@@ -0,0 +1,276 @@
+package handlers
+
+import (
+	"errors"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/saleh-ghazimoradi/Cartopher/internal/dto"
+	"github.com/saleh-ghazimoradi/Cartopher/internal/helper"
+	"github.com/saleh-ghazimoradi/Cartopher/internal/service"
+)
+
+type ReturnHandler struct {
+	returnService service.ReturnService
+}
+
+// CreateReturn docs
+// @Summary Request a return
+// @Description Request the return of delivered items from one of the current user's orders
+// @Tags Returns
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "Order ID"
+// @Param request body dto.CreateReturnRequest true "Items to return"
+// @Success 201 {object} helper.Response{data=dto.ReturnResponse} "Return requested successfully"
+// @Failure 400 {object} helper.Response "Invalid request data"
+// @Failure 401 {object} helper.Response "Unauthorized"
+// @Router /orders/{id}/returns [post]
+func (r *ReturnHandler) CreateReturn(ctx *gin.Context) {
+	userId := ctx.GetUint("user_id")
+
+	id, err := strconv.ParseUint(ctx.Param("id"), 10, 32)
+	if err != nil {
+		helper.BadRequestResponse(ctx, "invalid order id", err)
+		return
+	}
+
+	var payload *dto.CreateReturnRequest
+	if err := ctx.ShouldBindJSON(&payload); err != nil {
+		helper.BadRequestResponse(ctx, "invalid payload given", err)
+		return
+	}
+
+	returnRequest, err := r.returnService.CreateReturn(ctx, userId, uint(id), payload)
+	if err != nil {
+		helper.InternalServerError(ctx, "error while requesting return", err)
+		return
+	}
+
+	helper.CreatedResponse(ctx, "return successfully requested", returnRequest)
+}
+
+// GetReturns docs
+// @Summary Get user's returns
+// @Description Retrieve every return requested by the current user
+// @Tags Returns
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {object} helper.Response{data=[]dto.ReturnResponse} "Returns retrieved successfully"
+// @Failure 400 {object} helper.Response "Invalid status"
+// @Failure 401 {object} helper.Response "Unauthorized"
+// @Router /returns [get]
+func (r *ReturnHandler) GetReturns(ctx *gin.Context) {
+	userId := ctx.GetUint("user_id")
+
+	returns, err := r.returnService.GetReturns(ctx, userId)
+	if err != nil {
+		helper.InternalServerError(ctx, "error while getting returns", err)
+		return
+	}
+
+	helper.SuccessResponse(ctx, "returns successfully retrieved", returns)
+}
+
+// GetReturn docs
+// @Summary Get return by ID
+// @Description Retrieve one of the current user's returns
+// @Tags Returns
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "Return ID"
+// @Success 200 {object} helper.Response{data=dto.ReturnResponse} "Return retrieved successfully"
+// @Failure 400 {object} helper.Response "Invalid return ID"
+// @Failure 401 {object} helper.Response "Unauthorized"
+// @Failure 404 {object} helper.Response "Return not found"
+// @Router /returns/{id} [get]
+func (r *ReturnHandler) GetReturn(ctx *gin.Context) {
+	userId := ctx.GetUint("user_id")
+
+	id, err := strconv.ParseUint(ctx.Param("id"), 10, 32)
+	if err != nil {
+		helper.BadRequestResponse(ctx, "invalid return id", err)
+		return
+	}
+
+	returnRequest, err := r.returnService.GetReturn(ctx, userId, uint(id))
+	if err != nil {
+		helper.NotFoundResponse(ctx, "return not found")
+		return
+	}
+
+	helper.SuccessResponse(ctx, "return successfully retrieved", returnRequest)
+}
+
+// GetReturnsByStatus docs
+// @Summary Get all returns
+// @Description Retrieve every return, optionally filtered by status (Admin only)
+// @Tags Returns
+// @Produce json
+// @Security BearerAuth
+// @Param status query string false "Return status" Enums(requested, approved, rejected, received, refunded)
+// @Success 200 {object} helper.Response{data=[]dto.ReturnResponse} "Returns retrieved successfully"
+// @Failure 400 {object} helper.Response "Invalid status"
+// @Failure 401 {object} helper.Response "Unauthorized"
+// @Failure 403 {object} helper.Response "Admin access required"
+// @Router /admin/returns [get]
+func (r *ReturnHandler) GetReturnsByStatus(ctx *gin.Context) {
+	returns, err := r.returnService.GetReturnsByStatus(ctx, ctx.Query("status"))
+	if err != nil {
+		if errors.Is(err, service.ErrInvalidReturnStatus) {
+			helper.BadRequestResponse(ctx, "Error getting returns", err)
+			return
+		}
+		helper.InternalServerError(ctx, "Error getting returns", err)
+		return
+	}
+
+	helper.SuccessResponse(ctx, "Returns successfully retrieved", returns)
+}
+
+// ApproveReturn docs
+// @Summary Approve a return
+// @Description Accept a requested return so the customer can send the items back (Admin only)
+// @Tags Returns
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "Return ID"
+// @Param request body dto.ReviewReturnRequest false "Note for the customer"
+// @Success 200 {object} helper.Response{data=dto.ReturnResponse} "Return approved successfully"
+// @Failure 400 {object} helper.Response "Invalid request data"
+// @Failure 401 {object} helper.Response "Unauthorized"
+// @Failure 403 {object} helper.Response "Admin access required"
+// @Router /admin/returns/{id}/approve [put]
+func (r *ReturnHandler) ApproveReturn(ctx *gin.Context) {
+	id, payload, ok := r.bindReview(ctx)
+	if !ok {
+		return
+	}
+
+	returnRequest, err := r.returnService.ApproveReturn(ctx, id, payload)
+	if err != nil {
+		helper.InternalServerError(ctx, "Error approving return", err)
+		return
+	}
+
+	helper.SuccessResponse(ctx, "Return successfully approved", returnRequest)
+}
+
+// RejectReturn docs
+// @Summary Reject a return
+// @Description Decline a requested return (Admin only)
+// @Tags Returns
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "Return ID"
+// @Param request body dto.ReviewReturnRequest false "Note for the customer"
+// @Success 200 {object} helper.Response{data=dto.ReturnResponse} "Return rejected successfully"
+// @Failure 400 {object} helper.Response "Invalid request data"
+// @Failure 401 {object} helper.Response "Unauthorized"
+// @Failure 403 {object} helper.Response "Admin access required"
+// @Router /admin/returns/{id}/reject [put]
+func (r *ReturnHandler) RejectReturn(ctx *gin.Context) {
+	id, payload, ok := r.bindReview(ctx)
+	if !ok {
+		return
+	}
+
+	returnRequest, err := r.returnService.RejectReturn(ctx, id, payload)
+	if err != nil {
+		helper.InternalServerError(ctx, "Error rejecting return", err)
+		return
+	}
+
+	helper.SuccessResponse(ctx, "Return successfully rejected", returnRequest)
+}
+
+// ReceiveReturn docs
+// @Summary Receive a return
+// @Description Record that the returned items arrived and restock them (Admin only)
+// @Tags Returns
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "Return ID"
+// @Success 200 {object} helper.Response{data=dto.ReturnResponse} "Return received successfully"
+// @Failure 400 {object} helper.Response "Invalid return ID"
+// @Failure 401 {object} helper.Response "Unauthorized"
+// @Failure 403 {object} helper.Response "Admin access required"
+// @Router /admin/returns/{id}/receive [put]
+func (r *ReturnHandler) ReceiveReturn(ctx *gin.Context) {
+	id, err := strconv.ParseUint(ctx.Param("id"), 10, 32)
+	if err != nil {
+		helper.BadRequestResponse(ctx, "Invalid id given", err)
+		return
+	}
+
+	returnRequest, err := r.returnService.ReceiveReturn(ctx, uint(id))
+	if err != nil {
+		helper.InternalServerError(ctx, "Error receiving return", err)
+		return
+	}
+
+	helper.SuccessResponse(ctx, "Return successfully received", returnRequest)
+}
+
+// RefundReturn docs
+// @Summary Refund a return
+// @Description Record the refund issued for a received return (Admin only)
+// @Tags Returns
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "Return ID"
+// @Param request body dto.RefundReturnRequest true "Refund reference"
+// @Success 200 {object} helper.Response{data=dto.ReturnResponse} "Return refunded successfully"
+// @Failure 400 {object} helper.Response "Invalid request data"
+// @Failure 401 {object} helper.Response "Unauthorized"
+// @Failure 403 {object} helper.Response "Admin access required"
+// @Router /admin/returns/{id}/refund [put]
+func (r *ReturnHandler) RefundReturn(ctx *gin.Context) {
+	id, err := strconv.ParseUint(ctx.Param("id"), 10, 32)
+	if err != nil {
+		helper.BadRequestResponse(ctx, "Invalid id given", err)
+		return
+	}
+
+	var payload *dto.RefundReturnRequest
+	if err := ctx.ShouldBindJSON(&payload); err != nil {
+		helper.BadRequestResponse(ctx, "Invalid payload given", err)
+		return
+	}
+
+	returnRequest, err := r.returnService.RefundReturn(ctx, uint(id), payload)
+	if err != nil {
+		helper.InternalServerError(ctx, "Error refunding return", err)
+		return
+	}
+
+	helper.SuccessResponse(ctx, "Return successfully refunded", returnRequest)
+}
+
+func (r *ReturnHandler) bindReview(ctx *gin.Context) (uint, *dto.ReviewReturnRequest, bool) {
+	id, err := strconv.ParseUint(ctx.Param("id"), 10, 32)
+	if err != nil {
+		helper.BadRequestResponse(ctx, "Invalid id given", err)
+		return 0, nil, false
+	}
+
+	var payload dto.ReviewReturnRequest
+	if ctx.Request.ContentLength != 0 {
+		if err := ctx.ShouldBindJSON(&payload); err != nil {
+			helper.BadRequestResponse(ctx, "Invalid payload given", err)
+			return 0, nil, false
+		}
+	}
+
+	return uint(id), &payload, true
+}
+
+func NewReturnHandler(returnService service.ReturnService) *ReturnHandler {
+	return &ReturnHandler{
+		returnService: returnService,
+	}
+}
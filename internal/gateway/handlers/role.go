@@ -0,0 +1,178 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/saleh-ghazimoradi/Cartopher/internal/dto"
+	"github.com/saleh-ghazimoradi/Cartopher/internal/helper"
+	"github.com/saleh-ghazimoradi/Cartopher/internal/repository"
+	"github.com/saleh-ghazimoradi/Cartopher/internal/service"
+)
+
+type RoleHandler struct {
+	roleService service.RoleService
+}
+
+// GetPermissions docs
+// @Summary Get permissions
+// @Description List every permission a role can grant (requires roles:manage)
+// @Tags Roles
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {object} helper.Response{data=[]string} "Permissions retrieved successfully"
+// @Failure 401 {object} helper.Response "Unauthorized"
+// @Failure 403 {object} helper.Response "Permission required"
+// @Router /admin/roles/permissions [get]
+func (r *RoleHandler) GetPermissions(ctx *gin.Context) {
+	helper.SuccessResponse(ctx, "permissions successfully retrieved", r.roleService.GetPermissions())
+}
+
+// GetRoles docs
+// @Summary Get roles
+// @Description List every role with the permissions it grants (requires roles:manage)
+// @Tags Roles
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {object} helper.Response{data=[]dto.RoleResponse} "Roles retrieved successfully"
+// @Failure 401 {object} helper.Response "Unauthorized"
+// @Failure 403 {object} helper.Response "Permission required"
+// @Router /admin/roles [get]
+func (r *RoleHandler) GetRoles(ctx *gin.Context) {
+	roles, err := r.roleService.GetRoles(ctx)
+	if err != nil {
+		helper.InternalServerError(ctx, "error while getting roles", err)
+		return
+	}
+
+	helper.SuccessResponse(ctx, "roles successfully retrieved", roles)
+}
+
+// GetRole docs
+// @Summary Get role by name
+// @Description Retrieve a role with the permissions it grants (requires roles:manage)
+// @Tags Roles
+// @Produce json
+// @Security BearerAuth
+// @Param name path string true "Role name"
+// @Success 200 {object} helper.Response{data=dto.RoleResponse} "Role retrieved successfully"
+// @Failure 401 {object} helper.Response "Unauthorized"
+// @Failure 403 {object} helper.Response "Permission required"
+// @Failure 404 {object} helper.Response "Role not found"
+// @Router /admin/roles/{name} [get]
+func (r *RoleHandler) GetRole(ctx *gin.Context) {
+	role, err := r.roleService.GetRole(ctx, ctx.Param("name"))
+	if err != nil {
+		helper.NotFoundResponse(ctx, "role not found")
+		return
+	}
+
+	helper.SuccessResponse(ctx, "role successfully retrieved", role)
+}
+
+// CreateRole docs
+// @Summary Create a role
+// @Description Create a staff role granting the given permissions, all of which the caller must have (requires roles:manage)
+// @Tags Roles
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param request body dto.CreateRoleRequest true "Role"
+// @Success 201 {object} helper.Response{data=dto.RoleResponse} "Role created successfully"
+// @Failure 400 {object} helper.Response "Invalid request data"
+// @Failure 401 {object} helper.Response "Unauthorized"
+// @Failure 403 {object} helper.Response "Permission required or not held"
+// @Failure 409 {object} helper.Response "Role already exists"
+// @Router /admin/roles [post]
+func (r *RoleHandler) CreateRole(ctx *gin.Context) {
+	var payload *dto.CreateRoleRequest
+	if err := ctx.ShouldBindJSON(&payload); err != nil {
+		helper.BadRequestResponse(ctx, "invalid payload given", err)
+		return
+	}
+
+	role, err := r.roleService.CreateRole(ctx, ctx.GetUint("user_id"), payload)
+	if err != nil {
+		roleError(ctx, "error while creating role", err)
+		return
+	}
+
+	helper.CreatedResponse(ctx, "role successfully created", role)
+}
+
+// UpdateRole docs
+// @Summary Update a role
+// @Description Replace the description and permissions of a role. The caller cannot change their own role, nor grant or revoke permissions they do not have. Its users have to refresh their tokens (requires roles:manage)
+// @Tags Roles
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param name path string true "Role name"
+// @Param request body dto.UpdateRoleRequest true "Role"
+// @Success 200 {object} helper.Response{data=dto.RoleResponse} "Role updated successfully"
+// @Failure 400 {object} helper.Response "Invalid request data"
+// @Failure 401 {object} helper.Response "Unauthorized"
+// @Failure 403 {object} helper.Response "Permission required or not held, own or system role"
+// @Failure 404 {object} helper.Response "Role not found"
+// @Router /admin/roles/{name} [put]
+func (r *RoleHandler) UpdateRole(ctx *gin.Context) {
+	var payload *dto.UpdateRoleRequest
+	if err := ctx.ShouldBindJSON(&payload); err != nil {
+		helper.BadRequestResponse(ctx, "invalid payload given", err)
+		return
+	}
+
+	role, err := r.roleService.UpdateRole(ctx, ctx.GetUint("user_id"), ctx.Param("name"), payload)
+	if err != nil {
+		roleError(ctx, "error while updating role", err)
+		return
+	}
+
+	helper.SuccessResponse(ctx, "role successfully updated", role)
+}
+
+// DeleteRole docs
+// @Summary Delete a role
+// @Description Delete a role no user has. The caller must have every permission it grants (requires roles:manage)
+// @Tags Roles
+// @Produce json
+// @Security BearerAuth
+// @Param name path string true "Role name"
+// @Success 200 {object} helper.Response "Role deleted successfully"
+// @Failure 401 {object} helper.Response "Unauthorized"
+// @Failure 403 {object} helper.Response "Permission required or not held, own or system role"
+// @Failure 404 {object} helper.Response "Role not found"
+// @Failure 409 {object} helper.Response "Role is assigned to users"
+// @Router /admin/roles/{name} [delete]
+func (r *RoleHandler) DeleteRole(ctx *gin.Context) {
+	if err := r.roleService.DeleteRole(ctx, ctx.GetUint("user_id"), ctx.Param("name")); err != nil {
+		roleError(ctx, "error while deleting role", err)
+		return
+	}
+
+	helper.SuccessResponse(ctx, "role successfully deleted", nil)
+}
+
+// roleError answers a failed role operation with the status its error
+// calls for.
+func roleError(ctx *gin.Context, message string, err error) {
+	switch {
+	case errors.Is(err, service.ErrInvalidRole), errors.Is(err, service.ErrInvalidRoleName):
+		helper.BadRequestResponse(ctx, message, err)
+	case errors.Is(err, service.ErrSystemRole), errors.Is(err, service.ErrOwnRole), errors.Is(err, service.ErrPermissionDenied):
+		helper.ForbiddenResponse(ctx, err.Error())
+	case errors.Is(err, service.ErrRoleExists), errors.Is(err, service.ErrRoleInUse):
+		helper.ErrorResponse(ctx, http.StatusConflict, message, err)
+	case errors.Is(err, repository.ErrNotFound):
+		helper.NotFoundResponse(ctx, "role not found")
+	default:
+		helper.InternalServerError(ctx, message, err)
+	}
+}
+
+func NewRoleHandler(roleService service.RoleService) *RoleHandler {
+	return &RoleHandler{
+		roleService: roleService,
+	}
+}
@@ -0,0 +1,112 @@
+package handlers
+
+import (
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/saleh-ghazimoradi/Cartopher/internal/dto"
+	"github.com/saleh-ghazimoradi/Cartopher/internal/helper"
+	"github.com/saleh-ghazimoradi/Cartopher/internal/service"
+)
+
+type ShipmentHandler struct {
+	shipmentService service.ShipmentService
+}
+
+// CreateShipment docs
+// @Summary Create a shipment
+// @Description Ship some or all of an order's item quantities under one tracking number (Admin only)
+// @Tags Shipments
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "Order ID"
+// @Param request body dto.CreateShipmentRequest true "Shipment data"
+// @Success 201 {object} helper.Response{data=dto.ShipmentResponse} "Shipment created successfully"
+// @Failure 400 {object} helper.Response "Invalid request data"
+// @Failure 401 {object} helper.Response "Unauthorized"
+// @Failure 403 {object} helper.Response "Admin access required"
+// @Router /orders/{id}/shipments [post]
+func (s *ShipmentHandler) CreateShipment(ctx *gin.Context) {
+	id, err := strconv.ParseUint(ctx.Param("id"), 10, 32)
+	if err != nil {
+		helper.BadRequestResponse(ctx, "Invalid id given", err)
+		return
+	}
+
+	var payload *dto.CreateShipmentRequest
+	if err := ctx.ShouldBindJSON(&payload); err != nil {
+		helper.BadRequestResponse(ctx, "Invalid payload given", err)
+		return
+	}
+
+	shipment, err := s.shipmentService.CreateShipment(ctx, uint(id), payload)
+	if err != nil {
+		helper.InternalServerError(ctx, "Error creating shipment", err)
+		return
+	}
+
+	helper.CreatedResponse(ctx, "Shipment successfully created", shipment)
+}
+
+// GetShipments docs
+// @Summary Get order shipments
+// @Description Retrieve every shipment of an order (Admin only)
+// @Tags Shipments
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "Order ID"
+// @Success 200 {object} helper.Response{data=[]dto.ShipmentResponse} "Shipments retrieved successfully"
+// @Failure 400 {object} helper.Response "Invalid order ID"
+// @Failure 401 {object} helper.Response "Unauthorized"
+// @Failure 403 {object} helper.Response "Admin access required"
+// @Router /orders/{id}/shipments [get]
+func (s *ShipmentHandler) GetShipments(ctx *gin.Context) {
+	id, err := strconv.ParseUint(ctx.Param("id"), 10, 32)
+	if err != nil {
+		helper.BadRequestResponse(ctx, "Invalid id given", err)
+		return
+	}
+
+	shipments, err := s.shipmentService.GetShipments(ctx, uint(id))
+	if err != nil {
+		helper.InternalServerError(ctx, "Error getting shipments", err)
+		return
+	}
+
+	helper.SuccessResponse(ctx, "Shipments successfully retrieved", shipments)
+}
+
+// MarkDelivered docs
+// @Summary Mark a shipment delivered
+// @Description Record that a shipment reached the customer (Admin only)
+// @Tags Shipments
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "Shipment ID"
+// @Success 200 {object} helper.Response{data=dto.ShipmentResponse} "Shipment updated successfully"
+// @Failure 400 {object} helper.Response "Invalid shipment ID"
+// @Failure 401 {object} helper.Response "Unauthorized"
+// @Failure 403 {object} helper.Response "Admin access required"
+// @Router /shipments/{id}/deliver [put]
+func (s *ShipmentHandler) MarkDelivered(ctx *gin.Context) {
+	id, err := strconv.ParseUint(ctx.Param("id"), 10, 32)
+	if err != nil {
+		helper.BadRequestResponse(ctx, "Invalid id given", err)
+		return
+	}
+
+	shipment, err := s.shipmentService.MarkDelivered(ctx, uint(id))
+	if err != nil {
+		helper.InternalServerError(ctx, "Error updating shipment", err)
+		return
+	}
+
+	helper.SuccessResponse(ctx, "Shipment successfully updated", shipment)
+}
+
+func NewShipmentHandler(shipmentService service.ShipmentService) *ShipmentHandler {
+	return &ShipmentHandler{
+		shipmentService: shipmentService,
+	}
+}
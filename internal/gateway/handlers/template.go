@@ -0,0 +1,69 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/saleh-ghazimoradi/Cartopher/infra/mailer"
+	"github.com/saleh-ghazimoradi/Cartopher/internal/helper"
+	"github.com/saleh-ghazimoradi/Cartopher/internal/service"
+)
+
+type TemplateHandler struct {
+	templateService service.TemplateService
+}
+
+// GetTemplates docs
+// @Summary List email templates
+// @Description List the available email templates, one per event type (Admin only)
+// @Tags Email Templates
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {object} helper.Response{data=[]string} "Templates retrieved successfully"
+// @Failure 401 {object} helper.Response "Unauthorized"
+// @Failure 403 {object} helper.Response "Admin access required"
+// @Router /admin/email-templates [get]
+func (t *TemplateHandler) GetTemplates(ctx *gin.Context) {
+	helper.SuccessResponse(ctx, "Templates successfully retrieved", t.templateService.GetTemplates(ctx))
+}
+
+// PreviewTemplate docs
+// @Summary Preview an email template
+// @Description Render an email template with sample data. With format=html the HTML part is returned as a page (Admin only)
+// @Tags Email Templates
+// @Produce json,html
+// @Security BearerAuth
+// @Param name path string true "Template name"
+// @Param locale query string false "Locale, e.g. de"
+// @Param format query string false "Set to html to get the rendered HTML page"
+// @Success 200 {object} helper.Response{data=dto.EmailPreviewResponse} "Template rendered successfully"
+// @Failure 401 {object} helper.Response "Unauthorized"
+// @Failure 403 {object} helper.Response "Admin access required"
+// @Failure 404 {object} helper.Response "Template not found"
+// @Failure 500 {object} helper.Response "Internal server error"
+// @Router /admin/email-templates/{name}/preview [get]
+func (t *TemplateHandler) PreviewTemplate(ctx *gin.Context) {
+	preview, err := t.templateService.PreviewTemplate(ctx, ctx.Param("name"), ctx.Query("locale"))
+	if err != nil {
+		if errors.Is(err, mailer.ErrTemplateNotFound) {
+			helper.NotFoundResponse(ctx, "template not found")
+			return
+		}
+		helper.InternalServerError(ctx, "error rendering template", err)
+		return
+	}
+
+	if ctx.Query("format") == "html" {
+		ctx.Data(http.StatusOK, "text/html; charset=utf-8", []byte(preview.HTML))
+		return
+	}
+
+	helper.SuccessResponse(ctx, "Template successfully rendered", preview)
+}
+
+func NewTemplateHandler(templateService service.TemplateService) *TemplateHandler {
+	return &TemplateHandler{
+		templateService: templateService,
+	}
+}
@@ -1,6 +1,8 @@
 package handlers
 
 import (
+	"errors"
+
 	"github.com/gin-gonic/gin"
 	"github.com/saleh-ghazimoradi/Cartopher/internal/dto"
 	"github.com/saleh-ghazimoradi/Cartopher/internal/helper"
@@ -63,6 +65,39 @@ func (u *UserHandler) UpdateProfile(ctx *gin.Context) {
 	helper.SuccessResponse(ctx, "User profile updated successfully", updatedUserProfile)
 }
 
+// ChangePassword docs
+// @Summary Change password
+// @Description Change the current authenticated user's password and log out every session
+// @Tags User
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param request body dto.ChangePasswordRequest true "Current and new password"
+// @Success 200 {object} helper.Response "Password changed successfully"
+// @Failure 400 {object} helper.Response "Invalid request data or incorrect current password"
+// @Failure 401 {object} helper.Response "Unauthorized"
+// @Router /users/password [put]
+func (u *UserHandler) ChangePassword(ctx *gin.Context) {
+	userId := ctx.GetUint("user_id")
+
+	var payload dto.ChangePasswordRequest
+	if err := ctx.ShouldBindJSON(&payload); err != nil {
+		helper.BadRequestResponse(ctx, "invalid request data", err)
+		return
+	}
+
+	if err := u.userService.ChangePassword(ctx, userId, &payload); err != nil {
+		if errors.Is(err, service.ErrIncorrectPassword) {
+			helper.BadRequestResponse(ctx, "could not change password", err)
+			return
+		}
+		helper.InternalServerError(ctx, "could not change password", err)
+		return
+	}
+
+	helper.SuccessResponse(ctx, "Password changed successfully", nil)
+}
+
 func NewUserHandler(userService service.UserService) *UserHandler {
 	return &UserHandler{
 		userService: userService,
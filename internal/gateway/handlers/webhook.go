@@ -0,0 +1,271 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/saleh-ghazimoradi/Cartopher/internal/dto"
+	"github.com/saleh-ghazimoradi/Cartopher/internal/helper"
+	"github.com/saleh-ghazimoradi/Cartopher/internal/repository"
+	"github.com/saleh-ghazimoradi/Cartopher/internal/service"
+)
+
+type WebhookHandler struct {
+	webhookService service.WebhookService
+}
+
+// CreateEndpoint docs
+// @Summary Create a webhook endpoint
+// @Description Register a URL that receives the given event types as signed JSON POSTs. The X-Cartopher-Signature header is "t=<unix time>,v1=<hex HMAC-SHA256 of "<unix time>.<body>" keyed with the secret>". The secret is only returned here (Admin only)
+// @Tags Webhooks
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param request body dto.CreateWebhookEndpointRequest true "Webhook endpoint"
+// @Success 201 {object} helper.Response{data=dto.WebhookEndpointResponse} "Webhook endpoint created successfully"
+// @Failure 400 {object} helper.Response "Invalid request data"
+// @Failure 401 {object} helper.Response "Unauthorized"
+// @Failure 403 {object} helper.Response "Admin access required"
+// @Router /admin/webhooks [post]
+func (w *WebhookHandler) CreateEndpoint(ctx *gin.Context) {
+	var payload *dto.CreateWebhookEndpointRequest
+	if err := ctx.ShouldBindJSON(&payload); err != nil {
+		helper.BadRequestResponse(ctx, "invalid payload given", err)
+		return
+	}
+
+	endpoint, err := w.webhookService.CreateEndpoint(ctx, payload)
+	if err != nil {
+		webhookError(ctx, "error while creating webhook endpoint", "webhook endpoint not found", err)
+		return
+	}
+
+	helper.CreatedResponse(ctx, "webhook endpoint successfully created", endpoint)
+}
+
+// GetEndpoints docs
+// @Summary Get webhook endpoints
+// @Description Retrieve every webhook endpoint (Admin only)
+// @Tags Webhooks
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {object} helper.Response{data=[]dto.WebhookEndpointResponse} "Webhook endpoints retrieved successfully"
+// @Failure 401 {object} helper.Response "Unauthorized"
+// @Failure 403 {object} helper.Response "Admin access required"
+// @Router /admin/webhooks [get]
+func (w *WebhookHandler) GetEndpoints(ctx *gin.Context) {
+	endpoints, err := w.webhookService.GetEndpoints(ctx)
+	if err != nil {
+		helper.InternalServerError(ctx, "error while getting webhook endpoints", err)
+		return
+	}
+
+	helper.SuccessResponse(ctx, "webhook endpoints successfully retrieved", endpoints)
+}
+
+// GetEndpoint docs
+// @Summary Get webhook endpoint by ID
+// @Description Retrieve a webhook endpoint (Admin only)
+// @Tags Webhooks
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "Webhook endpoint ID"
+// @Success 200 {object} helper.Response{data=dto.WebhookEndpointResponse} "Webhook endpoint retrieved successfully"
+// @Failure 400 {object} helper.Response "Invalid webhook endpoint ID"
+// @Failure 401 {object} helper.Response "Unauthorized"
+// @Failure 403 {object} helper.Response "Admin access required"
+// @Failure 404 {object} helper.Response "Webhook endpoint not found"
+// @Router /admin/webhooks/{id} [get]
+func (w *WebhookHandler) GetEndpoint(ctx *gin.Context) {
+	id, err := strconv.ParseUint(ctx.Param("id"), 10, 32)
+	if err != nil {
+		helper.BadRequestResponse(ctx, "invalid webhook endpoint id", err)
+		return
+	}
+
+	endpoint, err := w.webhookService.GetEndpoint(ctx, uint(id))
+	if err != nil {
+		webhookError(ctx, "error while getting webhook endpoint", "webhook endpoint not found", err)
+		return
+	}
+
+	helper.SuccessResponse(ctx, "webhook endpoint successfully retrieved", endpoint)
+}
+
+// UpdateEndpoint docs
+// @Summary Update a webhook endpoint
+// @Description Change the URL, description or subscribed event types of a webhook endpoint, or disable it (Admin only)
+// @Tags Webhooks
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "Webhook endpoint ID"
+// @Param request body dto.UpdateWebhookEndpointRequest true "Webhook endpoint"
+// @Success 200 {object} helper.Response{data=dto.WebhookEndpointResponse} "Webhook endpoint updated successfully"
+// @Failure 400 {object} helper.Response "Invalid request data"
+// @Failure 401 {object} helper.Response "Unauthorized"
+// @Failure 403 {object} helper.Response "Admin access required"
+// @Failure 404 {object} helper.Response "Webhook endpoint not found"
+// @Router /admin/webhooks/{id} [put]
+func (w *WebhookHandler) UpdateEndpoint(ctx *gin.Context) {
+	id, err := strconv.ParseUint(ctx.Param("id"), 10, 32)
+	if err != nil {
+		helper.BadRequestResponse(ctx, "invalid webhook endpoint id", err)
+		return
+	}
+
+	var payload *dto.UpdateWebhookEndpointRequest
+	if err := ctx.ShouldBindJSON(&payload); err != nil {
+		helper.BadRequestResponse(ctx, "invalid payload given", err)
+		return
+	}
+
+	endpoint, err := w.webhookService.UpdateEndpoint(ctx, uint(id), payload)
+	if err != nil {
+		webhookError(ctx, "error while updating webhook endpoint", "webhook endpoint not found", err)
+		return
+	}
+
+	helper.SuccessResponse(ctx, "webhook endpoint successfully updated", endpoint)
+}
+
+// DeleteEndpoint docs
+// @Summary Delete a webhook endpoint
+// @Description Delete a webhook endpoint together with its delivery log (Admin only)
+// @Tags Webhooks
+// @Security BearerAuth
+// @Param id path int true "Webhook endpoint ID"
+// @Success 200 {object} helper.Response "Webhook endpoint deleted successfully"
+// @Failure 400 {object} helper.Response "Invalid webhook endpoint ID"
+// @Failure 401 {object} helper.Response "Unauthorized"
+// @Failure 403 {object} helper.Response "Admin access required"
+// @Failure 404 {object} helper.Response "Webhook endpoint not found"
+// @Router /admin/webhooks/{id} [delete]
+func (w *WebhookHandler) DeleteEndpoint(ctx *gin.Context) {
+	id, err := strconv.ParseUint(ctx.Param("id"), 10, 32)
+	if err != nil {
+		helper.BadRequestResponse(ctx, "invalid webhook endpoint id", err)
+		return
+	}
+
+	if err := w.webhookService.DeleteEndpoint(ctx, uint(id)); err != nil {
+		webhookError(ctx, "error while deleting webhook endpoint", "webhook endpoint not found", err)
+		return
+	}
+
+	helper.SuccessResponse(ctx, "webhook endpoint successfully deleted", nil)
+}
+
+// GetDeliveries docs
+// @Summary Get webhook deliveries
+// @Description Retrieve the paginated delivery log of a webhook endpoint, newest first (Admin only)
+// @Tags Webhooks
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "Webhook endpoint ID"
+// @Param page query int false "Page number" default(1)
+// @Param limit query int false "Items per page" default(20)
+// @Success 200 {object} helper.PaginatedResponse{data=[]dto.WebhookDeliveryResponse} "Webhook deliveries retrieved successfully"
+// @Failure 400 {object} helper.Response "Invalid webhook endpoint ID"
+// @Failure 401 {object} helper.Response "Unauthorized"
+// @Failure 403 {object} helper.Response "Admin access required"
+// @Failure 404 {object} helper.Response "Webhook endpoint not found"
+// @Router /admin/webhooks/{id}/deliveries [get]
+func (w *WebhookHandler) GetDeliveries(ctx *gin.Context) {
+	id, err := strconv.ParseUint(ctx.Param("id"), 10, 32)
+	if err != nil {
+		helper.BadRequestResponse(ctx, "invalid webhook endpoint id", err)
+		return
+	}
+
+	page, _ := strconv.Atoi(ctx.DefaultQuery("page", "1"))
+	limit, _ := strconv.Atoi(ctx.DefaultQuery("limit", "20"))
+
+	deliveries, meta, err := w.webhookService.GetDeliveries(ctx, uint(id), page, limit)
+	if err != nil {
+		webhookError(ctx, "error while getting webhook deliveries", "webhook endpoint not found", err)
+		return
+	}
+
+	helper.PaginatedSuccessResponse(ctx, "webhook deliveries successfully retrieved", deliveries, *meta)
+}
+
+// GetDelivery docs
+// @Summary Get webhook delivery by ID
+// @Description Retrieve a webhook delivery with its payload and every attempt made (Admin only)
+// @Tags Webhooks
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "Webhook delivery ID"
+// @Success 200 {object} helper.Response{data=dto.WebhookDeliveryResponse} "Webhook delivery retrieved successfully"
+// @Failure 400 {object} helper.Response "Invalid webhook delivery ID"
+// @Failure 401 {object} helper.Response "Unauthorized"
+// @Failure 403 {object} helper.Response "Admin access required"
+// @Failure 404 {object} helper.Response "Webhook delivery not found"
+// @Router /admin/webhook-deliveries/{id} [get]
+func (w *WebhookHandler) GetDelivery(ctx *gin.Context) {
+	id, err := strconv.ParseUint(ctx.Param("id"), 10, 32)
+	if err != nil {
+		helper.BadRequestResponse(ctx, "invalid webhook delivery id", err)
+		return
+	}
+
+	delivery, err := w.webhookService.GetDelivery(ctx, uint(id))
+	if err != nil {
+		webhookError(ctx, "error while getting webhook delivery", "webhook delivery not found", err)
+		return
+	}
+
+	helper.SuccessResponse(ctx, "webhook delivery successfully retrieved", delivery)
+}
+
+// Redeliver docs
+// @Summary Redeliver a webhook
+// @Description Queue a webhook delivery again with a fresh retry budget (Admin only)
+// @Tags Webhooks
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "Webhook delivery ID"
+// @Success 200 {object} helper.Response{data=dto.WebhookDeliveryResponse} "Webhook delivery queued successfully"
+// @Failure 400 {object} helper.Response "Invalid webhook delivery ID"
+// @Failure 401 {object} helper.Response "Unauthorized"
+// @Failure 403 {object} helper.Response "Admin access required"
+// @Failure 404 {object} helper.Response "Webhook delivery not found"
+// @Failure 409 {object} helper.Response "Webhook endpoint is disabled"
+// @Router /admin/webhook-deliveries/{id}/redeliver [post]
+func (w *WebhookHandler) Redeliver(ctx *gin.Context) {
+	id, err := strconv.ParseUint(ctx.Param("id"), 10, 32)
+	if err != nil {
+		helper.BadRequestResponse(ctx, "invalid webhook delivery id", err)
+		return
+	}
+
+	delivery, err := w.webhookService.Redeliver(ctx, uint(id))
+	if err != nil {
+		webhookError(ctx, "error while redelivering webhook", "webhook delivery not found", err)
+		return
+	}
+
+	helper.SuccessResponse(ctx, "webhook delivery successfully queued", delivery)
+}
+
+func webhookError(ctx *gin.Context, message, notFound string, err error) {
+	switch {
+	case errors.Is(err, service.ErrInvalidWebhookEndpoint), errors.Is(err, service.ErrWebhookAddressNotAllowed):
+		helper.BadRequestResponse(ctx, message, err)
+	case errors.Is(err, service.ErrWebhookEndpointDisabled):
+		helper.ErrorResponse(ctx, http.StatusConflict, message, err)
+	case errors.Is(err, repository.ErrNotFound):
+		helper.NotFoundResponse(ctx, notFound)
+	default:
+		helper.InternalServerError(ctx, message, err)
+	}
+}
+
+func NewWebhookHandler(webhookService service.WebhookService) *WebhookHandler {
+	return &WebhookHandler{
+		webhookService: webhookService,
+	}
+}
@@ -2,17 +2,23 @@ package middlewares
 
 import (
 	"context"
+	"errors"
+	"slices"
 	"strings"
 
+	"github.com/99designs/gqlgen/graphql/handler/transport"
 	"github.com/gin-gonic/gin"
 	"github.com/saleh-ghazimoradi/Cartopher/config"
+	"github.com/saleh-ghazimoradi/Cartopher/infra/cache"
 	"github.com/saleh-ghazimoradi/Cartopher/internal/domain"
 	"github.com/saleh-ghazimoradi/Cartopher/internal/helper"
 	"github.com/saleh-ghazimoradi/Cartopher/utils"
 )
 
 type Authentication struct {
-	config *config.Config
+	config        *config.Config
+	keys          *utils.KeySet
+	tokenVersions cache.TokenVersions
 }
 
 func (a *Authentication) Authenticate() gin.HandlerFunc {
@@ -31,31 +37,49 @@ func (a *Authentication) Authenticate() gin.HandlerFunc {
 			return
 		}
 
-		claims, err := utils.ValidateToken(tokenParts[1], a.config.JWT.Secret)
+		claims, err := utils.ValidateToken(tokenParts[1], a.keys, utils.TokenTypeAccess)
 		if err != nil {
 			helper.UnauthorizedResponse(ctx, "invalid token")
 			ctx.Abort()
 			return
 		}
 
+		revoked, err := a.revoked(ctx.Request.Context(), claims)
+		if err != nil {
+			helper.InternalServerError(ctx, "Failed to validate token", err)
+			ctx.Abort()
+			return
+		}
+
+		if revoked {
+			helper.UnauthorizedResponse(ctx, "token has been revoked")
+			ctx.Abort()
+			return
+		}
+
+		permissions, twoFactorRequired := a.permissions(claims)
+
 		ctx.Set("user_id", claims.UserId)
 		ctx.Set("user_email", claims.Email)
 		ctx.Set("user_role", claims.Role)
+		ctx.Set("user_permissions", permissions)
+		ctx.Set("two_factor_required", twoFactorRequired)
 
 		ctx.Next()
 	}
 }
 
-func (a *Authentication) AdminMiddleware() gin.HandlerFunc {
+// RequirePermission only lets requests through whose token grants
+// permission. It runs after Authenticate.
+func (a *Authentication) RequirePermission(permission domain.Permission) gin.HandlerFunc {
 	return func(ctx *gin.Context) {
-		role, exists := ctx.Get("user_role")
-		if !exists {
-			helper.ForbiddenResponse(ctx, "You are not authorized to access this resource")
+		if ctx.GetBool("two_factor_required") {
+			helper.ForbiddenResponse(ctx, "Two-factor authentication is required for staff access")
 			ctx.Abort()
 			return
 		}
 
-		if role != string(domain.UserRoleAdmin) {
+		if !slices.Contains(ctx.GetStringSlice("user_permissions"), string(permission)) {
 			helper.ForbiddenResponse(ctx, "You are not authorized to access this resource")
 			ctx.Abort()
 			return
@@ -69,18 +93,78 @@ func (a *Authentication) GraphqlMiddleware() gin.HandlerFunc {
 		userId, _ := c.Get("user_id")
 		userEmail, _ := c.Get("user_email")
 		userRole, _ := c.Get("user_role")
+		userPermissions, _ := c.Get("user_permissions")
 
 		ctx := context.WithValue(c.Request.Context(), utils.UserIdKey, userId)
 		ctx = context.WithValue(ctx, utils.UserEmailKey, userEmail)
 		ctx = context.WithValue(ctx, utils.UserRoleKey, userRole)
+		ctx = context.WithValue(ctx, utils.UserPermissionsKey, userPermissions)
 		ctx = context.WithValue(ctx, utils.GinContextKey, c)
 		c.Request = c.Request.WithContext(ctx)
 		c.Next()
 	}
 }
 
-func NewAuthentication(config *config.Config) *Authentication {
+// WebsocketInit authenticates a GraphQL websocket connection. Browsers
+// cannot set headers on the upgrade request, so the access token is taken
+// from the Authorization field of the connection_init payload instead, with
+// or without the Bearer prefix.
+func (a *Authentication) WebsocketInit(ctx context.Context, initPayload transport.InitPayload) (context.Context, *transport.InitPayload, error) {
+	token := strings.TrimPrefix(initPayload.Authorization(), "Bearer ")
+	if token == "" {
+		return nil, nil, errors.New("authorization is required")
+	}
+
+	claims, err := utils.ValidateToken(token, a.keys, utils.TokenTypeAccess)
+	if err != nil {
+		return nil, nil, errors.New("invalid token")
+	}
+
+	revoked, err := a.revoked(ctx, claims)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if revoked {
+		return nil, nil, errors.New("token has been revoked")
+	}
+
+	permissions, _ := a.permissions(claims)
+
+	ctx = context.WithValue(ctx, utils.UserIdKey, claims.UserId)
+	ctx = context.WithValue(ctx, utils.UserEmailKey, claims.Email)
+	ctx = context.WithValue(ctx, utils.UserRoleKey, claims.Role)
+	ctx = context.WithValue(ctx, utils.UserPermissionsKey, permissions)
+	return ctx, &initPayload, nil
+}
+
+// permissions returns the permissions a token grants. When staff are
+// required to use two-factor authentication, a staff token issued without
+// it grants none, which is still enough to enrol.
+func (a *Authentication) permissions(claims *utils.Claims) (permissions []string, twoFactorRequired bool) {
+	if a.config.Auth.RequireAdminTwoFactor && len(claims.Permissions) > 0 && !claims.TwoFactor {
+		return nil, true
+	}
+	return claims.Permissions, false
+}
+
+// revoked reports whether the token was issued before the token version of
+// its user last changed, e.g. on logout, a password change or deactivation.
+// The versions must match exactly, so a version lost in Redis revokes
+// tokens rather than reviving them.
+func (a *Authentication) revoked(ctx context.Context, claims *utils.Claims) (bool, error) {
+	version, err := a.tokenVersions.Get(ctx, claims.UserId)
+	if err != nil {
+		return false, err
+	}
+
+	return claims.Version != version, nil
+}
+
+func NewAuthentication(config *config.Config, keys *utils.KeySet, tokenVersions cache.TokenVersions) *Authentication {
 	return &Authentication{
-		config: config,
+		config:        config,
+		keys:          keys,
+		tokenVersions: tokenVersions,
 	}
 }
@@ -2,13 +2,22 @@ package middlewares
 
 import (
 	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
 	"github.com/saleh-ghazimoradi/Cartopher/config"
+	"github.com/saleh-ghazimoradi/Cartopher/infra/events"
 	"github.com/saleh-ghazimoradi/Cartopher/internal/helper"
+	"github.com/saleh-ghazimoradi/Cartopher/utils"
 	"net/http"
 	"strconv"
 	"time"
 )
 
+const (
+	requestIdHeader    = "X-Request-ID"
+	maxRequestIdLength = 128
+	maxUserAgentLength = 512
+)
+
 type Middlewares struct {
 	cfg         *config.Config
 	rateLimiter helper.RateLimiter
@@ -18,7 +27,7 @@ func (m *Middlewares) CorsMiddleware() gin.HandlerFunc {
 	return func(ctx *gin.Context) {
 		ctx.Header("Access-Control-Allow-Origin", "*")
 		ctx.Header("Access-Control-Allow-Methods", "POST, GET, OPTIONS, PUT, DELETE")
-		ctx.Header("Access-control-Allow-Headers", "Content-Type, Accept, Authorization")
+		ctx.Header("Access-control-Allow-Headers", "Content-Type, Accept, Authorization, X-Request-ID")
 		if ctx.Request.Method == "OPTIONS" {
 			ctx.AbortWithStatus(204)
 			return
@@ -27,6 +36,39 @@ func (m *Middlewares) CorsMiddleware() gin.HandlerFunc {
 	}
 }
 
+// RequestIdMiddleware takes the request id from the X-Request-ID header or
+// generates one, echoes it back and puts it on the request context, so events
+// raised while handling the request carry it as their correlation id.
+func (m *Middlewares) RequestIdMiddleware() gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		requestId := ctx.GetHeader(requestIdHeader)
+		if requestId == "" || len(requestId) > maxRequestIdLength {
+			requestId = uuid.NewString()
+		}
+
+		ctx.Header(requestIdHeader, requestId)
+		ctx.Request = ctx.Request.WithContext(events.WithCorrelationId(ctx.Request.Context(), requestId))
+		ctx.Next()
+	}
+}
+
+// ClientMiddleware puts the user agent and IP address of the request on its
+// context, so sessions record the device they were started from.
+func (m *Middlewares) ClientMiddleware() gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		userAgent := ctx.Request.UserAgent()
+		if len(userAgent) > maxUserAgentLength {
+			userAgent = userAgent[:maxUserAgentLength]
+		}
+
+		ctx.Request = ctx.Request.WithContext(utils.WithClient(ctx.Request.Context(), utils.Client{
+			UserAgent: userAgent,
+			IPAddress: ctx.ClientIP(),
+		}))
+		ctx.Next()
+	}
+}
+
 func (m *Middlewares) RateLimitMiddleware() gin.HandlerFunc {
 	return func(ctx *gin.Context) {
 		var key string
@@ -0,0 +1,35 @@
+package routes
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/saleh-ghazimoradi/Cartopher/internal/domain"
+	"github.com/saleh-ghazimoradi/Cartopher/internal/gateway/handlers"
+	"github.com/saleh-ghazimoradi/Cartopher/internal/gateway/middlewares"
+)
+
+type AdminUserRoutes struct {
+	adminUserHandler *handlers.AdminUserHandler
+	authMiddleware   *middlewares.Authentication
+}
+
+func (a *AdminUserRoutes) AdminUserRoute(router *gin.Engine) {
+	v1 := router.Group("/v1")
+	users := v1.Group("/admin/users")
+	users.Use(a.authMiddleware.Authenticate(), a.authMiddleware.RequirePermission(domain.PermissionUsersManage))
+	users.GET("/", a.adminUserHandler.GetUsers)
+	users.GET("/:id", a.adminUserHandler.GetUser)
+	users.GET("/:id/audit-log", a.adminUserHandler.GetAuditLog)
+	users.PUT("/:id/deactivate", a.adminUserHandler.DeactivateUser)
+	users.PUT("/:id/reactivate", a.adminUserHandler.ReactivateUser)
+	users.PUT("/:id/role", a.adminUserHandler.ChangeRole)
+	users.DELETE("/:id", a.adminUserHandler.DeleteUser)
+	users.DELETE("/:id/sessions", a.adminUserHandler.RevokeSessions)
+	users.POST("/:id/unlock", a.adminUserHandler.UnlockUser)
+}
+
+func NewAdminUserRoutes(adminUserHandler *handlers.AdminUserHandler, authMiddleware *middlewares.Authentication) *AdminUserRoutes {
+	return &AdminUserRoutes{
+		adminUserHandler: adminUserHandler,
+		authMiddleware:   authMiddleware,
+	}
+}
@@ -3,21 +3,49 @@ package routes
 import (
 	"github.com/gin-gonic/gin"
 	"github.com/saleh-ghazimoradi/Cartopher/internal/gateway/handlers"
+	"github.com/saleh-ghazimoradi/Cartopher/internal/gateway/middlewares"
 )
 
 type AuthRoutes struct {
-	authHandler *handlers.AuthHandler
+	authHandler    *handlers.AuthHandler
+	authMiddleware *middlewares.Authentication
 }
 
 func (a *AuthRoutes) AuthRoute(router *gin.Engine) {
+	router.GET("/.well-known/jwks.json", a.authHandler.JWKS)
+
 	v1 := router.Group("/v1")
 	auth := v1.Group("/auth")
 	auth.POST("/register", a.authHandler.Register)
 	auth.POST("/login", a.authHandler.Login)
 	auth.POST("/refresh", a.authHandler.RefreshToken)
 	auth.POST("/logout", a.authHandler.Logout)
+	auth.POST("/verify-email", a.authHandler.VerifyEmail)
+	auth.POST("/resend-verification", a.authHandler.ResendVerification)
+	auth.POST("/forgot-password", a.authHandler.ForgotPassword)
+	auth.POST("/reset-password", a.authHandler.ResetPassword)
+
+	oidc := auth.Group("/oidc")
+	oidc.GET("/providers", a.authHandler.GetOIDCProviders)
+	oidc.GET("/:provider/authorize", a.authHandler.AuthorizeOIDC)
+	oidc.POST("/:provider/callback", a.authHandler.LoginWithOIDC)
+
+	twoFactor := auth.Group("/2fa")
+	twoFactor.POST("/verify", a.authHandler.VerifyTwoFactor)
+	twoFactor.POST("/enrol", a.authMiddleware.Authenticate(), a.authHandler.EnrolTwoFactor)
+	twoFactor.POST("/confirm", a.authMiddleware.Authenticate(), a.authHandler.ConfirmTwoFactor)
+	twoFactor.POST("/disable", a.authMiddleware.Authenticate(), a.authHandler.DisableTwoFactor)
+
+	sessions := auth.Group("/sessions")
+	sessions.Use(a.authMiddleware.Authenticate())
+	sessions.GET("/", a.authHandler.GetSessions)
+	sessions.DELETE("/", a.authHandler.RevokeAllSessions)
+	sessions.DELETE("/:id", a.authHandler.RevokeSession)
 }
 
-func NewAuthRoutes(authHandler *handlers.AuthHandler) *AuthRoutes {
-	return &AuthRoutes{authHandler: authHandler}
+func NewAuthRoutes(authHandler *handlers.AuthHandler, authMiddleware *middlewares.Authentication) *AuthRoutes {
+	return &AuthRoutes{
+		authHandler:    authHandler,
+		authMiddleware: authMiddleware,
+	}
 }
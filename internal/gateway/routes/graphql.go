@@ -25,6 +25,9 @@ func (g *GraphQLRoutes) GraphQLRoute(router *gin.Engine) {
 	graphqlProtected.Use(g.authMiddleware.GraphqlMiddleware())
 	graphqlProtected.POST("/", g.graphqlHandler.GraphqlHandler())
 
+	// Subscriptions authenticate on websocket connection init.
+	router.GET("/graphql/", g.graphqlHandler.SubscriptionHandler())
+
 }
 
 func NewGraphQLRoutes(handler *handlers.GraphQLHandler, authMiddleware *middlewares.Authentication) *GraphQLRoutes {
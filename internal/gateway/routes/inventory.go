@@ -0,0 +1,41 @@
+package routes
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/saleh-ghazimoradi/Cartopher/internal/domain"
+	"github.com/saleh-ghazimoradi/Cartopher/internal/gateway/handlers"
+	"github.com/saleh-ghazimoradi/Cartopher/internal/gateway/middlewares"
+)
+
+type InventoryRoutes struct {
+	inventoryHandler *handlers.InventoryHandler
+	authMiddleware   *middlewares.Authentication
+}
+
+func (i *InventoryRoutes) InventoryRoute(router *gin.Engine) {
+	v1 := router.Group("/v1")
+
+	protected := v1.Group("/")
+	protected.Use(i.authMiddleware.Authenticate())
+	protected.Use(i.authMiddleware.RequirePermission(domain.PermissionInventoryManage))
+
+	warehouses := protected.Group("/warehouses")
+	warehouses.GET("/", i.inventoryHandler.GetWarehouses)
+	warehouses.POST("/", i.inventoryHandler.CreateWarehouse)
+	warehouses.PUT("/:id", i.inventoryHandler.UpdateWarehouse)
+	warehouses.DELETE("/:id", i.inventoryHandler.DeleteWarehouse)
+
+	products := protected.Group("/products")
+	products.GET("/:id/stock", i.inventoryHandler.GetProductStock)
+	products.PUT("/:id/stock", i.inventoryHandler.SetStockLevel)
+
+	reports := protected.Group("/reports")
+	reports.GET("/low-stock", i.inventoryHandler.GetLowStockProducts)
+}
+
+func NewInventoryRoutes(inventoryHandler *handlers.InventoryHandler, authMiddleware *middlewares.Authentication) *InventoryRoutes {
+	return &InventoryRoutes{
+		inventoryHandler: inventoryHandler,
+		authMiddleware:   authMiddleware,
+	}
+}
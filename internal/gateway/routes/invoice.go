@@ -0,0 +1,31 @@
+package routes
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/saleh-ghazimoradi/Cartopher/internal/domain"
+	"github.com/saleh-ghazimoradi/Cartopher/internal/gateway/handlers"
+	"github.com/saleh-ghazimoradi/Cartopher/internal/gateway/middlewares"
+)
+
+type InvoiceRoutes struct {
+	invoiceHandler *handlers.InvoiceHandler
+	authMiddleware *middlewares.Authentication
+}
+
+func (i *InvoiceRoutes) InvoiceRoute(router *gin.Engine) {
+	v1 := router.Group("/v1")
+	protected := v1.Group("/")
+	protected.Use(i.authMiddleware.Authenticate())
+
+	orders := protected.Group("/orders")
+	orders.GET("/:id/invoice", i.invoiceHandler.GetInvoice)
+	orders.POST("/:id/invoice/regenerate", i.authMiddleware.RequirePermission(domain.PermissionOrdersManage), i.invoiceHandler.RegenerateInvoice)
+	orders.GET("/:id/packing-slip", i.authMiddleware.RequirePermission(domain.PermissionOrdersManage), i.invoiceHandler.GetPackingSlip)
+}
+
+func NewInvoiceRoutes(invoiceHandler *handlers.InvoiceHandler, authMiddleware *middlewares.Authentication) *InvoiceRoutes {
+	return &InvoiceRoutes{
+		invoiceHandler: invoiceHandler,
+		authMiddleware: authMiddleware,
+	}
+}
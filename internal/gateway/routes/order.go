@@ -2,6 +2,7 @@ package routes
 
 import (
 	"github.com/gin-gonic/gin"
+	"github.com/saleh-ghazimoradi/Cartopher/internal/domain"
 	"github.com/saleh-ghazimoradi/Cartopher/internal/gateway/handlers"
 	"github.com/saleh-ghazimoradi/Cartopher/internal/gateway/middlewares"
 )
@@ -19,6 +20,9 @@ func (o *OrderRoutes) OrderRoute(router *gin.Engine) {
 	orders.POST("/", o.orderHandler.CreateOrder)
 	orders.GET("/", o.orderHandler.GetOrders)
 	orders.GET("/:id", o.orderHandler.GetOrder)
+	orders.GET("/:id/events", o.orderHandler.OrderEvents)
+	orders.PUT("/:id/cancel", o.orderHandler.CancelOrder)
+	orders.PUT("/:id/confirm", o.authMiddleware.RequirePermission(domain.PermissionOrdersManage), o.orderHandler.ConfirmOrder)
 }
 
 func NewOrderRoutes(orderHandler *handlers.OrderHandler, authMiddleware *middlewares.Authentication) *OrderRoutes {
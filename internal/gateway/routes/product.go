@@ -2,6 +2,7 @@ package routes
 
 import (
 	"github.com/gin-gonic/gin"
+	"github.com/saleh-ghazimoradi/Cartopher/internal/domain"
 	"github.com/saleh-ghazimoradi/Cartopher/internal/gateway/handlers"
 	"github.com/saleh-ghazimoradi/Cartopher/internal/gateway/middlewares"
 )
@@ -26,16 +27,16 @@ func (p *ProductRoutes) ProductRoute(router *gin.Engine) {
 
 	// Category admin routes
 	categories := protected.Group("/categories")
-	categories.POST("/", p.authMiddleware.AdminMiddleware(), p.productHandler.CreateCategory)
-	categories.PUT("/:id", p.authMiddleware.AdminMiddleware(), p.productHandler.UpdateCategory)
-	categories.DELETE("/:id", p.authMiddleware.AdminMiddleware(), p.productHandler.DeleteCategory)
+	categories.POST("/", p.authMiddleware.RequirePermission(domain.PermissionCatalogWrite), p.productHandler.CreateCategory)
+	categories.PUT("/:id", p.authMiddleware.RequirePermission(domain.PermissionCatalogWrite), p.productHandler.UpdateCategory)
+	categories.DELETE("/:id", p.authMiddleware.RequirePermission(domain.PermissionCatalogWrite), p.productHandler.DeleteCategory)
 
 	// Product admin routes
 	products := protected.Group("/products")
-	products.POST("/", p.authMiddleware.AdminMiddleware(), p.productHandler.CreateProduct)
-	products.PUT("/:id", p.authMiddleware.AdminMiddleware(), p.productHandler.UpdateProduct)
-	products.DELETE("/:id", p.authMiddleware.AdminMiddleware(), p.productHandler.DeleteProduct)
-	products.POST("/:id/images", p.authMiddleware.AdminMiddleware(), p.productHandler.UploadProductImage)
+	products.POST("/", p.authMiddleware.RequirePermission(domain.PermissionCatalogWrite), p.productHandler.CreateProduct)
+	products.PUT("/:id", p.authMiddleware.RequirePermission(domain.PermissionCatalogWrite), p.productHandler.UpdateProduct)
+	products.DELETE("/:id", p.authMiddleware.RequirePermission(domain.PermissionCatalogWrite), p.productHandler.DeleteProduct)
+	products.POST("/:id/images", p.authMiddleware.RequirePermission(domain.PermissionCatalogWrite), p.productHandler.UploadProductImage)
 
 }
 
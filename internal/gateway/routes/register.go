@@ -9,14 +9,22 @@ import (
 )
 
 type Register struct {
-	middlewares  *middlewares.Middlewares
-	healthRoute  *HealthRoutes
-	authRoute    *AuthRoutes
-	userRoute    *UserRoutes
-	productRoute *ProductRoutes
-	cartRoute    *CartRoutes
-	orderRoute   *OrderRoutes
-	graphqlRoute *GraphQLRoutes
+	middlewares    *middlewares.Middlewares
+	healthRoute    *HealthRoutes
+	authRoute      *AuthRoutes
+	userRoute      *UserRoutes
+	productRoute   *ProductRoutes
+	cartRoute      *CartRoutes
+	orderRoute     *OrderRoutes
+	inventoryRoute *InventoryRoutes
+	shipmentRoute  *ShipmentRoutes
+	returnRoute    *ReturnRoutes
+	invoiceRoute   *InvoiceRoutes
+	templateRoute  *TemplateRoutes
+	webhookRoute   *WebhookRoutes
+	roleRoute      *RoleRoutes
+	adminUserRoute *AdminUserRoutes
+	graphqlRoute   *GraphQLRoutes
 }
 
 type Options func(*Register)
@@ -57,6 +65,54 @@ func WithOrderRoute(orderRoute *OrderRoutes) Options {
 	}
 }
 
+func WithInventoryRoute(inventoryRoute *InventoryRoutes) Options {
+	return func(r *Register) {
+		r.inventoryRoute = inventoryRoute
+	}
+}
+
+func WithShipmentRoute(shipmentRoute *ShipmentRoutes) Options {
+	return func(r *Register) {
+		r.shipmentRoute = shipmentRoute
+	}
+}
+
+func WithReturnRoute(returnRoute *ReturnRoutes) Options {
+	return func(r *Register) {
+		r.returnRoute = returnRoute
+	}
+}
+
+func WithInvoiceRoute(invoiceRoute *InvoiceRoutes) Options {
+	return func(r *Register) {
+		r.invoiceRoute = invoiceRoute
+	}
+}
+
+func WithTemplateRoute(templateRoute *TemplateRoutes) Options {
+	return func(r *Register) {
+		r.templateRoute = templateRoute
+	}
+}
+
+func WithWebhookRoute(webhookRoute *WebhookRoutes) Options {
+	return func(r *Register) {
+		r.webhookRoute = webhookRoute
+	}
+}
+
+func WithRoleRoute(roleRoute *RoleRoutes) Options {
+	return func(r *Register) {
+		r.roleRoute = roleRoute
+	}
+}
+
+func WithAdminUserRoute(adminUserRoute *AdminUserRoutes) Options {
+	return func(r *Register) {
+		r.adminUserRoute = adminUserRoute
+	}
+}
+
 func WithMiddlewares(middlewares *middlewares.Middlewares) Options {
 	return func(r *Register) {
 		r.middlewares = middlewares
@@ -71,9 +127,14 @@ func WithGraphqlRoute(graphqlRoute *GraphQLRoutes) Options {
 
 func (r *Register) RegisterRoutes() *gin.Engine {
 	router := gin.New()
+	// Handlers pass the gin context to services; let it fall back to the
+	// request context so values such as the request id reach them.
+	router.ContextWithFallback = true
 
 	router.Use(gin.Logger())
 	router.Use(gin.Recovery())
+	router.Use(r.middlewares.RequestIdMiddleware())
+	router.Use(r.middlewares.ClientMiddleware())
 	router.Use(r.middlewares.CorsMiddleware())
 	router.Use(r.middlewares.RateLimitMiddleware())
 
@@ -89,6 +150,14 @@ func (r *Register) RegisterRoutes() *gin.Engine {
 	r.productRoute.ProductRoute(router)
 	r.cartRoute.cartRoute(router)
 	r.orderRoute.OrderRoute(router)
+	r.inventoryRoute.InventoryRoute(router)
+	r.shipmentRoute.ShipmentRoute(router)
+	r.returnRoute.ReturnRoute(router)
+	r.invoiceRoute.InvoiceRoute(router)
+	r.templateRoute.TemplateRoute(router)
+	r.webhookRoute.WebhookRoute(router)
+	r.roleRoute.RoleRoute(router)
+	r.adminUserRoute.AdminUserRoute(router)
 	r.graphqlRoute.GraphQLRoute(router)
 	return router
 }
@@ -0,0 +1,42 @@
+package routes
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/saleh-ghazimoradi/Cartopher/internal/domain"
+	"github.com/saleh-ghazimoradi/Cartopher/internal/gateway/handlers"
+	"github.com/saleh-ghazimoradi/Cartopher/internal/gateway/middlewares"
+)
+
+type ReturnRoutes struct {
+	returnHandler  *handlers.ReturnHandler
+	authMiddleware *middlewares.Authentication
+}
+
+func (r *ReturnRoutes) ReturnRoute(router *gin.Engine) {
+	v1 := router.Group("/v1")
+	protected := v1.Group("/")
+	protected.Use(r.authMiddleware.Authenticate())
+
+	orders := protected.Group("/orders")
+	orders.POST("/:id/returns", r.returnHandler.CreateReturn)
+
+	returns := protected.Group("/returns")
+	returns.GET("/", r.returnHandler.GetReturns)
+	returns.GET("/:id", r.returnHandler.GetReturn)
+
+	// Return admin routes
+	admin := protected.Group("/admin/returns")
+	admin.Use(r.authMiddleware.RequirePermission(domain.PermissionReturnsManage))
+	admin.GET("/", r.returnHandler.GetReturnsByStatus)
+	admin.PUT("/:id/approve", r.returnHandler.ApproveReturn)
+	admin.PUT("/:id/reject", r.returnHandler.RejectReturn)
+	admin.PUT("/:id/receive", r.returnHandler.ReceiveReturn)
+	admin.PUT("/:id/refund", r.authMiddleware.RequirePermission(domain.PermissionOrdersRefund), r.returnHandler.RefundReturn)
+}
+
+func NewReturnRoutes(returnHandler *handlers.ReturnHandler, authMiddleware *middlewares.Authentication) *ReturnRoutes {
+	return &ReturnRoutes{
+		returnHandler:  returnHandler,
+		authMiddleware: authMiddleware,
+	}
+}
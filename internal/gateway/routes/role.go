@@ -0,0 +1,32 @@
+package routes
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/saleh-ghazimoradi/Cartopher/internal/domain"
+	"github.com/saleh-ghazimoradi/Cartopher/internal/gateway/handlers"
+	"github.com/saleh-ghazimoradi/Cartopher/internal/gateway/middlewares"
+)
+
+type RoleRoutes struct {
+	roleHandler    *handlers.RoleHandler
+	authMiddleware *middlewares.Authentication
+}
+
+func (r *RoleRoutes) RoleRoute(router *gin.Engine) {
+	v1 := router.Group("/v1")
+	roles := v1.Group("/admin/roles")
+	roles.Use(r.authMiddleware.Authenticate(), r.authMiddleware.RequirePermission(domain.PermissionRolesManage))
+	roles.GET("/", r.roleHandler.GetRoles)
+	roles.GET("/permissions", r.roleHandler.GetPermissions)
+	roles.POST("/", r.roleHandler.CreateRole)
+	roles.GET("/:name", r.roleHandler.GetRole)
+	roles.PUT("/:name", r.roleHandler.UpdateRole)
+	roles.DELETE("/:name", r.roleHandler.DeleteRole)
+}
+
+func NewRoleRoutes(roleHandler *handlers.RoleHandler, authMiddleware *middlewares.Authentication) *RoleRoutes {
+	return &RoleRoutes{
+		roleHandler:    roleHandler,
+		authMiddleware: authMiddleware,
+	}
+}
@@ -0,0 +1,35 @@
+package routes
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/saleh-ghazimoradi/Cartopher/internal/domain"
+	"github.com/saleh-ghazimoradi/Cartopher/internal/gateway/handlers"
+	"github.com/saleh-ghazimoradi/Cartopher/internal/gateway/middlewares"
+)
+
+type ShipmentRoutes struct {
+	shipmentHandler *handlers.ShipmentHandler
+	authMiddleware  *middlewares.Authentication
+}
+
+func (s *ShipmentRoutes) ShipmentRoute(router *gin.Engine) {
+	v1 := router.Group("/v1")
+
+	protected := v1.Group("/")
+	protected.Use(s.authMiddleware.Authenticate())
+	protected.Use(s.authMiddleware.RequirePermission(domain.PermissionOrdersManage))
+
+	orders := protected.Group("/orders")
+	orders.POST("/:id/shipments", s.shipmentHandler.CreateShipment)
+	orders.GET("/:id/shipments", s.shipmentHandler.GetShipments)
+
+	shipments := protected.Group("/shipments")
+	shipments.PUT("/:id/deliver", s.shipmentHandler.MarkDelivered)
+}
+
+func NewShipmentRoutes(shipmentHandler *handlers.ShipmentHandler, authMiddleware *middlewares.Authentication) *ShipmentRoutes {
+	return &ShipmentRoutes{
+		shipmentHandler: shipmentHandler,
+		authMiddleware:  authMiddleware,
+	}
+}
@@ -0,0 +1,31 @@
+package routes
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/saleh-ghazimoradi/Cartopher/internal/domain"
+	"github.com/saleh-ghazimoradi/Cartopher/internal/gateway/handlers"
+	"github.com/saleh-ghazimoradi/Cartopher/internal/gateway/middlewares"
+)
+
+type TemplateRoutes struct {
+	templateHandler *handlers.TemplateHandler
+	authMiddleware  *middlewares.Authentication
+}
+
+func (t *TemplateRoutes) TemplateRoute(router *gin.Engine) {
+	v1 := router.Group("/v1")
+	protected := v1.Group("/")
+	protected.Use(t.authMiddleware.Authenticate())
+
+	admin := protected.Group("/admin/email-templates")
+	admin.Use(t.authMiddleware.RequirePermission(domain.PermissionTemplatesRead))
+	admin.GET("/", t.templateHandler.GetTemplates)
+	admin.GET("/:name/preview", t.templateHandler.PreviewTemplate)
+}
+
+func NewTemplateRoutes(templateHandler *handlers.TemplateHandler, authMiddleware *middlewares.Authentication) *TemplateRoutes {
+	return &TemplateRoutes{
+		templateHandler: templateHandler,
+		authMiddleware:  authMiddleware,
+	}
+}
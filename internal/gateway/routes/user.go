@@ -18,6 +18,7 @@ func (u *UserRoutes) UserRoute(router *gin.Engine) {
 	user := protected.Group("/users")
 	user.GET("/profile", u.userHandler.GetProfile)
 	user.PUT("/profile", u.userHandler.UpdateProfile)
+	user.PUT("/password", u.userHandler.ChangePassword)
 }
 
 func NewUserRoutes(userHandler *handlers.UserHandler, authMiddleware *middlewares.Authentication) *UserRoutes {
@@ -0,0 +1,41 @@
+package routes
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/saleh-ghazimoradi/Cartopher/internal/domain"
+	"github.com/saleh-ghazimoradi/Cartopher/internal/gateway/handlers"
+	"github.com/saleh-ghazimoradi/Cartopher/internal/gateway/middlewares"
+)
+
+type WebhookRoutes struct {
+	webhookHandler *handlers.WebhookHandler
+	authMiddleware *middlewares.Authentication
+}
+
+func (w *WebhookRoutes) WebhookRoute(router *gin.Engine) {
+	v1 := router.Group("/v1")
+	protected := v1.Group("/")
+	protected.Use(w.authMiddleware.Authenticate())
+
+	admin := protected.Group("/admin")
+	admin.Use(w.authMiddleware.RequirePermission(domain.PermissionWebhooksManage))
+
+	webhooks := admin.Group("/webhooks")
+	webhooks.POST("/", w.webhookHandler.CreateEndpoint)
+	webhooks.GET("/", w.webhookHandler.GetEndpoints)
+	webhooks.GET("/:id", w.webhookHandler.GetEndpoint)
+	webhooks.PUT("/:id", w.webhookHandler.UpdateEndpoint)
+	webhooks.DELETE("/:id", w.webhookHandler.DeleteEndpoint)
+	webhooks.GET("/:id/deliveries", w.webhookHandler.GetDeliveries)
+
+	deliveries := admin.Group("/webhook-deliveries")
+	deliveries.GET("/:id", w.webhookHandler.GetDelivery)
+	deliveries.POST("/:id/redeliver", w.webhookHandler.Redeliver)
+}
+
+func NewWebhookRoutes(webhookHandler *handlers.WebhookHandler, authMiddleware *middlewares.Authentication) *WebhookRoutes {
+	return &WebhookRoutes{
+		webhookHandler: webhookHandler,
+		authMiddleware: authMiddleware,
+	}
+}
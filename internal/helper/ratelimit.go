@@ -8,6 +8,10 @@ import (
 
 type RateLimiter interface {
 	Allow(ctx context.Context, key string, rpm int) (remaining int, retryAfter time.Duration, allowed bool, err error)
+	// AllowN records n events of key against limit. With n = 0 it only
+	// reads the state of key.
+	AllowN(ctx context.Context, key string, limit redis_rate.Limit, n int) (*redis_rate.Result, error)
+	Reset(ctx context.Context, key string) error
 }
 
 type rateLimiter struct {
@@ -23,6 +27,14 @@ func (r *rateLimiter) Allow(ctx context.Context, key string, rpm int) (remaining
 	return res.Remaining, res.RetryAfter, res.Allowed > 0, nil
 }
 
+func (r *rateLimiter) AllowN(ctx context.Context, key string, limit redis_rate.Limit, n int) (*redis_rate.Result, error) {
+	return r.limiter.AllowN(ctx, key, limit, n)
+}
+
+func (r *rateLimiter) Reset(ctx context.Context, key string) error {
+	return r.limiter.Reset(ctx, key)
+}
+
 func NewRateLimiter(limiter *redis_rate.Limiter) RateLimiter {
 	return &rateLimiter{
 		limiter: limiter,
@@ -70,6 +70,10 @@ func NotFoundResponse(ctx *gin.Context, message string) {
 	ErrorResponse(ctx, http.StatusNotFound, message, nil)
 }
 
+func TooManyRequestsResponse(ctx *gin.Context, message string) {
+	ErrorResponse(ctx, http.StatusTooManyRequests, message, nil)
+}
+
 func InternalServerError(ctx *gin.Context, message string, err error) {
 	ErrorResponse(ctx, http.StatusInternalServerError, message, err)
 }
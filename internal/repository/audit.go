@@ -0,0 +1,63 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/saleh-ghazimoradi/Cartopher/internal/domain"
+	"gorm.io/gorm"
+)
+
+type AuditRepository interface {
+	CreateAuditLog(ctx context.Context, log *domain.AuditLog) error
+	GetAuditLogsByUserId(ctx context.Context, userId uint, offset, limit int) ([]domain.AuditLog, error)
+	CountAuditLogsByUserId(ctx context.Context, userId uint) (int64, error)
+	WithTx(tx *gorm.DB) AuditRepository
+}
+
+type auditRepository struct {
+	dbWrite *gorm.DB
+	dbRead  *gorm.DB
+	tx      *gorm.DB
+}
+
+func (a *auditRepository) CreateAuditLog(ctx context.Context, log *domain.AuditLog) error {
+	return exec(a.dbWrite, a.tx).WithContext(ctx).Create(log).Error
+}
+
+// GetAuditLogsByUserId returns a page of the entries about the user,
+// newest first.
+func (a *auditRepository) GetAuditLogsByUserId(ctx context.Context, userId uint, offset, limit int) ([]domain.AuditLog, error) {
+	var logs []domain.AuditLog
+	if err := exec(a.dbRead, a.tx).WithContext(ctx).
+		Where("user_id = ?", userId).
+		Order("created_at DESC, id DESC").
+		Offset(offset).
+		Limit(limit).
+		Find(&logs).Error; err != nil {
+		return nil, err
+	}
+	return logs, nil
+}
+
+func (a *auditRepository) CountAuditLogsByUserId(ctx context.Context, userId uint) (int64, error) {
+	var count int64
+	if err := exec(a.dbRead, a.tx).WithContext(ctx).Model(&domain.AuditLog{}).Where("user_id = ?", userId).Count(&count).Error; err != nil {
+		return 0, err
+	}
+	return count, nil
+}
+
+func (a *auditRepository) WithTx(tx *gorm.DB) AuditRepository {
+	return &auditRepository{
+		dbWrite: a.dbWrite,
+		dbRead:  a.dbRead,
+		tx:      tx,
+	}
+}
+
+func NewAuditRepository(dbWrite, dbRead *gorm.DB) AuditRepository {
+	return &auditRepository{
+		dbWrite: dbWrite,
+		dbRead:  dbRead,
+	}
+}
@@ -34,7 +34,7 @@ func (c *cartRepository) CreateCart(ctx context.Context, cart *domain.Cart) erro
 
 func (c *cartRepository) GetCartByUserId(ctx context.Context, userId uint) (*domain.Cart, error) {
 	var cart domain.Cart
-	if err := exec(c.dbRead, c.tx).WithContext(ctx).Preload("CartItems.Product.Category").Where("user_id = ?", userId).First(&cart).Error; err != nil {
+	if err := exec(c.dbRead, c.tx).WithContext(ctx).Preload("CartItems.Product.Category").Preload("CartItems.Product.Stocks.Warehouse").Where("user_id = ?", userId).First(&cart).Error; err != nil {
 		switch {
 		case errors.Is(err, gorm.ErrRecordNotFound):
 			return nil, ErrNotFound
@@ -46,7 +46,7 @@ func (c *cartRepository) GetCartByUserId(ctx context.Context, userId uint) (*dom
 
 func (c *cartRepository) GetCartWithItemsAndProducts(ctx context.Context, userId uint) (*domain.Cart, error) {
 	var cart domain.Cart
-	if err := exec(c.dbRead, c.tx).WithContext(ctx).Preload("CartItems.Product").Where("user_id = ?", userId).First(&cart).Error; err != nil {
+	if err := exec(c.dbRead, c.tx).WithContext(ctx).Preload("CartItems.Product.Category").Where("user_id = ?", userId).First(&cart).Error; err != nil {
 		switch {
 		case errors.Is(err, gorm.ErrRecordNotFound):
 			return nil, ErrNotFound
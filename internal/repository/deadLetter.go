@@ -0,0 +1,89 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/saleh-ghazimoradi/Cartopher/internal/domain"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type DeadLetterRepository interface {
+	CreateDeadLetter(ctx context.Context, deadLetter *domain.DeadLetter) error
+	GetDeadLetterById(ctx context.Context, id uint) (*domain.DeadLetter, error)
+	GetDeadLetters(ctx context.Context, includeReplayed bool, limit int) ([]domain.DeadLetter, error)
+	GetDeadLetterForUpdate(ctx context.Context, id uint) (*domain.DeadLetter, error)
+	MarkReplayed(ctx context.Context, id uint, replayedAt time.Time) error
+	WithTx(tx *gorm.DB) DeadLetterRepository
+}
+
+type deadLetterRepository struct {
+	dbWrite *gorm.DB
+	dbRead  *gorm.DB
+	tx      *gorm.DB
+}
+
+func (d *deadLetterRepository) CreateDeadLetter(ctx context.Context, deadLetter *domain.DeadLetter) error {
+	return exec(d.dbWrite, d.tx).WithContext(ctx).Create(deadLetter).Error
+}
+
+func (d *deadLetterRepository) GetDeadLetterById(ctx context.Context, id uint) (*domain.DeadLetter, error) {
+	var deadLetter domain.DeadLetter
+	if err := exec(d.dbRead, d.tx).WithContext(ctx).First(&deadLetter, id).Error; err != nil {
+		switch {
+		case errors.Is(err, gorm.ErrRecordNotFound):
+			return nil, ErrNotFound
+		default:
+			return nil, err
+		}
+	}
+	return &deadLetter, nil
+}
+
+func (d *deadLetterRepository) GetDeadLetters(ctx context.Context, includeReplayed bool, limit int) ([]domain.DeadLetter, error) {
+	var deadLetters []domain.DeadLetter
+	query := exec(d.dbRead, d.tx).WithContext(ctx)
+	if !includeReplayed {
+		query = query.Where("replayed_at IS NULL")
+	}
+	if err := query.Order("created_at DESC").Limit(limit).Find(&deadLetters).Error; err != nil {
+		return nil, err
+	}
+	return deadLetters, nil
+}
+
+// GetDeadLetterForUpdate locks the dead letter row. It is meant to be called
+// inside a transaction.
+func (d *deadLetterRepository) GetDeadLetterForUpdate(ctx context.Context, id uint) (*domain.DeadLetter, error) {
+	var deadLetter domain.DeadLetter
+	if err := exec(d.dbWrite, d.tx).WithContext(ctx).Clauses(clause.Locking{Strength: "UPDATE"}).First(&deadLetter, id).Error; err != nil {
+		switch {
+		case errors.Is(err, gorm.ErrRecordNotFound):
+			return nil, ErrNotFound
+		default:
+			return nil, err
+		}
+	}
+	return &deadLetter, nil
+}
+
+func (d *deadLetterRepository) MarkReplayed(ctx context.Context, id uint, replayedAt time.Time) error {
+	return exec(d.dbWrite, d.tx).WithContext(ctx).Model(&domain.DeadLetter{}).Where("id = ?", id).Update("replayed_at", replayedAt).Error
+}
+
+func (d *deadLetterRepository) WithTx(tx *gorm.DB) DeadLetterRepository {
+	return &deadLetterRepository{
+		dbWrite: d.dbWrite,
+		dbRead:  d.dbRead,
+		tx:      tx,
+	}
+}
+
+func NewDeadLetterRepository(dbWrite, dbRead *gorm.DB) DeadLetterRepository {
+	return &deadLetterRepository{
+		dbWrite: dbWrite,
+		dbRead:  dbRead,
+	}
+}
@@ -0,0 +1,185 @@
+package repository
+
+import (
+	"context"
+	"errors"
+
+	"github.com/saleh-ghazimoradi/Cartopher/internal/domain"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+var (
+	ErrInsufficientStock = errors.New("insufficient stock")
+)
+
+type InventoryRepository interface {
+	CreateWarehouse(ctx context.Context, warehouse *domain.Warehouse) error
+	GetWarehouseById(ctx context.Context, id uint) (*domain.Warehouse, error)
+	GetWarehouseByCode(ctx context.Context, code string) (*domain.Warehouse, error)
+	GetWarehouses(ctx context.Context) ([]*domain.Warehouse, error)
+	UpdateWarehouse(ctx context.Context, warehouse *domain.Warehouse) error
+	DeleteWarehouse(ctx context.Context, id uint) error
+
+	GetProductStocks(ctx context.Context, productId uint) ([]domain.WarehouseStock, error)
+	GetStocksForUpdate(ctx context.Context, productIds []uint) ([]domain.WarehouseStock, error)
+	SetStockLevel(ctx context.Context, warehouseId, productId uint, quantity int) error
+	AdjustStock(ctx context.Context, warehouseId, productId uint, delta int) error
+	GetLowStockProducts(ctx context.Context) ([]domain.LowStockProduct, error)
+	WithTx(tx *gorm.DB) InventoryRepository
+}
+
+type inventoryRepository struct {
+	dbWrite *gorm.DB
+	dbRead  *gorm.DB
+	tx      *gorm.DB
+}
+
+func (i *inventoryRepository) CreateWarehouse(ctx context.Context, warehouse *domain.Warehouse) error {
+	return exec(i.dbWrite, i.tx).WithContext(ctx).Create(warehouse).Error
+}
+
+func (i *inventoryRepository) GetWarehouseById(ctx context.Context, id uint) (*domain.Warehouse, error) {
+	var warehouse domain.Warehouse
+	if err := exec(i.dbRead, i.tx).WithContext(ctx).First(&warehouse, id).Error; err != nil {
+		switch {
+		case errors.Is(err, gorm.ErrRecordNotFound):
+			return nil, ErrNotFound
+		default:
+			return nil, err
+		}
+	}
+	return &warehouse, nil
+}
+
+func (i *inventoryRepository) GetWarehouseByCode(ctx context.Context, code string) (*domain.Warehouse, error) {
+	var warehouse domain.Warehouse
+	if err := exec(i.dbRead, i.tx).WithContext(ctx).Where("code = ?", code).First(&warehouse).Error; err != nil {
+		switch {
+		case errors.Is(err, gorm.ErrRecordNotFound):
+			return nil, ErrNotFound
+		default:
+			return nil, err
+		}
+	}
+	return &warehouse, nil
+}
+
+func (i *inventoryRepository) GetWarehouses(ctx context.Context) ([]*domain.Warehouse, error) {
+	var warehouses []*domain.Warehouse
+	if err := exec(i.dbRead, i.tx).WithContext(ctx).Order("priority ASC, id ASC").Find(&warehouses).Error; err != nil {
+		return nil, err
+	}
+	return warehouses, nil
+}
+
+func (i *inventoryRepository) UpdateWarehouse(ctx context.Context, warehouse *domain.Warehouse) error {
+	return exec(i.dbWrite, i.tx).WithContext(ctx).Omit(clause.Associations).Save(warehouse).Error
+}
+
+func (i *inventoryRepository) DeleteWarehouse(ctx context.Context, id uint) error {
+	return exec(i.dbWrite, i.tx).WithContext(ctx).Delete(&domain.Warehouse{}, id).Error
+}
+
+func (i *inventoryRepository) GetProductStocks(ctx context.Context, productId uint) ([]domain.WarehouseStock, error) {
+	var stocks []domain.WarehouseStock
+	if err := exec(i.dbRead, i.tx).WithContext(ctx).
+		Preload("Warehouse").
+		Joins("JOIN warehouses ON warehouses.id = warehouse_stocks.warehouse_id AND warehouses.deleted_at IS NULL").
+		Where("warehouse_stocks.product_id = ?", productId).
+		Order("warehouses.priority ASC, warehouses.id ASC").
+		Find(&stocks).Error; err != nil {
+		return nil, err
+	}
+	return stocks, nil
+}
+
+// GetStocksForUpdate locks the stock rows of the given products held in active
+// warehouses. It is meant to be called inside a transaction.
+func (i *inventoryRepository) GetStocksForUpdate(ctx context.Context, productIds []uint) ([]domain.WarehouseStock, error) {
+	var stocks []domain.WarehouseStock
+	if err := exec(i.dbWrite, i.tx).WithContext(ctx).
+		Clauses(clause.Locking{Strength: "UPDATE", Table: clause.Table{Name: "warehouse_stocks"}}).
+		Preload("Warehouse").
+		Joins("JOIN warehouses ON warehouses.id = warehouse_stocks.warehouse_id AND warehouses.deleted_at IS NULL AND warehouses.is_active = ?", true).
+		Where("warehouse_stocks.product_id IN ?", productIds).
+		Order("warehouse_stocks.id ASC").
+		Find(&stocks).Error; err != nil {
+		return nil, err
+	}
+	return stocks, nil
+}
+
+func (i *inventoryRepository) SetStockLevel(ctx context.Context, warehouseId, productId uint, quantity int) error {
+	stock := &domain.WarehouseStock{
+		WarehouseId: warehouseId,
+		ProductId:   productId,
+		Quantity:    quantity,
+	}
+	return exec(i.dbWrite, i.tx).WithContext(ctx).Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "warehouse_id"}, {Name: "product_id"}},
+		DoUpdates: clause.AssignmentColumns([]string{"quantity", "updated_at"}),
+	}).Create(stock).Error
+}
+
+func (i *inventoryRepository) AdjustStock(ctx context.Context, warehouseId, productId uint, delta int) error {
+	result := exec(i.dbWrite, i.tx).WithContext(ctx).
+		Model(&domain.WarehouseStock{}).
+		Where("warehouse_id = ? AND product_id = ? AND quantity + ? >= 0", warehouseId, productId, delta).
+		Updates(map[string]any{
+			"quantity":   gorm.Expr("quantity + ?", delta),
+			"updated_at": gorm.Expr("CURRENT_TIMESTAMP"),
+		})
+	if result.Error != nil {
+		return result.Error
+	}
+
+	if result.RowsAffected > 0 {
+		return nil
+	}
+
+	if delta < 0 {
+		return ErrInsufficientStock
+	}
+
+	return i.SetStockLevel(ctx, warehouseId, productId, delta)
+}
+
+// GetLowStockProducts lists active products whose stock across active
+// warehouses is at or below their effective threshold. Products without a
+// threshold, neither their own nor their category's, are never reported.
+func (i *inventoryRepository) GetLowStockProducts(ctx context.Context) ([]domain.LowStockProduct, error) {
+	var products []domain.LowStockProduct
+	if err := exec(i.dbRead, i.tx).WithContext(ctx).
+		Table("products").
+		Select(`products.id AS product_id, products.name, products.sku,
+			COALESCE(SUM(CASE WHEN warehouses.id IS NOT NULL THEN warehouse_stocks.quantity END), 0) AS stock,
+			COALESCE(products.low_stock_threshold, categories.low_stock_threshold) AS threshold`).
+		Joins("JOIN categories ON categories.id = products.category_id").
+		Joins("LEFT JOIN warehouse_stocks ON warehouse_stocks.product_id = products.id").
+		Joins("LEFT JOIN warehouses ON warehouses.id = warehouse_stocks.warehouse_id AND warehouses.deleted_at IS NULL AND warehouses.is_active = ?", true).
+		Where("products.deleted_at IS NULL AND products.is_active = ?", true).
+		Group("products.id, products.name, products.sku, products.low_stock_threshold, categories.low_stock_threshold").
+		Having(`COALESCE(products.low_stock_threshold, categories.low_stock_threshold) > 0
+			AND COALESCE(SUM(CASE WHEN warehouses.id IS NOT NULL THEN warehouse_stocks.quantity END), 0) <= COALESCE(products.low_stock_threshold, categories.low_stock_threshold)`).
+		Order("stock ASC, products.id ASC").
+		Scan(&products).Error; err != nil {
+		return nil, err
+	}
+	return products, nil
+}
+
+func (i *inventoryRepository) WithTx(tx *gorm.DB) InventoryRepository {
+	return &inventoryRepository{
+		dbWrite: i.dbWrite,
+		dbRead:  i.dbRead,
+		tx:      tx,
+	}
+}
+
+func NewInventoryRepository(dbWrite, dbRead *gorm.DB) InventoryRepository {
+	return &inventoryRepository{
+		dbWrite: dbWrite,
+		dbRead:  dbRead,
+	}
+}
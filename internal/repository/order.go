@@ -3,9 +3,11 @@ package repository
 import (
 	"context"
 	"errors"
+	"time"
 
 	"github.com/saleh-ghazimoradi/Cartopher/internal/domain"
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 )
 
 type OrderRepository interface {
@@ -14,6 +16,11 @@ type OrderRepository interface {
 	GetOrderById(ctx context.Context, id uint) (*domain.Order, error)
 	GetOrders(ctx context.Context, userId uint, offset, limit int) ([]domain.Order, error)
 	CountOrders(ctx context.Context, userId uint) (int64, error)
+	GetOrderForUpdate(ctx context.Context, id uint) (*domain.Order, error)
+	UpdateOrderStatus(ctx context.Context, id uint, status domain.OrderStatus) error
+	SetInvoice(ctx context.Context, id uint, number string, invoicedAt time.Time) error
+	SetInvoicePath(ctx context.Context, id uint, path string) error
+	NextInvoiceNumber(ctx context.Context) (int64, error)
 	WithTx(tx *gorm.DB) OrderRepository
 }
 
@@ -29,7 +36,7 @@ func (o *orderRepository) CreateOrder(ctx context.Context, order *domain.Order)
 
 func (o *orderRepository) GetOrderByUserId(ctx context.Context, userId, orderId uint) (*domain.Order, error) {
 	var order domain.Order
-	if err := exec(o.dbRead, o.tx).WithContext(ctx).Preload("OrderItems.Product.Category").Where("id = ? AND user_id = ?", orderId, userId).First(&order).Error; err != nil {
+	if err := exec(o.dbRead, o.tx).WithContext(ctx).Preload("User").Preload("OrderItems.Product.Category").Preload("OrderItems.Product.Stocks.Warehouse").Preload("OrderItems.Warehouse").Preload("Shipments.Items").Where("id = ? AND user_id = ?", orderId, userId).First(&order).Error; err != nil {
 		switch {
 		case errors.Is(err, gorm.ErrRecordNotFound):
 			return nil, ErrNotFound
@@ -42,7 +49,7 @@ func (o *orderRepository) GetOrderByUserId(ctx context.Context, userId, orderId
 
 func (o *orderRepository) GetOrderById(ctx context.Context, id uint) (*domain.Order, error) {
 	var order domain.Order
-	if err := exec(o.dbRead, o.tx).WithContext(ctx).Preload("OrderItems.Product.Category").First(&order, id).Error; err != nil {
+	if err := exec(o.dbRead, o.tx).WithContext(ctx).Preload("User").Preload("OrderItems.Product.Category").Preload("OrderItems.Product.Stocks.Warehouse").Preload("OrderItems.Warehouse").Preload("Shipments.Items").First(&order, id).Error; err != nil {
 		switch {
 		case errors.Is(err, gorm.ErrRecordNotFound):
 			return nil, ErrNotFound
@@ -55,7 +62,7 @@ func (o *orderRepository) GetOrderById(ctx context.Context, id uint) (*domain.Or
 
 func (o *orderRepository) GetOrders(ctx context.Context, userId uint, offset, limit int) ([]domain.Order, error) {
 	var orders []domain.Order
-	if err := exec(o.dbRead, o.tx).WithContext(ctx).Preload("OrderItems.Product.Category").Where("user_id = ?", userId).Order("created_at DESC").Offset(offset).Limit(limit).Find(&orders).Error; err != nil {
+	if err := exec(o.dbRead, o.tx).WithContext(ctx).Preload("OrderItems.Product.Category").Preload("OrderItems.Product.Stocks.Warehouse").Preload("OrderItems.Warehouse").Preload("Shipments.Items").Where("user_id = ?", userId).Order("created_at DESC").Offset(offset).Limit(limit).Find(&orders).Error; err != nil {
 		switch {
 		case errors.Is(err, gorm.ErrRecordNotFound):
 			return nil, ErrNotFound
@@ -77,6 +84,53 @@ func (o *orderRepository) CountOrders(ctx context.Context, userId uint) (int64,
 	return count, nil
 }
 
+// GetOrderForUpdate locks the order row and loads its items and shipments. It
+// is meant to be called inside a transaction.
+func (o *orderRepository) GetOrderForUpdate(ctx context.Context, id uint) (*domain.Order, error) {
+	var order domain.Order
+	if err := exec(o.dbWrite, o.tx).WithContext(ctx).
+		Clauses(clause.Locking{Strength: "UPDATE"}).
+		Preload("OrderItems").
+		Preload("Shipments.Items").
+		First(&order, id).Error; err != nil {
+		switch {
+		case errors.Is(err, gorm.ErrRecordNotFound):
+			return nil, ErrNotFound
+		default:
+			return nil, err
+		}
+	}
+	return &order, nil
+}
+
+func (o *orderRepository) UpdateOrderStatus(ctx context.Context, id uint, status domain.OrderStatus) error {
+	return exec(o.dbWrite, o.tx).WithContext(ctx).Model(&domain.Order{}).Where("id = ?", id).Update("status", status).Error
+}
+
+func (o *orderRepository) SetInvoice(ctx context.Context, id uint, number string, invoicedAt time.Time) error {
+	return exec(o.dbWrite, o.tx).WithContext(ctx).Model(&domain.Order{}).Where("id = ?", id).Updates(map[string]any{
+		"invoice_number": number,
+		"invoiced_at":    invoicedAt,
+	}).Error
+}
+
+func (o *orderRepository) SetInvoicePath(ctx context.Context, id uint, path string) error {
+	return exec(o.dbWrite, o.tx).WithContext(ctx).Model(&domain.Order{}).Where("id = ?", id).Update("invoice_path", path).Error
+}
+
+// NextInvoiceNumber claims the next invoice number. The counter row stays
+// locked until the surrounding transaction ends, so numbers are handed out
+// in order and a rolled back transaction gives its number back.
+func (o *orderRepository) NextInvoiceNumber(ctx context.Context) (int64, error) {
+	var number int64
+	if err := exec(o.dbWrite, o.tx).WithContext(ctx).
+		Raw("UPDATE invoice_sequences SET last_number = last_number + 1 WHERE id = 1 RETURNING last_number").
+		Scan(&number).Error; err != nil {
+		return 0, err
+	}
+	return number, nil
+}
+
 func (o *orderRepository) WithTx(tx *gorm.DB) OrderRepository {
 	return &orderRepository{
 		dbWrite: o.dbWrite,
@@ -0,0 +1,68 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/saleh-ghazimoradi/Cartopher/internal/domain"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type OutboxRepository interface {
+	CreateMessage(ctx context.Context, message *domain.OutboxMessage) error
+	GetPendingMessagesForUpdate(ctx context.Context, limit int) ([]domain.OutboxMessage, error)
+	UpdateMessage(ctx context.Context, message *domain.OutboxMessage) error
+	DeleteSentMessages(ctx context.Context, before time.Time) (int64, error)
+	WithTx(tx *gorm.DB) OutboxRepository
+}
+
+type outboxRepository struct {
+	dbWrite *gorm.DB
+	dbRead  *gorm.DB
+	tx      *gorm.DB
+}
+
+func (o *outboxRepository) CreateMessage(ctx context.Context, message *domain.OutboxMessage) error {
+	return exec(o.dbWrite, o.tx).WithContext(ctx).Create(message).Error
+}
+
+// GetPendingMessagesForUpdate locks the oldest messages that are due, skipping
+// rows another relay already holds. It is meant to be called inside a
+// transaction.
+func (o *outboxRepository) GetPendingMessagesForUpdate(ctx context.Context, limit int) ([]domain.OutboxMessage, error) {
+	var messages []domain.OutboxMessage
+	if err := exec(o.dbWrite, o.tx).WithContext(ctx).
+		Clauses(clause.Locking{Strength: "UPDATE", Options: "SKIP LOCKED"}).
+		Where("sent_at IS NULL AND available_at <= ?", time.Now()).
+		Order("available_at ASC, id ASC").
+		Limit(limit).
+		Find(&messages).Error; err != nil {
+		return nil, err
+	}
+	return messages, nil
+}
+
+func (o *outboxRepository) UpdateMessage(ctx context.Context, message *domain.OutboxMessage) error {
+	return exec(o.dbWrite, o.tx).WithContext(ctx).Save(message).Error
+}
+
+func (o *outboxRepository) DeleteSentMessages(ctx context.Context, before time.Time) (int64, error) {
+	result := exec(o.dbWrite, o.tx).WithContext(ctx).Where("sent_at IS NOT NULL AND sent_at < ?", before).Delete(&domain.OutboxMessage{})
+	return result.RowsAffected, result.Error
+}
+
+func (o *outboxRepository) WithTx(tx *gorm.DB) OutboxRepository {
+	return &outboxRepository{
+		dbWrite: o.dbWrite,
+		dbRead:  o.dbRead,
+		tx:      tx,
+	}
+}
+
+func NewOutboxRepository(dbWrite, dbRead *gorm.DB) OutboxRepository {
+	return &outboxRepository{
+		dbWrite: dbWrite,
+		dbRead:  dbRead,
+	}
+}
@@ -6,6 +6,7 @@ import (
 	"github.com/saleh-ghazimoradi/Cartopher/internal/domain"
 	"github.com/saleh-ghazimoradi/Cartopher/internal/dto"
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 )
 
 type ProductRepository interface {
@@ -75,7 +76,7 @@ func (p *productRepository) CreateProductImage(ctx context.Context, productImage
 
 func (p *productRepository) GetProductById(ctx context.Context, id uint) (*domain.Product, error) {
 	var product *domain.Product
-	if err := exec(p.dbRead, p.tx).WithContext(ctx).Preload("Category").Preload("Images").First(&product, id).Error; err != nil {
+	if err := exec(p.dbRead, p.tx).WithContext(ctx).Preload("Category").Preload("Images").Preload("Stocks.Warehouse").First(&product, id).Error; err != nil {
 		switch {
 		case errors.Is(err, gorm.ErrRecordNotFound):
 			return nil, ErrNotFound
@@ -90,6 +91,7 @@ func (p *productRepository) GetProducts(ctx context.Context, offset, limit int)
 		WithContext(ctx).
 		Preload("Category").
 		Preload("Images").
+		Preload("Stocks.Warehouse").
 		Where("is_active = ?", true).
 		Offset(offset).
 		Limit(limit).
@@ -117,7 +119,7 @@ func (p *productRepository) CountActiveProducts(ctx context.Context) (int64, err
 }
 
 func (p *productRepository) UpdateProduct(ctx context.Context, product *domain.Product) error {
-	return exec(p.dbWrite, p.tx).WithContext(ctx).Save(product).Error
+	return exec(p.dbWrite, p.tx).WithContext(ctx).Omit(clause.Associations).Save(product).Error
 }
 
 func (p *productRepository) DeleteProduct(ctx context.Context, id uint) error {
@@ -164,6 +166,7 @@ func (p *productRepository) SearchProducts(ctx context.Context, req *dto.SearchP
 	if err := db.
 		Preload("Category").
 		Preload("Images").
+		Preload("Stocks.Warehouse").
 		Order("rank DESC, created_at DESC").
 		Offset(offset).
 		Limit(limit).
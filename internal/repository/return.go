@@ -0,0 +1,141 @@
+package repository
+
+import (
+	"context"
+	"errors"
+
+	"github.com/saleh-ghazimoradi/Cartopher/internal/domain"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type ReturnRepository interface {
+	CreateReturn(ctx context.Context, returnRequest *domain.ReturnRequest) error
+	GetReturnById(ctx context.Context, id uint) (*domain.ReturnRequest, error)
+	GetReturnByUserId(ctx context.Context, userId, id uint) (*domain.ReturnRequest, error)
+	GetReturnForUpdate(ctx context.Context, id uint) (*domain.ReturnRequest, error)
+	GetReturnsByUserId(ctx context.Context, userId uint) ([]domain.ReturnRequest, error)
+	GetReturnsByStatus(ctx context.Context, status domain.ReturnStatus) ([]domain.ReturnRequest, error)
+	GetReturnedQuantities(ctx context.Context, orderId uint) (map[uint]int, error)
+	UpdateReturn(ctx context.Context, returnRequest *domain.ReturnRequest) error
+	WithTx(tx *gorm.DB) ReturnRepository
+}
+
+type returnRepository struct {
+	dbWrite *gorm.DB
+	dbRead  *gorm.DB
+	tx      *gorm.DB
+}
+
+func (r *returnRepository) CreateReturn(ctx context.Context, returnRequest *domain.ReturnRequest) error {
+	return exec(r.dbWrite, r.tx).WithContext(ctx).Create(returnRequest).Error
+}
+
+func (r *returnRepository) GetReturnById(ctx context.Context, id uint) (*domain.ReturnRequest, error) {
+	var returnRequest domain.ReturnRequest
+	if err := exec(r.dbRead, r.tx).WithContext(ctx).Preload("Items.OrderItem").First(&returnRequest, id).Error; err != nil {
+		switch {
+		case errors.Is(err, gorm.ErrRecordNotFound):
+			return nil, ErrNotFound
+		default:
+			return nil, err
+		}
+	}
+	return &returnRequest, nil
+}
+
+func (r *returnRepository) GetReturnByUserId(ctx context.Context, userId, id uint) (*domain.ReturnRequest, error) {
+	var returnRequest domain.ReturnRequest
+	if err := exec(r.dbRead, r.tx).WithContext(ctx).Preload("Items.OrderItem").Where("id = ? AND user_id = ?", id, userId).First(&returnRequest).Error; err != nil {
+		switch {
+		case errors.Is(err, gorm.ErrRecordNotFound):
+			return nil, ErrNotFound
+		default:
+			return nil, err
+		}
+	}
+	return &returnRequest, nil
+}
+
+// GetReturnForUpdate locks the return request row. It is meant to be called
+// inside a transaction.
+func (r *returnRepository) GetReturnForUpdate(ctx context.Context, id uint) (*domain.ReturnRequest, error) {
+	var returnRequest domain.ReturnRequest
+	if err := exec(r.dbWrite, r.tx).WithContext(ctx).
+		Clauses(clause.Locking{Strength: "UPDATE"}).
+		Preload("Items.OrderItem").
+		First(&returnRequest, id).Error; err != nil {
+		switch {
+		case errors.Is(err, gorm.ErrRecordNotFound):
+			return nil, ErrNotFound
+		default:
+			return nil, err
+		}
+	}
+	return &returnRequest, nil
+}
+
+func (r *returnRepository) GetReturnsByUserId(ctx context.Context, userId uint) ([]domain.ReturnRequest, error) {
+	var returns []domain.ReturnRequest
+	if err := exec(r.dbRead, r.tx).WithContext(ctx).Preload("Items.OrderItem").Where("user_id = ?", userId).Order("created_at DESC").Find(&returns).Error; err != nil {
+		return nil, err
+	}
+	return returns, nil
+}
+
+// GetReturnsByStatus lists return requests in the given status, or every
+// return request when status is empty.
+func (r *returnRepository) GetReturnsByStatus(ctx context.Context, status domain.ReturnStatus) ([]domain.ReturnRequest, error) {
+	var returns []domain.ReturnRequest
+	db := exec(r.dbRead, r.tx).WithContext(ctx).Preload("Items.OrderItem")
+	if status != "" {
+		db = db.Where("status = ?", status)
+	}
+	if err := db.Order("created_at ASC").Find(&returns).Error; err != nil {
+		return nil, err
+	}
+	return returns, nil
+}
+
+// GetReturnedQuantities sums, per order item, the units claimed by the
+// order's return requests that have not been rejected.
+func (r *returnRepository) GetReturnedQuantities(ctx context.Context, orderId uint) (map[uint]int, error) {
+	var rows []struct {
+		OrderItemId uint
+		Quantity    int
+	}
+	if err := exec(r.dbRead, r.tx).WithContext(ctx).
+		Table("return_items").
+		Select("return_items.order_item_id, SUM(return_items.quantity) AS quantity").
+		Joins("JOIN return_requests ON return_requests.id = return_items.return_request_id").
+		Where("return_requests.order_id = ? AND return_requests.status <> ?", orderId, domain.ReturnStatusRejected).
+		Group("return_items.order_item_id").
+		Scan(&rows).Error; err != nil {
+		return nil, err
+	}
+
+	returned := make(map[uint]int, len(rows))
+	for _, row := range rows {
+		returned[row.OrderItemId] = row.Quantity
+	}
+	return returned, nil
+}
+
+func (r *returnRepository) UpdateReturn(ctx context.Context, returnRequest *domain.ReturnRequest) error {
+	return exec(r.dbWrite, r.tx).WithContext(ctx).Omit(clause.Associations).Save(returnRequest).Error
+}
+
+func (r *returnRepository) WithTx(tx *gorm.DB) ReturnRepository {
+	return &returnRepository{
+		dbWrite: r.dbWrite,
+		dbRead:  r.dbRead,
+		tx:      tx,
+	}
+}
+
+func NewReturnRepository(dbWrite, dbRead *gorm.DB) ReturnRepository {
+	return &returnRepository{
+		dbWrite: dbWrite,
+		dbRead:  dbRead,
+	}
+}
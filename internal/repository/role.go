@@ -0,0 +1,88 @@
+package repository
+
+import (
+	"context"
+	"errors"
+
+	"github.com/saleh-ghazimoradi/Cartopher/internal/domain"
+	"gorm.io/gorm"
+)
+
+type RoleRepository interface {
+	CreateRole(ctx context.Context, role *domain.Role) error
+	GetRoleByName(ctx context.Context, name domain.UserRole) (*domain.Role, error)
+	GetRoles(ctx context.Context) ([]domain.Role, error)
+	UpdateRole(ctx context.Context, role *domain.Role) error
+	DeleteRole(ctx context.Context, name domain.UserRole) error
+	CountUsersWithRole(ctx context.Context, name domain.UserRole) (int64, error)
+	WithTx(tx *gorm.DB) RoleRepository
+}
+
+type roleRepository struct {
+	dbWrite *gorm.DB
+	dbRead  *gorm.DB
+	tx      *gorm.DB
+}
+
+func (r *roleRepository) CreateRole(ctx context.Context, role *domain.Role) error {
+	return exec(r.dbWrite, r.tx).WithContext(ctx).Create(role).Error
+}
+
+func (r *roleRepository) GetRoleByName(ctx context.Context, name domain.UserRole) (*domain.Role, error) {
+	var role domain.Role
+	if err := exec(r.dbRead, r.tx).WithContext(ctx).Where("name = ?", name).First(&role).Error; err != nil {
+		switch {
+		case errors.Is(err, gorm.ErrRecordNotFound):
+			return nil, ErrNotFound
+		default:
+			return nil, err
+		}
+	}
+	return &role, nil
+}
+
+func (r *roleRepository) GetRoles(ctx context.Context) ([]domain.Role, error) {
+	var roles []domain.Role
+	if err := exec(r.dbRead, r.tx).WithContext(ctx).Order("id ASC").Find(&roles).Error; err != nil {
+		return nil, err
+	}
+	return roles, nil
+}
+
+func (r *roleRepository) UpdateRole(ctx context.Context, role *domain.Role) error {
+	return exec(r.dbWrite, r.tx).WithContext(ctx).Model(role).Select("description", "permissions").Updates(role).Error
+}
+
+func (r *roleRepository) DeleteRole(ctx context.Context, name domain.UserRole) error {
+	result := exec(r.dbWrite, r.tx).WithContext(ctx).Where("name = ? AND is_system = ?", name, false).Delete(&domain.Role{})
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
+
+func (r *roleRepository) CountUsersWithRole(ctx context.Context, name domain.UserRole) (int64, error) {
+	var count int64
+	if err := exec(r.dbRead, r.tx).WithContext(ctx).Model(&domain.User{}).Where("role = ?", name).Count(&count).Error; err != nil {
+		return 0, err
+	}
+	return count, nil
+}
+
+func (r *roleRepository) WithTx(tx *gorm.DB) RoleRepository {
+	return &roleRepository{
+		dbWrite: r.dbWrite,
+		dbRead:  r.dbRead,
+		tx:      tx,
+	}
+}
+
+func NewRoleRepository(dbWrite, dbRead *gorm.DB) RoleRepository {
+	return &roleRepository{
+		dbWrite: dbWrite,
+		dbRead:  dbRead,
+	}
+}
@@ -0,0 +1,68 @@
+package repository
+
+import (
+	"context"
+	"errors"
+
+	"github.com/saleh-ghazimoradi/Cartopher/internal/domain"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type ShipmentRepository interface {
+	CreateShipment(ctx context.Context, shipment *domain.Shipment) error
+	GetShipmentById(ctx context.Context, id uint) (*domain.Shipment, error)
+	GetShipmentsByOrderId(ctx context.Context, orderId uint) ([]domain.Shipment, error)
+	UpdateShipment(ctx context.Context, shipment *domain.Shipment) error
+	WithTx(tx *gorm.DB) ShipmentRepository
+}
+
+type shipmentRepository struct {
+	dbWrite *gorm.DB
+	dbRead  *gorm.DB
+	tx      *gorm.DB
+}
+
+func (s *shipmentRepository) CreateShipment(ctx context.Context, shipment *domain.Shipment) error {
+	return exec(s.dbWrite, s.tx).WithContext(ctx).Create(shipment).Error
+}
+
+func (s *shipmentRepository) GetShipmentById(ctx context.Context, id uint) (*domain.Shipment, error) {
+	var shipment domain.Shipment
+	if err := exec(s.dbRead, s.tx).WithContext(ctx).Preload("Items").First(&shipment, id).Error; err != nil {
+		switch {
+		case errors.Is(err, gorm.ErrRecordNotFound):
+			return nil, ErrNotFound
+		default:
+			return nil, err
+		}
+	}
+	return &shipment, nil
+}
+
+func (s *shipmentRepository) GetShipmentsByOrderId(ctx context.Context, orderId uint) ([]domain.Shipment, error) {
+	var shipments []domain.Shipment
+	if err := exec(s.dbRead, s.tx).WithContext(ctx).Preload("Items").Where("order_id = ?", orderId).Order("id ASC").Find(&shipments).Error; err != nil {
+		return nil, err
+	}
+	return shipments, nil
+}
+
+func (s *shipmentRepository) UpdateShipment(ctx context.Context, shipment *domain.Shipment) error {
+	return exec(s.dbWrite, s.tx).WithContext(ctx).Omit(clause.Associations).Save(shipment).Error
+}
+
+func (s *shipmentRepository) WithTx(tx *gorm.DB) ShipmentRepository {
+	return &shipmentRepository{
+		dbWrite: s.dbWrite,
+		dbRead:  s.dbRead,
+		tx:      tx,
+	}
+}
+
+func NewShipmentRepository(dbWrite, dbRead *gorm.DB) ShipmentRepository {
+	return &shipmentRepository{
+		dbWrite: dbWrite,
+		dbRead:  dbRead,
+	}
+}
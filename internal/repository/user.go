@@ -3,10 +3,13 @@ package repository
 import (
 	"context"
 	"errors"
+	"strings"
 	"time"
 
 	"github.com/saleh-ghazimoradi/Cartopher/internal/domain"
+	"github.com/saleh-ghazimoradi/Cartopher/internal/dto"
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 )
 
 type UserRepository interface {
@@ -14,13 +17,39 @@ type UserRepository interface {
 	GetUserById(ctx context.Context, id uint) (*domain.User, error)
 	GetUserByEmail(ctx context.Context, email string) (*domain.User, error)
 	GetUserByEmailAndActive(ctx context.Context, email string, isActive bool) (*domain.User, error)
+	GetUsers(ctx context.Context, req *dto.GetUsersRequest, offset, limit int) ([]domain.User, int64, error)
 	UpdateUser(ctx context.Context, user *domain.User) error
+	UpdatePassword(ctx context.Context, id uint, password string) error
+	UpdateRole(ctx context.Context, id uint, role domain.UserRole) error
+	UpdateActive(ctx context.Context, id uint, isActive bool) error
+	GetUserIdsByRole(ctx context.Context, role domain.UserRole) ([]uint, error)
+	UpdateTwoFactor(ctx context.Context, id uint, secret string, enabledAt *time.Time) error
+	UseTOTPStep(ctx context.Context, id uint, step int64) (bool, error)
 	DeleteUser(ctx context.Context, id uint) error
+	MarkEmailVerified(ctx context.Context, id uint, email string, verifiedAt time.Time) error
+	MarkVerificationSent(ctx context.Context, id uint, sentAt, notSince time.Time) (bool, error)
 
 	CreateRefreshToken(ctx context.Context, token *domain.RefreshToken) error
-	GetValidRefreshToken(ctx context.Context, token string) (*domain.RefreshToken, error)
-	DeleteRefreshToken(ctx context.Context, token string) error
+	GetValidRefreshTokenForUpdate(ctx context.Context, tokenHash string) (*domain.RefreshToken, error)
+	GetValidRefreshTokensByUserId(ctx context.Context, userId uint) ([]domain.RefreshToken, error)
+	UpdateRefreshToken(ctx context.Context, token *domain.RefreshToken) error
+	DeleteRefreshToken(ctx context.Context, tokenHash string) error
 	DeleteRefreshTokenById(ctx context.Context, id uint) error
+	DeleteRefreshTokensByUserId(ctx context.Context, userId uint) error
+	DeleteUserRefreshTokenById(ctx context.Context, userId, id uint) error
+	CreateRotatedRefreshToken(ctx context.Context, token *domain.RotatedRefreshToken) error
+	GetRotatedRefreshToken(ctx context.Context, tokenHash string) (*domain.RotatedRefreshToken, error)
+
+	CreatePasswordResetToken(ctx context.Context, token *domain.PasswordResetToken) error
+	GetValidPasswordResetTokenForUpdate(ctx context.Context, tokenHash string) (*domain.PasswordResetToken, error)
+	UsePasswordResetTokens(ctx context.Context, userId uint, usedAt time.Time) error
+
+	ReplaceRecoveryCodes(ctx context.Context, userId uint, codes []domain.RecoveryCode) error
+	DeleteRecoveryCodes(ctx context.Context, userId uint) error
+	UseRecoveryCode(ctx context.Context, userId uint, codeHash string, usedAt time.Time) (bool, error)
+
+	CreateUserIdentity(ctx context.Context, identity *domain.UserIdentity) error
+	GetUserIdentity(ctx context.Context, provider, subject string) (*domain.UserIdentity, error)
 	WithTx(tx *gorm.DB) UserRepository
 }
 
@@ -73,34 +102,276 @@ func (u *userRepository) GetUserByEmailAndActive(ctx context.Context, email stri
 	return user, nil
 }
 
+// GetUsers returns a page of the users matching req, newest first, and how
+// many match in total.
+func (u *userRepository) GetUsers(ctx context.Context, req *dto.GetUsersRequest, offset, limit int) ([]domain.User, int64, error) {
+	db := exec(u.dbRead, u.tx).WithContext(ctx).Model(&domain.User{})
+
+	if query := strings.TrimSpace(req.Query); query != "" {
+		pattern := "%" + escapeLike(query) + "%"
+		db = db.Where("email ILIKE ? OR first_name ILIKE ? OR last_name ILIKE ? OR CONCAT(first_name, ' ', last_name) ILIKE ?", pattern, pattern, pattern, pattern)
+	}
+
+	if req.Role != "" {
+		db = db.Where("role = ?", req.Role)
+	}
+
+	if req.IsActive != nil {
+		db = db.Where("is_active = ?", *req.IsActive)
+	}
+
+	var total int64
+	if err := db.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	var users []domain.User
+	if err := db.Order("created_at DESC, id DESC").Offset(offset).Limit(limit).Find(&users).Error; err != nil {
+		return nil, 0, err
+	}
+
+	return users, total, nil
+}
+
 func (u *userRepository) UpdateUser(ctx context.Context, user *domain.User) error {
 	return exec(u.dbWrite, u.tx).WithContext(ctx).Save(user).Error
 }
 
+func (u *userRepository) UpdatePassword(ctx context.Context, id uint, password string) error {
+	return exec(u.dbWrite, u.tx).WithContext(ctx).Model(&domain.User{}).Where("id = ?", id).Update("password", password).Error
+}
+
+func (u *userRepository) UpdateRole(ctx context.Context, id uint, role domain.UserRole) error {
+	result := exec(u.dbWrite, u.tx).WithContext(ctx).Model(&domain.User{}).Where("id = ?", id).Update("role", role)
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
+
+func (u *userRepository) UpdateActive(ctx context.Context, id uint, isActive bool) error {
+	result := exec(u.dbWrite, u.tx).WithContext(ctx).Model(&domain.User{}).Where("id = ?", id).Update("is_active", isActive)
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
+
+func (u *userRepository) GetUserIdsByRole(ctx context.Context, role domain.UserRole) ([]uint, error) {
+	var ids []uint
+	if err := exec(u.dbRead, u.tx).WithContext(ctx).Model(&domain.User{}).Where("role = ?", role).Pluck("id", &ids).Error; err != nil {
+		return nil, err
+	}
+	return ids, nil
+}
+
+// UpdateTwoFactor sets the TOTP secret of the user and when it was
+// confirmed; a nil enabledAt leaves enrolment pending or disables it.
+func (u *userRepository) UpdateTwoFactor(ctx context.Context, id uint, secret string, enabledAt *time.Time) error {
+	return exec(u.dbWrite, u.tx).WithContext(ctx).Model(&domain.User{}).Where("id = ?", id).Updates(map[string]any{
+		"totp_secret":     secret,
+		"totp_enabled_at": enabledAt,
+	}).Error
+}
+
+// UseTOTPStep records that the user used the TOTP code of step, unless
+// they already used the code of that or a later step. It reports whether
+// the step was recorded.
+func (u *userRepository) UseTOTPStep(ctx context.Context, id uint, step int64) (bool, error) {
+	result := exec(u.dbWrite, u.tx).WithContext(ctx).Model(&domain.User{}).
+		Where("id = ? AND (totp_last_step IS NULL OR totp_last_step < ?)", id, step).
+		Update("totp_last_step", step)
+	if result.Error != nil {
+		return false, result.Error
+	}
+	return result.RowsAffected == 1, nil
+}
+
 func (u *userRepository) DeleteUser(ctx context.Context, id uint) error {
 	return exec(u.dbWrite, u.tx).WithContext(ctx).Delete(&domain.User{}, id).Error
 }
 
+// MarkEmailVerified sets the verification time of the user if email is
+// still their address and was not verified before.
+func (u *userRepository) MarkEmailVerified(ctx context.Context, id uint, email string, verifiedAt time.Time) error {
+	return exec(u.dbWrite, u.tx).WithContext(ctx).Model(&domain.User{}).
+		Where("id = ? AND email = ? AND email_verified_at IS NULL", id, email).
+		Update("email_verified_at", verifiedAt).Error
+}
+
+// MarkVerificationSent records that a verification email is sent at sentAt,
+// unless one was already sent since notSince. It reports whether the time
+// was recorded.
+func (u *userRepository) MarkVerificationSent(ctx context.Context, id uint, sentAt, notSince time.Time) (bool, error) {
+	result := exec(u.dbWrite, u.tx).WithContext(ctx).Model(&domain.User{}).
+		Where("id = ? AND (verification_sent_at IS NULL OR verification_sent_at < ?)", id, notSince).
+		Update("verification_sent_at", sentAt)
+	if result.Error != nil {
+		return false, result.Error
+	}
+	return result.RowsAffected == 1, nil
+}
+
 func (u *userRepository) CreateRefreshToken(ctx context.Context, token *domain.RefreshToken) error {
 	return exec(u.dbWrite, u.tx).WithContext(ctx).Create(token).Error
 }
 
-func (u *userRepository) GetValidRefreshToken(ctx context.Context, token string) (*domain.RefreshToken, error) {
+// GetValidRefreshTokenForUpdate locks the unexpired token family whose
+// current token has tokenHash. It is meant to be called inside a
+// transaction.
+func (u *userRepository) GetValidRefreshTokenForUpdate(ctx context.Context, tokenHash string) (*domain.RefreshToken, error) {
 	var refreshToken *domain.RefreshToken
-	if err := exec(u.dbRead, u.tx).WithContext(ctx).Where("token = ? AND expires_at > ?", token, time.Now()).First(&refreshToken).Error; err != nil {
-		return nil, err
+	if err := exec(u.dbWrite, u.tx).WithContext(ctx).
+		Clauses(clause.Locking{Strength: "UPDATE"}).
+		Where("token_hash = ? AND expires_at > ?", tokenHash, time.Now()).
+		First(&refreshToken).Error; err != nil {
+		switch {
+		case errors.Is(err, gorm.ErrRecordNotFound):
+			return nil, ErrNotFound
+		default:
+			return nil, err
+		}
 	}
 	return refreshToken, nil
 }
 
-func (u *userRepository) DeleteRefreshToken(ctx context.Context, token string) error {
-	return exec(u.dbWrite, u.tx).WithContext(ctx).Where("token = ?", token).Delete(&domain.RefreshToken{}).Error
+// GetValidRefreshTokensByUserId returns the unexpired sessions of the user,
+// most recently used first.
+func (u *userRepository) GetValidRefreshTokensByUserId(ctx context.Context, userId uint) ([]domain.RefreshToken, error) {
+	var refreshTokens []domain.RefreshToken
+	if err := exec(u.dbRead, u.tx).WithContext(ctx).
+		Where("user_id = ? AND expires_at > ?", userId, time.Now()).
+		Order("COALESCE(last_used_at, created_at) DESC").
+		Find(&refreshTokens).Error; err != nil {
+		return nil, err
+	}
+	return refreshTokens, nil
+}
+
+func (u *userRepository) UpdateRefreshToken(ctx context.Context, token *domain.RefreshToken) error {
+	return exec(u.dbWrite, u.tx).WithContext(ctx).Save(token).Error
+}
+
+func (u *userRepository) DeleteRefreshToken(ctx context.Context, tokenHash string) error {
+	return exec(u.dbWrite, u.tx).WithContext(ctx).Where("token_hash = ?", tokenHash).Delete(&domain.RefreshToken{}).Error
 }
 
 func (u *userRepository) DeleteRefreshTokenById(ctx context.Context, id uint) error {
 	return exec(u.dbWrite, u.tx).WithContext(ctx).Delete(&domain.RefreshToken{}, id).Error
 }
 
+func (u *userRepository) DeleteRefreshTokensByUserId(ctx context.Context, userId uint) error {
+	return exec(u.dbWrite, u.tx).WithContext(ctx).Where("user_id = ?", userId).Delete(&domain.RefreshToken{}).Error
+}
+
+// DeleteUserRefreshTokenById deletes one session of the user, returning
+// ErrNotFound when the user has no session with id.
+func (u *userRepository) DeleteUserRefreshTokenById(ctx context.Context, userId, id uint) error {
+	result := exec(u.dbWrite, u.tx).WithContext(ctx).Where("id = ? AND user_id = ?", id, userId).Delete(&domain.RefreshToken{})
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
+
+func (u *userRepository) CreateRotatedRefreshToken(ctx context.Context, token *domain.RotatedRefreshToken) error {
+	return exec(u.dbWrite, u.tx).WithContext(ctx).Create(token).Error
+}
+
+func (u *userRepository) GetRotatedRefreshToken(ctx context.Context, tokenHash string) (*domain.RotatedRefreshToken, error) {
+	var rotatedToken *domain.RotatedRefreshToken
+	if err := exec(u.dbRead, u.tx).WithContext(ctx).Where("token_hash = ?", tokenHash).First(&rotatedToken).Error; err != nil {
+		switch {
+		case errors.Is(err, gorm.ErrRecordNotFound):
+			return nil, ErrNotFound
+		default:
+			return nil, err
+		}
+	}
+	return rotatedToken, nil
+}
+
+func (u *userRepository) CreatePasswordResetToken(ctx context.Context, token *domain.PasswordResetToken) error {
+	return exec(u.dbWrite, u.tx).WithContext(ctx).Create(token).Error
+}
+
+// GetValidPasswordResetTokenForUpdate locks the unused, unexpired reset
+// token with tokenHash. It is meant to be called inside a transaction.
+func (u *userRepository) GetValidPasswordResetTokenForUpdate(ctx context.Context, tokenHash string) (*domain.PasswordResetToken, error) {
+	var token *domain.PasswordResetToken
+	if err := exec(u.dbWrite, u.tx).WithContext(ctx).
+		Clauses(clause.Locking{Strength: "UPDATE"}).
+		Where("token_hash = ? AND used_at IS NULL AND expires_at > ?", tokenHash, time.Now()).
+		First(&token).Error; err != nil {
+		switch {
+		case errors.Is(err, gorm.ErrRecordNotFound):
+			return nil, ErrNotFound
+		default:
+			return nil, err
+		}
+	}
+	return token, nil
+}
+
+// UsePasswordResetTokens marks every unused reset token of the user as
+// used, so a reset link stops working once any of them was used.
+func (u *userRepository) UsePasswordResetTokens(ctx context.Context, userId uint, usedAt time.Time) error {
+	return exec(u.dbWrite, u.tx).WithContext(ctx).Model(&domain.PasswordResetToken{}).
+		Where("user_id = ? AND used_at IS NULL", userId).
+		Update("used_at", usedAt).Error
+}
+
+// ReplaceRecoveryCodes deletes the recovery codes of the user and stores
+// codes instead. It is meant to be called inside a transaction.
+func (u *userRepository) ReplaceRecoveryCodes(ctx context.Context, userId uint, codes []domain.RecoveryCode) error {
+	if err := u.DeleteRecoveryCodes(ctx, userId); err != nil {
+		return err
+	}
+	return exec(u.dbWrite, u.tx).WithContext(ctx).Create(&codes).Error
+}
+
+func (u *userRepository) DeleteRecoveryCodes(ctx context.Context, userId uint) error {
+	return exec(u.dbWrite, u.tx).WithContext(ctx).Where("user_id = ?", userId).Delete(&domain.RecoveryCode{}).Error
+}
+
+// UseRecoveryCode marks the unused recovery code of the user with codeHash
+// as used. It reports whether there was such a code.
+func (u *userRepository) UseRecoveryCode(ctx context.Context, userId uint, codeHash string, usedAt time.Time) (bool, error) {
+	result := exec(u.dbWrite, u.tx).WithContext(ctx).Model(&domain.RecoveryCode{}).
+		Where("user_id = ? AND code_hash = ? AND used_at IS NULL", userId, codeHash).
+		Update("used_at", usedAt)
+	if result.Error != nil {
+		return false, result.Error
+	}
+	return result.RowsAffected == 1, nil
+}
+
+func (u *userRepository) CreateUserIdentity(ctx context.Context, identity *domain.UserIdentity) error {
+	return exec(u.dbWrite, u.tx).WithContext(ctx).Create(identity).Error
+}
+
+func (u *userRepository) GetUserIdentity(ctx context.Context, provider, subject string) (*domain.UserIdentity, error) {
+	var identity *domain.UserIdentity
+	if err := exec(u.dbRead, u.tx).WithContext(ctx).Where("provider = ? AND subject = ?", provider, subject).First(&identity).Error; err != nil {
+		switch {
+		case errors.Is(err, gorm.ErrRecordNotFound):
+			return nil, ErrNotFound
+		default:
+			return nil, err
+		}
+	}
+	return identity, nil
+}
+
 func (u *userRepository) WithTx(tx *gorm.DB) UserRepository {
 	return &userRepository{
 		dbWrite: u.dbWrite,
@@ -109,6 +380,12 @@ func (u *userRepository) WithTx(tx *gorm.DB) UserRepository {
 	}
 }
 
+// escapeLike escapes the wildcards of a LIKE pattern, so s only matches
+// itself.
+func escapeLike(s string) string {
+	return strings.NewReplacer(`\`, `\\`, "%", `\%`, "_", `\_`).Replace(s)
+}
+
 func NewUserRepository(dbWrite, dbRead *gorm.DB) UserRepository {
 	return &userRepository{
 		dbWrite: dbWrite,
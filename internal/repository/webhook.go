@@ -0,0 +1,183 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/goccy/go-json"
+	"github.com/saleh-ghazimoradi/Cartopher/internal/domain"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type WebhookRepository interface {
+	CreateEndpoint(ctx context.Context, endpoint *domain.WebhookEndpoint) error
+	GetEndpointById(ctx context.Context, id uint) (*domain.WebhookEndpoint, error)
+	GetEndpoints(ctx context.Context) ([]domain.WebhookEndpoint, error)
+	GetActiveEndpointsByEventType(ctx context.Context, eventType string) ([]domain.WebhookEndpoint, error)
+	UpdateEndpoint(ctx context.Context, endpoint *domain.WebhookEndpoint) error
+	DeleteEndpoint(ctx context.Context, id uint) error
+	CreateDeliveries(ctx context.Context, deliveries []domain.WebhookDelivery) error
+	GetDeliveryById(ctx context.Context, id uint) (*domain.WebhookDelivery, error)
+	GetDeliveries(ctx context.Context, endpointId uint, offset, limit int) ([]domain.WebhookDelivery, error)
+	CountDeliveries(ctx context.Context, endpointId uint) (int64, error)
+	GetDueDeliveriesForUpdate(ctx context.Context, limit int) ([]domain.WebhookDelivery, error)
+	GetDeliveryForUpdate(ctx context.Context, id uint) (*domain.WebhookDelivery, error)
+	UpdateDelivery(ctx context.Context, delivery *domain.WebhookDelivery) error
+	CreateAttempt(ctx context.Context, attempt *domain.WebhookDeliveryAttempt) error
+	WithTx(tx *gorm.DB) WebhookRepository
+}
+
+type webhookRepository struct {
+	dbWrite *gorm.DB
+	dbRead  *gorm.DB
+	tx      *gorm.DB
+}
+
+func (w *webhookRepository) CreateEndpoint(ctx context.Context, endpoint *domain.WebhookEndpoint) error {
+	return exec(w.dbWrite, w.tx).WithContext(ctx).Create(endpoint).Error
+}
+
+func (w *webhookRepository) GetEndpointById(ctx context.Context, id uint) (*domain.WebhookEndpoint, error) {
+	var endpoint domain.WebhookEndpoint
+	if err := exec(w.dbRead, w.tx).WithContext(ctx).First(&endpoint, id).Error; err != nil {
+		switch {
+		case errors.Is(err, gorm.ErrRecordNotFound):
+			return nil, ErrNotFound
+		default:
+			return nil, err
+		}
+	}
+	return &endpoint, nil
+}
+
+func (w *webhookRepository) GetEndpoints(ctx context.Context) ([]domain.WebhookEndpoint, error) {
+	var endpoints []domain.WebhookEndpoint
+	if err := exec(w.dbRead, w.tx).WithContext(ctx).Order("id ASC").Find(&endpoints).Error; err != nil {
+		return nil, err
+	}
+	return endpoints, nil
+}
+
+func (w *webhookRepository) GetActiveEndpointsByEventType(ctx context.Context, eventType string) ([]domain.WebhookEndpoint, error) {
+	eventTypes, err := json.Marshal([]string{eventType})
+	if err != nil {
+		return nil, err
+	}
+
+	var endpoints []domain.WebhookEndpoint
+	if err := exec(w.dbRead, w.tx).WithContext(ctx).Where("is_active = ? AND event_types @> ?::jsonb", true, string(eventTypes)).Find(&endpoints).Error; err != nil {
+		return nil, err
+	}
+	return endpoints, nil
+}
+
+func (w *webhookRepository) UpdateEndpoint(ctx context.Context, endpoint *domain.WebhookEndpoint) error {
+	return exec(w.dbWrite, w.tx).WithContext(ctx).Model(endpoint).Select("url", "description", "event_types", "is_active").Updates(endpoint).Error
+}
+
+func (w *webhookRepository) DeleteEndpoint(ctx context.Context, id uint) error {
+	result := exec(w.dbWrite, w.tx).WithContext(ctx).Delete(&domain.WebhookEndpoint{}, id)
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
+
+func (w *webhookRepository) CreateDeliveries(ctx context.Context, deliveries []domain.WebhookDelivery) error {
+	if len(deliveries) == 0 {
+		return nil
+	}
+	return exec(w.dbWrite, w.tx).WithContext(ctx).Omit(clause.Associations).Create(&deliveries).Error
+}
+
+func (w *webhookRepository) GetDeliveryById(ctx context.Context, id uint) (*domain.WebhookDelivery, error) {
+	var delivery domain.WebhookDelivery
+	if err := exec(w.dbRead, w.tx).WithContext(ctx).Preload("History", func(db *gorm.DB) *gorm.DB {
+		return db.Order("created_at ASC, id ASC")
+	}).First(&delivery, id).Error; err != nil {
+		switch {
+		case errors.Is(err, gorm.ErrRecordNotFound):
+			return nil, ErrNotFound
+		default:
+			return nil, err
+		}
+	}
+	return &delivery, nil
+}
+
+func (w *webhookRepository) GetDeliveries(ctx context.Context, endpointId uint, offset, limit int) ([]domain.WebhookDelivery, error) {
+	var deliveries []domain.WebhookDelivery
+	if err := exec(w.dbRead, w.tx).WithContext(ctx).Where("endpoint_id = ?", endpointId).Order("created_at DESC, id DESC").Offset(offset).Limit(limit).Find(&deliveries).Error; err != nil {
+		return nil, err
+	}
+	return deliveries, nil
+}
+
+func (w *webhookRepository) CountDeliveries(ctx context.Context, endpointId uint) (int64, error) {
+	var count int64
+	if err := exec(w.dbRead, w.tx).WithContext(ctx).Model(&domain.WebhookDelivery{}).Where("endpoint_id = ?", endpointId).Count(&count).Error; err != nil {
+		return 0, err
+	}
+	return count, nil
+}
+
+// GetDueDeliveriesForUpdate locks the oldest pending deliveries that are due
+// and whose endpoint is active, skipping rows another dispatcher already
+// holds. It is meant to be called inside a transaction.
+func (w *webhookRepository) GetDueDeliveriesForUpdate(ctx context.Context, limit int) ([]domain.WebhookDelivery, error) {
+	var deliveries []domain.WebhookDelivery
+	if err := exec(w.dbWrite, w.tx).WithContext(ctx).
+		Clauses(clause.Locking{Strength: "UPDATE", Table: clause.Table{Name: "webhook_deliveries"}, Options: "SKIP LOCKED"}).
+		Joins("JOIN webhook_endpoints ON webhook_endpoints.id = webhook_deliveries.endpoint_id").
+		Preload("Endpoint").
+		Where("webhook_deliveries.status = ? AND webhook_deliveries.next_attempt_at <= ? AND webhook_endpoints.is_active = ?", domain.WebhookDeliveryStatusPending, time.Now(), true).
+		Order("webhook_deliveries.next_attempt_at ASC, webhook_deliveries.id ASC").
+		Limit(limit).
+		Find(&deliveries).Error; err != nil {
+		return nil, err
+	}
+	return deliveries, nil
+}
+
+// GetDeliveryForUpdate locks the delivery row. It is meant to be called
+// inside a transaction.
+func (w *webhookRepository) GetDeliveryForUpdate(ctx context.Context, id uint) (*domain.WebhookDelivery, error) {
+	var delivery domain.WebhookDelivery
+	if err := exec(w.dbWrite, w.tx).WithContext(ctx).Clauses(clause.Locking{Strength: "UPDATE"}).Preload("Endpoint").First(&delivery, id).Error; err != nil {
+		switch {
+		case errors.Is(err, gorm.ErrRecordNotFound):
+			return nil, ErrNotFound
+		default:
+			return nil, err
+		}
+	}
+	return &delivery, nil
+}
+
+func (w *webhookRepository) UpdateDelivery(ctx context.Context, delivery *domain.WebhookDelivery) error {
+	return exec(w.dbWrite, w.tx).WithContext(ctx).Model(delivery).Select("status", "attempts", "last_status_code", "last_error", "next_attempt_at", "delivered_at").Updates(delivery).Error
+}
+
+func (w *webhookRepository) CreateAttempt(ctx context.Context, attempt *domain.WebhookDeliveryAttempt) error {
+	return exec(w.dbWrite, w.tx).WithContext(ctx).Create(attempt).Error
+}
+
+func (w *webhookRepository) WithTx(tx *gorm.DB) WebhookRepository {
+	return &webhookRepository{
+		dbWrite: w.dbWrite,
+		dbRead:  w.dbRead,
+		tx:      tx,
+	}
+}
+
+func NewWebhookRepository(dbWrite, dbRead *gorm.DB) WebhookRepository {
+	return &webhookRepository{
+		dbWrite: dbWrite,
+		dbRead:  dbRead,
+	}
+}
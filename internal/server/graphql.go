@@ -1,23 +1,43 @@
 package server
 
 import (
+	"net/http"
+	"time"
+
 	"github.com/99designs/gqlgen/graphql/handler"
 	"github.com/99designs/gqlgen/graphql/handler/extension"
 	"github.com/99designs/gqlgen/graphql/handler/lru"
 	"github.com/99designs/gqlgen/graphql/handler/transport"
+	"github.com/gorilla/websocket"
 	"github.com/saleh-ghazimoradi/Cartopher/graph"
 	"github.com/saleh-ghazimoradi/Cartopher/graph/resolver"
 	"github.com/vektah/gqlparser/v2/ast"
 )
 
 type Graphql struct {
-	resolver *resolver.Resolver
+	resolver      *resolver.Resolver
+	websocketInit transport.WebsocketInitFunc
 }
 
 func (g *Graphql) Connect() *handler.Server {
-	schema := graph.NewExecutableSchema(graph.Config{Resolvers: g.resolver})
+	schema := graph.NewExecutableSchema(graph.Config{
+		Resolvers: g.resolver,
+		Directives: graph.DirectiveRoot{
+			HasPermission: resolver.HasPermission,
+		},
+	})
 	srv := handler.New(schema)
 
+	srv.AddTransport(transport.Websocket{
+		KeepAlivePingInterval: 10 * time.Second,
+		Upgrader: websocket.Upgrader{
+			// CORS allows every origin, so does the upgrade.
+			CheckOrigin: func(r *http.Request) bool {
+				return true
+			},
+		},
+		InitFunc: g.websocketInit,
+	})
 	srv.AddTransport(transport.Options{})
 	srv.AddTransport(transport.GET{})
 	srv.AddTransport(transport.POST{})
@@ -32,8 +52,11 @@ func (g *Graphql) Connect() *handler.Server {
 	return srv
 }
 
-func NewGraphql(resolver *resolver.Resolver) *Graphql {
+// NewGraphql creates the GraphQL server. websocketInit authenticates
+// subscriptions when the websocket connection is initialised.
+func NewGraphql(resolver *resolver.Resolver, websocketInit transport.WebsocketInitFunc) *Graphql {
 	return &Graphql{
-		resolver: resolver,
+		resolver:      resolver,
+		websocketInit: websocketInit,
 	}
 }
@@ -0,0 +1,383 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/saleh-ghazimoradi/Cartopher/infra/cache"
+	"github.com/saleh-ghazimoradi/Cartopher/internal/domain"
+	"github.com/saleh-ghazimoradi/Cartopher/internal/dto"
+	"github.com/saleh-ghazimoradi/Cartopher/internal/helper"
+	"github.com/saleh-ghazimoradi/Cartopher/internal/repository"
+	"github.com/saleh-ghazimoradi/Cartopher/utils"
+	"gorm.io/gorm"
+)
+
+var (
+	ErrUserAlreadyInactive = errors.New("the user is already deactivated")
+	ErrUserAlreadyActive   = errors.New("the user is already active")
+)
+
+// AdminUserService lets staff manage the accounts of users. Every change is
+// recorded in the audit log of the user with the staff member, the actor,
+// who made it.
+type AdminUserService interface {
+	GetUsers(ctx context.Context, req *dto.GetUsersRequest) ([]*dto.UserResponse, *helper.PaginatedMeta, error)
+	GetUser(ctx context.Context, id uint) (*dto.UserResponse, error)
+	GetAuditLog(ctx context.Context, id uint, page, limit int) ([]*dto.AuditLogResponse, *helper.PaginatedMeta, error)
+	DeactivateUser(ctx context.Context, actorId, id uint, req *dto.DeactivateUserRequest) (*dto.UserResponse, error)
+	ReactivateUser(ctx context.Context, actorId, id uint) (*dto.UserResponse, error)
+	ChangeRole(ctx context.Context, actorId, id uint, req *dto.AssignRoleRequest) (*dto.UserResponse, error)
+	DeleteUser(ctx context.Context, actorId, id uint) error
+	RevokeSessions(ctx context.Context, actorId, id uint) error
+	UnlockUser(ctx context.Context, actorId, id uint) error
+}
+
+type adminUserService struct {
+	authService     AuthService
+	userRepository  repository.UserRepository
+	roleRepository  repository.RoleRepository
+	auditRepository repository.AuditRepository
+	tokenVersions   cache.TokenVersions
+	db              *gorm.DB
+}
+
+func (a *adminUserService) GetUsers(ctx context.Context, req *dto.GetUsersRequest) ([]*dto.UserResponse, *helper.PaginatedMeta, error) {
+	if req.Page < 1 {
+		req.Page = 1
+	}
+
+	if req.Limit < 1 {
+		req.Limit = 20
+	}
+
+	if req.Limit > 100 {
+		req.Limit = 100
+	}
+
+	users, total, err := a.userRepository.GetUsers(ctx, req, (req.Page-1)*req.Limit, req.Limit)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	response := make([]*dto.UserResponse, len(users))
+	for i := range users {
+		response[i] = toUserResponse(&users[i])
+	}
+
+	meta := &helper.PaginatedMeta{
+		Page:      req.Page,
+		Limit:     req.Limit,
+		Total:     total,
+		TotalPage: int((total + int64(req.Limit) - 1) / int64(req.Limit)),
+	}
+
+	return response, meta, nil
+}
+
+func (a *adminUserService) GetUser(ctx context.Context, id uint) (*dto.UserResponse, error) {
+	user, err := a.userRepository.GetUserById(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	return toUserResponse(user), nil
+}
+
+func (a *adminUserService) GetAuditLog(ctx context.Context, id uint, page, limit int) ([]*dto.AuditLogResponse, *helper.PaginatedMeta, error) {
+	if page < 1 {
+		page = 1
+	}
+
+	if limit < 1 {
+		limit = 20
+	}
+
+	if limit > 100 {
+		limit = 100
+	}
+
+	total, err := a.auditRepository.CountAuditLogsByUserId(ctx, id)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	// Deleted users keep their log, which records the deletion; a user
+	// without entries must still exist.
+	if total == 0 {
+		if _, err := a.userRepository.GetUserById(ctx, id); err != nil {
+			return nil, nil, err
+		}
+	}
+
+	logs, err := a.auditRepository.GetAuditLogsByUserId(ctx, id, (page-1)*limit, limit)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	response := make([]*dto.AuditLogResponse, len(logs))
+	for i := range logs {
+		response[i] = toAuditLogResponse(&logs[i])
+	}
+
+	meta := &helper.PaginatedMeta{
+		Page:      page,
+		Limit:     limit,
+		Total:     total,
+		TotalPage: int((total + int64(limit) - 1) / int64(limit)),
+	}
+
+	return response, meta, nil
+}
+
+// DeactivateUser keeps the user from logging in and ends their sessions.
+// Their access tokens are revoked, so this applies at once.
+func (a *adminUserService) DeactivateUser(ctx context.Context, actorId, id uint, req *dto.DeactivateUserRequest) (*dto.UserResponse, error) {
+	user, err := a.manageableUser(ctx, actorId, id)
+	if err != nil {
+		return nil, err
+	}
+
+	if !user.IsActive {
+		return nil, ErrUserAlreadyInactive
+	}
+
+	err = a.db.Transaction(func(tx *gorm.DB) error {
+		userRepo := a.userRepository.WithTx(tx)
+
+		if err := userRepo.UpdateActive(ctx, user.Id, false); err != nil {
+			return err
+		}
+
+		if err := userRepo.DeleteRefreshTokensByUserId(ctx, user.Id); err != nil {
+			return err
+		}
+
+		return a.record(ctx, a.auditRepository.WithTx(tx), actorId, user.Id, domain.AuditActionUserDeactivated, map[string]string{
+			"reason": req.Reason,
+		})
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if err := a.tokenVersions.Bump(ctx, user.Id); err != nil {
+		return nil, err
+	}
+
+	return a.GetUser(ctx, user.Id)
+}
+
+func (a *adminUserService) ReactivateUser(ctx context.Context, actorId, id uint) (*dto.UserResponse, error) {
+	user, err := a.manageableUser(ctx, actorId, id)
+	if err != nil {
+		return nil, err
+	}
+
+	if user.IsActive {
+		return nil, ErrUserAlreadyActive
+	}
+
+	err = a.db.Transaction(func(tx *gorm.DB) error {
+		if err := a.userRepository.WithTx(tx).UpdateActive(ctx, user.Id, true); err != nil {
+			return err
+		}
+
+		return a.record(ctx, a.auditRepository.WithTx(tx), actorId, user.Id, domain.AuditActionUserReactivated, nil)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return a.GetUser(ctx, user.Id)
+}
+
+// ChangeRole gives the user a role, revoking their access tokens so it
+// applies at once. Staff can only grant roles whose permissions they have
+// themselves.
+func (a *adminUserService) ChangeRole(ctx context.Context, actorId, id uint, req *dto.AssignRoleRequest) (*dto.UserResponse, error) {
+	user, err := a.manageableUser(ctx, actorId, id)
+	if err != nil {
+		return nil, err
+	}
+
+	role, err := a.roleRepository.GetRoleByName(ctx, domain.UserRole(req.Role))
+	if err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			return nil, fmt.Errorf("%w: unknown role %s", ErrInvalidRole, req.Role)
+		}
+		return nil, err
+	}
+
+	if err := a.checkGrantable(ctx, actorId, role.Name); err != nil {
+		return nil, err
+	}
+
+	if user.Role == role.Name {
+		return toUserResponse(user), nil
+	}
+
+	err = a.db.Transaction(func(tx *gorm.DB) error {
+		if err := a.userRepository.WithTx(tx).UpdateRole(ctx, user.Id, role.Name); err != nil {
+			return err
+		}
+
+		return a.record(ctx, a.auditRepository.WithTx(tx), actorId, user.Id, domain.AuditActionUserRoleChanged, map[string]string{
+			"from": string(user.Role),
+			"to":   string(role.Name),
+		})
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if err := a.tokenVersions.Bump(ctx, user.Id); err != nil {
+		return nil, err
+	}
+
+	return a.GetUser(ctx, user.Id)
+}
+
+// DeleteUser soft-deletes the user, ending their sessions. Their orders and
+// audit log are kept.
+func (a *adminUserService) DeleteUser(ctx context.Context, actorId, id uint) error {
+	user, err := a.manageableUser(ctx, actorId, id)
+	if err != nil {
+		return err
+	}
+
+	err = a.db.Transaction(func(tx *gorm.DB) error {
+		userRepo := a.userRepository.WithTx(tx)
+
+		if err := userRepo.DeleteRefreshTokensByUserId(ctx, user.Id); err != nil {
+			return err
+		}
+
+		if err := userRepo.DeleteUser(ctx, user.Id); err != nil {
+			return err
+		}
+
+		return a.record(ctx, a.auditRepository.WithTx(tx), actorId, user.Id, domain.AuditActionUserDeleted, map[string]string{
+			"email": user.Email,
+		})
+	})
+	if err != nil {
+		return err
+	}
+
+	return a.tokenVersions.Bump(ctx, user.Id)
+}
+
+// RevokeSessions logs the user out everywhere. Sessions and lockouts are
+// not kept in a transaction with the audit log, so the action is recorded
+// before it is taken: an entry may stand for a failed attempt, but nothing
+// is done unrecorded.
+func (a *adminUserService) RevokeSessions(ctx context.Context, actorId, id uint) error {
+	user, err := a.manageableUser(ctx, actorId, id)
+	if err != nil {
+		return err
+	}
+
+	if err := a.record(ctx, a.auditRepository, actorId, user.Id, domain.AuditActionUserSessionsRevoked, nil); err != nil {
+		return err
+	}
+
+	return a.authService.RevokeAllSessions(ctx, user.Id)
+}
+
+// UnlockUser lifts the login lockout of the user, recording it first like
+// RevokeSessions.
+func (a *adminUserService) UnlockUser(ctx context.Context, actorId, id uint) error {
+	user, err := a.manageableUser(ctx, actorId, id)
+	if err != nil {
+		return err
+	}
+
+	if err := a.record(ctx, a.auditRepository, actorId, user.Id, domain.AuditActionUserUnlocked, nil); err != nil {
+		return err
+	}
+
+	return a.authService.UnlockLogin(ctx, user.Id)
+}
+
+// manageableUser returns the user with id if the actor may manage them:
+// staff cannot manage their own account, nor users with permissions they
+// do not have.
+func (a *adminUserService) manageableUser(ctx context.Context, actorId, id uint) (*domain.User, error) {
+	if actorId == id {
+		return nil, ErrSelfManagement
+	}
+
+	user, err := a.userRepository.GetUserById(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := a.checkGrantable(ctx, actorId, user.Role); err != nil {
+		return nil, err
+	}
+
+	return user, nil
+}
+
+// checkGrantable returns ErrInsufficientRole unless the actor has every
+// permission role grants.
+func (a *adminUserService) checkGrantable(ctx context.Context, actorId uint, role domain.UserRole) error {
+	_, held, err := actorPermissions(ctx, a.userRepository, a.roleRepository, actorId)
+	if err != nil {
+		return err
+	}
+
+	permissions, err := rolePermissions(ctx, a.roleRepository, role)
+	if err != nil {
+		return err
+	}
+
+	if !holdsAll(held, permissions) {
+		return ErrInsufficientRole
+	}
+	return nil
+}
+
+// record adds an entry to the audit log of the user, noting the client
+// the request came from.
+func (a *adminUserService) record(ctx context.Context, auditRepository repository.AuditRepository, actorId, userId uint, action domain.AuditAction, details map[string]string) error {
+	if details == nil {
+		details = map[string]string{}
+	}
+
+	client := utils.ClientFromContext(ctx)
+	return auditRepository.CreateAuditLog(ctx, &domain.AuditLog{
+		ActorId:   actorId,
+		UserId:    userId,
+		Action:    action,
+		Details:   details,
+		IPAddress: client.IPAddress,
+		UserAgent: client.UserAgent,
+	})
+}
+
+func toAuditLogResponse(log *domain.AuditLog) *dto.AuditLogResponse {
+	return &dto.AuditLogResponse{
+		Id:        log.Id,
+		ActorId:   log.ActorId,
+		UserId:    log.UserId,
+		Action:    string(log.Action),
+		Details:   log.Details,
+		IPAddress: log.IPAddress,
+		UserAgent: log.UserAgent,
+		CreatedAt: log.CreatedAt,
+	}
+}
+
+func NewAdminUserService(authService AuthService, userRepository repository.UserRepository, roleRepository repository.RoleRepository, auditRepository repository.AuditRepository, tokenVersions cache.TokenVersions, db *gorm.DB) AdminUserService {
+	return &adminUserService{
+		authService:     authService,
+		userRepository:  userRepository,
+		roleRepository:  roleRepository,
+		auditRepository: auditRepository,
+		tokenVersions:   tokenVersions,
+		db:              db,
+	}
+}
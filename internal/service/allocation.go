@@ -0,0 +1,236 @@
+package service
+
+import (
+	"fmt"
+	"math"
+	"sort"
+
+	"github.com/saleh-ghazimoradi/Cartopher/internal/domain"
+)
+
+const earthRadiusKm = 6371.0
+
+type allocationLine struct {
+	ProductId uint
+	Name      string
+	Quantity  int
+}
+
+type allocation struct {
+	ProductId   uint
+	WarehouseId uint
+	Quantity    int
+}
+
+// allocate assigns every line to one or more warehouses holding enough stock,
+// using the given strategy to decide which warehouses are preferred. The
+// returned allocations keep the order of the input lines.
+func allocate(strategy domain.FulfilmentStrategy, lines []allocationLine, stocks []domain.WarehouseStock, address domain.Address) ([]allocation, error) {
+	available := make(map[uint]map[uint]int)
+	warehouses := make(map[uint]domain.Warehouse)
+
+	for i := range stocks {
+		stock := &stocks[i]
+		if _, ok := available[stock.ProductId]; !ok {
+			available[stock.ProductId] = make(map[uint]int)
+		}
+		available[stock.ProductId][stock.WarehouseId] += stock.Quantity
+		warehouses[stock.WarehouseId] = stock.Warehouse
+	}
+
+	ranked := rankWarehouses(strategy, warehouses, address)
+
+	perLine := make([][]allocation, len(lines))
+
+	if strategy == domain.FulfilmentStrategyFewestSplits {
+		if err := allocateFewestSplits(lines, ranked, available, perLine); err != nil {
+			return nil, err
+		}
+	} else {
+		for i := range lines {
+			allocations, err := allocateLine(lines[i], ranked, available)
+			if err != nil {
+				return nil, err
+			}
+			perLine[i] = allocations
+		}
+	}
+
+	var result []allocation
+	for i := range perLine {
+		result = append(result, perLine[i]...)
+	}
+
+	return result, nil
+}
+
+// allocateLine fills a single line from the warehouses in the given order,
+// splitting it when the first warehouse cannot cover the whole quantity.
+func allocateLine(line allocationLine, ranked []domain.Warehouse, available map[uint]map[uint]int) ([]allocation, error) {
+	var allocations []allocation
+	remaining := line.Quantity
+
+	for _, warehouse := range ranked {
+		if remaining == 0 {
+			break
+		}
+
+		qty := min(remaining, available[line.ProductId][warehouse.Id])
+		if qty <= 0 {
+			continue
+		}
+
+		available[line.ProductId][warehouse.Id] -= qty
+		remaining -= qty
+		allocations = append(allocations, allocation{
+			ProductId:   line.ProductId,
+			WarehouseId: warehouse.Id,
+			Quantity:    qty,
+		})
+	}
+
+	if remaining > 0 {
+		return nil, fmt.Errorf("not enough stock for product: %s", line.Name)
+	}
+
+	return allocations, nil
+}
+
+// allocateFewestSplits repeatedly picks the warehouse able to ship the most
+// remaining lines in full, so the order leaves from as few locations as
+// possible. Lines no single warehouse can cover are split, drawing from the
+// largest holdings first.
+func allocateFewestSplits(lines []allocationLine, ranked []domain.Warehouse, available map[uint]map[uint]int, perLine [][]allocation) error {
+	pending := make([]int, len(lines))
+	for i := range lines {
+		pending[i] = i
+	}
+
+	for len(pending) > 0 {
+		var best *domain.Warehouse
+		bestLines, bestUnits := 0, 0
+
+		for i := range ranked {
+			warehouse := &ranked[i]
+			count, units := 0, 0
+			for _, idx := range pending {
+				if available[lines[idx].ProductId][warehouse.Id] >= lines[idx].Quantity {
+					count++
+					units += lines[idx].Quantity
+				}
+			}
+			if count > bestLines || (count == bestLines && units > bestUnits) {
+				best = warehouse
+				bestLines, bestUnits = count, units
+			}
+		}
+
+		if best == nil || bestLines == 0 {
+			break
+		}
+
+		var next []int
+		for _, idx := range pending {
+			line := lines[idx]
+			if available[line.ProductId][best.Id] < line.Quantity {
+				next = append(next, idx)
+				continue
+			}
+			available[line.ProductId][best.Id] -= line.Quantity
+			perLine[idx] = []allocation{{
+				ProductId:   line.ProductId,
+				WarehouseId: best.Id,
+				Quantity:    line.Quantity,
+			}}
+		}
+		pending = next
+	}
+
+	for _, idx := range pending {
+		line := lines[idx]
+		byHolding := make([]domain.Warehouse, len(ranked))
+		copy(byHolding, ranked)
+		sort.SliceStable(byHolding, func(a, b int) bool {
+			return available[line.ProductId][byHolding[a].Id] > available[line.ProductId][byHolding[b].Id]
+		})
+
+		allocations, err := allocateLine(line, byHolding, available)
+		if err != nil {
+			return err
+		}
+		perLine[idx] = allocations
+	}
+
+	return nil
+}
+
+func rankWarehouses(strategy domain.FulfilmentStrategy, warehouses map[uint]domain.Warehouse, address domain.Address) []domain.Warehouse {
+	ranked := make([]domain.Warehouse, 0, len(warehouses))
+	for _, warehouse := range warehouses {
+		ranked = append(ranked, warehouse)
+	}
+
+	byPriority := func(a, b domain.Warehouse) bool {
+		if a.Priority != b.Priority {
+			return a.Priority < b.Priority
+		}
+		return a.Id < b.Id
+	}
+
+	if strategy != domain.FulfilmentStrategyNearest {
+		sort.Slice(ranked, func(i, j int) bool {
+			return byPriority(ranked[i], ranked[j])
+		})
+		return ranked
+	}
+
+	sort.Slice(ranked, func(i, j int) bool {
+		a, b := ranked[i], ranked[j]
+		distA, okA := distanceKm(address, a)
+		distB, okB := distanceKm(address, b)
+
+		switch {
+		case okA && okB && distA != distB:
+			return distA < distB
+		case okA != okB:
+			return okA
+		}
+
+		sameA := address.Country != "" && a.Country == address.Country
+		sameB := address.Country != "" && b.Country == address.Country
+		if sameA != sameB {
+			return sameA
+		}
+
+		return byPriority(a, b)
+	})
+
+	return ranked
+}
+
+// distanceKm returns the great-circle distance between the address and the
+// warehouse, or false when either side has no coordinates.
+func distanceKm(address domain.Address, warehouse domain.Warehouse) (float64, bool) {
+	if address.Latitude == nil || address.Longitude == nil || warehouse.Latitude == nil || warehouse.Longitude == nil {
+		return 0, false
+	}
+
+	toRad := func(deg float64) float64 { return deg * math.Pi / 180 }
+
+	lat1, lat2 := toRad(*address.Latitude), toRad(*warehouse.Latitude)
+	dLat := lat2 - lat1
+	dLon := toRad(*warehouse.Longitude - *address.Longitude)
+
+	h := math.Sin(dLat/2)*math.Sin(dLat/2) + math.Cos(lat1)*math.Cos(lat2)*math.Sin(dLon/2)*math.Sin(dLon/2)
+
+	return 2 * earthRadiusKm * math.Asin(math.Sqrt(h)), true
+}
+
+func parseFulfilmentStrategy(value string) domain.FulfilmentStrategy {
+	switch strategy := domain.FulfilmentStrategy(value); strategy {
+	case domain.FulfilmentStrategyNearest, domain.FulfilmentStrategyFewestSplits:
+		return strategy
+	default:
+		return domain.FulfilmentStrategyPriority
+	}
+}
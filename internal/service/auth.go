@@ -1,17 +1,57 @@
 package service
 
 import (
+	"bytes"
 	"context"
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/hex"
 	"errors"
 	"fmt"
+	"image/png"
+	"net/url"
+	"strings"
 	"time"
 
+	"github.com/pquerna/otp/totp"
 	"github.com/saleh-ghazimoradi/Cartopher/config"
+	"github.com/saleh-ghazimoradi/Cartopher/infra/cache"
 	"github.com/saleh-ghazimoradi/Cartopher/infra/events"
 	"github.com/saleh-ghazimoradi/Cartopher/internal/domain"
 	"github.com/saleh-ghazimoradi/Cartopher/internal/dto"
+	"github.com/saleh-ghazimoradi/Cartopher/internal/helper"
 	"github.com/saleh-ghazimoradi/Cartopher/internal/repository"
 	"github.com/saleh-ghazimoradi/Cartopher/utils"
+	"gorm.io/gorm"
+)
+
+const (
+	defaultVerificationTokenExpires   = 24 * time.Hour
+	defaultVerificationResendInterval = time.Minute
+	defaultPasswordResetTokenExpires  = time.Hour
+	defaultTwoFactorIssuer            = "Cartopher"
+	defaultTwoFactorChallengeExpires  = 5 * time.Minute
+
+	emailVerificationPurpose  = "email_verification"
+	twoFactorChallengePurpose = "two_factor_challenge"
+
+	recoveryCodeCount = 10
+	qrCodeSize        = 256
+
+	// Reset emails are sent at most once a minute per address and a few
+	// times a minute per client.
+	passwordResetsPerEmail   = 1
+	passwordResetsPerIP      = 10
+	passwordResetEmailPrefix = "password_reset:email:"
+	passwordResetIPPrefix    = "password_reset:ip:"
+)
+
+var (
+	ErrEmailNotVerified       = errors.New("email address is not verified")
+	ErrInvalidTwoFactorCode   = errors.New("invalid two-factor authentication code")
+	ErrPasswordResetThrottled = errors.New("too many password reset requests, please try again later")
+
+	errUnknownRefreshToken = errors.New("refresh token not found or expired")
 )
 
 type AuthService interface {
@@ -19,13 +59,43 @@ type AuthService interface {
 	Login(ctx context.Context, req *dto.LoginRequest) (*dto.AuthResponse, error)
 	RefreshToken(ctx context.Context, req *dto.RefreshTokenRequest) (*dto.AuthResponse, error)
 	Logout(ctx context.Context, refreshToken string) error
+	VerifyEmail(ctx context.Context, req *dto.VerifyEmailRequest) (*dto.UserResponse, error)
+	ResendVerification(ctx context.Context, req *dto.ResendVerificationRequest) error
+	ForgotPassword(ctx context.Context, req *dto.ForgotPasswordRequest) error
+	ResetPassword(ctx context.Context, req *dto.ResetPasswordRequest) error
+	VerifyTwoFactor(ctx context.Context, req *dto.TwoFactorLoginRequest) (*dto.AuthResponse, error)
+	EnrolTwoFactor(ctx context.Context, userId uint) (*dto.TwoFactorEnrolmentResponse, error)
+	ConfirmTwoFactor(ctx context.Context, userId uint, req *dto.TwoFactorCodeRequest) (*dto.RecoveryCodesResponse, error)
+	DisableTwoFactor(ctx context.Context, userId uint, req *dto.TwoFactorCodeRequest) error
+	GetSessions(ctx context.Context, userId uint) ([]dto.SessionResponse, error)
+	RevokeSession(ctx context.Context, userId, sessionId uint) error
+	RevokeAllSessions(ctx context.Context, userId uint) error
+	JWKS() *utils.JWKS
+	UnlockLogin(ctx context.Context, userId uint) error
+	OIDCProviders() []string
+	AuthorizeOIDC(ctx context.Context, provider string) (*dto.OIDCAuthorizationResponse, error)
+	LoginWithOIDC(ctx context.Context, provider string, req *dto.OIDCLoginRequest) (*dto.AuthResponse, error)
 }
 
 type authService struct {
-	cfg            *config.Config
-	eventPublisher events.Publisher
-	userRepository repository.UserRepository
-	cartRepository repository.CartRepository
+	cfg                        *config.Config
+	keys                       *utils.KeySet
+	requireVerifiedEmail       domain.EmailVerificationRequirement
+	verificationTokenExpires   time.Duration
+	verificationResendInterval time.Duration
+	passwordResetTokenExpires  time.Duration
+	twoFactorIssuer            string
+	twoFactorChallengeExpires  time.Duration
+	userRepository             repository.UserRepository
+	cartRepository             repository.CartRepository
+	outboxRepository           repository.OutboxRepository
+	roleRepository             repository.RoleRepository
+	tokenVersions              cache.TokenVersions
+	rateLimiter                helper.RateLimiter
+	cache                      cache.Cache
+	loginThrottle              *loginThrottle
+	oidcProviders              *oidcProviders
+	db                         *gorm.DB
 }
 
 func (a *authService) Register(ctx context.Context, req *dto.RegisterRequest) (*dto.AuthResponse, error) {
@@ -47,106 +117,846 @@ func (a *authService) Register(ctx context.Context, req *dto.RegisterRequest) (*
 		Role:      domain.UserRoleCustomer,
 	}
 
-	if err := a.userRepository.CreateUser(ctx, user); err != nil {
+	err = a.db.Transaction(func(tx *gorm.DB) error {
+		if err := a.userRepository.WithTx(tx).CreateUser(ctx, user); err != nil {
+			return err
+		}
+
+		cart := &domain.Cart{UserId: user.Id}
+		if err := a.cartRepository.WithTx(tx).CreateCart(ctx, cart); err != nil {
+			return err
+		}
+
+		return a.sendVerification(ctx, tx, user)
+	})
+	if err != nil {
 		return nil, err
 	}
 
-	cart := &domain.Cart{UserId: user.Id}
-	if err := a.cartRepository.CreateCart(ctx, cart); err != nil {
-		return nil, err
+	// Users who may not log in before verifying do not get tokens yet.
+	if a.requireVerifiedEmail == domain.EmailVerificationForLogin {
+		return &dto.AuthResponse{User: *toUserResponse(user)}, nil
 	}
 
-	return a.generateAuthResponse(ctx, user)
+	return a.generateAuthResponse(ctx, user, false)
 
 }
 
 func (a *authService) Login(ctx context.Context, req *dto.LoginRequest) (*dto.AuthResponse, error) {
+	attempt, err := a.loginThrottle.attempt(ctx, req.Email, utils.ClientFromContext(ctx).IPAddress)
+	if err != nil {
+		return nil, err
+	}
+
 	user, err := a.userRepository.GetUserByEmailAndActive(ctx, req.Email, true)
 	if err != nil {
+		if err := a.loginFailed(ctx, attempt, nil); err != nil {
+			return nil, err
+		}
 		return nil, errors.New("invalid credentials")
 	}
 
 	if !utils.CheckPassword(req.Password, user.Password) {
+		if err := a.loginFailed(ctx, attempt, user); err != nil {
+			return nil, err
+		}
 		return nil, errors.New("invalid credentials")
 	}
 
-	return a.generateAuthResponse(ctx, user)
+	// The attempts of users with two-factor authentication, this one
+	// included, are only forgotten once they pass it, so a known password
+	// cannot be used to retry codes.
+	if user.TOTPEnabledAt == nil {
+		if err := a.loginThrottle.reset(ctx, user.Email); err != nil {
+			return nil, err
+		}
+	}
+
+	if a.requireVerifiedEmail == domain.EmailVerificationForLogin && user.EmailVerifiedAt == nil {
+		return nil, ErrEmailNotVerified
+	}
+
+	// Users with two-factor authentication get their tokens from
+	// VerifyTwoFactor once they prove they hold the second factor.
+	if user.TOTPEnabledAt != nil {
+		return a.twoFactorChallenge(user)
+	}
+
+	return a.generateAuthResponse(ctx, user, false)
+}
+
+// twoFactorChallenge answers a login of a user with two-factor
+// authentication with the token VerifyTwoFactor takes with their code.
+func (a *authService) twoFactorChallenge(user *domain.User) (*dto.AuthResponse, error) {
+	challengeToken, err := utils.GeneratePurposeToken(a.keys, twoFactorChallengePurpose, user.Id, user.Email, a.twoFactorChallengeExpires)
+	if err != nil {
+		return nil, err
+	}
+
+	return &dto.AuthResponse{
+		User:              *toUserResponse(user),
+		TwoFactorRequired: true,
+		ChallengeToken:    challengeToken,
+	}, nil
 }
 
+// loginFailed answers a failed login attempt. The owner of an account it
+// locks is notified; user is nil for an unknown email.
+func (a *authService) loginFailed(ctx context.Context, attempt *loginAttempt, user *domain.User) error {
+	client := utils.ClientFromContext(ctx)
+
+	lockedUntil, err := a.loginThrottle.fail(ctx, attempt)
+	if err != nil || lockedUntil == nil || user == nil {
+		return err
+	}
+
+	return enqueueNotification(ctx, a.outboxRepository, events.AccountLocked, &events.AccountLockedV1{
+		UserId:      user.Id,
+		Email:       user.Email,
+		FirstName:   user.FirstName,
+		LastName:    user.LastName,
+		UserAgent:   client.UserAgent,
+		IPAddress:   client.IPAddress,
+		LockedUntil: *lockedUntil,
+	})
+}
+
+// RefreshToken rotates a refresh token. A token that was already rotated
+// has leaked: presenting it revokes its whole family, logging out whoever
+// holds the current token too, and publishes a security event.
 func (a *authService) RefreshToken(ctx context.Context, req *dto.RefreshTokenRequest) (*dto.AuthResponse, error) {
-	claim, err := utils.ValidateToken(req.RefreshToken, a.cfg.JWT.Secret)
+	claim, err := utils.ValidateToken(req.RefreshToken, a.keys, utils.TokenTypeRefresh)
 	if err != nil {
 		return nil, errors.New("invalid refresh token")
 	}
 
-	refreshToken, err := a.userRepository.GetValidRefreshToken(ctx, req.RefreshToken)
+	user, err := a.userRepository.GetUserById(ctx, claim.UserId)
 	if err != nil {
-		return nil, errors.New("refresh token not found or expired")
+		return nil, err
 	}
 
-	user, err := a.userRepository.GetUserById(ctx, claim.UserId)
+	if !user.IsActive {
+		return nil, errors.New("invalid refresh token")
+	}
+
+	version, err := a.tokenVersions.Get(ctx, user.Id)
+	if err != nil {
+		return nil, err
+	}
+
+	// The role is read again, so a role change applies from the next refresh.
+	permissions, err := rolePermissions(ctx, a.roleRepository, user.Role)
 	if err != nil {
 		return nil, err
 	}
 
-	if err := a.userRepository.DeleteRefreshTokenById(ctx, refreshToken.Id); err != nil {
+	accessToken, newRefreshToken, err := utils.GenerateToken(a.cfg, a.keys, user.Id, user.Email, string(user.Role), permissions, claim.TwoFactor, version)
+	if err != nil {
+		return nil, err
+	}
+
+	tokenHash := utils.HashToken(req.RefreshToken)
+	now := time.Now()
+	client := utils.ClientFromContext(ctx)
+
+	err = a.db.Transaction(func(tx *gorm.DB) error {
+		userRepo := a.userRepository.WithTx(tx)
+
+		refreshToken, err := userRepo.GetValidRefreshTokenForUpdate(ctx, tokenHash)
+		if err != nil {
+			if errors.Is(err, repository.ErrNotFound) {
+				return errUnknownRefreshToken
+			}
+			return err
+		}
+
+		if err := userRepo.CreateRotatedRefreshToken(ctx, &domain.RotatedRefreshToken{
+			RefreshTokenId: refreshToken.Id,
+			TokenHash:      tokenHash,
+			RotatedAt:      now,
+		}); err != nil {
+			return err
+		}
+
+		// Rotate the token in place, so the session keeps its id and start.
+		refreshToken.TokenHash = utils.HashToken(newRefreshToken)
+		refreshToken.ExpiresAt = now.Add(a.cfg.JWT.RefreshTokenExpires)
+		refreshToken.LastUsedAt = &now
+		refreshToken.UserAgent = client.UserAgent
+		refreshToken.IPAddress = client.IPAddress
+
+		return userRepo.UpdateRefreshToken(ctx, refreshToken)
+	})
+	if err != nil {
+		if errors.Is(err, errUnknownRefreshToken) {
+			if err := a.revokeReusedFamily(ctx, user, tokenHash); err != nil {
+				return nil, err
+			}
+		}
 		return nil, err
 	}
 
-	return a.generateAuthResponse(ctx, user)
+	return &dto.AuthResponse{
+		User:         *toUserResponse(user),
+		AccessToken:  accessToken,
+		RefreshToken: newRefreshToken,
+	}, nil
 }
 
+// revokeReusedFamily revokes the token family a rotated token with
+// tokenHash belonged to, if any and if it is still active.
+func (a *authService) revokeReusedFamily(ctx context.Context, user *domain.User, tokenHash string) error {
+	rotatedToken, err := a.userRepository.GetRotatedRefreshToken(ctx, tokenHash)
+	if err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			return nil
+		}
+		return err
+	}
+
+	client := utils.ClientFromContext(ctx)
+
+	err = a.db.Transaction(func(tx *gorm.DB) error {
+		if err := a.userRepository.WithTx(tx).DeleteUserRefreshTokenById(ctx, user.Id, rotatedToken.RefreshTokenId); err != nil {
+			return err
+		}
+
+		return enqueueNotification(ctx, a.outboxRepository.WithTx(tx), events.RefreshTokenReused, &events.RefreshTokenReusedV1{
+			UserId:    user.Id,
+			Email:     user.Email,
+			FirstName: user.FirstName,
+			LastName:  user.LastName,
+			SessionId: rotatedToken.RefreshTokenId,
+			UserAgent: client.UserAgent,
+			IPAddress: client.IPAddress,
+		})
+	})
+	if errors.Is(err, repository.ErrNotFound) {
+		// The family was already revoked.
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	return a.tokenVersions.Bump(ctx, user.Id)
+}
+
+// Logout deletes the session of the refresh token and revokes the access
+// tokens of the user. Their other sessions get new access tokens on their
+// next refresh.
 func (a *authService) Logout(ctx context.Context, refreshToken string) error {
-	return a.userRepository.DeleteRefreshToken(ctx, refreshToken)
+	if err := a.userRepository.DeleteRefreshToken(ctx, utils.HashToken(refreshToken)); err != nil {
+		return err
+	}
+
+	claims, err := utils.ValidateToken(refreshToken, a.keys, utils.TokenTypeRefresh)
+	if err != nil {
+		return nil
+	}
+
+	return a.tokenVersions.Bump(ctx, claims.UserId)
 }
 
-func (a *authService) generateAuthResponse(ctx context.Context, user *domain.User) (*dto.AuthResponse, error) {
+// VerifyEmail marks the address a verification token was issued for as
+// verified. Tokens issued before the user changed their address are
+// rejected.
+func (a *authService) VerifyEmail(ctx context.Context, req *dto.VerifyEmailRequest) (*dto.UserResponse, error) {
+	claims, err := utils.ValidatePurposeToken(req.Token, a.keys, emailVerificationPurpose)
+	if err != nil {
+		return nil, errors.New("invalid or expired verification token")
+	}
+
+	user, err := a.userRepository.GetUserById(ctx, claims.UserId)
+	if err != nil || user.Email != claims.Email {
+		return nil, errors.New("invalid or expired verification token")
+	}
+
+	if user.EmailVerifiedAt == nil {
+		now := time.Now()
+		if err := a.userRepository.MarkEmailVerified(ctx, user.Id, user.Email, now); err != nil {
+			return nil, err
+		}
+		user.EmailVerifiedAt = &now
+	}
+
+	return toUserResponse(user), nil
+}
+
+// ResendVerification emails a new verification link. Unknown and already
+// verified addresses are ignored, as are requests within the resend
+// interval, so the response does not tell which addresses have an account.
+func (a *authService) ResendVerification(ctx context.Context, req *dto.ResendVerificationRequest) error {
+	user, err := a.userRepository.GetUserByEmail(ctx, req.Email)
+	if err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			return nil
+		}
+		return err
+	}
+
+	if user.EmailVerifiedAt != nil {
+		return nil
+	}
+
+	return a.db.Transaction(func(tx *gorm.DB) error {
+		return a.sendVerification(ctx, tx, user)
+	})
+}
+
+// sendVerification enqueues the verification email for user inside tx,
+// unless one was sent within the resend interval, in which case it does
+// nothing.
+func (a *authService) sendVerification(ctx context.Context, tx *gorm.DB, user *domain.User) error {
+	now := time.Now()
+	recorded, err := a.userRepository.WithTx(tx).MarkVerificationSent(ctx, user.Id, now, now.Add(-a.verificationResendInterval))
+	if err != nil {
+		return err
+	}
+	if !recorded {
+		return nil
+	}
+
+	expiresAt := now.Add(a.verificationTokenExpires)
+	token, err := utils.GeneratePurposeToken(a.keys, emailVerificationPurpose, user.Id, user.Email, a.verificationTokenExpires)
+	if err != nil {
+		return err
+	}
+
+	verificationURL, err := tokenLink(a.cfg.Auth.VerificationURL, token)
+	if err != nil {
+		return err
+	}
+
+	return enqueueNotification(ctx, a.outboxRepository.WithTx(tx), events.EmailVerificationRequested, &events.EmailVerificationRequestedV1{
+		UserId:    user.Id,
+		Email:     user.Email,
+		FirstName: user.FirstName,
+		LastName:  user.LastName,
+		Token:     token,
+		URL:       verificationURL,
+		ExpiresAt: expiresAt,
+	})
+}
+
+// ForgotPassword emails a single-use password reset link. Unknown addresses
+// are ignored, as are repeated requests for an address within a minute, so
+// the response does not tell which addresses have an account. Clients that
+// ask too often get ErrPasswordResetThrottled.
+func (a *authService) ForgotPassword(ctx context.Context, req *dto.ForgotPasswordRequest) error {
+	_, _, allowed, err := a.rateLimiter.Allow(ctx, passwordResetIPPrefix+utils.ClientFromContext(ctx).IPAddress, passwordResetsPerIP)
+	if err != nil {
+		return err
+	}
+	if !allowed {
+		return ErrPasswordResetThrottled
+	}
+
+	_, _, allowed, err = a.rateLimiter.Allow(ctx, passwordResetEmailPrefix+normalizeEmail(req.Email), passwordResetsPerEmail)
+	if err != nil {
+		return err
+	}
+	if !allowed {
+		return nil
+	}
+
+	user, err := a.userRepository.GetUserByEmailAndActive(ctx, req.Email, true)
+	if err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			return nil
+		}
+		return err
+	}
+
+	token, err := utils.GenerateOpaqueToken()
+	if err != nil {
+		return err
+	}
+
+	resetURL, err := tokenLink(a.cfg.Auth.PasswordResetURL, token)
+	if err != nil {
+		return err
+	}
+
+	resetToken := &domain.PasswordResetToken{
+		UserId:    user.Id,
+		TokenHash: utils.HashToken(token),
+		ExpiresAt: time.Now().Add(a.passwordResetTokenExpires),
+	}
+
+	return a.db.Transaction(func(tx *gorm.DB) error {
+		if err := a.userRepository.WithTx(tx).CreatePasswordResetToken(ctx, resetToken); err != nil {
+			return err
+		}
+
+		return enqueueNotification(ctx, a.outboxRepository.WithTx(tx), events.PasswordResetRequested, &events.PasswordResetRequestedV1{
+			UserId:    user.Id,
+			Email:     user.Email,
+			FirstName: user.FirstName,
+			LastName:  user.LastName,
+			Token:     token,
+			URL:       resetURL,
+			ExpiresAt: resetToken.ExpiresAt,
+		})
+	})
+}
+
+// ResetPassword sets a new password with a reset token and signs the user
+// out everywhere by revoking their refresh tokens. The token and every
+// other outstanding reset token of the user stop working.
+func (a *authService) ResetPassword(ctx context.Context, req *dto.ResetPasswordRequest) error {
+	hashedPassword, err := utils.HashPassword(req.NewPassword)
+	if err != nil {
+		return err
+	}
+
+	var userId uint
+
+	err = a.db.Transaction(func(tx *gorm.DB) error {
+		userRepo := a.userRepository.WithTx(tx)
+
+		resetToken, err := userRepo.GetValidPasswordResetTokenForUpdate(ctx, utils.HashToken(req.Token))
+		if err != nil {
+			if errors.Is(err, repository.ErrNotFound) {
+				return errors.New("invalid or expired reset token")
+			}
+			return err
+		}
+		userId = resetToken.UserId
+
+		if err := userRepo.UpdatePassword(ctx, resetToken.UserId, hashedPassword); err != nil {
+			return err
+		}
+
+		if err := userRepo.UsePasswordResetTokens(ctx, resetToken.UserId, time.Now()); err != nil {
+			return err
+		}
+
+		return userRepo.DeleteRefreshTokensByUserId(ctx, resetToken.UserId)
+	})
+	if err != nil {
+		return err
+	}
+
+	return a.tokenVersions.Bump(ctx, userId)
+}
+
+// VerifyTwoFactor completes a login of a user with two-factor
+// authentication, taking a TOTP code or an unused recovery code.
+func (a *authService) VerifyTwoFactor(ctx context.Context, req *dto.TwoFactorLoginRequest) (*dto.AuthResponse, error) {
+	claims, err := utils.ValidatePurposeToken(req.ChallengeToken, a.keys, twoFactorChallengePurpose)
+	if err != nil {
+		return nil, errors.New("invalid or expired challenge token")
+	}
+
+	user, err := a.userRepository.GetUserById(ctx, claims.UserId)
+	if err != nil || user.TOTPEnabledAt == nil {
+		return nil, errors.New("invalid or expired challenge token")
+	}
+
+	attempt, err := a.loginThrottle.attempt(ctx, user.Email, utils.ClientFromContext(ctx).IPAddress)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := a.checkTwoFactorCode(ctx, user, req.Code); err != nil {
+		if errors.Is(err, ErrInvalidTwoFactorCode) {
+			if err := a.loginFailed(ctx, attempt, user); err != nil {
+				return nil, err
+			}
+		}
+		return nil, err
+	}
+
+	if err := a.loginThrottle.reset(ctx, user.Email); err != nil {
+		return nil, err
+	}
+
+	if err := a.claimTwoFactorChallenge(ctx, claims); err != nil {
+		return nil, err
+	}
+
+	return a.generateAuthResponse(ctx, user, true)
+}
+
+// EnrolTwoFactor generates a new TOTP secret for the user. It takes effect
+// once ConfirmTwoFactor receives a code generated from it.
+func (a *authService) EnrolTwoFactor(ctx context.Context, userId uint) (*dto.TwoFactorEnrolmentResponse, error) {
+	user, err := a.userRepository.GetUserById(ctx, userId)
+	if err != nil {
+		return nil, err
+	}
+
+	if user.TOTPEnabledAt != nil {
+		return nil, errors.New("two-factor authentication is already enabled")
+	}
+
+	key, err := totp.Generate(totp.GenerateOpts{
+		Issuer:      a.twoFactorIssuer,
+		AccountName: user.Email,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to generate two-factor secret: %w", err)
+	}
+
+	image, err := key.Image(qrCodeSize, qrCodeSize)
+	if err != nil {
+		return nil, fmt.Errorf("failed to render qr code: %w", err)
+	}
+
+	var qrCode bytes.Buffer
+	if err := png.Encode(&qrCode, image); err != nil {
+		return nil, fmt.Errorf("failed to encode qr code: %w", err)
+	}
+
+	if err := a.userRepository.UpdateTwoFactor(ctx, user.Id, key.Secret(), nil); err != nil {
+		return nil, err
+	}
+
+	return &dto.TwoFactorEnrolmentResponse{
+		Secret: key.Secret(),
+		URI:    key.URL(),
+		QRCode: "data:image/png;base64," + base64.StdEncoding.EncodeToString(qrCode.Bytes()),
+	}, nil
+}
+
+// ConfirmTwoFactor enables two-factor authentication once the user shows
+// their authenticator generates valid codes, and returns recovery codes
+// that are not shown again. Existing sessions keep working; the next login
+// asks for a code.
+func (a *authService) ConfirmTwoFactor(ctx context.Context, userId uint, req *dto.TwoFactorCodeRequest) (*dto.RecoveryCodesResponse, error) {
+	user, err := a.userRepository.GetUserById(ctx, userId)
+	if err != nil {
+		return nil, err
+	}
+
+	if user.TOTPEnabledAt != nil {
+		return nil, errors.New("two-factor authentication is already enabled")
+	}
+
+	if user.TOTPSecret == "" {
+		return nil, errors.New("two-factor authentication enrolment was not started")
+	}
+
+	if err := a.throttleTwoFactor(ctx, user.Id); err != nil {
+		return nil, err
+	}
+
+	valid, err := a.useTOTPCode(ctx, user, strings.TrimSpace(req.Code))
+	if err != nil {
+		return nil, err
+	}
+
+	if !valid {
+		return nil, ErrInvalidTwoFactorCode
+	}
+
+	codes, recoveryCodes, err := generateRecoveryCodes(user.Id)
+	if err != nil {
+		return nil, err
+	}
+
+	err = a.db.Transaction(func(tx *gorm.DB) error {
+		userRepo := a.userRepository.WithTx(tx)
+
+		now := time.Now()
+		if err := userRepo.UpdateTwoFactor(ctx, user.Id, user.TOTPSecret, &now); err != nil {
+			return err
+		}
+
+		return userRepo.ReplaceRecoveryCodes(ctx, user.Id, recoveryCodes)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &dto.RecoveryCodesResponse{RecoveryCodes: codes}, nil
+}
+
+// DisableTwoFactor turns two-factor authentication off, which takes a
+// current code. Admins cannot turn it off when it is required for them.
+func (a *authService) DisableTwoFactor(ctx context.Context, userId uint, req *dto.TwoFactorCodeRequest) error {
+	user, err := a.userRepository.GetUserById(ctx, userId)
+	if err != nil {
+		return err
+	}
+
+	if user.TOTPEnabledAt == nil {
+		return errors.New("two-factor authentication is not enabled")
+	}
+
+	if a.cfg.Auth.RequireAdminTwoFactor {
+		permissions, err := rolePermissions(ctx, a.roleRepository, user.Role)
+		if err != nil {
+			return err
+		}
+		if len(permissions) > 0 {
+			return errors.New("two-factor authentication is required for staff")
+		}
+	}
+
+	if err := a.checkTwoFactorCode(ctx, user, req.Code); err != nil {
+		return err
+	}
+
+	return a.db.Transaction(func(tx *gorm.DB) error {
+		userRepo := a.userRepository.WithTx(tx)
+
+		if err := userRepo.UpdateTwoFactor(ctx, user.Id, "", nil); err != nil {
+			return err
+		}
+
+		return userRepo.DeleteRecoveryCodes(ctx, user.Id)
+	})
+}
+
+// GetSessions returns the devices the user is logged in on.
+func (a *authService) GetSessions(ctx context.Context, userId uint) ([]dto.SessionResponse, error) {
+	refreshTokens, err := a.userRepository.GetValidRefreshTokensByUserId(ctx, userId)
+	if err != nil {
+		return nil, err
+	}
+
+	sessions := make([]dto.SessionResponse, len(refreshTokens))
+	for i, refreshToken := range refreshTokens {
+		sessions[i] = dto.SessionResponse{
+			Id:         refreshToken.Id,
+			UserAgent:  refreshToken.UserAgent,
+			IPAddress:  refreshToken.IPAddress,
+			CreatedAt:  refreshToken.CreatedAt,
+			LastUsedAt: refreshToken.LastUsedAt,
+			ExpiresAt:  refreshToken.ExpiresAt,
+		}
+	}
+
+	return sessions, nil
+}
+
+// RevokeSession logs the user out on one device. Access tokens cannot be
+// told apart by session, so all of the user's are revoked and the other
+// sessions get new ones on their next refresh.
+func (a *authService) RevokeSession(ctx context.Context, userId, sessionId uint) error {
+	if err := a.userRepository.DeleteUserRefreshTokenById(ctx, userId, sessionId); err != nil {
+		return err
+	}
+
+	return a.tokenVersions.Bump(ctx, userId)
+}
+
+// RevokeAllSessions logs the user out on every device.
+func (a *authService) RevokeAllSessions(ctx context.Context, userId uint) error {
+	if err := a.userRepository.DeleteRefreshTokensByUserId(ctx, userId); err != nil {
+		return err
+	}
+
+	return a.tokenVersions.Bump(ctx, userId)
+}
+
+// JWKS returns the public keys other services verify tokens with.
+func (a *authService) JWKS() *utils.JWKS {
+	return a.keys.JWKS()
+}
+
+// UnlockLogin lifts a lockout of the user after failed logins. Lockouts
+// of the client IPs involved are left to expire.
+func (a *authService) UnlockLogin(ctx context.Context, userId uint) error {
+	user, err := a.userRepository.GetUserById(ctx, userId)
+	if err != nil {
+		return err
+	}
+
+	return a.loginThrottle.reset(ctx, user.Email)
+}
+
+// checkTwoFactorCode accepts a TOTP code of the user or uses up one of
+// their recovery codes.
+func (a *authService) checkTwoFactorCode(ctx context.Context, user *domain.User, code string) error {
+	if err := a.throttleTwoFactor(ctx, user.Id); err != nil {
+		return err
+	}
+
+	code = strings.TrimSpace(code)
+	valid, err := a.useTOTPCode(ctx, user, code)
+	if err != nil {
+		return err
+	}
+
+	if valid {
+		return nil
+	}
+
+	used, err := a.userRepository.UseRecoveryCode(ctx, user.Id, utils.HashToken(normalizeRecoveryCode(code)), time.Now())
+	if err != nil {
+		return err
+	}
+	if !used {
+		return ErrInvalidTwoFactorCode
+	}
+
+	return nil
+}
+
+func (a *authService) generateAuthResponse(ctx context.Context, user *domain.User, twoFactor bool) (*dto.AuthResponse, error) {
+	version, err := a.tokenVersions.Get(ctx, user.Id)
+	if err != nil {
+		return nil, err
+	}
+
+	permissions, err := rolePermissions(ctx, a.roleRepository, user.Role)
+	if err != nil {
+		return nil, err
+	}
+
 	accessToken, refreshToken, err := utils.GenerateToken(
 		a.cfg,
+		a.keys,
 		user.Id,
 		user.Email,
 		string(user.Role),
+		permissions,
+		twoFactor,
+		version,
 	)
 	if err != nil {
 		return nil, err
 	}
 
+	now := time.Now()
+	client := utils.ClientFromContext(ctx)
 	refreshTokenDomain := &domain.RefreshToken{
-		UserId:    user.Id,
-		Token:     refreshToken,
-		ExpiresAt: time.Now().Add(a.cfg.JWT.RefreshTokenExpires),
-	}
-
-	if err := a.userRepository.CreateRefreshToken(ctx, refreshTokenDomain); err != nil {
-		return nil, err
+		UserId:     user.Id,
+		TokenHash:  utils.HashToken(refreshToken),
+		UserAgent:  client.UserAgent,
+		IPAddress:  client.IPAddress,
+		ExpiresAt:  now.Add(a.cfg.JWT.RefreshTokenExpires),
+		LastUsedAt: &now,
 	}
 
-	if err := a.eventPublisher.Publish(UserLoggedIn, user, map[string]string{}); err != nil {
-		return nil, fmt.Errorf("unable to publish user login event: %w", err)
-	}
+	err = a.db.Transaction(func(tx *gorm.DB) error {
+		if err := a.userRepository.WithTx(tx).CreateRefreshToken(ctx, refreshTokenDomain); err != nil {
+			return err
+		}
 
-	return &dto.AuthResponse{
-		User: dto.UserResponse{
-			Id:        user.Id,
+		return enqueueEvent(ctx, a.outboxRepository.WithTx(tx), events.UserLoggedIn, &events.UserLoggedInV1{
+			UserId:    user.Id,
 			Email:     user.Email,
 			FirstName: user.FirstName,
 			LastName:  user.LastName,
-			Phone:     user.Phone,
-			Role:      string(user.Role),
-			IsActive:  user.IsActive,
-			CreatedAt: user.CreatedAt,
-			UpdatedAt: user.UpdatedAt,
-		},
+		})
+	})
+
+	if err != nil {
+		return nil, err
+	}
+
+	return &dto.AuthResponse{
+		User:         *toUserResponse(user),
 		AccessToken:  accessToken,
 		RefreshToken: refreshToken,
 	}, nil
 }
 
-func NewAuthService(cfg *config.Config, eventPublisher events.Publisher, userRepository repository.UserRepository, cartRepository repository.CartRepository) AuthService {
+// generateRecoveryCodes returns new recovery codes for the user, both as
+// shown to them and as stored.
+func generateRecoveryCodes(userId uint) ([]string, []domain.RecoveryCode, error) {
+	codes := make([]string, recoveryCodeCount)
+	recoveryCodes := make([]domain.RecoveryCode, recoveryCodeCount)
+
+	for i := range codes {
+		random := make([]byte, 5)
+		if _, err := rand.Read(random); err != nil {
+			return nil, nil, fmt.Errorf("failed to generate recovery code: %w", err)
+		}
+
+		code := hex.EncodeToString(random)
+		codes[i] = code[:5] + "-" + code[5:]
+		recoveryCodes[i] = domain.RecoveryCode{
+			UserId:   userId,
+			CodeHash: utils.HashToken(normalizeRecoveryCode(codes[i])),
+		}
+	}
+
+	return codes, recoveryCodes, nil
+}
+
+// normalizeRecoveryCode makes recovery codes match regardless of case and
+// separators typed by the user.
+func normalizeRecoveryCode(code string) string {
+	return strings.ToLower(strings.NewReplacer("-", "", " ", "").Replace(code))
+}
+
+// tokenLink adds token to the query of the configured base URL of a link
+// emailed to users. Without a base URL the email only carries the token.
+func tokenLink(base, token string) (string, error) {
+	if base == "" {
+		return "", nil
+	}
+
+	link, err := url.Parse(base)
+	if err != nil {
+		return "", fmt.Errorf("invalid link url %q: %w", base, err)
+	}
+
+	query := link.Query()
+	query.Set("token", token)
+	link.RawQuery = query.Encode()
+
+	return link.String(), nil
+}
+
+func parseEmailVerificationRequirement(value string) domain.EmailVerificationRequirement {
+	switch requirement := domain.EmailVerificationRequirement(value); requirement {
+	case domain.EmailVerificationForLogin, domain.EmailVerificationForCheckout:
+		return requirement
+	default:
+		return domain.EmailVerificationOptional
+	}
+}
+
+func NewAuthService(cfg *config.Config, keys *utils.KeySet, userRepository repository.UserRepository, cartRepository repository.CartRepository, outboxRepository repository.OutboxRepository, roleRepository repository.RoleRepository, tokenVersions cache.TokenVersions, rateLimiter helper.RateLimiter, cacheService cache.Cache, db *gorm.DB) AuthService {
+	verificationTokenExpires := cfg.Auth.VerificationTokenExpires
+	if verificationTokenExpires <= 0 {
+		verificationTokenExpires = defaultVerificationTokenExpires
+	}
+
+	verificationResendInterval := cfg.Auth.VerificationResendInterval
+	if verificationResendInterval <= 0 {
+		verificationResendInterval = defaultVerificationResendInterval
+	}
+
+	passwordResetTokenExpires := cfg.Auth.PasswordResetTokenExpires
+	if passwordResetTokenExpires <= 0 {
+		passwordResetTokenExpires = defaultPasswordResetTokenExpires
+	}
+
+	twoFactorIssuer := cfg.Auth.TwoFactorIssuer
+	if twoFactorIssuer == "" {
+		twoFactorIssuer = defaultTwoFactorIssuer
+	}
+
+	twoFactorChallengeExpires := cfg.Auth.TwoFactorChallengeExpires
+	if twoFactorChallengeExpires <= 0 {
+		twoFactorChallengeExpires = defaultTwoFactorChallengeExpires
+	}
+
 	return &authService{
-		cfg:            cfg,
-		eventPublisher: eventPublisher,
-		userRepository: userRepository,
-		cartRepository: cartRepository,
+		cfg:                        cfg,
+		keys:                       keys,
+		requireVerifiedEmail:       parseEmailVerificationRequirement(cfg.Auth.RequireVerifiedEmail),
+		verificationTokenExpires:   verificationTokenExpires,
+		verificationResendInterval: verificationResendInterval,
+		passwordResetTokenExpires:  passwordResetTokenExpires,
+		twoFactorIssuer:            twoFactorIssuer,
+		twoFactorChallengeExpires:  twoFactorChallengeExpires,
+		userRepository:             userRepository,
+		cartRepository:             cartRepository,
+		outboxRepository:           outboxRepository,
+		roleRepository:             roleRepository,
+		tokenVersions:              tokenVersions,
+		rateLimiter:                rateLimiter,
+		cache:                      cacheService,
+		loginThrottle:              newLoginThrottle(cfg, rateLimiter),
+		oidcProviders:              newOIDCProviders(cfg, cacheService),
+		db:                         db,
 	}
 }
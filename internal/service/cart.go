@@ -4,9 +4,11 @@ import (
 	"context"
 	"errors"
 
+	"github.com/saleh-ghazimoradi/Cartopher/infra/events"
 	"github.com/saleh-ghazimoradi/Cartopher/internal/domain"
 	"github.com/saleh-ghazimoradi/Cartopher/internal/dto"
 	"github.com/saleh-ghazimoradi/Cartopher/internal/repository"
+	"gorm.io/gorm"
 )
 
 type CartService interface {
@@ -17,8 +19,10 @@ type CartService interface {
 }
 
 type cartService struct {
+	outboxRepository  repository.OutboxRepository
 	cartRepository    repository.CartRepository
 	productRepository repository.ProductRepository
+	db                *gorm.DB
 }
 
 func (c *cartService) GetCart(ctx context.Context, userId uint) (*dto.CartResponse, error) {
@@ -36,29 +40,43 @@ func (c *cartService) AddToCart(ctx context.Context, userId uint, req *dto.AddTo
 		return nil, errors.New("product not found")
 	}
 
-	if product.Stock < req.Quantity {
+	if product.TotalStock() < req.Quantity {
 		return nil, errors.New("not enough stock")
 	}
 
-	cart, err := c.cartRepository.GetOrCreateCart(ctx, userId)
-	if err != nil {
-		return nil, err
-	}
+	err = c.db.Transaction(func(tx *gorm.DB) error {
+		cartRepo := c.cartRepository.WithTx(tx)
 
-	cartItem, err := c.cartRepository.GetCartItem(ctx, cart.Id, req.ProductId)
-	if err != nil {
-		item := &domain.CartItem{
-			CartId:    cart.Id,
-			ProductId: product.Id,
-			Quantity:  req.Quantity,
+		cart, err := cartRepo.GetOrCreateCart(ctx, userId)
+		if err != nil {
+			return err
 		}
-		_ = c.cartRepository.CreateCartItem(ctx, item)
-	} else {
-		cartItem.Quantity += req.Quantity
-		if cartItem.Quantity > product.Stock {
-			return nil, errors.New("not enough stock")
+
+		cartItem, err := cartRepo.GetCartItem(ctx, cart.Id, req.ProductId)
+		if err != nil {
+			item := &domain.CartItem{
+				CartId:    cart.Id,
+				ProductId: product.Id,
+				Quantity:  req.Quantity,
+			}
+			if err := cartRepo.CreateCartItem(ctx, item); err != nil {
+				return err
+			}
+		} else {
+			cartItem.Quantity += req.Quantity
+			if cartItem.Quantity > product.TotalStock() {
+				return errors.New("not enough stock")
+			}
+			if err := cartRepo.UpdateCartItem(ctx, cartItem); err != nil {
+				return err
+			}
 		}
-		_ = c.cartRepository.UpdateCartItem(ctx, cartItem)
+
+		return enqueueCartUpdated(ctx, c.outboxRepository.WithTx(tx), cart.Id, userId)
+	})
+
+	if err != nil {
+		return nil, err
 	}
 
 	return c.GetCart(ctx, userId)
@@ -75,12 +93,21 @@ func (c *cartService) UpdateCartItem(ctx context.Context, userId, itemId uint, r
 		return nil, err
 	}
 
-	if product.Stock < req.Quantity {
+	if product.TotalStock() < req.Quantity {
 		return nil, errors.New("not enough stock")
 	}
 
 	cartItem.Quantity = req.Quantity
-	if err := c.cartRepository.UpdateCartItem(ctx, cartItem); err != nil {
+
+	err = c.db.Transaction(func(tx *gorm.DB) error {
+		if err := c.cartRepository.WithTx(tx).UpdateCartItem(ctx, cartItem); err != nil {
+			return err
+		}
+
+		return enqueueCartUpdated(ctx, c.outboxRepository.WithTx(tx), cartItem.CartId, userId)
+	})
+
+	if err != nil {
 		return nil, err
 	}
 
@@ -88,7 +115,18 @@ func (c *cartService) UpdateCartItem(ctx context.Context, userId, itemId uint, r
 }
 
 func (c *cartService) RemoveFromCart(ctx context.Context, userId, itemId uint) error {
-	return c.cartRepository.DeleteCartItem(ctx, userId, itemId)
+	cartItem, err := c.cartRepository.GetCartItemWithUser(ctx, userId, itemId)
+	if err != nil {
+		return err
+	}
+
+	return c.db.Transaction(func(tx *gorm.DB) error {
+		if err := c.cartRepository.WithTx(tx).DeleteCartItem(ctx, userId, itemId); err != nil {
+			return err
+		}
+
+		return enqueueCartUpdated(ctx, c.outboxRepository.WithTx(tx), cartItem.CartId, userId)
+	})
 }
 
 func (c *cartService) convertToCartResponse(cart *domain.Cart) *dto.CartResponse {
@@ -107,7 +145,8 @@ func (c *cartService) convertToCartResponse(cart *domain.Cart) *dto.CartResponse
 				Name:        cart.CartItems[i].Product.Name,
 				Description: cart.CartItems[i].Product.Description,
 				Price:       cart.CartItems[i].Product.Price,
-				Stock:       cart.CartItems[i].Product.Stock,
+				Stock:       cart.CartItems[i].Product.TotalStock(),
+				InStock:     cart.CartItems[i].Product.TotalStock() > 0,
 				SKU:         cart.CartItems[i].Product.SKU,
 				IsActive:    cart.CartItems[i].Product.IsActive,
 				Category: dto.CategoryResponse{
@@ -133,9 +172,19 @@ func (c *cartService) convertToCartResponse(cart *domain.Cart) *dto.CartResponse
 	}
 }
 
-func NewCartService(cartRepository repository.CartRepository, productRepository repository.ProductRepository) CartService {
+// enqueueCartUpdated records CART_UPDATED for a change to the user's cart.
+func enqueueCartUpdated(ctx context.Context, outboxRepository repository.OutboxRepository, cartId, userId uint) error {
+	return enqueueEvent(ctx, outboxRepository, events.CartUpdated, &events.CartV1{
+		CartId: cartId,
+		UserId: userId,
+	})
+}
+
+func NewCartService(outboxRepository repository.OutboxRepository, cartRepository repository.CartRepository, productRepository repository.ProductRepository, db *gorm.DB) CartService {
 	return &cartService{
+		outboxRepository:  outboxRepository,
 		cartRepository:    cartRepository,
 		productRepository: productRepository,
+		db:                db,
 	}
 }
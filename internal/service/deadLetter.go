@@ -0,0 +1,199 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"strconv"
+	"time"
+
+	"github.com/saleh-ghazimoradi/Cartopher/config"
+	"github.com/saleh-ghazimoradi/Cartopher/internal/domain"
+	"github.com/saleh-ghazimoradi/Cartopher/internal/dto"
+	"github.com/saleh-ghazimoradi/Cartopher/internal/repository"
+	"gorm.io/gorm"
+)
+
+const (
+	// MetadataAttempt counts how many times the notifier has tried a
+	// message; the first delivery carries no attempt and counts as 1.
+	MetadataAttempt = "attempt"
+	// MetadataTarget names the only consumer a message is meant for. Retries
+	// and replays are addressed to the notifier so other consumers of the
+	// topic do not see them twice.
+	MetadataTarget = "target"
+	NotifierTarget = "notifier"
+
+	defaultNotifierMaxAttempts  = 5
+	defaultNotifierRetryBackoff = 30 * time.Second
+	defaultNotifierMaxBackoff   = time.Hour
+)
+
+type DeadLetterService interface {
+	HandleFailure(ctx context.Context, message *dto.FailedMessage) error
+	GetDeadLetters(ctx context.Context, includeReplayed bool, limit int) ([]*dto.DeadLetterResponse, error)
+	GetDeadLetter(ctx context.Context, id uint) (*dto.DeadLetterResponse, error)
+	ReplayDeadLetter(ctx context.Context, id uint) error
+}
+
+type deadLetterService struct {
+	maxAttempts          int
+	retryBackoff         time.Duration
+	maxBackoff           time.Duration
+	outboxRepository     repository.OutboxRepository
+	deadLetterRepository repository.DeadLetterRepository
+	db                   *gorm.DB
+}
+
+// HandleFailure schedules another attempt of a message the notifier failed
+// to handle, delayed with exponential backoff, or moves it to the dead
+// letters once it has used up its attempts. Retries go through the outbox,
+// so they survive restarts and work the same on every transport.
+func (d *deadLetterService) HandleFailure(ctx context.Context, message *dto.FailedMessage) error {
+	attempt := messageAttempt(message.Metadata)
+
+	metadata := make(map[string]string, len(message.Metadata)+2)
+	for k, v := range message.Metadata {
+		metadata[k] = v
+	}
+	delete(metadata, "outbox_id")
+	metadata[MetadataTarget] = NotifierTarget
+
+	if attempt >= d.maxAttempts {
+		delete(metadata, MetadataAttempt)
+		return d.deadLetterRepository.CreateDeadLetter(ctx, &domain.DeadLetter{
+			EventType: message.EventType,
+			Payload:   message.Payload,
+			Metadata:  metadata,
+			Attempts:  attempt,
+			LastError: message.Error,
+		})
+	}
+
+	metadata[MetadataAttempt] = strconv.Itoa(attempt + 1)
+
+	return d.outboxRepository.CreateMessage(ctx, &domain.OutboxMessage{
+		EventType:   message.EventType,
+		Payload:     message.Payload,
+		Metadata:    metadata,
+		LastError:   message.Error,
+		AvailableAt: time.Now().Add(exponentialBackoff(d.retryBackoff, d.maxBackoff, attempt)),
+	})
+}
+
+func (d *deadLetterService) GetDeadLetters(ctx context.Context, includeReplayed bool, limit int) ([]*dto.DeadLetterResponse, error) {
+	if limit < 1 {
+		limit = 50
+	}
+
+	deadLetters, err := d.deadLetterRepository.GetDeadLetters(ctx, includeReplayed, limit)
+	if err != nil {
+		return nil, err
+	}
+
+	response := make([]*dto.DeadLetterResponse, len(deadLetters))
+	for i := range deadLetters {
+		response[i] = d.convertToDeadLetterResponse(&deadLetters[i])
+	}
+
+	return response, nil
+}
+
+func (d *deadLetterService) GetDeadLetter(ctx context.Context, id uint) (*dto.DeadLetterResponse, error) {
+	deadLetter, err := d.deadLetterRepository.GetDeadLetterById(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	return d.convertToDeadLetterResponse(deadLetter), nil
+}
+
+// ReplayDeadLetter hands a dead letter back to the notifier with a fresh
+// retry budget.
+func (d *deadLetterService) ReplayDeadLetter(ctx context.Context, id uint) error {
+	return d.db.Transaction(func(tx *gorm.DB) error {
+		deadLetterRepo := d.deadLetterRepository.WithTx(tx)
+
+		deadLetter, err := deadLetterRepo.GetDeadLetterForUpdate(ctx, id)
+		if err != nil {
+			return err
+		}
+
+		if deadLetter.ReplayedAt != nil {
+			return errors.New("dead letter has already been replayed")
+		}
+
+		metadata := make(map[string]string, len(deadLetter.Metadata)+1)
+		for k, v := range deadLetter.Metadata {
+			metadata[k] = v
+		}
+		metadata[MetadataTarget] = NotifierTarget
+
+		if err := d.outboxRepository.WithTx(tx).CreateMessage(ctx, &domain.OutboxMessage{
+			EventType:   deadLetter.EventType,
+			Payload:     deadLetter.Payload,
+			Metadata:    metadata,
+			AvailableAt: time.Now(),
+		}); err != nil {
+			return err
+		}
+
+		return deadLetterRepo.MarkReplayed(ctx, id, time.Now())
+	})
+}
+
+func (d *deadLetterService) convertToDeadLetterResponse(deadLetter *domain.DeadLetter) *dto.DeadLetterResponse {
+	return &dto.DeadLetterResponse{
+		Id:         deadLetter.Id,
+		EventType:  deadLetter.EventType,
+		Payload:    deadLetter.Payload,
+		Metadata:   deadLetter.Metadata,
+		Attempts:   deadLetter.Attempts,
+		LastError:  deadLetter.LastError,
+		ReplayedAt: deadLetter.ReplayedAt,
+		CreatedAt:  deadLetter.CreatedAt,
+	}
+}
+
+func messageAttempt(metadata map[string]string) int {
+	attempt, err := strconv.Atoi(metadata[MetadataAttempt])
+	if err != nil || attempt < 1 {
+		return 1
+	}
+	return attempt
+}
+
+// exponentialBackoff doubles base for every attempt after the first, capped
+// at max.
+func exponentialBackoff(base, max time.Duration, attempt int) time.Duration {
+	delay := base
+	for i := 1; i < attempt && delay < max; i++ {
+		delay *= 2
+	}
+	return min(delay, max)
+}
+
+func NewDeadLetterService(cfg *config.Config, outboxRepository repository.OutboxRepository, deadLetterRepository repository.DeadLetterRepository, db *gorm.DB) DeadLetterService {
+	maxAttempts := cfg.Notifier.MaxAttempts
+	if maxAttempts <= 0 {
+		maxAttempts = defaultNotifierMaxAttempts
+	}
+
+	retryBackoff := cfg.Notifier.RetryBackoff
+	if retryBackoff <= 0 {
+		retryBackoff = defaultNotifierRetryBackoff
+	}
+
+	maxBackoff := cfg.Notifier.MaxBackoff
+	if maxBackoff <= 0 {
+		maxBackoff = defaultNotifierMaxBackoff
+	}
+
+	return &deadLetterService{
+		maxAttempts:          maxAttempts,
+		retryBackoff:         retryBackoff,
+		maxBackoff:           maxBackoff,
+		outboxRepository:     outboxRepository,
+		deadLetterRepository: deadLetterRepository,
+		db:                   db,
+	}
+}
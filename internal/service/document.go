@@ -0,0 +1,277 @@
+package service
+
+import (
+	"bytes"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/go-pdf/fpdf"
+	"github.com/saleh-ghazimoradi/Cartopher/config"
+	"github.com/saleh-ghazimoradi/Cartopher/internal/domain"
+)
+
+const (
+	documentMargin   = 15.0
+	documentLineH    = 6.0
+	documentPageW    = 210.0
+	documentContentW = documentPageW - 2*documentMargin
+)
+
+type invoiceLine struct {
+	SKU      string
+	Name     string
+	Quantity int
+	Price    float64
+}
+
+// renderInvoice renders an invoiced order as an A4 PDF. The order must have
+// its User, OrderItems.Product and invoice number loaded.
+func renderInvoice(cfg config.Invoice, order *domain.Order) ([]byte, error) {
+	if order.InvoiceNumber == nil || order.InvoicedAt == nil {
+		return nil, fmt.Errorf("order %d has not been invoiced", order.Id)
+	}
+
+	pdf, tr := newDocument()
+
+	documentHeader(pdf, tr, cfg, "INVOICE", [][2]string{
+		{"Invoice number", *order.InvoiceNumber},
+		{"Invoice date", order.InvoicedAt.Format("2006-01-02")},
+		{"Order number", fmt.Sprintf("%d", order.Id)},
+		{"Order date", order.CreatedAt.Format("2006-01-02")},
+	})
+
+	documentAddresses(pdf, tr, order)
+
+	widths := []float64{30, 90, 15, 22.5, 22.5}
+	documentTableHeader(pdf, widths, []string{"SKU", "Description", "Qty", "Unit price", "Amount"})
+
+	var total float64
+	for _, line := range invoiceLines(order) {
+		amount := float64(line.Quantity) * line.Price
+		total += amount
+
+		pdf.CellFormat(widths[0], documentLineH, tr(line.SKU), "B", 0, "L", false, 0, "")
+		pdf.CellFormat(widths[1], documentLineH, tr(truncate(pdf, line.Name, widths[1])), "B", 0, "L", false, 0, "")
+		pdf.CellFormat(widths[2], documentLineH, fmt.Sprintf("%d", line.Quantity), "B", 0, "R", false, 0, "")
+		pdf.CellFormat(widths[3], documentLineH, formatAmount(line.Price), "B", 0, "R", false, 0, "")
+		pdf.CellFormat(widths[4], documentLineH, formatAmount(amount), "B", 1, "R", false, 0, "")
+	}
+
+	pdf.Ln(2)
+	pdf.SetFont("Helvetica", "B", 10)
+	pdf.CellFormat(documentContentW-widths[4], documentLineH, "Total", "", 0, "R", false, 0, "")
+	pdf.CellFormat(widths[4], documentLineH, formatAmount(total), "", 1, "R", false, 0, "")
+
+	return documentBytes(pdf)
+}
+
+// renderPackingSlip renders the pick list for an order, one section per
+// warehouse the order was allocated to. Prices are deliberately left out.
+// The order must have OrderItems.Product and OrderItems.Warehouse loaded.
+func renderPackingSlip(cfg config.Invoice, order *domain.Order) ([]byte, error) {
+	pdf, tr := newDocument()
+
+	documentHeader(pdf, tr, cfg, "PACKING SLIP", [][2]string{
+		{"Order number", fmt.Sprintf("%d", order.Id)},
+		{"Order date", order.CreatedAt.Format("2006-01-02")},
+	})
+
+	documentAddresses(pdf, tr, order)
+
+	byWarehouse := make(map[uint][]domain.OrderItem)
+	warehouses := make(map[uint]domain.Warehouse)
+	var warehouseIds []uint
+	for _, item := range order.OrderItems {
+		if _, ok := byWarehouse[item.WarehouseId]; !ok {
+			warehouseIds = append(warehouseIds, item.WarehouseId)
+		}
+		byWarehouse[item.WarehouseId] = append(byWarehouse[item.WarehouseId], item)
+		warehouses[item.WarehouseId] = item.Warehouse
+	}
+
+	shipped := order.ShippedQuantities()
+	widths := []float64{40, 110, 15, 15}
+
+	for _, id := range warehouseIds {
+		warehouse := warehouses[id]
+
+		pdf.SetFont("Helvetica", "B", 11)
+		pdf.CellFormat(documentContentW, documentLineH, tr(fmt.Sprintf("Ship from: %s (%s)", warehouse.Name, warehouse.Code)), "", 1, "L", false, 0, "")
+
+		documentTableHeader(pdf, widths, []string{"SKU", "Description", "Qty", "Shipped"})
+
+		for _, item := range byWarehouse[id] {
+			pdf.CellFormat(widths[0], documentLineH, tr(item.Product.SKU), "B", 0, "L", false, 0, "")
+			pdf.CellFormat(widths[1], documentLineH, tr(truncate(pdf, item.Product.Name, widths[1])), "B", 0, "L", false, 0, "")
+			pdf.CellFormat(widths[2], documentLineH, fmt.Sprintf("%d", item.Quantity), "B", 0, "R", false, 0, "")
+			pdf.CellFormat(widths[3], documentLineH, fmt.Sprintf("%d", shipped[item.Id]), "B", 1, "R", false, 0, "")
+		}
+
+		pdf.Ln(documentLineH)
+	}
+
+	return documentBytes(pdf)
+}
+
+// invoiceLines merges order items that were split across warehouses back
+// into one line per product and price, which is what the customer bought.
+func invoiceLines(order *domain.Order) []invoiceLine {
+	type key struct {
+		productId uint
+		price     float64
+	}
+
+	var keys []key
+	lines := make(map[key]*invoiceLine)
+
+	for _, item := range order.OrderItems {
+		k := key{productId: item.ProductId, price: item.Price}
+		line, ok := lines[k]
+		if !ok {
+			line = &invoiceLine{SKU: item.Product.SKU, Name: item.Product.Name, Price: item.Price}
+			lines[k] = line
+			keys = append(keys, k)
+		}
+		line.Quantity += item.Quantity
+	}
+
+	sort.SliceStable(keys, func(i, j int) bool {
+		return lines[keys[i]].SKU < lines[keys[j]].SKU
+	})
+
+	result := make([]invoiceLine, len(keys))
+	for i, k := range keys {
+		result[i] = *lines[k]
+	}
+	return result
+}
+
+func newDocument() (*fpdf.Fpdf, func(string) string) {
+	pdf := fpdf.New("P", "mm", "A4", "")
+	pdf.SetMargins(documentMargin, documentMargin, documentMargin)
+	pdf.SetAutoPageBreak(true, documentMargin)
+	pdf.AddPage()
+
+	// Core fonts only cover cp1252, so names are translated from UTF-8.
+	return pdf, pdf.UnicodeTranslatorFromDescriptor("")
+}
+
+func documentHeader(pdf *fpdf.Fpdf, tr func(string) string, cfg config.Invoice, title string, details [][2]string) {
+	top := pdf.GetY()
+
+	pdf.SetFont("Helvetica", "B", 16)
+	pdf.CellFormat(documentContentW/2, 9, tr(cfg.CompanyName), "", 1, "L", false, 0, "")
+	pdf.SetFont("Helvetica", "", 9)
+	for _, line := range strings.Split(cfg.CompanyAddress, ",") {
+		pdf.CellFormat(documentContentW/2, 4.5, tr(strings.TrimSpace(line)), "", 1, "L", false, 0, "")
+	}
+	bottom := pdf.GetY()
+
+	pdf.SetXY(documentMargin+documentContentW/2, top)
+	pdf.SetFont("Helvetica", "B", 16)
+	pdf.CellFormat(documentContentW/2, 9, title, "", 1, "R", false, 0, "")
+	pdf.SetFont("Helvetica", "", 9)
+	for _, detail := range details {
+		pdf.SetX(documentMargin + documentContentW/2)
+		pdf.CellFormat(documentContentW/2, 4.5, tr(fmt.Sprintf("%s: %s", detail[0], detail[1])), "", 1, "R", false, 0, "")
+	}
+
+	pdf.SetY(max(bottom, pdf.GetY()) + 8)
+}
+
+func documentAddresses(pdf *fpdf.Fpdf, tr func(string) string, order *domain.Order) {
+	customer := strings.TrimSpace(order.User.FirstName + " " + order.User.LastName)
+
+	billTo := []string{customer, order.User.Email}
+	shipTo := addressLines(order.ShippingAddress)
+	if len(shipTo) == 0 {
+		shipTo = []string{customer}
+	}
+
+	top := pdf.GetY()
+	half := documentContentW / 2
+
+	pdf.SetFont("Helvetica", "B", 10)
+	pdf.CellFormat(half, documentLineH, "Bill to", "", 0, "L", false, 0, "")
+	pdf.CellFormat(half, documentLineH, "Ship to", "", 1, "L", false, 0, "")
+
+	pdf.SetFont("Helvetica", "", 9)
+	for i := 0; i < max(len(billTo), len(shipTo)); i++ {
+		var left, right string
+		if i < len(billTo) {
+			left = billTo[i]
+		}
+		if i < len(shipTo) {
+			right = shipTo[i]
+		}
+		pdf.CellFormat(half, 4.5, tr(left), "", 0, "L", false, 0, "")
+		pdf.CellFormat(half, 4.5, tr(right), "", 1, "L", false, 0, "")
+	}
+
+	pdf.SetY(max(top, pdf.GetY()) + 8)
+}
+
+func documentTableHeader(pdf *fpdf.Fpdf, widths []float64, titles []string) {
+	pdf.SetFont("Helvetica", "B", 9)
+	pdf.SetFillColor(235, 235, 235)
+	for i, title := range titles {
+		align := "L"
+		if i >= 2 {
+			align = "R"
+		}
+		ln := 0
+		if i == len(titles)-1 {
+			ln = 1
+		}
+		pdf.CellFormat(widths[i], documentLineH, title, "B", ln, align, true, 0, "")
+	}
+	pdf.SetFont("Helvetica", "", 9)
+}
+
+func documentBytes(pdf *fpdf.Fpdf) ([]byte, error) {
+	var buf bytes.Buffer
+	if err := pdf.Output(&buf); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+func addressLines(address domain.Address) []string {
+	if address.Line1 == "" {
+		return nil
+	}
+
+	lines := []string{address.Name, address.Line1}
+	if address.Line2 != "" {
+		lines = append(lines, address.Line2)
+	}
+
+	city := strings.TrimSpace(strings.Join([]string{address.PostalCode, address.City}, " "))
+	if address.State != "" {
+		city += ", " + address.State
+	}
+	lines = append(lines, city, address.Country)
+
+	return lines
+}
+
+// truncate shortens text so it fits in a cell of the given width.
+func truncate(pdf *fpdf.Fpdf, text string, width float64) string {
+	const ellipsis = "..."
+	width -= 2
+
+	if pdf.GetStringWidth(text) <= width {
+		return text
+	}
+
+	runes := []rune(text)
+	for len(runes) > 0 && pdf.GetStringWidth(string(runes)+ellipsis) > width {
+		runes = runes[:len(runes)-1]
+	}
+	return string(runes) + ellipsis
+}
+
+func formatAmount(amount float64) string {
+	return fmt.Sprintf("%.2f", amount)
+}
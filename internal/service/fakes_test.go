@@ -0,0 +1,320 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/go-redis/redis_rate/v10"
+	"github.com/saleh-ghazimoradi/Cartopher/infra/cache"
+	"github.com/saleh-ghazimoradi/Cartopher/internal/domain"
+	"github.com/saleh-ghazimoradi/Cartopher/internal/helper"
+	"github.com/saleh-ghazimoradi/Cartopher/internal/repository"
+	"gorm.io/driver/postgres"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+// fakeUserRepository keeps users and identities in memory. Methods the
+// tests do not reach panic through the nil embedded interface.
+type fakeUserRepository struct {
+	repository.UserRepository
+
+	mu            sync.Mutex
+	users         []*domain.User
+	identities    []*domain.UserIdentity
+	refreshTokens int
+	// createIdentity, when set, runs before an identity is stored and may
+	// fail the insert.
+	createIdentity func(identity *domain.UserIdentity) error
+}
+
+func (f *fakeUserRepository) WithTx(*gorm.DB) repository.UserRepository {
+	return f
+}
+
+func (f *fakeUserRepository) CreateUser(_ context.Context, user *domain.User) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	user.Id = uint(len(f.users) + 1)
+	user.IsActive = true
+	f.users = append(f.users, user)
+	return nil
+}
+
+func (f *fakeUserRepository) GetUserById(_ context.Context, id uint) (*domain.User, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	for _, user := range f.users {
+		if user.Id == id {
+			return user, nil
+		}
+	}
+	return nil, repository.ErrNotFound
+}
+
+func (f *fakeUserRepository) GetUserByEmail(_ context.Context, email string) (*domain.User, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	for _, user := range f.users {
+		if user.Email == email {
+			return user, nil
+		}
+	}
+	return nil, repository.ErrNotFound
+}
+
+func (f *fakeUserRepository) GetUserByEmailAndActive(ctx context.Context, email string, isActive bool) (*domain.User, error) {
+	user, err := f.GetUserByEmail(ctx, email)
+	if err != nil {
+		return nil, err
+	}
+
+	if user.IsActive != isActive {
+		return nil, repository.ErrNotFound
+	}
+	return user, nil
+}
+
+func (f *fakeUserRepository) GetUserIdsByRole(_ context.Context, role domain.UserRole) ([]uint, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	var ids []uint
+	for _, user := range f.users {
+		if user.Role == role {
+			ids = append(ids, user.Id)
+		}
+	}
+	return ids, nil
+}
+
+func (f *fakeUserRepository) CreateUserIdentity(_ context.Context, identity *domain.UserIdentity) error {
+	if f.createIdentity != nil {
+		if err := f.createIdentity(identity); err != nil {
+			return err
+		}
+	}
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	f.identities = append(f.identities, identity)
+	return nil
+}
+
+func (f *fakeUserRepository) GetUserIdentity(_ context.Context, provider, subject string) (*domain.UserIdentity, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	for _, identity := range f.identities {
+		if identity.Provider == provider && identity.Subject == subject {
+			return identity, nil
+		}
+	}
+	return nil, repository.ErrNotFound
+}
+
+func (f *fakeUserRepository) CreateRefreshToken(context.Context, *domain.RefreshToken) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	f.refreshTokens++
+	return nil
+}
+
+type fakeCartRepository struct {
+	repository.CartRepository
+	carts []*domain.Cart
+}
+
+func (f *fakeCartRepository) WithTx(*gorm.DB) repository.CartRepository {
+	return f
+}
+
+func (f *fakeCartRepository) CreateCart(_ context.Context, cart *domain.Cart) error {
+	f.carts = append(f.carts, cart)
+	return nil
+}
+
+type fakeOutboxRepository struct {
+	repository.OutboxRepository
+}
+
+func (f *fakeOutboxRepository) WithTx(*gorm.DB) repository.OutboxRepository {
+	return f
+}
+
+func (f *fakeOutboxRepository) CreateMessage(context.Context, *domain.OutboxMessage) error {
+	return nil
+}
+
+// fakeRoleRepository keeps roles in memory, starting with the customer and
+// admin system roles.
+type fakeRoleRepository struct {
+	repository.RoleRepository
+
+	mu    sync.Mutex
+	roles map[domain.UserRole]domain.Role
+}
+
+func newFakeRoleRepository() *fakeRoleRepository {
+	return &fakeRoleRepository{
+		roles: map[domain.UserRole]domain.Role{
+			domain.UserRoleCustomer: {Name: domain.UserRoleCustomer, IsSystem: true},
+			domain.UserRoleAdmin:    {Name: domain.UserRoleAdmin, IsSystem: true},
+		},
+	}
+}
+
+func (f *fakeRoleRepository) CreateRole(_ context.Context, role *domain.Role) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	f.roles[role.Name] = *role
+	return nil
+}
+
+func (f *fakeRoleRepository) GetRoleByName(_ context.Context, name domain.UserRole) (*domain.Role, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	role, ok := f.roles[name]
+	if !ok {
+		return nil, repository.ErrNotFound
+	}
+	return &role, nil
+}
+
+func (f *fakeRoleRepository) UpdateRole(ctx context.Context, role *domain.Role) error {
+	return f.CreateRole(ctx, role)
+}
+
+func (f *fakeRoleRepository) DeleteRole(_ context.Context, name domain.UserRole) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	delete(f.roles, name)
+	return nil
+}
+
+type fakeTokenVersions struct{}
+
+func (fakeTokenVersions) Get(context.Context, uint) (int64, error) { return 0, nil }
+func (fakeTokenVersions) Bump(context.Context, uint) error         { return nil }
+
+// fakeCache keeps JSON encoded values in memory, ignoring their TTL.
+type fakeCache struct {
+	cache.Cache
+
+	mu     sync.Mutex
+	values map[string][]byte
+}
+
+func (f *fakeCache) Set(_ context.Context, key string, value any, _ time.Duration) error {
+	data, err := json.Marshal(value)
+	if err != nil {
+		return err
+	}
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	f.values[key] = data
+	return nil
+}
+
+func (f *fakeCache) Take(_ context.Context, key string, dest any) (bool, error) {
+	f.mu.Lock()
+	data, ok := f.values[key]
+	delete(f.values, key)
+	f.mu.Unlock()
+
+	if !ok {
+		return false, nil
+	}
+	return true, json.Unmarshal(data, dest)
+}
+
+// fakeRateLimiter counts the events of each key without letting them
+// expire, which is enough within one lockout period.
+type fakeRateLimiter struct {
+	helper.RateLimiter
+
+	mu     sync.Mutex
+	events map[string]int
+}
+
+func (f *fakeRateLimiter) AllowN(_ context.Context, key string, limit redis_rate.Limit, n int) (*redis_rate.Result, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	res := &redis_rate.Result{Limit: limit}
+	if f.events[key]+n <= limit.Burst {
+		f.events[key] += n
+		res.Allowed = n
+	}
+	res.Remaining = limit.Burst - f.events[key]
+	res.ResetAfter = time.Duration(f.events[key]) * limit.Period
+	return res, nil
+}
+
+func (f *fakeRateLimiter) Reset(_ context.Context, key string) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	delete(f.events, key)
+	return nil
+}
+
+// newFakeDB returns a gorm.DB whose transactions always commit, for
+// services whose repositories are all fakes.
+func newFakeDB(t *testing.T) *gorm.DB {
+	t.Helper()
+
+	db, err := gorm.Open(postgres.New(postgres.Config{Conn: &fakeConnPool{}}), &gorm.Config{
+		Logger: logger.Discard,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return db
+}
+
+// fakeConnPool lets gorm run transactions without a database; the fake
+// repositories never send it a query.
+type fakeConnPool struct{}
+
+func (fakeConnPool) PrepareContext(context.Context, string) (*sql.Stmt, error) {
+	return nil, errors.New("fakeConnPool: no database")
+}
+
+func (fakeConnPool) ExecContext(context.Context, string, ...any) (sql.Result, error) {
+	return nil, errors.New("fakeConnPool: no database")
+}
+
+func (fakeConnPool) QueryContext(context.Context, string, ...any) (*sql.Rows, error) {
+	return nil, errors.New("fakeConnPool: no database")
+}
+
+func (fakeConnPool) QueryRowContext(context.Context, string, ...any) *sql.Row {
+	return nil
+}
+
+func (p *fakeConnPool) BeginTx(context.Context, *sql.TxOptions) (gorm.ConnPool, error) {
+	return &fakeTx{}, nil
+}
+
+type fakeTx struct {
+	fakeConnPool
+}
+
+func (*fakeTx) Commit() error   { return nil }
+func (*fakeTx) Rollback() error { return nil }
@@ -0,0 +1,266 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"strings"
+
+	"github.com/saleh-ghazimoradi/Cartopher/infra/cache"
+	"github.com/saleh-ghazimoradi/Cartopher/infra/events"
+	"github.com/saleh-ghazimoradi/Cartopher/internal/domain"
+	"github.com/saleh-ghazimoradi/Cartopher/internal/dto"
+	"github.com/saleh-ghazimoradi/Cartopher/internal/repository"
+	"gorm.io/gorm"
+)
+
+var ErrNoDefaultWarehouse = errors.New("there is no default warehouse to hold the stock, set it per warehouse instead")
+
+type InventoryService interface {
+	CreateWarehouse(ctx context.Context, req *dto.CreateWarehouseRequest) (*dto.WarehouseResponse, error)
+	GetWarehouses(ctx context.Context) ([]*dto.WarehouseResponse, error)
+	UpdateWarehouse(ctx context.Context, id uint, req *dto.UpdateWarehouseRequest) (*dto.WarehouseResponse, error)
+	DeleteWarehouse(ctx context.Context, id uint) error
+
+	GetProductStock(ctx context.Context, productId uint) ([]*dto.StockLevelResponse, error)
+	SetStockLevel(ctx context.Context, productId uint, req *dto.SetStockLevelRequest) ([]*dto.StockLevelResponse, error)
+	GetLowStockProducts(ctx context.Context) ([]*dto.LowStockProductResponse, error)
+}
+
+type inventoryService struct {
+	outboxRepository    repository.OutboxRepository
+	inventoryRepository repository.InventoryRepository
+	productRepository   repository.ProductRepository
+	cache               cache.Cache
+	db                  *gorm.DB
+}
+
+func (i *inventoryService) CreateWarehouse(ctx context.Context, req *dto.CreateWarehouseRequest) (*dto.WarehouseResponse, error) {
+	warehouse := &domain.Warehouse{
+		Name:       req.Name,
+		Code:       strings.ToUpper(req.Code),
+		Priority:   req.Priority,
+		Country:    strings.ToUpper(req.Country),
+		City:       req.City,
+		PostalCode: req.PostalCode,
+		Latitude:   req.Latitude,
+		Longitude:  req.Longitude,
+		IsActive:   true,
+	}
+
+	if err := i.inventoryRepository.CreateWarehouse(ctx, warehouse); err != nil {
+		return nil, err
+	}
+
+	return i.convertToWarehouseResponse(warehouse), nil
+}
+
+func (i *inventoryService) GetWarehouses(ctx context.Context) ([]*dto.WarehouseResponse, error) {
+	warehouses, err := i.inventoryRepository.GetWarehouses(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	response := make([]*dto.WarehouseResponse, len(warehouses))
+	for idx := range warehouses {
+		response[idx] = i.convertToWarehouseResponse(warehouses[idx])
+	}
+
+	return response, nil
+}
+
+func (i *inventoryService) UpdateWarehouse(ctx context.Context, id uint, req *dto.UpdateWarehouseRequest) (*dto.WarehouseResponse, error) {
+	warehouse, err := i.inventoryRepository.GetWarehouseById(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	warehouse.Name = req.Name
+	warehouse.Priority = req.Priority
+	warehouse.Country = strings.ToUpper(req.Country)
+	warehouse.City = req.City
+	warehouse.PostalCode = req.PostalCode
+	warehouse.Latitude = req.Latitude
+	warehouse.Longitude = req.Longitude
+	if req.IsActive != nil {
+		warehouse.IsActive = *req.IsActive
+	}
+
+	if err := i.inventoryRepository.UpdateWarehouse(ctx, warehouse); err != nil {
+		return nil, err
+	}
+
+	_ = i.invalidateProducts(ctx)
+
+	return i.convertToWarehouseResponse(warehouse), nil
+}
+
+func (i *inventoryService) DeleteWarehouse(ctx context.Context, id uint) error {
+	if err := i.inventoryRepository.DeleteWarehouse(ctx, id); err != nil {
+		return err
+	}
+
+	_ = i.invalidateProducts(ctx)
+
+	return nil
+}
+
+func (i *inventoryService) GetProductStock(ctx context.Context, productId uint) ([]*dto.StockLevelResponse, error) {
+	stocks, err := i.inventoryRepository.GetProductStocks(ctx, productId)
+	if err != nil {
+		return nil, err
+	}
+
+	response := make([]*dto.StockLevelResponse, len(stocks))
+	for idx := range stocks {
+		response[idx] = &dto.StockLevelResponse{
+			WarehouseId:   stocks[idx].WarehouseId,
+			WarehouseCode: stocks[idx].Warehouse.Code,
+			WarehouseName: stocks[idx].Warehouse.Name,
+			Quantity:      stocks[idx].Quantity,
+			UpdatedAt:     stocks[idx].UpdatedAt,
+		}
+	}
+
+	return response, nil
+}
+
+func (i *inventoryService) SetStockLevel(ctx context.Context, productId uint, req *dto.SetStockLevelRequest) ([]*dto.StockLevelResponse, error) {
+	product, err := i.productRepository.GetProductById(ctx, productId)
+	if err != nil {
+		return nil, err
+	}
+
+	warehouse, err := i.inventoryRepository.GetWarehouseById(ctx, req.WarehouseId)
+	if err != nil {
+		return nil, err
+	}
+
+	err = i.db.Transaction(func(tx *gorm.DB) error {
+		return setStockLevel(ctx, i.inventoryRepository.WithTx(tx), i.outboxRepository.WithTx(tx), product, warehouse, req.Quantity)
+	})
+
+	if err != nil {
+		return nil, err
+	}
+
+	_ = i.cache.Delete(ctx, cache.ProductById(productId))
+	_ = i.cache.DeleteByPrefix(ctx, cache.ProductListPrefix())
+
+	return i.GetProductStock(ctx, productId)
+}
+
+func (i *inventoryService) GetLowStockProducts(ctx context.Context) ([]*dto.LowStockProductResponse, error) {
+	products, err := i.inventoryRepository.GetLowStockProducts(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	response := make([]*dto.LowStockProductResponse, len(products))
+	for idx := range products {
+		response[idx] = &dto.LowStockProductResponse{
+			ProductId: products[idx].ProductId,
+			Name:      products[idx].Name,
+			SKU:       products[idx].SKU,
+			Stock:     products[idx].Stock,
+			Threshold: products[idx].Threshold,
+		}
+	}
+
+	return response, nil
+}
+
+func (i *inventoryService) convertToWarehouseResponse(warehouse *domain.Warehouse) *dto.WarehouseResponse {
+	return &dto.WarehouseResponse{
+		Id:         warehouse.Id,
+		Name:       warehouse.Name,
+		Code:       warehouse.Code,
+		Priority:   warehouse.Priority,
+		Country:    warehouse.Country,
+		City:       warehouse.City,
+		PostalCode: warehouse.PostalCode,
+		Latitude:   warehouse.Latitude,
+		Longitude:  warehouse.Longitude,
+		IsActive:   warehouse.IsActive,
+		CreatedAt:  warehouse.CreatedAt,
+		UpdatedAt:  warehouse.UpdatedAt,
+	}
+}
+
+// invalidateProducts drops every cached product, since availability is
+// aggregated over warehouses and a warehouse change affects all of them.
+func (i *inventoryService) invalidateProducts(ctx context.Context) error {
+	if err := i.cache.DeleteByPrefix(ctx, cache.ProductByIdPrefix()); err != nil {
+		return err
+	}
+	return i.cache.DeleteByPrefix(ctx, cache.ProductListPrefix())
+}
+
+// setStockLevel sets the stock of the product in the warehouse, recording
+// PRODUCT_LOW_STOCK if that takes it to its threshold. The repositories
+// must be bound to a transaction: the stock of the product is locked for
+// the rest of it, so concurrent changes each see the one before.
+func setStockLevel(ctx context.Context, inventoryRepository repository.InventoryRepository, outboxRepository repository.OutboxRepository, product *domain.Product, warehouse *domain.Warehouse, quantity int) error {
+	stocks, err := inventoryRepository.GetStocksForUpdate(ctx, []uint{product.Id})
+	if err != nil {
+		return err
+	}
+
+	var before, after int
+	for idx := range stocks {
+		before += stocks[idx].Quantity
+		if stocks[idx].WarehouseId != warehouse.Id {
+			after += stocks[idx].Quantity
+		}
+	}
+	if warehouse.IsActive {
+		after += quantity
+	}
+
+	if err := inventoryRepository.SetStockLevel(ctx, warehouse.Id, product.Id, quantity); err != nil {
+		return err
+	}
+
+	return enqueueLowStock(ctx, outboxRepository, product, before, after)
+}
+
+// setDefaultStockLevel sets the stock of the product in the default
+// warehouse, for catalogues that do not manage warehouses. Like
+// setStockLevel, it must run in a transaction.
+func setDefaultStockLevel(ctx context.Context, inventoryRepository repository.InventoryRepository, outboxRepository repository.OutboxRepository, product *domain.Product, quantity int) error {
+	warehouse, err := inventoryRepository.GetWarehouseByCode(ctx, domain.DefaultWarehouseCode)
+	if err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			return ErrNoDefaultWarehouse
+		}
+		return err
+	}
+
+	return setStockLevel(ctx, inventoryRepository, outboxRepository, product, warehouse, quantity)
+}
+
+// enqueueLowStock records PRODUCT_LOW_STOCK when a stock change takes a
+// product from above its threshold to at or below it.
+func enqueueLowStock(ctx context.Context, outboxRepository repository.OutboxRepository, product *domain.Product, before, after int) error {
+	threshold := product.StockThreshold()
+	if threshold <= 0 || before <= threshold || after > threshold {
+		return nil
+	}
+
+	return enqueueEvent(ctx, outboxRepository, events.ProductLowStock, &events.ProductLowStockV1{
+		ProductId: product.Id,
+		Name:      product.Name,
+		SKU:       product.SKU,
+		Stock:     after,
+		Threshold: threshold,
+	})
+}
+
+func NewInventoryService(outboxRepository repository.OutboxRepository, inventoryRepository repository.InventoryRepository, productRepository repository.ProductRepository, cache cache.Cache, db *gorm.DB) InventoryService {
+	return &inventoryService{
+		outboxRepository:    outboxRepository,
+		inventoryRepository: inventoryRepository,
+		productRepository:   productRepository,
+		cache:               cache,
+		db:                  db,
+	}
+}
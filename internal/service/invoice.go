@@ -0,0 +1,136 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/saleh-ghazimoradi/Cartopher/config"
+	"github.com/saleh-ghazimoradi/Cartopher/internal/domain"
+	"github.com/saleh-ghazimoradi/Cartopher/internal/dto"
+	"github.com/saleh-ghazimoradi/Cartopher/internal/repository"
+	"github.com/saleh-ghazimoradi/Cartopher/pkg/uploadProvider"
+)
+
+const defaultInvoicePrefix = "INV"
+
+var ErrNotInvoiced = errors.New("order has not been invoiced yet")
+
+type InvoiceService interface {
+	GetInvoice(ctx context.Context, userId, orderId uint) (*dto.DocumentResponse, error)
+	RegenerateInvoice(ctx context.Context, orderId uint) (*dto.InvoiceResponse, error)
+	GetPackingSlip(ctx context.Context, orderId uint) (*dto.DocumentResponse, error)
+}
+
+type invoiceService struct {
+	cfg             config.Invoice
+	orderRepository repository.OrderRepository
+	provider        uploadProvider.UploadProvider
+}
+
+// GetInvoice returns the customer's invoice, rendering and storing it on
+// first access or when the stored copy has gone missing. The provider must
+// keep invoices private; they are only served through this method.
+func (i *invoiceService) GetInvoice(ctx context.Context, userId, orderId uint) (*dto.DocumentResponse, error) {
+	order, err := i.orderRepository.GetOrderByUserId(ctx, userId, orderId)
+	if err != nil {
+		return nil, err
+	}
+
+	if order.InvoiceNumber == nil {
+		return nil, ErrNotInvoiced
+	}
+
+	if order.InvoicePath != "" {
+		if content, err := i.provider.ReadFile(order.InvoicePath); err == nil {
+			return &dto.DocumentResponse{Filename: invoiceFilename(order), Content: content}, nil
+		}
+	}
+
+	content, err := i.storeInvoice(ctx, order)
+	if err != nil {
+		return nil, err
+	}
+
+	return &dto.DocumentResponse{Filename: invoiceFilename(order), Content: content}, nil
+}
+
+// RegenerateInvoice re-renders an invoice and replaces the stored copy. The
+// invoice number and date are kept.
+func (i *invoiceService) RegenerateInvoice(ctx context.Context, orderId uint) (*dto.InvoiceResponse, error) {
+	order, err := i.orderRepository.GetOrderById(ctx, orderId)
+	if err != nil {
+		return nil, err
+	}
+
+	if order.InvoiceNumber == nil {
+		return nil, ErrNotInvoiced
+	}
+
+	if _, err := i.storeInvoice(ctx, order); err != nil {
+		return nil, err
+	}
+
+	return &dto.InvoiceResponse{
+		OrderId:       order.Id,
+		InvoiceNumber: *order.InvoiceNumber,
+		InvoicedAt:    *order.InvoicedAt,
+	}, nil
+}
+
+func (i *invoiceService) GetPackingSlip(ctx context.Context, orderId uint) (*dto.DocumentResponse, error) {
+	order, err := i.orderRepository.GetOrderById(ctx, orderId)
+	if err != nil {
+		return nil, err
+	}
+
+	content, err := renderPackingSlip(i.cfg, order)
+	if err != nil {
+		return nil, err
+	}
+
+	return &dto.DocumentResponse{
+		Filename: fmt.Sprintf("packing-slip-%d.pdf", order.Id),
+		Content:  content,
+	}, nil
+}
+
+func (i *invoiceService) storeInvoice(ctx context.Context, order *domain.Order) ([]byte, error) {
+	content, err := renderInvoice(i.cfg, order)
+	if err != nil {
+		return nil, err
+	}
+
+	path := fmt.Sprintf("invoices/%d/%s", order.Id, invoiceFilename(order))
+	if _, err := i.provider.UploadBytes(content, path, "application/pdf"); err != nil {
+		return nil, err
+	}
+
+	if order.InvoicePath != path {
+		if err := i.orderRepository.SetInvoicePath(ctx, order.Id, path); err != nil {
+			return nil, err
+		}
+		order.InvoicePath = path
+	}
+
+	return content, nil
+}
+
+func invoiceFilename(order *domain.Order) string {
+	return fmt.Sprintf("%s.pdf", *order.InvoiceNumber)
+}
+
+func formatInvoiceNumber(prefix string, number int64) string {
+	if prefix == "" {
+		prefix = defaultInvoicePrefix
+	}
+	return fmt.Sprintf("%s-%06d", prefix, number)
+}
+
+func NewInvoiceService(cfg *config.Config, orderRepository repository.OrderRepository, provider uploadProvider.UploadProvider) InvoiceService {
+	return &invoiceService{
+		cfg:             cfg.Invoice,
+		orderRepository: orderRepository,
+		provider:        provider,
+	}
+}
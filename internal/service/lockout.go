@@ -0,0 +1,154 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/go-redis/redis_rate/v10"
+	"github.com/saleh-ghazimoradi/Cartopher/config"
+	"github.com/saleh-ghazimoradi/Cartopher/internal/helper"
+)
+
+const (
+	defaultLoginMaxAttempts   = 5
+	defaultLoginIPMaxAttempts = 50
+	defaultLoginLockout       = 15 * time.Minute
+
+	loginBaseDelay = 250 * time.Millisecond
+	loginMaxDelay  = 4 * time.Second
+
+	loginEmailPrefix = "login:email:"
+	loginIPPrefix    = "login:ip:"
+)
+
+var ErrLoginLocked = errors.New("too many failed login attempts, please try again later")
+
+// LoginLockedError is returned while an account or client is locked out
+// after too many failed logins. It matches ErrLoginLocked.
+type LoginLockedError struct {
+	RetryAfter time.Duration
+}
+
+func (e *LoginLockedError) Error() string {
+	return ErrLoginLocked.Error()
+}
+
+func (e *LoginLockedError) Unwrap() error {
+	return ErrLoginLocked
+}
+
+// loginThrottle counts failed logins per email and per client IP. Each
+// failure costs one lockout period: once the attempts are used up the key
+// is locked for a period, after which it regains one attempt per period.
+// Failures are answered more slowly the more there were.
+type loginThrottle struct {
+	rateLimiter helper.RateLimiter
+	emailLimit  redis_rate.Limit
+	ipLimit     redis_rate.Limit
+}
+
+// loginAttempt is an attempt taken from the throttle before the
+// credentials of a login are checked.
+type loginAttempt struct {
+	// failures counts the attempts on the email within its lockout period,
+	// this one included.
+	failures int
+	// lockedUntil is set if this was the last attempt the email had.
+	lockedUntil *time.Time
+}
+
+// attempt takes one attempt from email and ip, or returns a
+// LoginLockedError if either is locked out. The attempt is counted before
+// the credentials are checked, so concurrent guesses cannot all get in
+// before the first failure is recorded; a successful login gives it back
+// with reset.
+func (l *loginThrottle) attempt(ctx context.Context, email, ip string) (*loginAttempt, error) {
+	// The client IP goes first, so a locked out client does not use up the
+	// attempts of the email.
+	if ip != "" {
+		if _, err := l.take(ctx, loginIPPrefix+ip, l.ipLimit); err != nil {
+			return nil, err
+		}
+	}
+
+	res, err := l.take(ctx, loginEmailPrefix+normalizeEmail(email), l.emailLimit)
+	if err != nil {
+		return nil, err
+	}
+
+	attempt := &loginAttempt{failures: l.emailLimit.Burst - res.Remaining}
+	if res.Remaining == 0 {
+		until := time.Now().Add(lockedFor(res))
+		attempt.lockedUntil = &until
+	}
+	return attempt, nil
+}
+
+// take takes one attempt from key, or returns a LoginLockedError if it has
+// none left.
+func (l *loginThrottle) take(ctx context.Context, key string, limit redis_rate.Limit) (*redis_rate.Result, error) {
+	res, err := l.rateLimiter.AllowN(ctx, key, limit, 1)
+	if err != nil {
+		return nil, err
+	}
+
+	if res.Allowed < 1 {
+		return nil, &LoginLockedError{RetryAfter: lockedFor(res)}
+	}
+	return res, nil
+}
+
+// fail waits out the delay of a failed attempt. It reports whether the
+// attempt locked the email, and until when.
+func (l *loginThrottle) fail(ctx context.Context, attempt *loginAttempt) (lockedUntil *time.Time, err error) {
+	delay := min(loginBaseDelay<<min(max(attempt.failures-1, 0), 8), loginMaxDelay)
+
+	select {
+	case <-time.After(delay):
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+
+	return attempt.lockedUntil, nil
+}
+
+// reset forgets the attempts of email, after a successful login or when an
+// admin unlocks the account. Attempts of client IPs are kept.
+func (l *loginThrottle) reset(ctx context.Context, email string) error {
+	return l.rateLimiter.Reset(ctx, loginEmailPrefix+normalizeEmail(email))
+}
+
+// lockedFor returns how long a key that has used up its attempts stays
+// locked: until its oldest counted attempt expires.
+func lockedFor(res *redis_rate.Result) time.Duration {
+	return res.ResetAfter - time.Duration(res.Limit.Burst-1)*res.Limit.Period
+}
+
+func normalizeEmail(email string) string {
+	return strings.ToLower(strings.TrimSpace(email))
+}
+
+func newLoginThrottle(cfg *config.Config, rateLimiter helper.RateLimiter) *loginThrottle {
+	maxAttempts := cfg.Auth.LoginMaxAttempts
+	if maxAttempts <= 0 {
+		maxAttempts = defaultLoginMaxAttempts
+	}
+
+	ipMaxAttempts := cfg.Auth.LoginIPMaxAttempts
+	if ipMaxAttempts <= 0 {
+		ipMaxAttempts = defaultLoginIPMaxAttempts
+	}
+
+	lockout := cfg.Auth.LoginLockout
+	if lockout <= 0 {
+		lockout = defaultLoginLockout
+	}
+
+	return &loginThrottle{
+		rateLimiter: rateLimiter,
+		emailLimit:  redis_rate.Limit{Rate: 1, Burst: maxAttempts, Period: lockout},
+		ipLimit:     redis_rate.Limit{Rate: 1, Burst: ipMaxAttempts, Period: lockout},
+	}
+}
@@ -0,0 +1,128 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/saleh-ghazimoradi/Cartopher/config"
+	"github.com/saleh-ghazimoradi/Cartopher/internal/domain"
+	"github.com/saleh-ghazimoradi/Cartopher/internal/dto"
+	"github.com/saleh-ghazimoradi/Cartopher/utils"
+)
+
+const (
+	testLoginMaxAttempts = 5
+	testLoginEmail       = "ada@example.com"
+	testLoginPassword    = "correct horse battery staple"
+)
+
+// countingUserRepository counts the logins that got past the throttle to
+// have their credentials checked.
+type countingUserRepository struct {
+	*fakeUserRepository
+	lookups atomic.Int64
+}
+
+func (c *countingUserRepository) GetUserByEmailAndActive(ctx context.Context, email string, isActive bool) (*domain.User, error) {
+	c.lookups.Add(1)
+	return c.fakeUserRepository.GetUserByEmailAndActive(ctx, email, isActive)
+}
+
+func newLockoutTest(t *testing.T) (AuthService, *countingUserRepository) {
+	t.Helper()
+
+	cfg := &config.Config{}
+	cfg.JWT.Secret = "test-secret"
+	cfg.JWT.ExpiresIn = time.Minute
+	cfg.JWT.RefreshTokenExpires = time.Hour
+	cfg.Auth.LoginMaxAttempts = testLoginMaxAttempts
+
+	keys, err := utils.LoadKeySet(cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	password, err := utils.HashPassword(testLoginPassword)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	users := &countingUserRepository{fakeUserRepository: &fakeUserRepository{}}
+	_ = users.CreateUser(context.Background(), &domain.User{
+		Email:    testLoginEmail,
+		Password: password,
+		Role:     domain.UserRoleCustomer,
+	})
+
+	auth := NewAuthService(cfg, keys, users, &fakeCartRepository{}, &fakeOutboxRepository{}, newFakeRoleRepository(), fakeTokenVersions{}, &fakeRateLimiter{events: make(map[string]int)}, &fakeCache{values: make(map[string][]byte)}, newFakeDB(t))
+
+	return auth, users
+}
+
+// failLogin tries a wrong password without waiting out the delay of the
+// failure.
+func failLogin(auth AuthService) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+
+	_, err := auth.Login(ctx, &dto.LoginRequest{Email: testLoginEmail, Password: "wrong password"})
+	return err
+}
+
+func TestLoginChecksAtMostMaxAttemptsConcurrently(t *testing.T) {
+	auth, users := newLockoutTest(t)
+
+	const guesses = 4 * testLoginMaxAttempts
+
+	var (
+		wg     sync.WaitGroup
+		locked atomic.Int64
+	)
+	for range guesses {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if errors.Is(failLogin(auth), ErrLoginLocked) {
+				locked.Add(1)
+			}
+		}()
+	}
+	wg.Wait()
+
+	if got := users.lookups.Load(); got > testLoginMaxAttempts {
+		t.Fatalf("%d concurrent guesses had their password checked, want at most %d", got, testLoginMaxAttempts)
+	}
+	if got := locked.Load(); got != guesses-testLoginMaxAttempts {
+		t.Fatalf("%d guesses were locked out, want %d", got, guesses-testLoginMaxAttempts)
+	}
+
+	// The right password is refused too while the account is locked.
+	_, err := auth.Login(context.Background(), &dto.LoginRequest{Email: testLoginEmail, Password: testLoginPassword})
+	if !errors.Is(err, ErrLoginLocked) {
+		t.Fatalf("login with the right password: got %v, want %v", err, ErrLoginLocked)
+	}
+}
+
+func TestLoginGivesAttemptsBackOnSuccess(t *testing.T) {
+	auth, _ := newLockoutTest(t)
+
+	for range testLoginMaxAttempts - 1 {
+		if err := failLogin(auth); errors.Is(err, ErrLoginLocked) {
+			t.Fatal("locked out before using up the attempts")
+		}
+	}
+
+	if _, err := auth.Login(context.Background(), &dto.LoginRequest{Email: testLoginEmail, Password: testLoginPassword}); err != nil {
+		t.Fatalf("login with the right password: %v", err)
+	}
+
+	for range testLoginMaxAttempts {
+		if err := failLogin(auth); errors.Is(err, ErrLoginLocked) {
+			t.Fatal("attempts before a successful login were still counted")
+		}
+	}
+}
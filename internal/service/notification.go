@@ -4,22 +4,31 @@ import (
 	"fmt"
 	"net"
 	"net/smtp"
+	"strings"
 
 	"github.com/saleh-ghazimoradi/Cartopher/config"
+	"github.com/saleh-ghazimoradi/Cartopher/infra/events"
+	"github.com/saleh-ghazimoradi/Cartopher/infra/mailer"
 	"github.com/saleh-ghazimoradi/Cartopher/internal/dto"
 )
 
-const (
-	UserLoggedIn = "USER_LOGGED_IN"
-)
-
 type Notifier interface {
 	Send(email *dto.Email) error
 	SendLoginNotification(userEmail, username string) error
+	SendEmailVerification(verification *events.EmailVerificationRequestedV1) error
+	SendPasswordReset(reset *events.PasswordResetRequestedV1) error
+	SendRefreshTokenReuseAlert(reuse *events.RefreshTokenReusedV1) error
+	SendAccountLocked(lockout *events.AccountLockedV1) error
+	SendLowStockAlert(product *events.ProductLowStockV1) error
+	SendOrderConfirmation(order *events.OrderV1) error
+	SendOrderShipped(order *events.OrderV1) error
+	SendOrderDelivered(order *events.OrderV1) error
+	SendOrderCancelled(order *events.OrderV1) error
 }
 
 type emailNotifier struct {
-	cfg *config.Config
+	cfg      *config.Config
+	renderer *mailer.Renderer
 }
 
 func (e *emailNotifier) Send(email *dto.Email) error {
@@ -61,9 +70,18 @@ func (e *emailNotifier) Send(email *dto.Email) error {
 		return err
 	}
 
-	msg := fmt.Sprintf("From: %s\r\nTo: %s\r\nSubject: %s\r\n\r\n%s", e.cfg.SMTP.From, email.To, email.Subject, email.Body)
+	message := &mailer.Message{
+		Subject: email.Subject,
+		Text:    email.Body,
+		HTML:    email.HTML,
+	}
 
-	_, err = w.Write([]byte(msg))
+	msg, err := message.Build(e.cfg.SMTP.From, email.To)
+	if err != nil {
+		return err
+	}
+
+	_, err = w.Write(msg)
 	if err != nil {
 		return err
 	}
@@ -72,23 +90,77 @@ func (e *emailNotifier) Send(email *dto.Email) error {
 }
 
 func (e *emailNotifier) SendLoginNotification(userEmail, username string) error {
-	email := &dto.Email{
-		To:      userEmail,
-		Subject: "Login Notification",
-		Body: fmt.Sprintf(`Hello %s
-	
-You have successfully logged into your account.
+	return e.sendTemplate(userEmail, events.UserLoggedIn, map[string]string{"Name": username})
+}
+
+func (e *emailNotifier) SendEmailVerification(verification *events.EmailVerificationRequestedV1) error {
+	return e.sendTemplate(verification.Email, events.EmailVerificationRequested, verification)
+}
+
+func (e *emailNotifier) SendPasswordReset(reset *events.PasswordResetRequestedV1) error {
+	return e.sendTemplate(reset.Email, events.PasswordResetRequested, reset)
+}
+
+func (e *emailNotifier) SendRefreshTokenReuseAlert(reuse *events.RefreshTokenReusedV1) error {
+	return e.sendTemplate(reuse.Email, events.RefreshTokenReused, reuse)
+}
+
+func (e *emailNotifier) SendAccountLocked(lockout *events.AccountLockedV1) error {
+	return e.sendTemplate(lockout.Email, events.AccountLocked, lockout)
+}
+
+func (e *emailNotifier) SendLowStockAlert(product *events.ProductLowStockV1) error {
+	for _, adminEmail := range e.cfg.Notifier.AdminEmails {
+		if err := e.sendTemplate(adminEmail, events.ProductLowStock, product); err != nil {
+			return err
+		}
+	}
+	return nil
+}
 
-If this was not you, please contact support immediately.
+func (e *emailNotifier) SendOrderConfirmation(order *events.OrderV1) error {
+	return e.sendTemplate(order.Email, events.OrderCreated, order)
+}
 
-Yours,
-The Cartopher Team,`, username),
+func (e *emailNotifier) SendOrderShipped(order *events.OrderV1) error {
+	if order.Shipment == nil {
+		return fmt.Errorf("order %d shipped event has no shipment", order.OrderId)
 	}
-	return e.Send(email)
+	return e.sendTemplate(order.Email, events.OrderShipped, order)
+}
+
+func (e *emailNotifier) SendOrderDelivered(order *events.OrderV1) error {
+	return e.sendTemplate(order.Email, events.OrderDelivered, order)
+}
+
+func (e *emailNotifier) SendOrderCancelled(order *events.OrderV1) error {
+	return e.sendTemplate(order.Email, events.OrderCancelled, order)
+}
+
+// sendTemplate renders the template of the event type in the store-wide
+// locale and sends it.
+func (e *emailNotifier) sendTemplate(to, eventType string, data any) error {
+	message, err := e.renderer.Render(templateName(eventType), e.cfg.Notifier.Locale, data)
+	if err != nil {
+		return err
+	}
+
+	return e.Send(&dto.Email{
+		To:      to,
+		Subject: message.Subject,
+		Body:    message.Text,
+		HTML:    message.HTML,
+	})
+}
+
+// templateName maps an event type to the template rendering its email.
+func templateName(eventType string) string {
+	return strings.ToLower(eventType)
 }
 
 func NewEmailNotifier(cfg *config.Config) Notifier {
 	return &emailNotifier{
-		cfg: cfg,
+		cfg:      cfg,
+		renderer: mailer.NewRenderer(cfg.Notifier.TemplatesDir, cfg.Notifier.Locale),
 	}
 }
@@ -0,0 +1,385 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/coreos/go-oidc/v3/oidc"
+	"github.com/saleh-ghazimoradi/Cartopher/config"
+	"github.com/saleh-ghazimoradi/Cartopher/infra/cache"
+	"github.com/saleh-ghazimoradi/Cartopher/internal/domain"
+	"github.com/saleh-ghazimoradi/Cartopher/internal/dto"
+	"github.com/saleh-ghazimoradi/Cartopher/internal/repository"
+	"github.com/saleh-ghazimoradi/Cartopher/utils"
+	"golang.org/x/oauth2"
+	"gorm.io/gorm"
+)
+
+const defaultOIDCStateExpires = 10 * time.Minute
+
+var (
+	ErrUnknownOIDCProvider  = errors.New("unknown identity provider")
+	ErrInvalidOIDCState     = errors.New("invalid or expired login state")
+	ErrOIDCLoginFailed      = errors.New("failed to log in with the identity provider")
+	ErrOIDCEmailNotVerified = errors.New("the identity provider has not verified the email address")
+	// ErrOIDCAccountNotLinkable is returned when the email address belongs
+	// to an account that must not be taken over by whoever controls the
+	// address at a provider; its owner logs in with their password instead.
+	ErrOIDCAccountNotLinkable = errors.New("the account with this email address cannot be linked to the identity provider")
+)
+
+// oidcProviders logs users in through OpenID Connect providers with the
+// authorization code flow and PKCE. The verifier and nonce of a login are
+// kept in Redis under its state until the provider redirects back.
+type oidcProviders struct {
+	cache        cache.Cache
+	stateExpires time.Duration
+	providers    map[string]*oidcProvider
+	names        []string
+}
+
+// oidcProvider discovers its provider on first use, so a provider that is
+// down does not keep the server from starting.
+type oidcProvider struct {
+	config   config.OIDCProvider
+	mu       sync.Mutex
+	provider *oidc.Provider
+}
+
+// oidcLogin is a login waiting for the provider to redirect back.
+type oidcLogin struct {
+	Provider string `json:"provider"`
+	Verifier string `json:"verifier"`
+	Nonce    string `json:"nonce"`
+}
+
+// oidcClaims are the claims of an ID token used to find or create a user.
+type oidcClaims struct {
+	Subject       string `json:"sub"`
+	Email         string `json:"email"`
+	EmailVerified bool   `json:"email_verified"`
+	Name          string `json:"name"`
+	GivenName     string `json:"given_name"`
+	FamilyName    string `json:"family_name"`
+}
+
+func (p *oidcProvider) discover(ctx context.Context) (*oidc.Provider, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if p.provider == nil {
+		provider, err := oidc.NewProvider(ctx, p.config.Issuer)
+		if err != nil {
+			return nil, fmt.Errorf("failed to discover identity provider %s: %w", p.config.Name, err)
+		}
+		p.provider = provider
+	}
+	return p.provider, nil
+}
+
+func (p *oidcProvider) oauth2Config(provider *oidc.Provider) *oauth2.Config {
+	scopes := p.config.Scopes
+	if len(scopes) == 0 {
+		scopes = []string{oidc.ScopeOpenID, "email", "profile"}
+	}
+
+	return &oauth2.Config{
+		ClientID:     p.config.ClientId,
+		ClientSecret: p.config.ClientSecret,
+		RedirectURL:  p.config.RedirectURL,
+		Endpoint:     provider.Endpoint(),
+		Scopes:       scopes,
+	}
+}
+
+func (o *oidcProviders) get(name string) (*oidcProvider, error) {
+	provider, ok := o.providers[name]
+	if !ok {
+		return nil, ErrUnknownOIDCProvider
+	}
+	return provider, nil
+}
+
+// authorize starts a login and returns where to send the user.
+func (o *oidcProviders) authorize(ctx context.Context, name string) (*dto.OIDCAuthorizationResponse, error) {
+	p, err := o.get(name)
+	if err != nil {
+		return nil, err
+	}
+
+	provider, err := p.discover(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	state, err := utils.GenerateOpaqueToken()
+	if err != nil {
+		return nil, err
+	}
+
+	nonce, err := utils.GenerateOpaqueToken()
+	if err != nil {
+		return nil, err
+	}
+
+	login := &oidcLogin{
+		Provider: name,
+		Verifier: oauth2.GenerateVerifier(),
+		Nonce:    nonce,
+	}
+
+	if err := o.cache.Set(ctx, cache.OIDCState(state), login, o.stateExpires); err != nil {
+		return nil, err
+	}
+
+	return &dto.OIDCAuthorizationResponse{
+		AuthorizationURL: p.oauth2Config(provider).AuthCodeURL(state, oidc.Nonce(nonce), oauth2.S256ChallengeOption(login.Verifier)),
+		State:            state,
+	}, nil
+}
+
+// exchange completes a login started by authorize, returning the claims of
+// the verified ID token.
+func (o *oidcProviders) exchange(ctx context.Context, name string, req *dto.OIDCLoginRequest) (*oidcClaims, error) {
+	p, err := o.get(name)
+	if err != nil {
+		return nil, err
+	}
+
+	var login oidcLogin
+	found, err := o.cache.Take(ctx, cache.OIDCState(req.State), &login)
+	if err != nil {
+		return nil, err
+	}
+	if !found || login.Provider != name {
+		return nil, ErrInvalidOIDCState
+	}
+
+	provider, err := p.discover(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	token, err := p.oauth2Config(provider).Exchange(ctx, req.Code, oauth2.VerifierOption(login.Verifier))
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrOIDCLoginFailed, err)
+	}
+
+	rawIdToken, ok := token.Extra("id_token").(string)
+	if !ok {
+		return nil, fmt.Errorf("%w: no ID token returned", ErrOIDCLoginFailed)
+	}
+
+	idToken, err := provider.Verifier(&oidc.Config{ClientID: p.config.ClientId}).Verify(ctx, rawIdToken)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrOIDCLoginFailed, err)
+	}
+
+	if idToken.Nonce != login.Nonce {
+		return nil, fmt.Errorf("%w: nonce mismatch", ErrOIDCLoginFailed)
+	}
+
+	var claims oidcClaims
+	if err := idToken.Claims(&claims); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrOIDCLoginFailed, err)
+	}
+
+	return &claims, nil
+}
+
+// OIDCProviders returns the names of the identity providers users can log
+// in with.
+func (a *authService) OIDCProviders() []string {
+	return a.oidcProviders.names
+}
+
+func (a *authService) AuthorizeOIDC(ctx context.Context, provider string) (*dto.OIDCAuthorizationResponse, error) {
+	return a.oidcProviders.authorize(ctx, provider)
+}
+
+// LoginWithOIDC logs a user in with the code an identity provider passed
+// back. A user who logged in with the provider before is found by their
+// identity; otherwise the verified email address links the identity to
+// the user with that address, or to a new user. Only verified customer
+// accounts are linked: an unverified one may have been registered by
+// someone else ahead of the address's owner, and staff accounts are not
+// handed to whoever controls the address at a provider.
+func (a *authService) LoginWithOIDC(ctx context.Context, provider string, req *dto.OIDCLoginRequest) (*dto.AuthResponse, error) {
+	claims, err := a.oidcProviders.exchange(ctx, provider, req)
+	if err != nil {
+		return nil, err
+	}
+
+	user, err := a.oidcUser(ctx, provider, claims)
+	if err != nil {
+		return nil, err
+	}
+
+	if !user.IsActive {
+		return nil, fmt.Errorf("%w: invalid credentials", ErrOIDCLoginFailed)
+	}
+
+	// The provider is only a first factor.
+	if user.TOTPEnabledAt != nil {
+		return a.twoFactorChallenge(user)
+	}
+
+	return a.generateAuthResponse(ctx, user, false)
+}
+
+func (a *authService) oidcUser(ctx context.Context, provider string, claims *oidcClaims) (*domain.User, error) {
+	user, err := a.identityUser(ctx, provider, claims.Subject)
+	if !errors.Is(err, repository.ErrNotFound) {
+		return user, err
+	}
+
+	user, err = a.linkOIDCUser(ctx, provider, claims)
+	if err != nil {
+		// A concurrent first login with the same identity may have linked
+		// it in the meantime, making this one fail on the unique identity
+		// or email address.
+		if user, lookupErr := a.identityUser(ctx, provider, claims.Subject); lookupErr == nil {
+			return user, nil
+		}
+		return nil, err
+	}
+	return user, nil
+}
+
+// identityUser returns the user linked to the identity, or
+// repository.ErrNotFound if there is none.
+func (a *authService) identityUser(ctx context.Context, provider, subject string) (*domain.User, error) {
+	identity, err := a.userRepository.GetUserIdentity(ctx, provider, subject)
+	if err != nil {
+		return nil, err
+	}
+
+	user, err := a.userRepository.GetUserById(ctx, identity.UserId)
+	if err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			return nil, fmt.Errorf("%w: invalid credentials", ErrOIDCLoginFailed)
+		}
+		return nil, err
+	}
+	return user, nil
+}
+
+// linkOIDCUser links a new identity to the user with its verified email
+// address, or to a new user.
+func (a *authService) linkOIDCUser(ctx context.Context, provider string, claims *oidcClaims) (*domain.User, error) {
+	if claims.Email == "" || !claims.EmailVerified {
+		return nil, ErrOIDCEmailNotVerified
+	}
+
+	identity := &domain.UserIdentity{
+		Provider: provider,
+		Subject:  claims.Subject,
+		Email:    claims.Email,
+	}
+
+	user, err := a.userRepository.GetUserByEmail(ctx, claims.Email)
+	if err != nil && !errors.Is(err, repository.ErrNotFound) {
+		return nil, err
+	}
+
+	if user != nil {
+		if err := a.checkLinkable(ctx, user); err != nil {
+			return nil, err
+		}
+
+		identity.UserId = user.Id
+		if err := a.userRepository.CreateUserIdentity(ctx, identity); err != nil {
+			return nil, err
+		}
+		return user, nil
+	}
+
+	// Users created through a provider have no password they know; they
+	// can set one with a password reset.
+	password, err := utils.GenerateOpaqueToken()
+	if err != nil {
+		return nil, err
+	}
+
+	hashedPassword, err := utils.HashPassword(password)
+	if err != nil {
+		return nil, err
+	}
+
+	firstName, lastName := claims.GivenName, claims.FamilyName
+	if firstName == "" && lastName == "" {
+		firstName, lastName, _ = strings.Cut(strings.TrimSpace(claims.Name), " ")
+	}
+
+	now := time.Now()
+
+	user = &domain.User{
+		Email:           claims.Email,
+		Password:        hashedPassword,
+		FirstName:       firstName,
+		LastName:        lastName,
+		Role:            domain.UserRoleCustomer,
+		EmailVerifiedAt: &now,
+	}
+
+	err = a.db.Transaction(func(tx *gorm.DB) error {
+		if err := a.userRepository.WithTx(tx).CreateUser(ctx, user); err != nil {
+			return err
+		}
+
+		cart := &domain.Cart{UserId: user.Id}
+		if err := a.cartRepository.WithTx(tx).CreateCart(ctx, cart); err != nil {
+			return err
+		}
+
+		identity.UserId = user.Id
+		return a.userRepository.WithTx(tx).CreateUserIdentity(ctx, identity)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return user, nil
+}
+
+// checkLinkable returns ErrOIDCAccountNotLinkable unless an identity may be
+// linked to the existing user by their email address.
+func (a *authService) checkLinkable(ctx context.Context, user *domain.User) error {
+	if user.EmailVerifiedAt == nil {
+		return fmt.Errorf("%w: its email address is not verified", ErrOIDCAccountNotLinkable)
+	}
+
+	permissions, err := rolePermissions(ctx, a.roleRepository, user.Role)
+	if err != nil {
+		return err
+	}
+	if len(permissions) > 0 {
+		return fmt.Errorf("%w: it is a staff account", ErrOIDCAccountNotLinkable)
+	}
+	return nil
+}
+
+func newOIDCProviders(cfg *config.Config, cacheService cache.Cache) *oidcProviders {
+	stateExpires := cfg.OIDC.StateExpires
+	if stateExpires <= 0 {
+		stateExpires = defaultOIDCStateExpires
+	}
+
+	providers := &oidcProviders{
+		cache:        cacheService,
+		stateExpires: stateExpires,
+		providers:    make(map[string]*oidcProvider, len(cfg.OIDC.Providers)),
+		names:        make([]string, 0, len(cfg.OIDC.Providers)),
+	}
+
+	for _, provider := range cfg.OIDC.Providers {
+		providers.providers[provider.Name] = &oidcProvider{config: provider}
+		providers.names = append(providers.names, provider.Name)
+	}
+
+	return providers
+}
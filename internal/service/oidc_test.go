@@ -0,0 +1,422 @@
+package service
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/saleh-ghazimoradi/Cartopher/config"
+	"github.com/saleh-ghazimoradi/Cartopher/internal/domain"
+	"github.com/saleh-ghazimoradi/Cartopher/internal/dto"
+	"github.com/saleh-ghazimoradi/Cartopher/utils"
+)
+
+const (
+	testOIDCProvider = "test"
+	testOIDCClientId = "cartopher"
+	testOIDCKeyId    = "test-key"
+	testOIDCCode     = "authorization-code"
+)
+
+// mockOIDCProvider is an OpenID Connect provider serving discovery, its
+// JWKS and a token endpoint that checks the PKCE verifier of the code.
+type mockOIDCProvider struct {
+	t      *testing.T
+	server *httptest.Server
+	key    *rsa.PrivateKey
+
+	mu        sync.Mutex
+	challenge string
+	nonce     string
+	verifier  string
+	claims    jwt.MapClaims
+}
+
+func newMockOIDCProvider(t *testing.T) *mockOIDCProvider {
+	t.Helper()
+
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	p := &mockOIDCProvider{t: t, key: key}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/.well-known/openid-configuration", p.discovery)
+	mux.HandleFunc("/jwks", p.jwks)
+	mux.HandleFunc("/token", p.token)
+
+	p.server = httptest.NewServer(mux)
+	t.Cleanup(p.server.Close)
+
+	return p
+}
+
+func (p *mockOIDCProvider) discovery(w http.ResponseWriter, _ *http.Request) {
+	writeJSON(w, map[string]any{
+		"issuer":                                p.server.URL,
+		"authorization_endpoint":                p.server.URL + "/authorize",
+		"token_endpoint":                        p.server.URL + "/token",
+		"jwks_uri":                              p.server.URL + "/jwks",
+		"id_token_signing_alg_values_supported": []string{"RS256"},
+	})
+}
+
+func (p *mockOIDCProvider) jwks(w http.ResponseWriter, _ *http.Request) {
+	writeJSON(w, map[string]any{
+		"keys": []map[string]string{{
+			"kty": "RSA",
+			"kid": testOIDCKeyId,
+			"use": "sig",
+			"alg": "RS256",
+			"n":   base64.RawURLEncoding.EncodeToString(p.key.N.Bytes()),
+			"e":   base64.RawURLEncoding.EncodeToString(big.NewInt(int64(p.key.E)).Bytes()),
+		}},
+	})
+}
+
+func (p *mockOIDCProvider) token(w http.ResponseWriter, r *http.Request) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if r.FormValue("code") != testOIDCCode {
+		http.Error(w, `{"error":"invalid_grant"}`, http.StatusBadRequest)
+		return
+	}
+
+	p.verifier = r.FormValue("code_verifier")
+	sum := sha256.Sum256([]byte(p.verifier))
+	if base64.RawURLEncoding.EncodeToString(sum[:]) != p.challenge {
+		http.Error(w, `{"error":"invalid_grant"}`, http.StatusBadRequest)
+		return
+	}
+
+	claims := jwt.MapClaims{
+		"iss":   p.server.URL,
+		"aud":   testOIDCClientId,
+		"exp":   time.Now().Add(time.Minute).Unix(),
+		"iat":   time.Now().Unix(),
+		"nonce": p.nonce,
+	}
+	for name, value := range p.claims {
+		claims[name] = value
+	}
+
+	token := jwt.NewWithClaims(jwt.SigningMethodRS256, claims)
+	token.Header["kid"] = testOIDCKeyId
+	idToken, err := token.SignedString(p.key)
+	if err != nil {
+		p.t.Error(err)
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	writeJSON(w, map[string]any{
+		"access_token": "provider-access-token",
+		"token_type":   "Bearer",
+		"expires_in":   60,
+		"id_token":     idToken,
+	})
+}
+
+// authorize starts a login and lets the provider expect its PKCE challenge
+// and nonce, as if the user signed in and got redirected back.
+func (p *mockOIDCProvider) authorize(t *testing.T, auth AuthService, claims jwt.MapClaims) string {
+	t.Helper()
+
+	authorization, err := auth.AuthorizeOIDC(context.Background(), testOIDCProvider)
+	if err != nil {
+		t.Fatalf("AuthorizeOIDC: %v", err)
+	}
+
+	authorizationURL, err := url.Parse(authorization.AuthorizationURL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	query := authorizationURL.Query()
+
+	if query.Get("code_challenge_method") != "S256" || query.Get("code_challenge") == "" {
+		t.Fatalf("authorization URL has no S256 PKCE challenge: %s", authorization.AuthorizationURL)
+	}
+	if query.Get("state") != authorization.State {
+		t.Fatalf("authorization URL state %q, want %q", query.Get("state"), authorization.State)
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.challenge = query.Get("code_challenge")
+	p.nonce = query.Get("nonce")
+	p.claims = claims
+
+	return authorization.State
+}
+
+func writeJSON(w http.ResponseWriter, body any) {
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(body)
+}
+
+type oidcTest struct {
+	provider *mockOIDCProvider
+	users    *fakeUserRepository
+	carts    *fakeCartRepository
+	auth     AuthService
+}
+
+func newOIDCTest(t *testing.T) *oidcTest {
+	t.Helper()
+
+	provider := newMockOIDCProvider(t)
+
+	cfg := &config.Config{}
+	cfg.JWT.Secret = "test-secret"
+	cfg.JWT.ExpiresIn = time.Minute
+	cfg.JWT.RefreshTokenExpires = time.Hour
+	cfg.OIDC.Providers = []config.OIDCProvider{{
+		Name:         testOIDCProvider,
+		Issuer:       provider.server.URL,
+		ClientId:     testOIDCClientId,
+		ClientSecret: "client-secret",
+		RedirectURL:  "https://shop.example/oidc/callback",
+	}}
+
+	keys, err := utils.LoadKeySet(cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	users := &fakeUserRepository{}
+	carts := &fakeCartRepository{}
+	auth := NewAuthService(cfg, keys, users, carts, &fakeOutboxRepository{}, newFakeRoleRepository(), fakeTokenVersions{}, nil, &fakeCache{values: make(map[string][]byte)}, newFakeDB(t))
+
+	return &oidcTest{
+		provider: provider,
+		users:    users,
+		carts:    carts,
+		auth:     auth,
+	}
+}
+
+func (o *oidcTest) login(t *testing.T, claims jwt.MapClaims) (*dto.AuthResponse, error) {
+	t.Helper()
+
+	state := o.provider.authorize(t, o.auth, claims)
+	return o.auth.LoginWithOIDC(context.Background(), testOIDCProvider, &dto.OIDCLoginRequest{
+		Code:  testOIDCCode,
+		State: state,
+	})
+}
+
+func verifiedClaims(subject, email string) jwt.MapClaims {
+	return jwt.MapClaims{
+		"sub":            subject,
+		"email":          email,
+		"email_verified": true,
+		"given_name":     "Ada",
+		"family_name":    "Lovelace",
+	}
+}
+
+func TestLoginWithOIDCSendsPKCEVerifier(t *testing.T) {
+	o := newOIDCTest(t)
+
+	if _, err := o.login(t, verifiedClaims("subject-1", "ada@example.com")); err != nil {
+		t.Fatalf("LoginWithOIDC: %v", err)
+	}
+
+	// The token endpoint refuses a code without the verifier matching the
+	// challenge, so the login succeeding already shows it was sent.
+	o.provider.mu.Lock()
+	defer o.provider.mu.Unlock()
+	if o.provider.verifier == "" {
+		t.Fatal("no PKCE verifier was sent with the code")
+	}
+}
+
+func TestLoginWithOIDCTakesStateOnce(t *testing.T) {
+	o := newOIDCTest(t)
+
+	state := o.provider.authorize(t, o.auth, verifiedClaims("subject-1", "ada@example.com"))
+	req := &dto.OIDCLoginRequest{Code: testOIDCCode, State: state}
+
+	if _, err := o.auth.LoginWithOIDC(context.Background(), testOIDCProvider, req); err != nil {
+		t.Fatalf("first LoginWithOIDC: %v", err)
+	}
+
+	if _, err := o.auth.LoginWithOIDC(context.Background(), testOIDCProvider, req); !errors.Is(err, ErrInvalidOIDCState) {
+		t.Fatalf("second LoginWithOIDC with the same state: got %v, want %v", err, ErrInvalidOIDCState)
+	}
+}
+
+func TestLoginWithOIDCRejectsNonceMismatch(t *testing.T) {
+	o := newOIDCTest(t)
+
+	state := o.provider.authorize(t, o.auth, verifiedClaims("subject-1", "ada@example.com"))
+	o.provider.mu.Lock()
+	o.provider.nonce = "another-login"
+	o.provider.mu.Unlock()
+
+	_, err := o.auth.LoginWithOIDC(context.Background(), testOIDCProvider, &dto.OIDCLoginRequest{
+		Code:  testOIDCCode,
+		State: state,
+	})
+	if !errors.Is(err, ErrOIDCLoginFailed) {
+		t.Fatalf("got %v, want %v", err, ErrOIDCLoginFailed)
+	}
+	if len(o.users.users) != 0 {
+		t.Fatalf("a user was created for a token with the wrong nonce")
+	}
+}
+
+func TestLoginWithOIDCRejectsUnverifiedEmail(t *testing.T) {
+	o := newOIDCTest(t)
+
+	claims := verifiedClaims("subject-1", "ada@example.com")
+	claims["email_verified"] = false
+
+	if _, err := o.login(t, claims); !errors.Is(err, ErrOIDCEmailNotVerified) {
+		t.Fatalf("got %v, want %v", err, ErrOIDCEmailNotVerified)
+	}
+	if len(o.users.users) != 0 {
+		t.Fatalf("a user was created for an unverified email address")
+	}
+}
+
+func TestLoginWithOIDCCreatesUserAndCart(t *testing.T) {
+	o := newOIDCTest(t)
+
+	response, err := o.login(t, verifiedClaims("subject-1", "ada@example.com"))
+	if err != nil {
+		t.Fatalf("LoginWithOIDC: %v", err)
+	}
+
+	if len(o.users.users) != 1 {
+		t.Fatalf("got %d users, want 1", len(o.users.users))
+	}
+	user := o.users.users[0]
+	if user.Email != "ada@example.com" || user.FirstName != "Ada" || user.LastName != "Lovelace" {
+		t.Errorf("user created as %s %s <%s>", user.FirstName, user.LastName, user.Email)
+	}
+	if user.Role != domain.UserRoleCustomer {
+		t.Errorf("user created with role %s, want %s", user.Role, domain.UserRoleCustomer)
+	}
+	if user.EmailVerifiedAt == nil {
+		t.Error("user created without a verified email address")
+	}
+
+	if len(o.carts.carts) != 1 || o.carts.carts[0].UserId != user.Id {
+		t.Errorf("got carts %+v, want one for user %d", o.carts.carts, user.Id)
+	}
+
+	if len(o.users.identities) != 1 || o.users.identities[0].UserId != user.Id {
+		t.Errorf("got identities %+v, want one for user %d", o.users.identities, user.Id)
+	}
+
+	if response.AccessToken == "" || response.RefreshToken == "" || response.User.Id != user.Id {
+		t.Errorf("got response %+v, want tokens for user %d", response, user.Id)
+	}
+
+	// The next login finds the user by their identity.
+	if _, err := o.login(t, verifiedClaims("subject-1", "ada@example.com")); err != nil {
+		t.Fatalf("second LoginWithOIDC: %v", err)
+	}
+	if len(o.users.users) != 1 || len(o.users.identities) != 1 {
+		t.Fatalf("second login created another user or identity")
+	}
+}
+
+func TestLoginWithOIDCLinksVerifiedAccountByEmail(t *testing.T) {
+	o := newOIDCTest(t)
+
+	verifiedAt := time.Now()
+	existing := &domain.User{Email: "ada@example.com", Role: domain.UserRoleCustomer, EmailVerifiedAt: &verifiedAt}
+	_ = o.users.CreateUser(context.Background(), existing)
+
+	response, err := o.login(t, verifiedClaims("subject-1", "ada@example.com"))
+	if err != nil {
+		t.Fatalf("LoginWithOIDC: %v", err)
+	}
+
+	if len(o.users.users) != 1 {
+		t.Fatalf("got %d users, want the existing one only", len(o.users.users))
+	}
+	if len(o.users.identities) != 1 || o.users.identities[0].UserId != existing.Id {
+		t.Errorf("got identities %+v, want one for user %d", o.users.identities, existing.Id)
+	}
+	if response.User.Id != existing.Id {
+		t.Errorf("logged in as user %d, want %d", response.User.Id, existing.Id)
+	}
+}
+
+func TestLoginWithOIDCRefusesToLinkUnverifiedOrStaffAccounts(t *testing.T) {
+	verifiedAt := time.Now()
+
+	tests := []struct {
+		name string
+		user *domain.User
+	}{
+		{
+			name: "unverified",
+			user: &domain.User{Email: "ada@example.com", Role: domain.UserRoleCustomer},
+		},
+		{
+			name: "staff",
+			user: &domain.User{Email: "ada@example.com", Role: domain.UserRoleAdmin, EmailVerifiedAt: &verifiedAt},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			o := newOIDCTest(t)
+			_ = o.users.CreateUser(context.Background(), tt.user)
+
+			if _, err := o.login(t, verifiedClaims("subject-1", "ada@example.com")); !errors.Is(err, ErrOIDCAccountNotLinkable) {
+				t.Fatalf("got %v, want %v", err, ErrOIDCAccountNotLinkable)
+			}
+			if len(o.users.identities) != 0 {
+				t.Fatalf("an identity was linked: %+v", o.users.identities)
+			}
+		})
+	}
+}
+
+func TestLoginWithOIDCRecoversFromConcurrentFirstLogin(t *testing.T) {
+	o := newOIDCTest(t)
+
+	// Another login with the same identity links it first, so this one's
+	// insert hits the unique identity.
+	var winner *domain.User
+	o.users.createIdentity = func(identity *domain.UserIdentity) error {
+		o.users.createIdentity = nil
+
+		winner = &domain.User{Email: "ada@example.com", Role: domain.UserRoleCustomer}
+		_ = o.users.CreateUser(context.Background(), winner)
+		_ = o.users.CreateUserIdentity(context.Background(), &domain.UserIdentity{
+			UserId:   winner.Id,
+			Provider: identity.Provider,
+			Subject:  identity.Subject,
+		})
+		return errors.New(`duplicate key value violates unique constraint "user_identities_provider_subject_key"`)
+	}
+
+	response, err := o.login(t, verifiedClaims("subject-1", "ada@example.com"))
+	if err != nil {
+		t.Fatalf("LoginWithOIDC: %v", err)
+	}
+	if response.User.Id != winner.Id {
+		t.Fatalf("logged in as user %d, want the concurrently linked user %d", response.User.Id, winner.Id)
+	}
+}
@@ -4,7 +4,12 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"strings"
+	"time"
 
+	"github.com/saleh-ghazimoradi/Cartopher/config"
+	"github.com/saleh-ghazimoradi/Cartopher/infra/cache"
+	"github.com/saleh-ghazimoradi/Cartopher/infra/events"
 	"github.com/saleh-ghazimoradi/Cartopher/internal/domain"
 	"github.com/saleh-ghazimoradi/Cartopher/internal/dto"
 	"github.com/saleh-ghazimoradi/Cartopher/internal/helper"
@@ -13,25 +18,52 @@ import (
 )
 
 type OrderService interface {
-	CreateOrder(ctx context.Context, userId uint) (*dto.OrderResponse, error)
+	CreateOrder(ctx context.Context, userId uint, req *dto.CreateOrderRequest) (*dto.OrderResponse, error)
 	GetOrder(ctx context.Context, userId, orderId uint) (*dto.OrderResponse, error)
 	GetOrders(ctx context.Context, userId uint, page, limit int) ([]*dto.OrderResponse, *helper.PaginatedMeta, error)
+	ConfirmOrder(ctx context.Context, orderId uint) (*dto.OrderResponse, error)
+	CancelOrder(ctx context.Context, userId, orderId uint) (*dto.OrderResponse, error)
 }
 
 type orderService struct {
-	orderRepository   repository.OrderRepository
-	cartRepository    repository.CartRepository
-	productRepository repository.ProductRepository
-	db                *gorm.DB
+	strategy             domain.FulfilmentStrategy
+	requireVerifiedEmail domain.EmailVerificationRequirement
+	userRepository       repository.UserRepository
+	invoicePrefix        string
+	outboxRepository     repository.OutboxRepository
+	orderRepository      repository.OrderRepository
+	cartRepository       repository.CartRepository
+	productRepository    repository.ProductRepository
+	inventoryRepository  repository.InventoryRepository
+	cache                cache.Cache
+	db                   *gorm.DB
 }
 
-func (o *orderService) CreateOrder(ctx context.Context, userId uint) (*dto.OrderResponse, error) {
-	var orderResponse *dto.OrderResponse
+func (o *orderService) CreateOrder(ctx context.Context, userId uint, req *dto.CreateOrderRequest) (*dto.OrderResponse, error) {
+	var createdOrder *domain.Order
+	var products []*domain.Product
+	before := make(map[uint]int)
+	allocated := make(map[uint]int)
+
+	if o.requireVerifiedEmail != domain.EmailVerificationOptional {
+		user, err := o.userRepository.GetUserById(ctx, userId)
+		if err != nil {
+			return nil, err
+		}
+		if user.EmailVerifiedAt == nil {
+			return nil, ErrEmailNotVerified
+		}
+	}
+
+	var shippingAddress domain.Address
+	if req != nil && req.ShippingAddress != nil {
+		shippingAddress = o.convertToAddress(req.ShippingAddress)
+	}
 
 	err := o.db.Transaction(func(tx *gorm.DB) error {
 
 		cartRepo := o.cartRepository.WithTx(tx)
-		productRepo := o.productRepository.WithTx(tx)
+		inventoryRepo := o.inventoryRepository.WithTx(tx)
 		orderRepo := o.orderRepository.WithTx(tx)
 
 		cart, err := cartRepo.GetCartWithItemsAndProducts(ctx, userId)
@@ -43,35 +75,61 @@ func (o *orderService) CreateOrder(ctx context.Context, userId uint) (*dto.Order
 			return errors.New("cart items is empty")
 		}
 
-		var totalAmount float64
-		var orderItems []domain.OrderItem
+		lines := make([]allocationLine, len(cart.CartItems))
+		productIds := make([]uint, len(cart.CartItems))
+		prices := make(map[uint]float64, len(cart.CartItems))
 
 		for i := range cart.CartItems {
 			item := &cart.CartItems[i]
-
-			if item.Product.Stock < item.Quantity {
-				return fmt.Errorf("not enough stock for product: %s", item.Product.Name)
+			lines[i] = allocationLine{
+				ProductId: item.ProductId,
+				Name:      item.Product.Name,
+				Quantity:  item.Quantity,
 			}
+			productIds[i] = item.ProductId
+			prices[item.ProductId] = item.Product.Price
+			products = append(products, &item.Product)
+		}
+
+		stocks, err := inventoryRepo.GetStocksForUpdate(ctx, productIds)
+		if err != nil {
+			return err
+		}
 
-			item.Product.Stock -= item.Quantity
-			if err := productRepo.UpdateProduct(ctx, &item.Product); err != nil {
+		for i := range stocks {
+			before[stocks[i].ProductId] += stocks[i].Quantity
+		}
+
+		allocations, err := allocate(o.strategy, lines, stocks, shippingAddress)
+		if err != nil {
+			return err
+		}
+
+		var totalAmount float64
+		var orderItems []domain.OrderItem
+
+		for _, a := range allocations {
+			if err := inventoryRepo.AdjustStock(ctx, a.WarehouseId, a.ProductId, -a.Quantity); err != nil {
 				return err
 			}
 
-			totalAmount += float64(item.Quantity) * item.Product.Price
+			allocated[a.ProductId] += a.Quantity
+			totalAmount += float64(a.Quantity) * prices[a.ProductId]
 
 			orderItems = append(orderItems, domain.OrderItem{
-				ProductId: item.ProductId,
-				Quantity:  item.Quantity,
-				Price:     item.Product.Price,
+				ProductId:   a.ProductId,
+				WarehouseId: a.WarehouseId,
+				Quantity:    a.Quantity,
+				Price:       prices[a.ProductId],
 			})
 		}
 
 		order := &domain.Order{
-			UserId:      userId,
-			Status:      domain.OrderStatusPending,
-			TotalAmount: totalAmount,
-			OrderItems:  orderItems,
+			UserId:          userId,
+			Status:          domain.OrderStatusPending,
+			TotalAmount:     totalAmount,
+			ShippingAddress: shippingAddress,
+			OrderItems:      orderItems,
 		}
 
 		if err := orderRepo.CreateOrder(ctx, order); err != nil {
@@ -82,22 +140,36 @@ func (o *orderService) CreateOrder(ctx context.Context, userId uint) (*dto.Order
 			return err
 		}
 
-		createdOrder, err := orderRepo.GetOrderById(ctx, order.Id)
+		createdOrder, err = orderRepo.GetOrderById(ctx, order.Id)
 		if err != nil {
 			return err
 		}
 
-		resp := o.convertToOrderRepository(createdOrder)
-		orderResponse = resp
+		outboxRepo := o.outboxRepository.WithTx(tx)
+		for _, product := range products {
+			if err := enqueueLowStock(ctx, outboxRepo, product, before[product.Id], before[product.Id]-allocated[product.Id]); err != nil {
+				return err
+			}
+		}
+
+		if err := enqueueCartUpdated(ctx, outboxRepo, cart.Id, userId); err != nil {
+			return err
+		}
 
-		return nil
+		return enqueueOrderEvent(ctx, outboxRepo, events.OrderCreated, createdOrder, nil)
 	})
 
 	if err != nil {
 		return nil, err
 	}
 
-	return orderResponse, nil
+	productIds := make([]uint, len(products))
+	for i, product := range products {
+		productIds[i] = product.Id
+	}
+	o.invalidateProducts(ctx, productIds)
+
+	return o.convertToOrderRepository(createdOrder), nil
 }
 
 func (o *orderService) GetOrders(ctx context.Context, userId uint, page, limit int) ([]*dto.OrderResponse, *helper.PaginatedMeta, error) {
@@ -153,6 +225,111 @@ func (o *orderService) GetOrder(ctx context.Context, userId, orderId uint) (*dto
 	return response, nil
 }
 
+// ConfirmOrder accepts a pending order and issues its invoice number in the
+// same transaction, so a number is only ever consumed by a confirmed order.
+func (o *orderService) ConfirmOrder(ctx context.Context, orderId uint) (*dto.OrderResponse, error) {
+	err := o.db.Transaction(func(tx *gorm.DB) error {
+		orderRepo := o.orderRepository.WithTx(tx)
+
+		order, err := orderRepo.GetOrderForUpdate(ctx, orderId)
+		if err != nil {
+			return err
+		}
+
+		if order.Status != domain.OrderStatusPending {
+			return fmt.Errorf("cannot confirm an order that is %s", order.Status)
+		}
+
+		if err := orderRepo.UpdateOrderStatus(ctx, orderId, domain.OrderStatusConfirmed); err != nil {
+			return err
+		}
+
+		number, err := orderRepo.NextInvoiceNumber(ctx)
+		if err != nil {
+			return err
+		}
+
+		if err := orderRepo.SetInvoice(ctx, orderId, formatInvoiceNumber(o.invoicePrefix, number), time.Now()); err != nil {
+			return err
+		}
+
+		confirmedOrder, err := orderRepo.GetOrderById(ctx, orderId)
+		if err != nil {
+			return err
+		}
+
+		return enqueueOrderEvent(ctx, o.outboxRepository.WithTx(tx), events.OrderConfirmed, confirmedOrder, nil)
+	})
+
+	if err != nil {
+		return nil, err
+	}
+
+	return o.getOrderResponse(ctx, orderId)
+}
+
+// CancelOrder cancels one of the user's orders before anything has shipped
+// and puts the allocated stock back into the warehouses it was taken from.
+func (o *orderService) CancelOrder(ctx context.Context, userId, orderId uint) (*dto.OrderResponse, error) {
+	var cancelledOrder *domain.Order
+
+	err := o.db.Transaction(func(tx *gorm.DB) error {
+		orderRepo := o.orderRepository.WithTx(tx)
+		inventoryRepo := o.inventoryRepository.WithTx(tx)
+
+		order, err := orderRepo.GetOrderForUpdate(ctx, orderId)
+		if err != nil {
+			return err
+		}
+
+		if order.UserId != userId {
+			return repository.ErrNotFound
+		}
+
+		if order.Status != domain.OrderStatusPending && order.Status != domain.OrderStatusConfirmed {
+			return fmt.Errorf("cannot cancel an order that is %s", order.Status)
+		}
+
+		for _, item := range order.OrderItems {
+			if err := inventoryRepo.AdjustStock(ctx, item.WarehouseId, item.ProductId, item.Quantity); err != nil {
+				return err
+			}
+		}
+
+		if err := orderRepo.UpdateOrderStatus(ctx, orderId, domain.OrderStatusCancelled); err != nil {
+			return err
+		}
+
+		cancelledOrder, err = orderRepo.GetOrderById(ctx, orderId)
+		if err != nil {
+			return err
+		}
+
+		return enqueueOrderEvent(ctx, o.outboxRepository.WithTx(tx), events.OrderCancelled, cancelledOrder, nil)
+	})
+
+	if err != nil {
+		return nil, err
+	}
+
+	productIds := make([]uint, len(cancelledOrder.OrderItems))
+	for i, item := range cancelledOrder.OrderItems {
+		productIds[i] = item.ProductId
+	}
+	o.invalidateProducts(ctx, productIds)
+
+	return o.convertToOrderRepository(cancelledOrder), nil
+}
+
+// invalidateProducts drops the cached products whose stock an order
+// changed, along with the cached product lists.
+func (o *orderService) invalidateProducts(ctx context.Context, productIds []uint) {
+	for _, id := range productIds {
+		_ = o.cache.Delete(ctx, cache.ProductById(id))
+	}
+	_ = o.cache.DeleteByPrefix(ctx, cache.ProductListPrefix())
+}
+
 func (o *orderService) getOrderResponse(ctx context.Context, orderId uint) (*dto.OrderResponse, error) {
 	order, err := o.orderRepository.GetOrderById(ctx, orderId)
 	if err != nil {
@@ -176,7 +353,8 @@ func (o *orderService) convertToOrderRepository(order *domain.Order) *dto.OrderR
 				Name:        item.Product.Name,
 				Description: item.Product.Description,
 				Price:       item.Product.Price,
-				Stock:       item.Product.Stock,
+				Stock:       item.Product.TotalStock(),
+				InStock:     item.Product.TotalStock() > 0,
 				SKU:         item.Product.SKU,
 				IsActive:    item.Product.IsActive,
 				Category: dto.CategoryResponse{
@@ -186,27 +364,140 @@ func (o *orderService) convertToOrderRepository(order *domain.Order) *dto.OrderR
 					IsActive:    item.Product.Category.IsActive,
 				},
 			},
-			Quantity:  item.Quantity,
-			Price:     item.Price,
-			CreatedAt: item.CreatedAt,
+			WarehouseId:   item.WarehouseId,
+			WarehouseCode: item.Warehouse.Code,
+			Quantity:      item.Quantity,
+			Price:         item.Price,
+			CreatedAt:     item.CreatedAt,
 		}
 	}
+
+	var shippingAddress *dto.AddressResponse
+	if order.ShippingAddress.Line1 != "" {
+		shippingAddress = &dto.AddressResponse{
+			Name:       order.ShippingAddress.Name,
+			Line1:      order.ShippingAddress.Line1,
+			Line2:      order.ShippingAddress.Line2,
+			City:       order.ShippingAddress.City,
+			State:      order.ShippingAddress.State,
+			PostalCode: order.ShippingAddress.PostalCode,
+			Country:    order.ShippingAddress.Country,
+			Latitude:   order.ShippingAddress.Latitude,
+			Longitude:  order.ShippingAddress.Longitude,
+		}
+	}
+
+	var invoiceNumber string
+	if order.InvoiceNumber != nil {
+		invoiceNumber = *order.InvoiceNumber
+	}
+
+	shipments := make([]dto.ShipmentResponse, len(order.Shipments))
+	for i := range order.Shipments {
+		shipments[i] = *convertToShipmentResponse(&order.Shipments[i])
+	}
+
 	return &dto.OrderResponse{
-		Id:          order.Id,
-		UserId:      order.UserId,
-		Status:      string(order.Status),
-		TotalAmount: order.TotalAmount,
-		OrderItems:  orderItems,
-		CreatedAt:   order.CreatedAt,
-		UpdatedAt:   order.UpdatedAt,
+		Id:              order.Id,
+		UserId:          order.UserId,
+		Status:          string(order.Status),
+		TotalAmount:     order.TotalAmount,
+		ShippingAddress: shippingAddress,
+		InvoiceNumber:   invoiceNumber,
+		InvoicedAt:      order.InvoicedAt,
+		OrderItems:      orderItems,
+		Shipments:       shipments,
+		CreatedAt:       order.CreatedAt,
+		UpdatedAt:       order.UpdatedAt,
+	}
+}
+
+// enqueueOrderEvent records an order lifecycle event. The order must have its
+// User and OrderItems.Product loaded; shipment is only set for ORDER_SHIPPED.
+func enqueueOrderEvent(ctx context.Context, outboxRepository repository.OutboxRepository, eventType string, order *domain.Order, shipment *domain.Shipment) error {
+	var invoiceNumber string
+	if order.InvoiceNumber != nil {
+		invoiceNumber = *order.InvoiceNumber
+	}
+
+	event := &events.OrderV1{
+		OrderId:       order.Id,
+		UserId:        order.UserId,
+		Email:         order.User.Email,
+		CustomerName:  strings.TrimSpace(order.User.FirstName + " " + order.User.LastName),
+		Status:        string(order.Status),
+		TotalAmount:   order.TotalAmount,
+		InvoiceNumber: invoiceNumber,
+		Items:         convertToOrderEventItems(invoiceLines(order)),
+	}
+
+	if shipment != nil {
+		orderItems := make(map[uint]domain.OrderItem, len(order.OrderItems))
+		for _, item := range order.OrderItems {
+			orderItems[item.Id] = item
+		}
+
+		items := make([]events.OrderItemV1, len(shipment.Items))
+		for i, item := range shipment.Items {
+			orderItem := orderItems[item.OrderItemId]
+			items[i] = events.OrderItemV1{
+				SKU:      orderItem.Product.SKU,
+				Name:     orderItem.Product.Name,
+				Quantity: item.Quantity,
+				Price:    orderItem.Price,
+			}
+		}
+
+		event.Shipment = &events.OrderShipmentV1{
+			Id:             shipment.Id,
+			Carrier:        shipment.Carrier,
+			TrackingNumber: shipment.TrackingNumber,
+			Items:          items,
+		}
+	}
+
+	return enqueueEvent(ctx, outboxRepository, eventType, event)
+}
+
+func convertToOrderEventItems(lines []invoiceLine) []events.OrderItemV1 {
+	items := make([]events.OrderItemV1, len(lines))
+	for i, line := range lines {
+		items[i] = events.OrderItemV1{
+			SKU:      line.SKU,
+			Name:     line.Name,
+			Quantity: line.Quantity,
+			Price:    line.Price,
+		}
+	}
+	return items
+}
+
+func (o *orderService) convertToAddress(req *dto.AddressRequest) domain.Address {
+	return domain.Address{
+		Name:       req.Name,
+		Line1:      req.Line1,
+		Line2:      req.Line2,
+		City:       req.City,
+		State:      req.State,
+		PostalCode: req.PostalCode,
+		Country:    strings.ToUpper(req.Country),
+		Latitude:   req.Latitude,
+		Longitude:  req.Longitude,
 	}
 }
 
-func NewOrderService(orderRepository repository.OrderRepository, cartRepository repository.CartRepository, productRepository repository.ProductRepository, db *gorm.DB) OrderService {
+func NewOrderService(cfg *config.Config, userRepository repository.UserRepository, outboxRepository repository.OutboxRepository, orderRepository repository.OrderRepository, cartRepository repository.CartRepository, productRepository repository.ProductRepository, inventoryRepository repository.InventoryRepository, cacheService cache.Cache, db *gorm.DB) OrderService {
 	return &orderService{
-		orderRepository:   orderRepository,
-		cartRepository:    cartRepository,
-		productRepository: productRepository,
-		db:                db,
+		strategy:             parseFulfilmentStrategy(cfg.Fulfilment.Strategy),
+		requireVerifiedEmail: parseEmailVerificationRequirement(cfg.Auth.RequireVerifiedEmail),
+		userRepository:       userRepository,
+		invoicePrefix:        cfg.Invoice.Prefix,
+		outboxRepository:     outboxRepository,
+		orderRepository:      orderRepository,
+		cartRepository:       cartRepository,
+		productRepository:    productRepository,
+		inventoryRepository:  inventoryRepository,
+		cache:                cacheService,
+		db:                   db,
 	}
 }
@@ -0,0 +1,189 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/goccy/go-json"
+	"github.com/saleh-ghazimoradi/Cartopher/config"
+	"github.com/saleh-ghazimoradi/Cartopher/infra/events"
+	"github.com/saleh-ghazimoradi/Cartopher/internal/domain"
+	"github.com/saleh-ghazimoradi/Cartopher/internal/repository"
+	"gorm.io/gorm"
+)
+
+const (
+	defaultOutboxBatchSize  = 100
+	defaultOutboxMaxBackoff = 10 * time.Minute
+	outboxBaseBackoff       = time.Second
+	outboxLease             = 5 * time.Minute
+)
+
+type OutboxService interface {
+	RelayPending(ctx context.Context) (int, error)
+	DeleteSent(ctx context.Context, olderThan time.Duration) (int64, error)
+}
+
+type outboxService struct {
+	batchSize         int
+	maxBackoff        time.Duration
+	eventPublisher    events.Publisher
+	broadcaster       events.Broadcaster
+	outboxRepository  repository.OutboxRepository
+	webhookRepository repository.WebhookRepository
+	db                *gorm.DB
+}
+
+// RelayPending forwards one batch of due messages to the publisher and
+// returns how many were sent. The batch is claimed in a short transaction
+// leasing it to this relay, then published without holding row locks. A
+// message that fails to publish, or whose relay stops before recording the
+// outcome, is retried later, so delivery is at-least-once and messages may
+// arrive out of order; consumers can deduplicate on the outbox_id metadata.
+// Webhook deliveries of a domain event are created with its first claim,
+// and live update subscribers notified once that claim commits, whether or
+// not the broker accepts it.
+func (o *outboxService) RelayPending(ctx context.Context) (int, error) {
+	messages, err := o.claimPending(ctx)
+	if err != nil {
+		return 0, err
+	}
+
+	var sent int
+	for i := range messages {
+		message := &messages[i]
+
+		if message.Attempts == 1 && message.Metadata[MetadataTarget] == "" {
+			broadcastEvent(ctx, o.broadcaster, message)
+		}
+
+		metadata := make(map[string]string, len(message.Metadata)+1)
+		for k, v := range message.Metadata {
+			metadata[k] = v
+		}
+		metadata["outbox_id"] = fmt.Sprintf("%d", message.Id)
+
+		if err := o.eventPublisher.Publish(message.EventType, json.RawMessage(message.Payload), metadata); err != nil {
+			message.LastError = err.Error()
+			message.AvailableAt = time.Now().Add(exponentialBackoff(outboxBaseBackoff, o.maxBackoff, message.Attempts))
+		} else {
+			now := time.Now()
+			message.LastError = ""
+			message.SentAt = &now
+			sent++
+		}
+
+		if err := o.outboxRepository.UpdateMessage(ctx, message); err != nil {
+			return 0, err
+		}
+	}
+
+	return sent, nil
+}
+
+// claimPending leases a batch of due messages to this relay by counting the
+// attempt and holding them back from other relays for outboxLease, after
+// which the batch of a relay that stopped is taken over. Webhook deliveries
+// of domain events claimed for the first time are created with the claim.
+func (o *outboxService) claimPending(ctx context.Context) ([]domain.OutboxMessage, error) {
+	var messages []domain.OutboxMessage
+
+	err := o.db.Transaction(func(tx *gorm.DB) error {
+		outboxRepo := o.outboxRepository.WithTx(tx)
+		webhookRepo := o.webhookRepository.WithTx(tx)
+
+		var err error
+		messages, err = outboxRepo.GetPendingMessagesForUpdate(ctx, o.batchSize)
+		if err != nil {
+			return err
+		}
+
+		leasedUntil := time.Now().Add(outboxLease)
+		for i := range messages {
+			message := &messages[i]
+			message.Attempts++
+			message.AvailableAt = leasedUntil
+
+			// Retries and replays are addressed to a single consumer and
+			// were already fanned out as the original event.
+			if message.Attempts == 1 && message.Metadata[MetadataTarget] == "" {
+				if err := enqueueWebhookDeliveries(ctx, webhookRepo, message); err != nil {
+					return err
+				}
+			}
+
+			if err := outboxRepo.UpdateMessage(ctx, message); err != nil {
+				return err
+			}
+		}
+
+		return nil
+	})
+
+	if err != nil {
+		return nil, err
+	}
+
+	return messages, nil
+}
+
+// DeleteSent removes messages that were relayed more than olderThan ago.
+func (o *outboxService) DeleteSent(ctx context.Context, olderThan time.Duration) (int64, error) {
+	return o.outboxRepository.DeleteSentMessages(ctx, time.Now().Add(-olderThan))
+}
+
+// enqueueEvent records an event in the outbox. Called with a repository bound
+// to the transaction of the change that caused it, the event is relayed if
+// and only if that change commits.
+func enqueueEvent(ctx context.Context, outboxRepository repository.OutboxRepository, eventType string, payload events.Payload) error {
+	return enqueueMessage(ctx, outboxRepository, eventType, payload, nil)
+}
+
+// enqueueNotification records an event that is only relayed to the
+// notifier, for events carrying secrets that must not reach webhooks or live
+// update subscribers.
+func enqueueNotification(ctx context.Context, outboxRepository repository.OutboxRepository, eventType string, payload events.Payload) error {
+	return enqueueMessage(ctx, outboxRepository, eventType, payload, map[string]string{MetadataTarget: NotifierTarget})
+}
+
+func enqueueMessage(ctx context.Context, outboxRepository repository.OutboxRepository, eventType string, payload events.Payload, metadata map[string]string) error {
+	envelope, err := events.NewEnvelope(ctx, eventType, payload)
+	if err != nil {
+		return err
+	}
+
+	data, err := json.Marshal(envelope)
+	if err != nil {
+		return fmt.Errorf("failed to encode %s event: %w", eventType, err)
+	}
+
+	return outboxRepository.CreateMessage(ctx, &domain.OutboxMessage{
+		EventType:   eventType,
+		Payload:     data,
+		Metadata:    metadata,
+		AvailableAt: time.Now(),
+	})
+}
+
+func NewOutboxService(cfg *config.Config, eventPublisher events.Publisher, broadcaster events.Broadcaster, outboxRepository repository.OutboxRepository, webhookRepository repository.WebhookRepository, db *gorm.DB) OutboxService {
+	batchSize := cfg.Outbox.BatchSize
+	if batchSize <= 0 {
+		batchSize = defaultOutboxBatchSize
+	}
+
+	maxBackoff := cfg.Outbox.MaxBackoff
+	if maxBackoff <= 0 {
+		maxBackoff = defaultOutboxMaxBackoff
+	}
+
+	return &outboxService{
+		batchSize:         batchSize,
+		maxBackoff:        maxBackoff,
+		eventPublisher:    eventPublisher,
+		broadcaster:       broadcaster,
+		outboxRepository:  outboxRepository,
+		webhookRepository: webhookRepository,
+		db:                db,
+	}
+}
@@ -9,6 +9,7 @@ import (
 	"github.com/saleh-ghazimoradi/Cartopher/internal/dto"
 	"github.com/saleh-ghazimoradi/Cartopher/internal/helper"
 	"github.com/saleh-ghazimoradi/Cartopher/internal/repository"
+	"gorm.io/gorm"
 )
 
 type ProductService interface {
@@ -27,14 +28,18 @@ type ProductService interface {
 }
 
 type productService struct {
-	productRepository repository.ProductRepository
-	cache             cache.Cache
+	outboxRepository    repository.OutboxRepository
+	productRepository   repository.ProductRepository
+	inventoryRepository repository.InventoryRepository
+	cache               cache.Cache
+	db                  *gorm.DB
 }
 
 func (p *productService) CreateCategory(ctx context.Context, req *dto.CreateCategoryRequest) (*dto.CategoryResponse, error) {
 	category := &domain.Category{
-		Name:        req.Name,
-		Description: req.Description,
+		Name:              req.Name,
+		Description:       req.Description,
+		LowStockThreshold: req.LowStockThreshold,
 	}
 
 	if err := p.productRepository.CreateCategory(ctx, category); err != nil {
@@ -42,12 +47,13 @@ func (p *productService) CreateCategory(ctx context.Context, req *dto.CreateCate
 	}
 
 	return &dto.CategoryResponse{
-		Id:          category.Id,
-		Name:        category.Name,
-		Description: category.Description,
-		IsActive:    category.IsActive,
-		CreatedAt:   category.CreatedAt,
-		UpdatedAt:   category.UpdatedAt,
+		Id:                category.Id,
+		Name:              category.Name,
+		Description:       category.Description,
+		IsActive:          category.IsActive,
+		LowStockThreshold: category.LowStockThreshold,
+		CreatedAt:         category.CreatedAt,
+		UpdatedAt:         category.UpdatedAt,
 	}, nil
 }
 
@@ -60,12 +66,13 @@ func (p *productService) GetCategories(ctx context.Context) ([]*dto.CategoryResp
 	categoriesResponse := make([]*dto.CategoryResponse, len(categories))
 	for i := range categories {
 		categoriesResponse[i] = &dto.CategoryResponse{
-			Id:          categories[i].Id,
-			Name:        categories[i].Name,
-			Description: categories[i].Description,
-			IsActive:    categories[i].IsActive,
-			CreatedAt:   categories[i].CreatedAt,
-			UpdatedAt:   categories[i].UpdatedAt,
+			Id:                categories[i].Id,
+			Name:              categories[i].Name,
+			Description:       categories[i].Description,
+			IsActive:          categories[i].IsActive,
+			LowStockThreshold: categories[i].LowStockThreshold,
+			CreatedAt:         categories[i].CreatedAt,
+			UpdatedAt:         categories[i].UpdatedAt,
 		}
 	}
 
@@ -83,18 +90,22 @@ func (p *productService) UpdateCategory(ctx context.Context, id uint, req *dto.U
 	if req.IsActive != nil {
 		category.IsActive = *req.IsActive
 	}
+	if req.LowStockThreshold != nil {
+		category.LowStockThreshold = *req.LowStockThreshold
+	}
 
 	if err := p.productRepository.UpdateCategory(ctx, category); err != nil {
 		return nil, err
 	}
 
 	return &dto.CategoryResponse{
-		Id:          category.Id,
-		Name:        category.Name,
-		Description: category.Description,
-		IsActive:    category.IsActive,
-		CreatedAt:   category.CreatedAt,
-		UpdatedAt:   category.UpdatedAt,
+		Id:                category.Id,
+		Name:              category.Name,
+		Description:       category.Description,
+		IsActive:          category.IsActive,
+		LowStockThreshold: category.LowStockThreshold,
+		CreatedAt:         category.CreatedAt,
+		UpdatedAt:         category.UpdatedAt,
 	}, nil
 }
 
@@ -104,14 +115,21 @@ func (p *productService) DeleteCategory(ctx context.Context, id uint) error {
 
 func (p *productService) CreateProduct(ctx context.Context, req *dto.CreateProductRequest) (*dto.ProductResponse, error) {
 	product := &domain.Product{
-		CategoryId:  req.CategoryId,
-		Name:        req.Name,
-		Description: req.Description,
-		Price:       req.Price,
-		Stock:       req.Stock,
-		SKU:         req.SKU,
-	}
-	if err := p.productRepository.CreateProduct(ctx, product); err != nil {
+		CategoryId:        req.CategoryId,
+		Name:              req.Name,
+		Description:       req.Description,
+		Price:             req.Price,
+		SKU:               req.SKU,
+		LowStockThreshold: req.LowStockThreshold,
+	}
+	err := p.db.Transaction(func(tx *gorm.DB) error {
+		if err := p.productRepository.WithTx(tx).CreateProduct(ctx, product); err != nil {
+			return err
+		}
+
+		return p.setStock(ctx, tx, product.Id, req.Stock)
+	})
+	if err != nil {
 		return nil, err
 	}
 
@@ -221,12 +239,21 @@ func (p *productService) UpdateProduct(ctx context.Context, id uint, req *dto.Up
 	product.Name = req.Name
 	product.Description = req.Description
 	product.Price = req.Price
-	product.Stock = req.Stock
 	if req.IsActive != nil {
 		product.IsActive = *req.IsActive
 	}
+	if req.LowStockThreshold != nil {
+		product.LowStockThreshold = req.LowStockThreshold
+	}
+
+	err = p.db.Transaction(func(tx *gorm.DB) error {
+		if err := p.productRepository.WithTx(tx).UpdateProduct(ctx, product); err != nil {
+			return err
+		}
 
-	if err := p.productRepository.UpdateProduct(ctx, product); err != nil {
+		return p.setStock(ctx, tx, product.Id, req.Stock)
+	})
+	if err != nil {
 		return nil, err
 	}
 
@@ -236,6 +263,22 @@ func (p *productService) UpdateProduct(ctx context.Context, id uint, req *dto.Up
 	return p.GetProductById(ctx, product.Id)
 }
 
+// setStock sets the stock of a product being saved in tx in the default
+// warehouse, unless stock is nil. The product is read back for the category
+// its low-stock threshold falls back to.
+func (p *productService) setStock(ctx context.Context, tx *gorm.DB, productId uint, stock *int) error {
+	if stock == nil {
+		return nil
+	}
+
+	product, err := p.productRepository.WithTx(tx).GetProductById(ctx, productId)
+	if err != nil {
+		return err
+	}
+
+	return setDefaultStockLevel(ctx, p.inventoryRepository.WithTx(tx), p.outboxRepository.WithTx(tx), product, *stock)
+}
+
 func (p *productService) DeleteProduct(ctx context.Context, id uint) error {
 	_ = p.invalidateProductById(ctx, id)
 	_ = p.invalidateProductLists(ctx)
@@ -299,21 +342,24 @@ func (p *productService) convertToProductResponse(product *domain.Product) *dto.
 	}
 
 	return &dto.ProductResponse{
-		Id:          product.Id,
-		CategoryId:  product.CategoryId,
-		Name:        product.Name,
-		Description: product.Description,
-		Price:       product.Price,
-		Stock:       product.Stock,
-		SKU:         product.SKU,
-		IsActive:    product.IsActive,
+		Id:                product.Id,
+		CategoryId:        product.CategoryId,
+		Name:              product.Name,
+		Description:       product.Description,
+		Price:             product.Price,
+		Stock:             product.TotalStock(),
+		InStock:           product.TotalStock() > 0,
+		SKU:               product.SKU,
+		IsActive:          product.IsActive,
+		LowStockThreshold: product.LowStockThreshold,
 		Category: dto.CategoryResponse{
-			Id:          product.Category.Id,
-			Name:        product.Category.Name,
-			Description: product.Category.Description,
-			IsActive:    product.Category.IsActive,
-			CreatedAt:   product.Category.CreatedAt,
-			UpdatedAt:   product.Category.UpdatedAt,
+			Id:                product.Category.Id,
+			Name:              product.Category.Name,
+			Description:       product.Category.Description,
+			IsActive:          product.Category.IsActive,
+			LowStockThreshold: product.Category.LowStockThreshold,
+			CreatedAt:         product.Category.CreatedAt,
+			UpdatedAt:         product.Category.UpdatedAt,
 		},
 		Images:    images,
 		CreatedAt: product.CreatedAt,
@@ -329,9 +375,12 @@ func (p *productService) invalidateProductLists(ctx context.Context) error {
 	return p.cache.DeleteByPrefix(ctx, cache.ProductListPrefix())
 }
 
-func NewProductService(productRepository repository.ProductRepository, cache cache.Cache) ProductService {
+func NewProductService(outboxRepository repository.OutboxRepository, productRepository repository.ProductRepository, inventoryRepository repository.InventoryRepository, cache cache.Cache, db *gorm.DB) ProductService {
 	return &productService{
-		productRepository: productRepository,
-		cache:             cache,
+		outboxRepository:    outboxRepository,
+		productRepository:   productRepository,
+		inventoryRepository: inventoryRepository,
+		cache:               cache,
+		db:                  db,
 	}
 }
@@ -0,0 +1,194 @@
+package service
+
+import (
+	"context"
+
+	"github.com/saleh-ghazimoradi/Cartopher/infra/events"
+	"github.com/saleh-ghazimoradi/Cartopher/internal/domain"
+	"github.com/saleh-ghazimoradi/Cartopher/internal/dto"
+)
+
+// RealtimeService streams live updates of orders and carts to clients
+// connected to this instance.
+type RealtimeService interface {
+	OrderUpdates(ctx context.Context, userId, orderId uint) (<-chan *dto.OrderResponse, error)
+	CartUpdates(ctx context.Context, userId uint) (<-chan *dto.CartResponse, error)
+	OrderEvents(ctx context.Context, userId, orderId uint, lastEventId string) (<-chan *events.Envelope, error)
+}
+
+type realtimeService struct {
+	broadcaster  events.Broadcaster
+	orderService OrderService
+	cartService  CartService
+}
+
+// OrderUpdates sends one of the user's orders every time an event about it
+// is broadcast, until ctx is done.
+func (r *realtimeService) OrderUpdates(ctx context.Context, userId, orderId uint) (<-chan *dto.OrderResponse, error) {
+	if _, err := r.orderService.GetOrder(ctx, userId, orderId); err != nil {
+		return nil, err
+	}
+
+	return subscribeUpdates(ctx, r.broadcaster, events.OrderChannel(orderId), func() (*dto.OrderResponse, error) {
+		return r.orderService.GetOrder(ctx, userId, orderId)
+	})
+}
+
+// CartUpdates sends the user's cart every time it changes, until ctx is
+// done.
+func (r *realtimeService) CartUpdates(ctx context.Context, userId uint) (<-chan *dto.CartResponse, error) {
+	return subscribeUpdates(ctx, r.broadcaster, events.CartChannel(userId), func() (*dto.CartResponse, error) {
+		return r.cartService.GetCart(ctx, userId)
+	})
+}
+
+// OrderEvents sends the events about one of the user's orders as they are
+// broadcast, until ctx is done. With a lastEventId, the recent events that
+// followed it are sent first.
+func (r *realtimeService) OrderEvents(ctx context.Context, userId, orderId uint, lastEventId string) (<-chan *events.Envelope, error) {
+	if _, err := r.orderService.GetOrder(ctx, userId, orderId); err != nil {
+		return nil, err
+	}
+
+	channel := events.OrderChannel(orderId)
+
+	// Subscribe before replaying so nothing falls in between; events seen
+	// in both are skipped by id.
+	payloads, err := r.broadcaster.Subscribe(ctx, channel)
+	if err != nil {
+		return nil, err
+	}
+
+	var missed [][]byte
+	if lastEventId != "" {
+		missed, err = r.broadcaster.Replay(ctx, channel, lastEventId)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	envelopes := make(chan *events.Envelope)
+	go func() {
+		defer close(envelopes)
+
+		send := func(envelope *events.Envelope) bool {
+			select {
+			case envelopes <- envelope:
+				return true
+			case <-ctx.Done():
+				return false
+			}
+		}
+
+		// Only replayed events can arrive twice, and each at most once more,
+		// so only their ids are kept and each is dropped once matched.
+		replayed := make(map[string]struct{}, len(missed))
+		for _, payload := range missed {
+			envelope, err := events.DecodeEnvelope(payload, "")
+			if err != nil || envelope.Id == "" {
+				continue
+			}
+			if _, ok := replayed[envelope.Id]; ok {
+				continue
+			}
+			replayed[envelope.Id] = struct{}{}
+			if !send(envelope) {
+				return
+			}
+		}
+
+		for payload := range payloads {
+			envelope, err := events.DecodeEnvelope(payload, "")
+			if err != nil || envelope.Id == "" {
+				continue
+			}
+			if _, ok := replayed[envelope.Id]; ok {
+				delete(replayed, envelope.Id)
+				continue
+			}
+			if !send(envelope) {
+				return
+			}
+		}
+	}()
+
+	return envelopes, nil
+}
+
+// subscribeUpdates reloads and sends the current state with load whenever
+// something is broadcast on channel. Events only signal a change, so bursts
+// are coalesced into one reload when the client is slower than the events.
+func subscribeUpdates[T any](ctx context.Context, broadcaster events.Broadcaster, channel string, load func() (*T, error)) (<-chan *T, error) {
+	payloads, err := broadcaster.Subscribe(ctx, channel)
+	if err != nil {
+		return nil, err
+	}
+
+	updates := make(chan *T, 1)
+	go func() {
+		defer close(updates)
+
+		for range payloads {
+			state, err := load()
+			if err != nil {
+				continue
+			}
+
+			select {
+			case updates <- state:
+			case <-ctx.Done():
+				return
+			default:
+				// The previous state was not picked up yet; replace it.
+				select {
+				case <-updates:
+				default:
+				}
+				updates <- state
+			}
+		}
+	}()
+
+	return updates, nil
+}
+
+// broadcastEvent notifies live update subscribers of an outbox message.
+// Live updates are best effort, so failures are ignored.
+func broadcastEvent(ctx context.Context, broadcaster events.Broadcaster, message *domain.OutboxMessage) {
+	if broadcaster == nil {
+		return
+	}
+
+	envelope, err := events.DecodeEnvelope(message.Payload, message.EventType)
+	if err != nil {
+		return
+	}
+
+	var channel string
+	switch envelope.Type {
+	case events.OrderCreated, events.OrderConfirmed, events.OrderShipped, events.ShipmentDelivered, events.OrderDelivered, events.OrderCancelled:
+		order, err := events.DecodeOrder(envelope)
+		if err != nil {
+			return
+		}
+		channel = events.OrderChannel(order.OrderId)
+	case events.CartUpdated:
+		cart, err := events.DecodeCart(envelope)
+		if err != nil {
+			return
+		}
+		channel = events.CartChannel(cart.UserId)
+	default:
+		return
+	}
+
+	_ = broadcaster.Broadcast(ctx, channel, message.Payload)
+}
+
+func NewRealtimeService(broadcaster events.Broadcaster, orderService OrderService, cartService CartService) RealtimeService {
+	return &realtimeService{
+		broadcaster:  broadcaster,
+		orderService: orderService,
+		cartService:  cartService,
+	}
+}
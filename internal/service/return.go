@@ -0,0 +1,366 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/saleh-ghazimoradi/Cartopher/infra/cache"
+	"github.com/saleh-ghazimoradi/Cartopher/infra/events"
+	"github.com/saleh-ghazimoradi/Cartopher/internal/domain"
+	"github.com/saleh-ghazimoradi/Cartopher/internal/dto"
+	"github.com/saleh-ghazimoradi/Cartopher/internal/repository"
+	"gorm.io/gorm"
+)
+
+var ErrInvalidReturnStatus = errors.New("invalid return status")
+
+type ReturnService interface {
+	CreateReturn(ctx context.Context, userId, orderId uint, req *dto.CreateReturnRequest) (*dto.ReturnResponse, error)
+	GetReturn(ctx context.Context, userId, id uint) (*dto.ReturnResponse, error)
+	GetReturns(ctx context.Context, userId uint) ([]*dto.ReturnResponse, error)
+
+	GetReturnsByStatus(ctx context.Context, status string) ([]*dto.ReturnResponse, error)
+	ApproveReturn(ctx context.Context, id uint, req *dto.ReviewReturnRequest) (*dto.ReturnResponse, error)
+	RejectReturn(ctx context.Context, id uint, req *dto.ReviewReturnRequest) (*dto.ReturnResponse, error)
+	ReceiveReturn(ctx context.Context, id uint) (*dto.ReturnResponse, error)
+	RefundReturn(ctx context.Context, id uint, req *dto.RefundReturnRequest) (*dto.ReturnResponse, error)
+}
+
+type returnService struct {
+	outboxRepository    repository.OutboxRepository
+	returnRepository    repository.ReturnRepository
+	orderRepository     repository.OrderRepository
+	inventoryRepository repository.InventoryRepository
+	cache               cache.Cache
+	db                  *gorm.DB
+}
+
+func (r *returnService) CreateReturn(ctx context.Context, userId, orderId uint, req *dto.CreateReturnRequest) (*dto.ReturnResponse, error) {
+	var returnRequest *domain.ReturnRequest
+
+	if len(req.Items) == 0 {
+		return nil, errors.New("return has no items")
+	}
+
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		orderRepo := r.orderRepository.WithTx(tx)
+		returnRepo := r.returnRepository.WithTx(tx)
+
+		order, err := orderRepo.GetOrderForUpdate(ctx, orderId)
+		if err != nil {
+			return err
+		}
+
+		if order.UserId != userId {
+			return repository.ErrNotFound
+		}
+
+		returned, err := returnRepo.GetReturnedQuantities(ctx, orderId)
+		if err != nil {
+			return err
+		}
+
+		orderItems := make(map[uint]domain.OrderItem, len(order.OrderItems))
+		for _, item := range order.OrderItems {
+			orderItems[item.Id] = item
+		}
+
+		delivered := order.DeliveredQuantities()
+		requested := make(map[uint]int, len(req.Items))
+		var items []domain.ReturnItem
+		var refundAmount float64
+
+		for _, item := range req.Items {
+			orderItem, ok := orderItems[item.OrderItemId]
+			if !ok {
+				return fmt.Errorf("order item %d does not belong to order %d", item.OrderItemId, orderId)
+			}
+
+			if item.Quantity <= 0 {
+				return fmt.Errorf("invalid quantity for order item %d", item.OrderItemId)
+			}
+
+			reason := domain.ReturnReason(item.Reason)
+			if !reason.IsValid() {
+				return fmt.Errorf("invalid return reason: %s", item.Reason)
+			}
+
+			if _, ok := requested[item.OrderItemId]; ok {
+				return fmt.Errorf("order item %d is listed more than once", item.OrderItemId)
+			}
+			requested[item.OrderItemId] = item.Quantity
+
+			if returnable := delivered[item.OrderItemId] - returned[item.OrderItemId]; item.Quantity > returnable {
+				return fmt.Errorf("order item %d has only %d delivered unit(s) left to return", item.OrderItemId, max(returnable, 0))
+			}
+
+			refundAmount += float64(item.Quantity) * orderItem.Price
+			items = append(items, domain.ReturnItem{
+				OrderItemId: item.OrderItemId,
+				Quantity:    item.Quantity,
+				Reason:      reason,
+			})
+		}
+
+		returnRequest = &domain.ReturnRequest{
+			OrderId:      orderId,
+			UserId:       userId,
+			Status:       domain.ReturnStatusRequested,
+			Comment:      req.Comment,
+			RefundAmount: refundAmount,
+			Items:        items,
+		}
+
+		if err := returnRepo.CreateReturn(ctx, returnRequest); err != nil {
+			return err
+		}
+
+		for i := range returnRequest.Items {
+			returnRequest.Items[i].OrderItem = orderItems[returnRequest.Items[i].OrderItemId]
+		}
+
+		return r.enqueue(ctx, tx, events.ReturnRequested, returnRequest)
+	})
+
+	if err != nil {
+		return nil, err
+	}
+
+	return r.convertToReturnResponse(returnRequest), nil
+}
+
+func (r *returnService) GetReturn(ctx context.Context, userId, id uint) (*dto.ReturnResponse, error) {
+	returnRequest, err := r.returnRepository.GetReturnByUserId(ctx, userId, id)
+	if err != nil {
+		return nil, err
+	}
+
+	return r.convertToReturnResponse(returnRequest), nil
+}
+
+func (r *returnService) GetReturns(ctx context.Context, userId uint) ([]*dto.ReturnResponse, error) {
+	returns, err := r.returnRepository.GetReturnsByUserId(ctx, userId)
+	if err != nil {
+		return nil, err
+	}
+
+	return r.convertToReturnResponses(returns), nil
+}
+
+func (r *returnService) GetReturnsByStatus(ctx context.Context, status string) ([]*dto.ReturnResponse, error) {
+	if status != "" && !domain.ReturnStatus(status).IsValid() {
+		return nil, fmt.Errorf("%w: %s", ErrInvalidReturnStatus, status)
+	}
+
+	returns, err := r.returnRepository.GetReturnsByStatus(ctx, domain.ReturnStatus(status))
+	if err != nil {
+		return nil, err
+	}
+
+	return r.convertToReturnResponses(returns), nil
+}
+
+func (r *returnService) ApproveReturn(ctx context.Context, id uint, req *dto.ReviewReturnRequest) (*dto.ReturnResponse, error) {
+	return r.review(ctx, id, domain.ReturnStatusApproved, req.Note, events.ReturnApproved)
+}
+
+func (r *returnService) RejectReturn(ctx context.Context, id uint, req *dto.ReviewReturnRequest) (*dto.ReturnResponse, error) {
+	return r.review(ctx, id, domain.ReturnStatusRejected, req.Note, events.ReturnRejected)
+}
+
+// ReceiveReturn records that the returned goods arrived and puts them back
+// into the warehouses they were originally shipped from.
+func (r *returnService) ReceiveReturn(ctx context.Context, id uint) (*dto.ReturnResponse, error) {
+	var returnRequest *domain.ReturnRequest
+
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		returnRepo := r.returnRepository.WithTx(tx)
+		inventoryRepo := r.inventoryRepository.WithTx(tx)
+
+		found, err := returnRepo.GetReturnForUpdate(ctx, id)
+		if err != nil {
+			return err
+		}
+		returnRequest = found
+
+		if returnRequest.Status != domain.ReturnStatusApproved {
+			return fmt.Errorf("cannot receive a return that is %s", returnRequest.Status)
+		}
+
+		for _, item := range returnRequest.Items {
+			if err := inventoryRepo.AdjustStock(ctx, item.OrderItem.WarehouseId, item.OrderItem.ProductId, item.Quantity); err != nil {
+				return err
+			}
+		}
+
+		now := time.Now()
+		returnRequest.Status = domain.ReturnStatusReceived
+		returnRequest.ReceivedAt = &now
+
+		if err := returnRepo.UpdateReturn(ctx, returnRequest); err != nil {
+			return err
+		}
+
+		return r.enqueue(ctx, tx, events.ReturnReceived, returnRequest)
+	})
+
+	if err != nil {
+		return nil, err
+	}
+
+	for _, item := range returnRequest.Items {
+		_ = r.cache.Delete(ctx, cache.ProductById(item.OrderItem.ProductId))
+	}
+	_ = r.cache.DeleteByPrefix(ctx, cache.ProductListPrefix())
+
+	return r.convertToReturnResponse(returnRequest), nil
+}
+
+// RefundReturn records the refund issued for a received return. The
+// reference identifies the refund in the payment provider.
+func (r *returnService) RefundReturn(ctx context.Context, id uint, req *dto.RefundReturnRequest) (*dto.ReturnResponse, error) {
+	var returnRequest *domain.ReturnRequest
+
+	if req.Reference == "" {
+		return nil, errors.New("refund reference is required")
+	}
+
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		returnRepo := r.returnRepository.WithTx(tx)
+
+		found, err := returnRepo.GetReturnForUpdate(ctx, id)
+		if err != nil {
+			return err
+		}
+		returnRequest = found
+
+		if returnRequest.Status != domain.ReturnStatusReceived {
+			return fmt.Errorf("cannot refund a return that is %s", returnRequest.Status)
+		}
+
+		now := time.Now()
+		returnRequest.Status = domain.ReturnStatusRefunded
+		returnRequest.RefundReference = req.Reference
+		returnRequest.RefundedAt = &now
+
+		if err := returnRepo.UpdateReturn(ctx, returnRequest); err != nil {
+			return err
+		}
+
+		return r.enqueue(ctx, tx, events.ReturnRefunded, returnRequest)
+	})
+
+	if err != nil {
+		return nil, err
+	}
+
+	return r.convertToReturnResponse(returnRequest), nil
+}
+
+func (r *returnService) review(ctx context.Context, id uint, status domain.ReturnStatus, note, eventType string) (*dto.ReturnResponse, error) {
+	var returnRequest *domain.ReturnRequest
+
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		returnRepo := r.returnRepository.WithTx(tx)
+
+		found, err := returnRepo.GetReturnForUpdate(ctx, id)
+		if err != nil {
+			return err
+		}
+		returnRequest = found
+
+		if returnRequest.Status != domain.ReturnStatusRequested {
+			return fmt.Errorf("cannot review a return that is %s", returnRequest.Status)
+		}
+
+		now := time.Now()
+		returnRequest.Status = status
+		returnRequest.AdminNote = note
+		returnRequest.ReviewedAt = &now
+
+		if err := returnRepo.UpdateReturn(ctx, returnRequest); err != nil {
+			return err
+		}
+
+		return r.enqueue(ctx, tx, eventType, returnRequest)
+	})
+
+	if err != nil {
+		return nil, err
+	}
+
+	return r.convertToReturnResponse(returnRequest), nil
+}
+
+// enqueue records the event for a workflow step in the given transaction.
+func (r *returnService) enqueue(ctx context.Context, tx *gorm.DB, eventType string, returnRequest *domain.ReturnRequest) error {
+	items := make([]events.ReturnItemV1, len(returnRequest.Items))
+	for i, item := range returnRequest.Items {
+		items[i] = events.ReturnItemV1{
+			OrderItemId: item.OrderItemId,
+			Quantity:    item.Quantity,
+			Reason:      string(item.Reason),
+		}
+	}
+
+	return enqueueEvent(ctx, r.outboxRepository.WithTx(tx), eventType, &events.ReturnV1{
+		ReturnId:        returnRequest.Id,
+		OrderId:         returnRequest.OrderId,
+		UserId:          returnRequest.UserId,
+		Status:          string(returnRequest.Status),
+		RefundAmount:    returnRequest.RefundAmount,
+		RefundReference: returnRequest.RefundReference,
+		Items:           items,
+	})
+}
+
+func (r *returnService) convertToReturnResponses(returns []domain.ReturnRequest) []*dto.ReturnResponse {
+	response := make([]*dto.ReturnResponse, len(returns))
+	for i := range returns {
+		response[i] = r.convertToReturnResponse(&returns[i])
+	}
+	return response
+}
+
+func (r *returnService) convertToReturnResponse(returnRequest *domain.ReturnRequest) *dto.ReturnResponse {
+	items := make([]dto.ReturnItemResponse, len(returnRequest.Items))
+	for i := range returnRequest.Items {
+		item := &returnRequest.Items[i]
+		items[i] = dto.ReturnItemResponse{
+			OrderItemId: item.OrderItemId,
+			ProductId:   item.OrderItem.ProductId,
+			Quantity:    item.Quantity,
+			Reason:      string(item.Reason),
+		}
+	}
+
+	return &dto.ReturnResponse{
+		Id:              returnRequest.Id,
+		OrderId:         returnRequest.OrderId,
+		UserId:          returnRequest.UserId,
+		Status:          string(returnRequest.Status),
+		Comment:         returnRequest.Comment,
+		AdminNote:       returnRequest.AdminNote,
+		RefundAmount:    returnRequest.RefundAmount,
+		RefundReference: returnRequest.RefundReference,
+		Items:           items,
+		ReviewedAt:      returnRequest.ReviewedAt,
+		ReceivedAt:      returnRequest.ReceivedAt,
+		RefundedAt:      returnRequest.RefundedAt,
+		CreatedAt:       returnRequest.CreatedAt,
+		UpdatedAt:       returnRequest.UpdatedAt,
+	}
+}
+
+func NewReturnService(outboxRepository repository.OutboxRepository, returnRepository repository.ReturnRepository, orderRepository repository.OrderRepository, inventoryRepository repository.InventoryRepository, cache cache.Cache, db *gorm.DB) ReturnService {
+	return &returnService{
+		outboxRepository:    outboxRepository,
+		returnRepository:    returnRepository,
+		orderRepository:     orderRepository,
+		inventoryRepository: inventoryRepository,
+		cache:               cache,
+		db:                  db,
+	}
+}
@@ -0,0 +1,292 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"regexp"
+	"slices"
+
+	"github.com/saleh-ghazimoradi/Cartopher/infra/cache"
+	"github.com/saleh-ghazimoradi/Cartopher/internal/domain"
+	"github.com/saleh-ghazimoradi/Cartopher/internal/dto"
+	"github.com/saleh-ghazimoradi/Cartopher/internal/repository"
+)
+
+var (
+	ErrRoleExists       = errors.New("a role with this name already exists")
+	ErrRoleInUse        = errors.New("the role is assigned to users")
+	ErrSystemRole       = errors.New("system roles cannot be changed")
+	ErrInvalidRoleName  = errors.New("role names may only contain lowercase letters, digits and underscores")
+	ErrInvalidRole      = errors.New("invalid role or permissions")
+	ErrSelfManagement   = errors.New("you cannot manage your own account")
+	ErrInsufficientRole = errors.New("you cannot manage users with permissions you do not have")
+	ErrOwnRole          = errors.New("you cannot change your own role")
+	ErrPermissionDenied = errors.New("you cannot grant or revoke permissions you do not have")
+
+	roleNamePattern = regexp.MustCompile(`^[a-z][a-z0-9_]*$`)
+)
+
+type RoleService interface {
+	GetPermissions() []string
+	GetRoles(ctx context.Context) ([]dto.RoleResponse, error)
+	GetRole(ctx context.Context, name string) (*dto.RoleResponse, error)
+	CreateRole(ctx context.Context, actorId uint, req *dto.CreateRoleRequest) (*dto.RoleResponse, error)
+	UpdateRole(ctx context.Context, actorId uint, name string, req *dto.UpdateRoleRequest) (*dto.RoleResponse, error)
+	DeleteRole(ctx context.Context, actorId uint, name string) error
+}
+
+type roleService struct {
+	roleRepository repository.RoleRepository
+	userRepository repository.UserRepository
+	tokenVersions  cache.TokenVersions
+}
+
+func (r *roleService) GetPermissions() []string {
+	return permissionNames(domain.Permissions())
+}
+
+func (r *roleService) GetRoles(ctx context.Context) ([]dto.RoleResponse, error) {
+	roles, err := r.roleRepository.GetRoles(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	response := make([]dto.RoleResponse, len(roles))
+	for i := range roles {
+		response[i] = *toRoleResponse(&roles[i])
+	}
+	return response, nil
+}
+
+func (r *roleService) GetRole(ctx context.Context, name string) (*dto.RoleResponse, error) {
+	role, err := r.roleRepository.GetRoleByName(ctx, domain.UserRole(name))
+	if err != nil {
+		return nil, err
+	}
+
+	return toRoleResponse(role), nil
+}
+
+// CreateRole adds a role. Staff can only create roles granting permissions
+// they have themselves.
+func (r *roleService) CreateRole(ctx context.Context, actorId uint, req *dto.CreateRoleRequest) (*dto.RoleResponse, error) {
+	if !roleNamePattern.MatchString(req.Name) {
+		return nil, ErrInvalidRoleName
+	}
+
+	permissions, err := validatePermissions(req.Permissions)
+	if err != nil {
+		return nil, err
+	}
+
+	if _, err := r.roleRepository.GetRoleByName(ctx, domain.UserRole(req.Name)); err == nil {
+		return nil, ErrRoleExists
+	}
+
+	if err := r.checkChangeable(ctx, actorId, domain.UserRole(req.Name), permissions); err != nil {
+		return nil, err
+	}
+
+	role := &domain.Role{
+		Name:        domain.UserRole(req.Name),
+		Description: req.Description,
+		Permissions: permissions,
+	}
+
+	if err := r.roleRepository.CreateRole(ctx, role); err != nil {
+		return nil, err
+	}
+
+	return toRoleResponse(role), nil
+}
+
+// UpdateRole replaces the permissions of a role. The access tokens of its
+// users are revoked, so the change applies from their next refresh. Staff
+// cannot change their own role, nor grant or revoke permissions they do not
+// have.
+func (r *roleService) UpdateRole(ctx context.Context, actorId uint, name string, req *dto.UpdateRoleRequest) (*dto.RoleResponse, error) {
+	permissions, err := validatePermissions(req.Permissions)
+	if err != nil {
+		return nil, err
+	}
+
+	role, err := r.roleRepository.GetRoleByName(ctx, domain.UserRole(name))
+	if err != nil {
+		return nil, err
+	}
+
+	if role.IsSystem {
+		return nil, ErrSystemRole
+	}
+
+	if err := r.checkChangeable(ctx, actorId, role.Name, changedPermissions(role.Permissions, permissions)); err != nil {
+		return nil, err
+	}
+
+	role.Description = req.Description
+	role.Permissions = permissions
+
+	if err := r.roleRepository.UpdateRole(ctx, role); err != nil {
+		return nil, err
+	}
+
+	userIds, err := r.userRepository.GetUserIdsByRole(ctx, role.Name)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, userId := range userIds {
+		if err := r.tokenVersions.Bump(ctx, userId); err != nil {
+			return nil, err
+		}
+	}
+
+	return toRoleResponse(role), nil
+}
+
+// DeleteRole removes a role no user has. Like UpdateRole, staff cannot
+// delete their own role or one granting permissions they do not have.
+func (r *roleService) DeleteRole(ctx context.Context, actorId uint, name string) error {
+	role, err := r.roleRepository.GetRoleByName(ctx, domain.UserRole(name))
+	if err != nil {
+		return err
+	}
+
+	if role.IsSystem {
+		return ErrSystemRole
+	}
+
+	if err := r.checkChangeable(ctx, actorId, role.Name, role.Permissions); err != nil {
+		return err
+	}
+
+	count, err := r.roleRepository.CountUsersWithRole(ctx, role.Name)
+	if err != nil {
+		return err
+	}
+
+	if count > 0 {
+		return ErrRoleInUse
+	}
+
+	return r.roleRepository.DeleteRole(ctx, role.Name)
+}
+
+// checkChangeable returns ErrOwnRole if role is the actor's own, and
+// ErrPermissionDenied unless the actor has every permission the change
+// grants or revokes.
+func (r *roleService) checkChangeable(ctx context.Context, actorId uint, role domain.UserRole, changed []domain.Permission) error {
+	actorRole, held, err := actorPermissions(ctx, r.userRepository, r.roleRepository, actorId)
+	if err != nil {
+		return err
+	}
+
+	if actorRole == role {
+		return ErrOwnRole
+	}
+
+	if !holdsAll(held, permissionNames(changed)) {
+		return ErrPermissionDenied
+	}
+	return nil
+}
+
+// actorPermissions returns the role of the actor and the permissions it
+// grants.
+func actorPermissions(ctx context.Context, userRepository repository.UserRepository, roleRepository repository.RoleRepository, actorId uint) (domain.UserRole, []string, error) {
+	actor, err := userRepository.GetUserById(ctx, actorId)
+	if err != nil {
+		return "", nil, err
+	}
+
+	permissions, err := rolePermissions(ctx, roleRepository, actor.Role)
+	if err != nil {
+		return "", nil, err
+	}
+
+	return actor.Role, permissions, nil
+}
+
+// holdsAll reports whether held contains every one of permissions.
+func holdsAll(held, permissions []string) bool {
+	for _, permission := range permissions {
+		if !slices.Contains(held, permission) {
+			return false
+		}
+	}
+	return true
+}
+
+// changedPermissions returns the permissions granted by only one of before
+// and after.
+func changedPermissions(before, after []domain.Permission) []domain.Permission {
+	var changed []domain.Permission
+	for _, permission := range before {
+		if !slices.Contains(after, permission) {
+			changed = append(changed, permission)
+		}
+	}
+	for _, permission := range after {
+		if !slices.Contains(before, permission) {
+			changed = append(changed, permission)
+		}
+	}
+	return changed
+}
+
+// rolePermissions returns the permissions a role grants, as put in the
+// tokens of its users.
+func rolePermissions(ctx context.Context, roleRepository repository.RoleRepository, name domain.UserRole) ([]string, error) {
+	role, err := roleRepository.GetRoleByName(ctx, name)
+	if err != nil {
+		return nil, err
+	}
+
+	return permissionNames(role.GrantedPermissions()), nil
+}
+
+// validatePermissions checks that every permission exists and returns them
+// without duplicates.
+func validatePermissions(names []string) ([]domain.Permission, error) {
+	known := domain.Permissions()
+	permissions := make([]domain.Permission, 0, len(names))
+	for _, name := range names {
+		permission := domain.Permission(name)
+		if !slices.Contains(known, permission) {
+			return nil, fmt.Errorf("%w: unknown permission %s", ErrInvalidRole, name)
+		}
+		if !slices.Contains(permissions, permission) {
+			permissions = append(permissions, permission)
+		}
+	}
+	return permissions, nil
+}
+
+func permissionNames(permissions []domain.Permission) []string {
+	names := make([]string, len(permissions))
+	for i, permission := range permissions {
+		names[i] = string(permission)
+	}
+	return names
+}
+
+func toRoleResponse(role *domain.Role) *dto.RoleResponse {
+	return &dto.RoleResponse{
+		Id:          role.Id,
+		Name:        string(role.Name),
+		Description: role.Description,
+		Permissions: permissionNames(role.GrantedPermissions()),
+		IsSystem:    role.IsSystem,
+		CreatedAt:   role.CreatedAt,
+		UpdatedAt:   role.UpdatedAt,
+	}
+}
+
+func NewRoleService(roleRepository repository.RoleRepository, userRepository repository.UserRepository, tokenVersions cache.TokenVersions) RoleService {
+	return &roleService{
+		roleRepository: roleRepository,
+		userRepository: userRepository,
+		tokenVersions:  tokenVersions,
+	}
+}
@@ -0,0 +1,180 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"testing"
+
+	"github.com/saleh-ghazimoradi/Cartopher/internal/domain"
+	"github.com/saleh-ghazimoradi/Cartopher/internal/dto"
+)
+
+const (
+	testRoleSupport = domain.UserRole("support")
+	testRoleCatalog = domain.UserRole("catalog")
+	testRoleManager = domain.UserRole("manager")
+)
+
+type roleTest struct {
+	roles   *fakeRoleRepository
+	service RoleService
+	// support holds roles:manage and orders:manage only.
+	support *domain.User
+	admin   *domain.User
+}
+
+func newRoleTest(t *testing.T) *roleTest {
+	t.Helper()
+
+	roles := newFakeRoleRepository()
+	for _, role := range []domain.Role{
+		{Name: testRoleSupport, Permissions: []domain.Permission{domain.PermissionRolesManage, domain.PermissionOrdersManage}},
+		{Name: testRoleCatalog, Permissions: []domain.Permission{domain.PermissionCatalogWrite}},
+		{Name: testRoleManager, Permissions: []domain.Permission{domain.PermissionOrdersManage, domain.PermissionUsersManage}},
+	} {
+		_ = roles.CreateRole(context.Background(), &role)
+	}
+
+	users := &fakeUserRepository{}
+	support := &domain.User{Email: "support@example.com", Role: testRoleSupport}
+	admin := &domain.User{Email: "admin@example.com", Role: domain.UserRoleAdmin}
+	_ = users.CreateUser(context.Background(), support)
+	_ = users.CreateUser(context.Background(), admin)
+
+	return &roleTest{
+		roles:   roles,
+		service: NewRoleService(roles, users, fakeTokenVersions{}),
+		support: support,
+		admin:   admin,
+	}
+}
+
+func (r *roleTest) permissions(t *testing.T, name domain.UserRole) []domain.Permission {
+	t.Helper()
+
+	role, err := r.roles.GetRoleByName(context.Background(), name)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return role.Permissions
+}
+
+func TestUpdateRoleRefusesOwnRole(t *testing.T) {
+	r := newRoleTest(t)
+
+	_, err := r.service.UpdateRole(context.Background(), r.support.Id, string(testRoleSupport), &dto.UpdateRoleRequest{
+		Permissions: []string{
+			string(domain.PermissionRolesManage),
+			string(domain.PermissionOrdersManage),
+			string(domain.PermissionUsersManage),
+			string(domain.PermissionOrdersRefund),
+		},
+	})
+	if !errors.Is(err, ErrOwnRole) {
+		t.Fatalf("got %v, want %v", err, ErrOwnRole)
+	}
+
+	if slices.Contains(r.permissions(t, testRoleSupport), domain.PermissionUsersManage) {
+		t.Fatal("support escalated its own role to users:manage")
+	}
+}
+
+func TestUpdateRoleRefusesPermissionsNotHeld(t *testing.T) {
+	tests := []struct {
+		name        string
+		role        domain.UserRole
+		permissions []string
+		want        []domain.Permission
+	}{
+		{
+			name:        "grant",
+			role:        testRoleCatalog,
+			permissions: []string{string(domain.PermissionCatalogWrite), string(domain.PermissionUsersManage)},
+			want:        []domain.Permission{domain.PermissionCatalogWrite},
+		},
+		{
+			name:        "revoke",
+			role:        testRoleManager,
+			permissions: []string{string(domain.PermissionOrdersManage)},
+			want:        []domain.Permission{domain.PermissionOrdersManage, domain.PermissionUsersManage},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := newRoleTest(t)
+
+			_, err := r.service.UpdateRole(context.Background(), r.support.Id, string(tt.role), &dto.UpdateRoleRequest{
+				Permissions: tt.permissions,
+			})
+			if !errors.Is(err, ErrPermissionDenied) {
+				t.Fatalf("got %v, want %v", err, ErrPermissionDenied)
+			}
+
+			if got := r.permissions(t, tt.role); !slices.Equal(got, tt.want) {
+				t.Fatalf("role %s grants %v, want %v", tt.role, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestUpdateRoleChangesPermissionsHeld(t *testing.T) {
+	r := newRoleTest(t)
+
+	// The catalog permission is kept, not changed, so support need not
+	// hold it.
+	_, err := r.service.UpdateRole(context.Background(), r.support.Id, string(testRoleCatalog), &dto.UpdateRoleRequest{
+		Permissions: []string{string(domain.PermissionCatalogWrite), string(domain.PermissionOrdersManage)},
+	})
+	if err != nil {
+		t.Fatalf("UpdateRole: %v", err)
+	}
+
+	want := []domain.Permission{domain.PermissionCatalogWrite, domain.PermissionOrdersManage}
+	if got := r.permissions(t, testRoleCatalog); !slices.Equal(got, want) {
+		t.Fatalf("role grants %v, want %v", got, want)
+	}
+}
+
+func TestCreateRoleRefusesPermissionsNotHeld(t *testing.T) {
+	r := newRoleTest(t)
+
+	_, err := r.service.CreateRole(context.Background(), r.support.Id, &dto.CreateRoleRequest{
+		Name:        "refunds",
+		Permissions: []string{string(domain.PermissionOrdersRefund)},
+	})
+	if !errors.Is(err, ErrPermissionDenied) {
+		t.Fatalf("got %v, want %v", err, ErrPermissionDenied)
+	}
+
+	if _, err := r.service.CreateRole(context.Background(), r.support.Id, &dto.CreateRoleRequest{
+		Name:        "orders",
+		Permissions: []string{string(domain.PermissionOrdersManage)},
+	}); err != nil {
+		t.Fatalf("CreateRole with a permission held: %v", err)
+	}
+
+	if _, err := r.service.CreateRole(context.Background(), r.admin.Id, &dto.CreateRoleRequest{
+		Name:        "refunds",
+		Permissions: []string{string(domain.PermissionOrdersRefund)},
+	}); err != nil {
+		t.Fatalf("CreateRole by an admin: %v", err)
+	}
+}
+
+func TestDeleteRoleRefusesOwnRoleAndPermissionsNotHeld(t *testing.T) {
+	r := newRoleTest(t)
+
+	if err := r.service.DeleteRole(context.Background(), r.support.Id, string(testRoleSupport)); !errors.Is(err, ErrOwnRole) {
+		t.Fatalf("deleting own role: got %v, want %v", err, ErrOwnRole)
+	}
+
+	if err := r.service.DeleteRole(context.Background(), r.support.Id, string(testRoleManager)); !errors.Is(err, ErrPermissionDenied) {
+		t.Fatalf("deleting a more privileged role: got %v, want %v", err, ErrPermissionDenied)
+	}
+
+	if _, err := r.roles.GetRoleByName(context.Background(), testRoleManager); err != nil {
+		t.Fatalf("the manager role was deleted: %v", err)
+	}
+}
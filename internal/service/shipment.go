@@ -0,0 +1,235 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/saleh-ghazimoradi/Cartopher/infra/events"
+	"github.com/saleh-ghazimoradi/Cartopher/internal/domain"
+	"github.com/saleh-ghazimoradi/Cartopher/internal/dto"
+	"github.com/saleh-ghazimoradi/Cartopher/internal/repository"
+	"gorm.io/gorm"
+)
+
+type ShipmentService interface {
+	CreateShipment(ctx context.Context, orderId uint, req *dto.CreateShipmentRequest) (*dto.ShipmentResponse, error)
+	GetShipments(ctx context.Context, orderId uint) ([]*dto.ShipmentResponse, error)
+	MarkDelivered(ctx context.Context, shipmentId uint) (*dto.ShipmentResponse, error)
+}
+
+type shipmentService struct {
+	outboxRepository   repository.OutboxRepository
+	shipmentRepository repository.ShipmentRepository
+	orderRepository    repository.OrderRepository
+	db                 *gorm.DB
+}
+
+func (s *shipmentService) CreateShipment(ctx context.Context, orderId uint, req *dto.CreateShipmentRequest) (*dto.ShipmentResponse, error) {
+	var shipment *domain.Shipment
+
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		orderRepo := s.orderRepository.WithTx(tx)
+		shipmentRepo := s.shipmentRepository.WithTx(tx)
+
+		order, err := orderRepo.GetOrderForUpdate(ctx, orderId)
+		if err != nil {
+			return err
+		}
+
+		switch order.Status {
+		case domain.OrderStatusPending:
+			return errors.New("cannot ship an order before it is confirmed")
+		case domain.OrderStatusCancelled:
+			return errors.New("cannot ship a cancelled order")
+		}
+
+		if len(req.Items) == 0 {
+			return errors.New("shipment has no items")
+		}
+
+		ordered := make(map[uint]int, len(order.OrderItems))
+		for _, item := range order.OrderItems {
+			ordered[item.Id] = item.Quantity
+		}
+
+		shipped := order.ShippedQuantities()
+		requested := make(map[uint]int, len(req.Items))
+		var items []domain.ShipmentItem
+
+		for _, item := range req.Items {
+			if item.Quantity <= 0 {
+				return fmt.Errorf("invalid quantity for order item %d", item.OrderItemId)
+			}
+
+			if _, ok := ordered[item.OrderItemId]; !ok {
+				return fmt.Errorf("order item %d does not belong to order %d", item.OrderItemId, orderId)
+			}
+
+			if _, ok := requested[item.OrderItemId]; !ok {
+				items = append(items, domain.ShipmentItem{OrderItemId: item.OrderItemId})
+			}
+			requested[item.OrderItemId] += item.Quantity
+		}
+
+		for i := range items {
+			id := items[i].OrderItemId
+			if shipped[id]+requested[id] > ordered[id] {
+				return fmt.Errorf("order item %d has only %d unit(s) left to ship", id, ordered[id]-shipped[id])
+			}
+			items[i].Quantity = requested[id]
+		}
+
+		shipment = &domain.Shipment{
+			OrderId:        orderId,
+			Carrier:        req.Carrier,
+			TrackingNumber: strings.TrimSpace(req.TrackingNumber),
+			Status:         domain.ShipmentStatusShipped,
+			ShippedAt:      time.Now(),
+			Items:          items,
+		}
+
+		if err := shipmentRepo.CreateShipment(ctx, shipment); err != nil {
+			return err
+		}
+
+		order.Shipments = append(order.Shipments, *shipment)
+
+		if err := orderRepo.UpdateOrderStatus(ctx, orderId, order.FulfilmentStatus()); err != nil {
+			return err
+		}
+
+		return s.enqueue(ctx, tx, events.OrderShipped, orderId, shipment)
+	})
+
+	if err != nil {
+		return nil, err
+	}
+
+	return convertToShipmentResponse(shipment), nil
+}
+
+func (s *shipmentService) GetShipments(ctx context.Context, orderId uint) ([]*dto.ShipmentResponse, error) {
+	if _, err := s.orderRepository.GetOrderById(ctx, orderId); err != nil {
+		return nil, err
+	}
+
+	shipments, err := s.shipmentRepository.GetShipmentsByOrderId(ctx, orderId)
+	if err != nil {
+		return nil, err
+	}
+
+	response := make([]*dto.ShipmentResponse, len(shipments))
+	for i := range shipments {
+		response[i] = convertToShipmentResponse(&shipments[i])
+	}
+
+	return response, nil
+}
+
+func (s *shipmentService) MarkDelivered(ctx context.Context, shipmentId uint) (*dto.ShipmentResponse, error) {
+	var shipment *domain.Shipment
+
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		orderRepo := s.orderRepository.WithTx(tx)
+		shipmentRepo := s.shipmentRepository.WithTx(tx)
+
+		found, err := shipmentRepo.GetShipmentById(ctx, shipmentId)
+		if err != nil {
+			return err
+		}
+
+		// Re-read the shipment under the order lock so concurrent updates
+		// derive the order status from the same state.
+		order, err := orderRepo.GetOrderForUpdate(ctx, found.OrderId)
+		if err != nil {
+			return err
+		}
+
+		for i := range order.Shipments {
+			if order.Shipments[i].Id == shipmentId {
+				shipment = &order.Shipments[i]
+			}
+		}
+
+		if shipment == nil {
+			return repository.ErrNotFound
+		}
+
+		if shipment.Status == domain.ShipmentStatusDelivered {
+			return nil
+		}
+
+		now := time.Now()
+		shipment.Status = domain.ShipmentStatusDelivered
+		shipment.DeliveredAt = &now
+
+		if err := shipmentRepo.UpdateShipment(ctx, shipment); err != nil {
+			return err
+		}
+
+		status := order.FulfilmentStatus()
+		if err := orderRepo.UpdateOrderStatus(ctx, order.Id, status); err != nil {
+			return err
+		}
+
+		if err := s.enqueue(ctx, tx, events.ShipmentDelivered, order.Id, shipment); err != nil {
+			return err
+		}
+
+		if status != domain.OrderStatusDelivered || order.Status == domain.OrderStatusDelivered {
+			return nil
+		}
+
+		return s.enqueue(ctx, tx, events.OrderDelivered, order.Id, nil)
+	})
+
+	if err != nil {
+		return nil, err
+	}
+
+	return convertToShipmentResponse(shipment), nil
+}
+
+// enqueue reloads the order with its customer and products and records an
+// order event for it in the given transaction.
+func (s *shipmentService) enqueue(ctx context.Context, tx *gorm.DB, eventType string, orderId uint, shipment *domain.Shipment) error {
+	order, err := s.orderRepository.WithTx(tx).GetOrderById(ctx, orderId)
+	if err != nil {
+		return err
+	}
+
+	return enqueueOrderEvent(ctx, s.outboxRepository.WithTx(tx), eventType, order, shipment)
+}
+
+func convertToShipmentResponse(shipment *domain.Shipment) *dto.ShipmentResponse {
+	items := make([]dto.ShipmentItemResponse, len(shipment.Items))
+	for i := range shipment.Items {
+		items[i] = dto.ShipmentItemResponse{
+			OrderItemId: shipment.Items[i].OrderItemId,
+			Quantity:    shipment.Items[i].Quantity,
+		}
+	}
+
+	return &dto.ShipmentResponse{
+		Id:             shipment.Id,
+		OrderId:        shipment.OrderId,
+		Carrier:        shipment.Carrier,
+		TrackingNumber: shipment.TrackingNumber,
+		Status:         string(shipment.Status),
+		Items:          items,
+		ShippedAt:      shipment.ShippedAt,
+		DeliveredAt:    shipment.DeliveredAt,
+	}
+}
+
+func NewShipmentService(outboxRepository repository.OutboxRepository, shipmentRepository repository.ShipmentRepository, orderRepository repository.OrderRepository, db *gorm.DB) ShipmentService {
+	return &shipmentService{
+		outboxRepository:   outboxRepository,
+		shipmentRepository: shipmentRepository,
+		orderRepository:    orderRepository,
+		db:                 db,
+	}
+}
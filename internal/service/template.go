@@ -0,0 +1,128 @@
+package service
+
+import (
+	"context"
+	"time"
+
+	"github.com/saleh-ghazimoradi/Cartopher/config"
+	"github.com/saleh-ghazimoradi/Cartopher/infra/events"
+	"github.com/saleh-ghazimoradi/Cartopher/infra/mailer"
+	"github.com/saleh-ghazimoradi/Cartopher/internal/domain"
+	"github.com/saleh-ghazimoradi/Cartopher/internal/dto"
+)
+
+type TemplateService interface {
+	GetTemplates(ctx context.Context) []string
+	PreviewTemplate(ctx context.Context, name, locale string) (*dto.EmailPreviewResponse, error)
+}
+
+type templateService struct {
+	locale   string
+	renderer *mailer.Renderer
+}
+
+func (t *templateService) GetTemplates(ctx context.Context) []string {
+	return t.renderer.Templates()
+}
+
+// PreviewTemplate renders a template with sample data, so admins can check
+// overrides and translations without triggering the event.
+func (t *templateService) PreviewTemplate(ctx context.Context, name, locale string) (*dto.EmailPreviewResponse, error) {
+	if locale == "" {
+		locale = t.locale
+	}
+
+	message, err := t.renderer.Render(name, locale, sampleTemplateData(name))
+	if err != nil {
+		return nil, err
+	}
+
+	return &dto.EmailPreviewResponse{
+		Template: name,
+		Locale:   locale,
+		Subject:  message.Subject,
+		Text:     message.Text,
+		HTML:     message.HTML,
+	}, nil
+}
+
+// sampleTemplateData returns the payload of the event a template belongs to,
+// filled with made-up values. Unknown templates get the order payload, which
+// covers most fields a custom template is likely to use.
+func sampleTemplateData(name string) any {
+	switch name {
+	case templateName(events.UserLoggedIn):
+		return map[string]string{"Name": "Jane Doe"}
+	case templateName(events.EmailVerificationRequested):
+		return &events.EmailVerificationRequestedV1{
+			UserId:    7,
+			Email:     "jane.doe@example.com",
+			FirstName: "Jane",
+			LastName:  "Doe",
+			Token:     "sample-verification-token",
+			URL:       "https://shop.example.com/verify-email?token=sample-verification-token",
+			ExpiresAt: time.Date(2025, time.January, 2, 15, 4, 0, 0, time.UTC),
+		}
+	case templateName(events.PasswordResetRequested):
+		return &events.PasswordResetRequestedV1{
+			UserId:    7,
+			Email:     "jane.doe@example.com",
+			FirstName: "Jane",
+			LastName:  "Doe",
+			Token:     "sample-reset-token",
+			URL:       "https://shop.example.com/reset-password?token=sample-reset-token",
+			ExpiresAt: time.Date(2025, time.January, 2, 15, 4, 0, 0, time.UTC),
+		}
+	case templateName(events.RefreshTokenReused):
+		return &events.RefreshTokenReusedV1{
+			UserId:    7,
+			Email:     "jane.doe@example.com",
+			FirstName: "Jane",
+			LastName:  "Doe",
+			SessionId: 12,
+			UserAgent: "Mozilla/5.0 (X11; Linux x86_64)",
+			IPAddress: "203.0.113.7",
+		}
+	case templateName(events.AccountLocked):
+		return &events.AccountLockedV1{
+			UserId:      7,
+			Email:       "jane.doe@example.com",
+			FirstName:   "Jane",
+			LastName:    "Doe",
+			UserAgent:   "Mozilla/5.0 (X11; Linux x86_64)",
+			IPAddress:   "203.0.113.7",
+			LockedUntil: time.Date(2025, time.January, 2, 15, 4, 0, 0, time.UTC),
+		}
+	case templateName(events.ProductLowStock):
+		return &events.ProductLowStockV1{ProductId: 42, Name: "Wireless Mouse", SKU: "WM-001", Stock: 3, Threshold: 5}
+	}
+
+	items := []events.OrderItemV1{
+		{SKU: "WM-001", Name: "Wireless Mouse", Quantity: 2, Price: 24.99},
+		{SKU: "KB-104", Name: "Mechanical Keyboard", Quantity: 1, Price: 89.00},
+	}
+
+	return &events.OrderV1{
+		OrderId:       1001,
+		UserId:        7,
+		Email:         "jane.doe@example.com",
+		CustomerName:  "Jane Doe",
+		Status:        string(domain.OrderStatusPartiallyShipped),
+		TotalAmount:   138.98,
+		InvoiceNumber: formatInvoiceNumber("", 1),
+		Items:         items,
+		Shipment: &events.OrderShipmentV1{
+			Id:             12,
+			Carrier:        "DHL",
+			TrackingNumber: "JD014600003828",
+			Items:          items[:1],
+		},
+	}
+}
+
+func NewTemplateService(cfg *config.Config) TemplateService {
+	return &templateService{
+		locale:   cfg.Notifier.Locale,
+		renderer: mailer.NewRenderer(cfg.Notifier.TemplatesDir, cfg.Notifier.Locale),
+	}
+}
@@ -0,0 +1,81 @@
+package service
+
+import (
+	"context"
+	"crypto/subtle"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/pquerna/otp"
+	"github.com/pquerna/otp/totp"
+	"github.com/saleh-ghazimoradi/Cartopher/infra/cache"
+	"github.com/saleh-ghazimoradi/Cartopher/internal/domain"
+	"github.com/saleh-ghazimoradi/Cartopher/utils"
+)
+
+const (
+	twoFactorMaxAttempts    = 5
+	twoFactorAttemptsPrefix = "2fa:attempts:"
+
+	totpPeriod = 30
+	totpSkew   = 1
+)
+
+var ErrTwoFactorThrottled = errors.New("too many two-factor attempts, please try again later")
+
+// throttleTwoFactor counts an attempt at a two-factor code of the user,
+// returning ErrTwoFactorThrottled once they made too many in a minute.
+func (a *authService) throttleTwoFactor(ctx context.Context, userId uint) error {
+	_, _, allowed, err := a.rateLimiter.Allow(ctx, fmt.Sprintf("%s%d", twoFactorAttemptsPrefix, userId), twoFactorMaxAttempts)
+	if err != nil {
+		return err
+	}
+
+	if !allowed {
+		return ErrTwoFactorThrottled
+	}
+	return nil
+}
+
+// useTOTPCode reports whether code is the TOTP code of the user for the
+// current step or one next to it. Each code is only accepted once: codes
+// of steps at or before the last one the user used are refused.
+func (a *authService) useTOTPCode(ctx context.Context, user *domain.User, code string) (bool, error) {
+	now := time.Now()
+	for skew := -totpSkew; skew <= totpSkew; skew++ {
+		at := now.Add(time.Duration(skew*totpPeriod) * time.Second)
+
+		expected, err := totp.GenerateCodeCustom(user.TOTPSecret, at, totp.ValidateOpts{
+			Period:    totpPeriod,
+			Digits:    otp.DigitsSix,
+			Algorithm: otp.AlgorithmSHA1,
+		})
+		if err != nil {
+			return false, err
+		}
+
+		if subtle.ConstantTimeCompare([]byte(code), []byte(expected)) == 1 {
+			return a.userRepository.UseTOTPStep(ctx, user.Id, at.Unix()/totpPeriod)
+		}
+	}
+	return false, nil
+}
+
+// claimTwoFactorChallenge uses up a challenge token, so it only gets the
+// tokens of one login.
+func (a *authService) claimTwoFactorChallenge(ctx context.Context, claims *utils.Claims) error {
+	if claims.ID == "" || claims.ExpiresAt == nil {
+		return errors.New("invalid or expired challenge token")
+	}
+
+	claimed, err := a.cache.SetNX(ctx, cache.TwoFactorChallenge(claims.ID), true, time.Until(claims.ExpiresAt.Time))
+	if err != nil {
+		return err
+	}
+
+	if !claimed {
+		return errors.New("invalid or expired challenge token")
+	}
+	return nil
+}
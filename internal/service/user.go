@@ -2,18 +2,28 @@ package service
 
 import (
 	"context"
+	"errors"
 
+	"github.com/saleh-ghazimoradi/Cartopher/infra/cache"
+	"github.com/saleh-ghazimoradi/Cartopher/internal/domain"
 	"github.com/saleh-ghazimoradi/Cartopher/internal/dto"
 	"github.com/saleh-ghazimoradi/Cartopher/internal/repository"
+	"github.com/saleh-ghazimoradi/Cartopher/utils"
+	"gorm.io/gorm"
 )
 
+var ErrIncorrectPassword = errors.New("current password is incorrect")
+
 type UserService interface {
 	GetProfile(ctx context.Context, userId uint) (*dto.UserResponse, error)
 	UpdateProfile(ctx context.Context, userId uint, req *dto.UpdateProfileRequest) (*dto.UserResponse, error)
+	ChangePassword(ctx context.Context, userId uint, req *dto.ChangePasswordRequest) error
 }
 
 type userService struct {
 	userRepository repository.UserRepository
+	tokenVersions  cache.TokenVersions
+	db             *gorm.DB
 }
 
 func (u *userService) GetProfile(ctx context.Context, userId uint) (*dto.UserResponse, error) {
@@ -22,17 +32,7 @@ func (u *userService) GetProfile(ctx context.Context, userId uint) (*dto.UserRes
 		return nil, err
 	}
 
-	return &dto.UserResponse{
-		Id:        user.Id,
-		Email:     user.Email,
-		FirstName: user.FirstName,
-		LastName:  user.LastName,
-		Phone:     user.Phone,
-		Role:      string(user.Role),
-		IsActive:  user.IsActive,
-		CreatedAt: user.CreatedAt,
-		UpdatedAt: user.UpdatedAt,
-	}, nil
+	return toUserResponse(user), nil
 }
 
 func (u *userService) UpdateProfile(ctx context.Context, userId uint, req *dto.UpdateProfileRequest) (*dto.UserResponse, error) {
@@ -52,8 +52,59 @@ func (u *userService) UpdateProfile(ctx context.Context, userId uint, req *dto.U
 	return u.GetProfile(ctx, userId)
 }
 
-func NewUserService(userRepository repository.UserRepository) UserService {
+func (u *userService) ChangePassword(ctx context.Context, userId uint, req *dto.ChangePasswordRequest) error {
+	user, err := u.userRepository.GetUserById(ctx, userId)
+	if err != nil {
+		return err
+	}
+
+	if !utils.CheckPassword(req.CurrentPassword, user.Password) {
+		return ErrIncorrectPassword
+	}
+
+	hashedPassword, err := utils.HashPassword(req.NewPassword)
+	if err != nil {
+		return err
+	}
+
+	// Every session ends, including the one changing the password, so a
+	// stolen refresh token stops working along with the old password.
+	err = u.db.Transaction(func(tx *gorm.DB) error {
+		userRepo := u.userRepository.WithTx(tx)
+
+		if err := userRepo.UpdatePassword(ctx, userId, hashedPassword); err != nil {
+			return err
+		}
+
+		return userRepo.DeleteRefreshTokensByUserId(ctx, userId)
+	})
+	if err != nil {
+		return err
+	}
+
+	return u.tokenVersions.Bump(ctx, userId)
+}
+
+func toUserResponse(user *domain.User) *dto.UserResponse {
+	return &dto.UserResponse{
+		Id:               user.Id,
+		Email:            user.Email,
+		FirstName:        user.FirstName,
+		LastName:         user.LastName,
+		Phone:            user.Phone,
+		Role:             string(user.Role),
+		IsActive:         user.IsActive,
+		EmailVerifiedAt:  user.EmailVerifiedAt,
+		TwoFactorEnabled: user.TOTPEnabledAt != nil,
+		CreatedAt:        user.CreatedAt,
+		UpdatedAt:        user.UpdatedAt,
+	}
+}
+
+func NewUserService(userRepository repository.UserRepository, tokenVersions cache.TokenVersions, db *gorm.DB) UserService {
 	return &userService{
 		userRepository: userRepository,
+		tokenVersions:  tokenVersions,
+		db:             db,
 	}
 }
@@ -0,0 +1,580 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/url"
+	"slices"
+	"strconv"
+	"syscall"
+	"time"
+
+	"github.com/saleh-ghazimoradi/Cartopher/config"
+	"github.com/saleh-ghazimoradi/Cartopher/infra/events"
+	"github.com/saleh-ghazimoradi/Cartopher/internal/domain"
+	"github.com/saleh-ghazimoradi/Cartopher/internal/dto"
+	"github.com/saleh-ghazimoradi/Cartopher/internal/helper"
+	"github.com/saleh-ghazimoradi/Cartopher/internal/repository"
+	"gorm.io/gorm"
+)
+
+const (
+	// WebhookSignatureHeader carries "t=<unix time>,v1=<signature>", where
+	// the signature is the hex HMAC-SHA256 of "<unix time>.<body>" keyed
+	// with the endpoint secret. Receivers should reject stale timestamps.
+	WebhookSignatureHeader = "X-Cartopher-Signature"
+	WebhookEventHeader     = "X-Cartopher-Event"
+	WebhookEventIdHeader   = "X-Cartopher-Event-Id"
+	WebhookDeliveryHeader  = "X-Cartopher-Delivery"
+
+	defaultWebhookBatchSize    = 20
+	defaultWebhookTimeout      = 10 * time.Second
+	defaultWebhookMaxAttempts  = 8
+	defaultWebhookRetryBackoff = 30 * time.Second
+	defaultWebhookMaxBackoff   = 6 * time.Hour
+
+	webhookSecretPrefix      = "whsec_"
+	maxWebhookResponseLength = 64 * 1024
+)
+
+var (
+	ErrInvalidWebhookEndpoint   = errors.New("invalid webhook endpoint")
+	ErrWebhookEndpointDisabled  = errors.New("webhook endpoint is disabled")
+	ErrWebhookAddressNotAllowed = errors.New("webhook endpoints may not resolve to private, loopback or link-local addresses")
+)
+
+// deniedNetworks are the non-public ranges net.IP has no method for: "this"
+// network, carrier-grade NAT, benchmarking, the reserved class E range and
+// NAT64, which reaches IPv4 addresses through IPv6.
+var deniedNetworks = parseNetworks(
+	"0.0.0.0/8",
+	"100.64.0.0/10",
+	"198.18.0.0/15",
+	"240.0.0.0/4",
+	"64:ff9b::/96",
+)
+
+type WebhookService interface {
+	CreateEndpoint(ctx context.Context, req *dto.CreateWebhookEndpointRequest) (*dto.WebhookEndpointResponse, error)
+	GetEndpoints(ctx context.Context) ([]*dto.WebhookEndpointResponse, error)
+	GetEndpoint(ctx context.Context, id uint) (*dto.WebhookEndpointResponse, error)
+	UpdateEndpoint(ctx context.Context, id uint, req *dto.UpdateWebhookEndpointRequest) (*dto.WebhookEndpointResponse, error)
+	DeleteEndpoint(ctx context.Context, id uint) error
+	GetDeliveries(ctx context.Context, endpointId uint, page, limit int) ([]*dto.WebhookDeliveryResponse, *helper.PaginatedMeta, error)
+	GetDelivery(ctx context.Context, id uint) (*dto.WebhookDeliveryResponse, error)
+	Redeliver(ctx context.Context, id uint) (*dto.WebhookDeliveryResponse, error)
+	DeliverPending(ctx context.Context) (int, error)
+}
+
+type webhookService struct {
+	batchSize         int
+	timeout           time.Duration
+	maxAttempts       int
+	retryBackoff      time.Duration
+	maxBackoff        time.Duration
+	client            *http.Client
+	webhookRepository repository.WebhookRepository
+	db                *gorm.DB
+}
+
+func (w *webhookService) CreateEndpoint(ctx context.Context, req *dto.CreateWebhookEndpointRequest) (*dto.WebhookEndpointResponse, error) {
+	eventTypes, err := validateWebhookEndpoint(req.URL, req.EventTypes)
+	if err != nil {
+		return nil, err
+	}
+
+	secret, err := generateWebhookSecret()
+	if err != nil {
+		return nil, err
+	}
+
+	endpoint := &domain.WebhookEndpoint{
+		URL:         req.URL,
+		Description: req.Description,
+		Secret:      secret,
+		EventTypes:  eventTypes,
+		IsActive:    true,
+	}
+
+	if err := w.webhookRepository.CreateEndpoint(ctx, endpoint); err != nil {
+		return nil, err
+	}
+
+	response := w.convertToEndpointResponse(endpoint)
+	response.Secret = endpoint.Secret
+	return response, nil
+}
+
+func (w *webhookService) GetEndpoints(ctx context.Context) ([]*dto.WebhookEndpointResponse, error) {
+	endpoints, err := w.webhookRepository.GetEndpoints(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	response := make([]*dto.WebhookEndpointResponse, len(endpoints))
+	for i := range endpoints {
+		response[i] = w.convertToEndpointResponse(&endpoints[i])
+	}
+	return response, nil
+}
+
+func (w *webhookService) GetEndpoint(ctx context.Context, id uint) (*dto.WebhookEndpointResponse, error) {
+	endpoint, err := w.webhookRepository.GetEndpointById(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	return w.convertToEndpointResponse(endpoint), nil
+}
+
+func (w *webhookService) UpdateEndpoint(ctx context.Context, id uint, req *dto.UpdateWebhookEndpointRequest) (*dto.WebhookEndpointResponse, error) {
+	eventTypes, err := validateWebhookEndpoint(req.URL, req.EventTypes)
+	if err != nil {
+		return nil, err
+	}
+
+	endpoint, err := w.webhookRepository.GetEndpointById(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	endpoint.URL = req.URL
+	endpoint.Description = req.Description
+	endpoint.EventTypes = eventTypes
+	if req.IsActive != nil {
+		endpoint.IsActive = *req.IsActive
+	}
+
+	if err := w.webhookRepository.UpdateEndpoint(ctx, endpoint); err != nil {
+		return nil, err
+	}
+
+	return w.convertToEndpointResponse(endpoint), nil
+}
+
+func (w *webhookService) DeleteEndpoint(ctx context.Context, id uint) error {
+	return w.webhookRepository.DeleteEndpoint(ctx, id)
+}
+
+func (w *webhookService) GetDeliveries(ctx context.Context, endpointId uint, page, limit int) ([]*dto.WebhookDeliveryResponse, *helper.PaginatedMeta, error) {
+	if page < 1 {
+		page = 1
+	}
+
+	if limit < 1 {
+		limit = 20
+	}
+
+	if limit > 100 {
+		limit = 100
+	}
+
+	if _, err := w.webhookRepository.GetEndpointById(ctx, endpointId); err != nil {
+		return nil, nil, err
+	}
+
+	total, err := w.webhookRepository.CountDeliveries(ctx, endpointId)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	deliveries, err := w.webhookRepository.GetDeliveries(ctx, endpointId, (page-1)*limit, limit)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	response := make([]*dto.WebhookDeliveryResponse, len(deliveries))
+	for i := range deliveries {
+		response[i] = w.convertToDeliveryResponse(&deliveries[i])
+	}
+
+	meta := &helper.PaginatedMeta{
+		Page:      page,
+		Limit:     limit,
+		Total:     total,
+		TotalPage: int((total + int64(limit) - 1) / int64(limit)),
+	}
+
+	return response, meta, nil
+}
+
+// GetDelivery returns a delivery with its payload and every attempt made.
+func (w *webhookService) GetDelivery(ctx context.Context, id uint) (*dto.WebhookDeliveryResponse, error) {
+	delivery, err := w.webhookRepository.GetDeliveryById(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	response := w.convertToDeliveryResponse(delivery)
+	response.Payload = delivery.Payload
+	response.History = make([]dto.WebhookDeliveryAttemptResponse, len(delivery.History))
+	for i, attempt := range delivery.History {
+		response.History[i] = dto.WebhookDeliveryAttemptResponse{
+			Id:         attempt.Id,
+			StatusCode: attempt.StatusCode,
+			Error:      attempt.Error,
+			DurationMs: attempt.DurationMs,
+			CreatedAt:  attempt.CreatedAt,
+		}
+	}
+
+	return response, nil
+}
+
+// Redeliver queues a delivery again with a fresh retry budget, whatever its
+// outcome so far. The dispatcher picks it up on its next poll.
+func (w *webhookService) Redeliver(ctx context.Context, id uint) (*dto.WebhookDeliveryResponse, error) {
+	err := w.db.Transaction(func(tx *gorm.DB) error {
+		webhookRepo := w.webhookRepository.WithTx(tx)
+
+		delivery, err := webhookRepo.GetDeliveryForUpdate(ctx, id)
+		if err != nil {
+			return err
+		}
+
+		if !delivery.Endpoint.IsActive {
+			return ErrWebhookEndpointDisabled
+		}
+
+		delivery.Status = domain.WebhookDeliveryStatusPending
+		delivery.Attempts = 0
+		delivery.NextAttemptAt = time.Now()
+		delivery.DeliveredAt = nil
+
+		return webhookRepo.UpdateDelivery(ctx, delivery)
+	})
+
+	if err != nil {
+		return nil, err
+	}
+
+	return w.GetDelivery(ctx, id)
+}
+
+// DeliverPending sends one batch of due deliveries and returns how many were
+// attempted. The batch is leased for twice the request timeout before the
+// requests go out, so a dispatcher that dies mid-batch only delays those
+// deliveries and no database lock is held while waiting on endpoints.
+func (w *webhookService) DeliverPending(ctx context.Context) (int, error) {
+	var deliveries []domain.WebhookDelivery
+
+	err := w.db.Transaction(func(tx *gorm.DB) error {
+		webhookRepo := w.webhookRepository.WithTx(tx)
+
+		due, err := webhookRepo.GetDueDeliveriesForUpdate(ctx, w.batchSize)
+		if err != nil {
+			return err
+		}
+
+		leaseUntil := time.Now().Add(2 * w.timeout)
+		for i := range due {
+			due[i].NextAttemptAt = leaseUntil
+			if err := webhookRepo.UpdateDelivery(ctx, &due[i]); err != nil {
+				return err
+			}
+		}
+
+		deliveries = due
+		return nil
+	})
+
+	if err != nil {
+		return 0, err
+	}
+
+	for i := range deliveries {
+		if err := w.deliver(ctx, &deliveries[i]); err != nil {
+			return i, err
+		}
+	}
+
+	return len(deliveries), nil
+}
+
+// deliver makes one attempt of a delivery and records its outcome. Anything
+// but a 2xx response counts as a failure; redirects are not followed.
+func (w *webhookService) deliver(ctx context.Context, delivery *domain.WebhookDelivery) error {
+	attempt := &domain.WebhookDeliveryAttempt{DeliveryId: delivery.Id}
+
+	start := time.Now()
+	statusCode, err := w.post(ctx, delivery)
+	attempt.DurationMs = int(time.Since(start).Milliseconds())
+
+	switch {
+	case err != nil:
+		attempt.Error = err.Error()
+	case statusCode < 200 || statusCode > 299:
+		attempt.StatusCode = &statusCode
+		attempt.Error = fmt.Sprintf("endpoint responded with status %d", statusCode)
+	default:
+		attempt.StatusCode = &statusCode
+	}
+
+	delivery.Attempts++
+	delivery.LastStatusCode = attempt.StatusCode
+	delivery.LastError = attempt.Error
+
+	switch {
+	case attempt.Error == "":
+		now := time.Now()
+		delivery.Status = domain.WebhookDeliveryStatusSucceeded
+		delivery.DeliveredAt = &now
+	case delivery.Attempts >= w.maxAttempts:
+		delivery.Status = domain.WebhookDeliveryStatusFailed
+	default:
+		delivery.NextAttemptAt = time.Now().Add(exponentialBackoff(w.retryBackoff, w.maxBackoff, delivery.Attempts))
+	}
+
+	return w.db.Transaction(func(tx *gorm.DB) error {
+		webhookRepo := w.webhookRepository.WithTx(tx)
+
+		if err := webhookRepo.CreateAttempt(ctx, attempt); err != nil {
+			return err
+		}
+
+		return webhookRepo.UpdateDelivery(ctx, delivery)
+	})
+}
+
+// post sends a delivery and returns the response status. The response body
+// is drained but not kept, so an endpoint cannot use deliveries to read back
+// whatever it answers with.
+func (w *webhookService) post(ctx context.Context, delivery *domain.WebhookDelivery) (int, error) {
+	ctx, cancel := context.WithTimeout(ctx, w.timeout)
+	defer cancel()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, delivery.Endpoint.URL, bytes.NewReader(delivery.Payload))
+	if err != nil {
+		return 0, err
+	}
+
+	timestamp := strconv.FormatInt(time.Now().Unix(), 10)
+
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", "Cartopher-Webhooks/1.0")
+	req.Header.Set(WebhookEventHeader, delivery.EventType)
+	req.Header.Set(WebhookEventIdHeader, delivery.EventId)
+	req.Header.Set(WebhookDeliveryHeader, strconv.FormatUint(uint64(delivery.Id), 10))
+	req.Header.Set(WebhookSignatureHeader, "t="+timestamp+",v1="+signWebhookPayload(delivery.Endpoint.Secret, timestamp, delivery.Payload))
+
+	resp, err := w.client.Do(req)
+	if err != nil {
+		return 0, err
+	}
+	defer resp.Body.Close()
+
+	_, _ = io.Copy(io.Discard, io.LimitReader(resp.Body, maxWebhookResponseLength))
+
+	return resp.StatusCode, nil
+}
+
+func (w *webhookService) convertToEndpointResponse(endpoint *domain.WebhookEndpoint) *dto.WebhookEndpointResponse {
+	return &dto.WebhookEndpointResponse{
+		Id:          endpoint.Id,
+		URL:         endpoint.URL,
+		Description: endpoint.Description,
+		EventTypes:  endpoint.EventTypes,
+		IsActive:    endpoint.IsActive,
+		CreatedAt:   endpoint.CreatedAt,
+		UpdatedAt:   endpoint.UpdatedAt,
+	}
+}
+
+func (w *webhookService) convertToDeliveryResponse(delivery *domain.WebhookDelivery) *dto.WebhookDeliveryResponse {
+	return &dto.WebhookDeliveryResponse{
+		Id:             delivery.Id,
+		EndpointId:     delivery.EndpointId,
+		EventId:        delivery.EventId,
+		EventType:      delivery.EventType,
+		Status:         string(delivery.Status),
+		Attempts:       delivery.Attempts,
+		LastStatusCode: delivery.LastStatusCode,
+		LastError:      delivery.LastError,
+		NextAttemptAt:  delivery.NextAttemptAt,
+		DeliveredAt:    delivery.DeliveredAt,
+		CreatedAt:      delivery.CreatedAt,
+	}
+}
+
+// enqueueWebhookDeliveries creates a delivery of an outbox message for every
+// active endpoint subscribed to its event type.
+func enqueueWebhookDeliveries(ctx context.Context, webhookRepository repository.WebhookRepository, message *domain.OutboxMessage) error {
+	endpoints, err := webhookRepository.GetActiveEndpointsByEventType(ctx, message.EventType)
+	if err != nil || len(endpoints) == 0 {
+		return err
+	}
+
+	eventId := fmt.Sprintf("outbox-%d", message.Id)
+	if envelope, err := events.DecodeEnvelope(message.Payload, message.EventType); err == nil && envelope.Id != "" {
+		eventId = envelope.Id
+	}
+
+	deliveries := make([]domain.WebhookDelivery, len(endpoints))
+	for i, endpoint := range endpoints {
+		deliveries[i] = domain.WebhookDelivery{
+			EndpointId:    endpoint.Id,
+			EventId:       eventId,
+			EventType:     message.EventType,
+			Payload:       message.Payload,
+			Status:        domain.WebhookDeliveryStatusPending,
+			NextAttemptAt: time.Now(),
+		}
+	}
+
+	return webhookRepository.CreateDeliveries(ctx, deliveries)
+}
+
+// validateWebhookEndpoint checks the URL and returns the event types without
+// duplicates.
+func validateWebhookEndpoint(rawURL string, eventTypes []string) ([]string, error) {
+	parsed, err := url.Parse(rawURL)
+	if err != nil || (parsed.Scheme != "http" && parsed.Scheme != "https") || parsed.Host == "" {
+		return nil, fmt.Errorf("%w: url must be an absolute http or https url", ErrInvalidWebhookEndpoint)
+	}
+
+	// Host names are checked when they are dialled, since they may resolve
+	// differently by then.
+	if ip := net.ParseIP(parsed.Hostname()); ip != nil && !isPublicIP(ip) {
+		return nil, ErrWebhookAddressNotAllowed
+	}
+
+	if len(eventTypes) == 0 {
+		return nil, fmt.Errorf("%w: at least one event type is required", ErrInvalidWebhookEndpoint)
+	}
+
+	known := events.Types()
+	unique := make([]string, 0, len(eventTypes))
+	for _, eventType := range eventTypes {
+		if !slices.Contains(known, eventType) {
+			return nil, fmt.Errorf("%w: unknown event type %s", ErrInvalidWebhookEndpoint, eventType)
+		}
+		if !slices.Contains(unique, eventType) {
+			unique = append(unique, eventType)
+		}
+	}
+
+	return unique, nil
+}
+
+// isPublicIP reports whether a webhook may be delivered to the address, so
+// that endpoints cannot reach the internal network or the cloud metadata
+// service at 169.254.169.254.
+func isPublicIP(ip net.IP) bool {
+	if ip.IsLoopback() || ip.IsPrivate() || ip.IsUnspecified() ||
+		ip.IsLinkLocalUnicast() || ip.IsMulticast() {
+		return false
+	}
+
+	for _, network := range deniedNetworks {
+		if network.Contains(ip) {
+			return false
+		}
+	}
+	return true
+}
+
+func parseNetworks(cidrs ...string) []*net.IPNet {
+	networks := make([]*net.IPNet, len(cidrs))
+	for i, cidr := range cidrs {
+		_, network, err := net.ParseCIDR(cidr)
+		if err != nil {
+			panic(err)
+		}
+		networks[i] = network
+	}
+	return networks
+}
+
+// newWebhookClient returns a client that refuses to connect to addresses
+// that are not public. The check runs on the resolved address of every
+// connection, so DNS rebinding cannot get around it, and no proxy is used
+// since the dialled address would then be the proxy's.
+func newWebhookClient() *http.Client {
+	dialer := &net.Dialer{
+		Timeout: 30 * time.Second,
+		Control: func(network, address string, _ syscall.RawConn) error {
+			host, _, err := net.SplitHostPort(address)
+			if err != nil {
+				return err
+			}
+			if ip := net.ParseIP(host); ip == nil || !isPublicIP(ip) {
+				return ErrWebhookAddressNotAllowed
+			}
+			return nil
+		},
+	}
+
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.Proxy = nil
+	transport.DialContext = dialer.DialContext
+
+	return &http.Client{
+		Transport: transport,
+		CheckRedirect: func(*http.Request, []*http.Request) error {
+			return http.ErrUseLastResponse
+		},
+	}
+}
+
+func generateWebhookSecret() (string, error) {
+	secret := make([]byte, 32)
+	if _, err := rand.Read(secret); err != nil {
+		return "", fmt.Errorf("failed to generate webhook secret: %w", err)
+	}
+	return webhookSecretPrefix + hex.EncodeToString(secret), nil
+}
+
+func signWebhookPayload(secret, timestamp string, payload []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(timestamp))
+	mac.Write([]byte("."))
+	mac.Write(payload)
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+func NewWebhookService(cfg *config.Config, webhookRepository repository.WebhookRepository, db *gorm.DB) WebhookService {
+	batchSize := cfg.Webhooks.BatchSize
+	if batchSize <= 0 {
+		batchSize = defaultWebhookBatchSize
+	}
+
+	timeout := cfg.Webhooks.Timeout
+	if timeout <= 0 {
+		timeout = defaultWebhookTimeout
+	}
+
+	maxAttempts := cfg.Webhooks.MaxAttempts
+	if maxAttempts <= 0 {
+		maxAttempts = defaultWebhookMaxAttempts
+	}
+
+	retryBackoff := cfg.Webhooks.RetryBackoff
+	if retryBackoff <= 0 {
+		retryBackoff = defaultWebhookRetryBackoff
+	}
+
+	maxBackoff := cfg.Webhooks.MaxBackoff
+	if maxBackoff <= 0 {
+		maxBackoff = defaultWebhookMaxBackoff
+	}
+
+	return &webhookService{
+		batchSize:         batchSize,
+		timeout:           timeout,
+		maxAttempts:       maxAttempts,
+		retryBackoff:      retryBackoff,
+		maxBackoff:        maxBackoff,
+		client:            newWebhookClient(),
+		webhookRepository: webhookRepository,
+		db:                db,
+	}
+}
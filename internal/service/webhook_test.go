@@ -0,0 +1,51 @@
+package service
+
+import (
+	"net"
+	"testing"
+)
+
+func TestIsPublicIP(t *testing.T) {
+	tests := []struct {
+		ip   string
+		want bool
+	}{
+		{ip: "93.184.216.34", want: true},
+		{ip: "2606:2800:220:1:248:1893:25c8:1946", want: true},
+		{ip: "127.0.0.1", want: false},
+		{ip: "::1", want: false},
+		{ip: "10.0.0.1", want: false},
+		{ip: "172.16.0.1", want: false},
+		{ip: "192.168.1.1", want: false},
+		{ip: "fd00::1", want: false},
+		{ip: "0.0.0.0", want: false},
+		{ip: "0.1.2.3", want: false},
+		{ip: "::", want: false},
+		{ip: "169.254.169.254", want: false},
+		{ip: "fe80::1", want: false},
+		{ip: "100.64.0.1", want: false},
+		{ip: "100.127.255.254", want: false},
+		{ip: "100.128.0.1", want: true},
+		{ip: "198.18.0.1", want: false},
+		{ip: "198.19.255.254", want: false},
+		{ip: "198.20.0.1", want: true},
+		{ip: "224.0.0.1", want: false},
+		{ip: "239.255.255.250", want: false},
+		{ip: "ff02::1", want: false},
+		{ip: "ff0e::1", want: false},
+		{ip: "240.0.0.1", want: false},
+		{ip: "255.255.255.255", want: false},
+		{ip: "64:ff9b::a9fe:a9fe", want: false},
+		{ip: "64:ff9b::7f00:1", want: false},
+		{ip: "::ffff:127.0.0.1", want: false},
+		{ip: "::ffff:100.64.0.1", want: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.ip, func(t *testing.T) {
+			if got := isPublicIP(net.ParseIP(tt.ip)); got != tt.want {
+				t.Errorf("isPublicIP(%s) = %v, want %v", tt.ip, got, tt.want)
+			}
+		})
+	}
+}
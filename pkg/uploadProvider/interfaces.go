@@ -4,5 +4,7 @@ import "mime/multipart"
 
 type UploadProvider interface {
 	UploadFile(file *multipart.FileHeader, path string) (string, error)
+	UploadBytes(data []byte, path, contentType string) (string, error)
+	ReadFile(path string) ([]byte, error)
 	DeleteFile(path string) error
 }
@@ -37,6 +37,25 @@ func (l *LocalUploadProvider) UploadFile(file *multipart.FileHeader, path string
 	return fmt.Sprintf("/uploads/%s", path), nil
 }
 
+func (l *LocalUploadProvider) UploadBytes(data []byte, path, contentType string) (string, error) {
+	fullPath := filepath.Join(l.basePath, path)
+
+	if err := os.MkdirAll(filepath.Dir(fullPath), 0o755); err != nil {
+		return "", err
+	}
+
+	if err := os.WriteFile(fullPath, data, 0o644); err != nil {
+		return "", err
+	}
+
+	return fmt.Sprintf("/uploads/%s", path), nil
+}
+
+func (l *LocalUploadProvider) ReadFile(path string) ([]byte, error) {
+	fullPath := filepath.Join(l.basePath, path)
+	return os.ReadFile(fullPath)
+}
+
 func (l *LocalUploadProvider) DeleteFile(path string) error {
 	fullPath := filepath.Join(l.basePath, path)
 	return os.Remove(fullPath)
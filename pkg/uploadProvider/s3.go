@@ -1,7 +1,9 @@
 package uploadProvider
 
 import (
+	"bytes"
 	"context"
+	"io"
 	"mime/multipart"
 	"strings"
 
@@ -72,6 +74,33 @@ func (p *S3Provider) UploadFile(file *multipart.FileHeader, path string) (string
 	return *result.Key, nil
 }
 
+func (p *S3Provider) UploadBytes(data []byte, path, contentType string) (string, error) {
+	result, err := p.uploader.Upload(context.TODO(), &s3.PutObjectInput{
+		Bucket:      aws.String(p.bucket),
+		Key:         aws.String(path),
+		Body:        bytes.NewReader(data),
+		ContentType: aws.String(contentType),
+	})
+	if err != nil {
+		return "", err
+	}
+
+	return *result.Key, nil
+}
+
+func (p *S3Provider) ReadFile(path string) ([]byte, error) {
+	result, err := p.client.GetObject(context.TODO(), &s3.GetObjectInput{
+		Bucket: aws.String(p.bucket),
+		Key:    aws.String(strings.TrimPrefix(path, "/")),
+	})
+	if err != nil {
+		return nil, err
+	}
+	defer result.Body.Close()
+
+	return io.ReadAll(result.Body)
+}
+
 func (p *S3Provider) DeleteFile(path string) error {
 	_, err := p.client.DeleteObject(context.TODO(), &s3.DeleteObjectInput{
 		Bucket: aws.String(p.bucket),
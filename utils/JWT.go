@@ -1,65 +1,113 @@
 package utils
 
 import (
-	"errors"
+	"strconv"
 	"time"
 
 	"github.com/golang-jwt/jwt/v5"
+	"github.com/google/uuid"
 	"github.com/saleh-ghazimoradi/Cartopher/config"
 )
 
+// Token types. Purpose tokens have their purpose as type.
+const (
+	TokenTypeAccess  = "access"
+	TokenTypeRefresh = "refresh"
+)
+
 type Claims struct {
+	// Type tells access, refresh and purpose tokens apart, since they are
+	// signed with the same key; a token is only accepted as its own type.
+	Type   string `json:"typ"`
 	UserId uint   `json:"user_id"`
 	Email  string `json:"email"`
 	Role   string `json:"role"`
+	// Permissions are those granted by the role when the token was issued.
+	Permissions []string `json:"permissions,omitempty"`
+	// TwoFactor is set when the user passed two-factor authentication to
+	// get the token.
+	TwoFactor bool `json:"two_factor,omitempty"`
+	// Version is the token version of the user when the token was issued;
+	// the token is revoked once the version moves on.
+	Version int64 `json:"version,omitempty"`
 	jwt.RegisteredClaims
 }
 
-func GenerateToken(cfg *config.Config, userId uint, email string, role string) (accessToken, refreshToken string, err error) {
-	accessClaims := &Claims{
-		UserId: userId,
-		Email:  email,
-		Role:   role,
-		RegisteredClaims: jwt.RegisteredClaims{
-			ExpiresAt: jwt.NewNumericDate(time.Now().Add(cfg.JWT.ExpiresIn)),
-			IssuedAt:  jwt.NewNumericDate(time.Now()),
-		},
+// GenerateToken issues an access and a refresh token signed with the
+// signing key of keys.
+func GenerateToken(cfg *config.Config, keys *KeySet, userId uint, email string, role string, permissions []string, twoFactor bool, version int64) (accessToken, refreshToken string, err error) {
+	accessToken, err = keys.sign(newClaims(keys, TokenTypeAccess, userId, email, role, permissions, twoFactor, version, cfg.JWT.ExpiresIn))
+	if err != nil {
+		return "", "", err
 	}
 
-	at := jwt.NewWithClaims(jwt.SigningMethodHS256, accessClaims)
-	accessToken, err = at.SignedString([]byte(cfg.JWT.Secret))
+	refreshToken, err = keys.sign(newClaims(keys, TokenTypeRefresh, userId, email, role, permissions, twoFactor, version, cfg.JWT.RefreshTokenExpires))
 	if err != nil {
 		return "", "", err
 	}
 
-	refreshClaims := &Claims{
-		UserId: userId,
-		Email:  email,
-		Role:   role,
+	return accessToken, refreshToken, nil
+}
+
+// ValidateToken returns the claims of an access or refresh token, as given
+// by tokenType, issued by GenerateToken with any key of keys.
+func ValidateToken(tokenString string, keys *KeySet, tokenType string) (*Claims, error) {
+	return keys.parse(tokenString, tokenType, keys.audience)
+}
+
+func newClaims(keys *KeySet, tokenType string, userId uint, email string, role string, permissions []string, twoFactor bool, version int64, expiresIn time.Duration) *Claims {
+	now := time.Now()
+
+	claims := &Claims{
+		Type:        tokenType,
+		UserId:      userId,
+		Email:       email,
+		Role:        role,
+		Permissions: permissions,
+		TwoFactor:   twoFactor,
+		Version:     version,
 		RegisteredClaims: jwt.RegisteredClaims{
-			ExpiresAt: jwt.NewNumericDate(time.Now().Add(cfg.JWT.RefreshTokenExpires)),
-			IssuedAt:  jwt.NewNumericDate(time.Now()),
+			ID:        uuid.NewString(),
+			Issuer:    keys.issuer,
+			Subject:   strconv.FormatUint(uint64(userId), 10),
+			ExpiresAt: jwt.NewNumericDate(now.Add(expiresIn)),
+			IssuedAt:  jwt.NewNumericDate(now),
 		},
 	}
+	if keys.audience != "" {
+		claims.Audience = jwt.ClaimStrings{keys.audience}
+	}
+	return claims
+}
 
-	rt := jwt.NewWithClaims(jwt.SigningMethodHS256, refreshClaims)
-	refreshTokenString, err := rt.SignedString([]byte(cfg.JWT.Secret))
+// GeneratePurposeToken signs a short-lived token that is only valid for
+// purpose, e.g. verifying an email address. Its type and audience are the
+// purpose, so it is never accepted as an access token and the other way
+// round. Its random ID lets single-use tokens be claimed.
+func GeneratePurposeToken(keys *KeySet, purpose string, userId uint, email string, expiresIn time.Duration) (string, error) {
+	id, err := GenerateOpaqueToken()
 	if err != nil {
-		return "", "", err
+		return "", err
 	}
 
-	return accessToken, refreshTokenString, nil
-}
+	now := time.Now()
 
-func ValidateToken(tokenString, secret string) (*Claims, error) {
-	token, err := jwt.ParseWithClaims(tokenString, &Claims{}, func(token *jwt.Token) (interface{}, error) {
-		return []byte(secret), nil
+	return keys.sign(&Claims{
+		Type:   purpose,
+		UserId: userId,
+		Email:  email,
+		RegisteredClaims: jwt.RegisteredClaims{
+			ID:        id,
+			Issuer:    keys.issuer,
+			Audience:  jwt.ClaimStrings{purpose},
+			ExpiresAt: jwt.NewNumericDate(now.Add(expiresIn)),
+			IssuedAt:  jwt.NewNumericDate(now),
+		},
 	})
-	if err != nil {
-		return nil, err
-	}
-	if claims, ok := token.Claims.(*Claims); ok && token.Valid {
-		return claims, nil
-	}
-	return nil, errors.New("invalid token")
+}
+
+// ValidatePurposeToken returns the claims of a token generated by
+// GeneratePurposeToken for the same purpose.
+func ValidatePurposeToken(tokenString string, keys *KeySet, purpose string) (*Claims, error) {
+	return keys.parse(tokenString, purpose, purpose)
 }
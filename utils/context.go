@@ -1,10 +1,31 @@
 package utils
 
+import "context"
+
 type ContextKey string
 
 const (
-	UserIdKey     ContextKey = "user_id"
-	UserEmailKey  ContextKey = "user_email"
-	UserRoleKey   ContextKey = "user_role"
-	GinContextKey ContextKey = "gin_context"
+	UserIdKey          ContextKey = "user_id"
+	UserEmailKey       ContextKey = "user_email"
+	UserRoleKey        ContextKey = "user_role"
+	UserPermissionsKey ContextKey = "user_permissions"
+	GinContextKey      ContextKey = "gin_context"
+	ClientKey          ContextKey = "client"
 )
+
+// Client describes the device a request came from.
+type Client struct {
+	UserAgent string
+	IPAddress string
+}
+
+// WithClient returns a copy of ctx carrying the client of the request.
+func WithClient(ctx context.Context, client Client) context.Context {
+	return context.WithValue(ctx, ClientKey, client)
+}
+
+// ClientFromContext returns the client carried by ctx, if any.
+func ClientFromContext(ctx context.Context) Client {
+	client, _ := ctx.Value(ClientKey).(Client)
+	return client
+}
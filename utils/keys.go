@@ -0,0 +1,224 @@
+package utils
+
+import (
+	"crypto/ed25519"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"math/big"
+	"os"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/saleh-ghazimoradi/Cartopher/config"
+)
+
+// KeySet holds the keys access, refresh and purpose tokens are signed and
+// verified with. Without key files, tokens are signed with JWT_SECRET using HS256.
+// With key files, the first one signs and the others only verify, so a new
+// key can be published before it signs and an old one kept until the
+// tokens it signed have expired.
+type KeySet struct {
+	issuer   string
+	audience string
+	signing  *signingKey
+	keys     map[string]*signingKey
+	// published lists the asymmetric keys in the configured order.
+	published []*signingKey
+}
+
+type signingKey struct {
+	id      string
+	method  jwt.SigningMethod
+	private any
+	public  any
+}
+
+// JWKS is a JSON Web Key Set (RFC 7517) of the public verification keys.
+type JWKS struct {
+	Keys []JWK `json:"keys"`
+}
+
+type JWK struct {
+	Kty string `json:"kty"`
+	Kid string `json:"kid"`
+	Use string `json:"use"`
+	Alg string `json:"alg"`
+	N   string `json:"n,omitempty"`
+	E   string `json:"e,omitempty"`
+	Crv string `json:"crv,omitempty"`
+	X   string `json:"x,omitempty"`
+}
+
+// LoadKeySet reads the signing keys configured in JWT_KEY_FILES. Each file
+// holds one PEM encoded RSA or Ed25519 key, signing with RS256 or EdDSA;
+// the first one must be a private key, the others may be public keys.
+func LoadKeySet(cfg *config.Config) (*KeySet, error) {
+	keySet := &KeySet{
+		issuer:   cfg.JWT.Issuer,
+		audience: cfg.JWT.Audience,
+		keys:     make(map[string]*signingKey),
+	}
+
+	if len(cfg.JWT.KeyFiles) == 0 {
+		if cfg.JWT.Secret == "" {
+			return nil, errors.New("either JWT_SECRET or JWT_KEY_FILES must be set")
+		}
+
+		keySet.signing = &signingKey{
+			method:  jwt.SigningMethodHS256,
+			private: []byte(cfg.JWT.Secret),
+			public:  []byte(cfg.JWT.Secret),
+		}
+		keySet.keys[""] = keySet.signing
+		return keySet, nil
+	}
+
+	for i, path := range cfg.JWT.KeyFiles {
+		key, err := loadKey(path)
+		if err != nil {
+			return nil, fmt.Errorf("failed to load JWT key %s: %w", path, err)
+		}
+
+		if i == 0 {
+			if key.private == nil {
+				return nil, fmt.Errorf("JWT key %s signs tokens and must be a private key", path)
+			}
+			keySet.signing = key
+		}
+		keySet.keys[key.id] = key
+		keySet.published = append(keySet.published, key)
+	}
+
+	return keySet, nil
+}
+
+// JWKS returns the public keys tokens are verified with. A key set using
+// JWT_SECRET has none to publish.
+func (k *KeySet) JWKS() *JWKS {
+	jwks := &JWKS{Keys: make([]JWK, 0, len(k.published))}
+	for _, key := range k.published {
+		jwks.Keys = append(jwks.Keys, toJWK(key))
+	}
+	return jwks
+}
+
+func (k *KeySet) sign(claims *Claims) (string, error) {
+	token := jwt.NewWithClaims(k.signing.method, claims)
+	if k.signing.id != "" {
+		token.Header["kid"] = k.signing.id
+	}
+	return token.SignedString(k.signing.private)
+}
+
+// parse returns the claims of a token of tokenType signed with any key of
+// the set. An empty audience is not checked.
+func (k *KeySet) parse(tokenString, tokenType, audience string) (*Claims, error) {
+	opts := []jwt.ParserOption{}
+	if k.issuer != "" {
+		opts = append(opts, jwt.WithIssuer(k.issuer))
+	}
+	if audience != "" {
+		opts = append(opts, jwt.WithAudience(audience))
+	}
+
+	token, err := jwt.ParseWithClaims(tokenString, &Claims{}, func(token *jwt.Token) (interface{}, error) {
+		kid, _ := token.Header["kid"].(string)
+		key, ok := k.keys[kid]
+		if !ok {
+			return nil, errors.New("unknown signing key")
+		}
+		// The algorithm is bound to the key, never taken from the token.
+		if token.Method.Alg() != key.method.Alg() {
+			return nil, fmt.Errorf("unexpected signing method %s", token.Method.Alg())
+		}
+		return key.public, nil
+	}, opts...)
+	if err != nil {
+		return nil, err
+	}
+	claims, ok := token.Claims.(*Claims)
+	if !ok || !token.Valid {
+		return nil, errors.New("invalid token")
+	}
+	if claims.Type != tokenType {
+		return nil, fmt.Errorf("expected a %s token, got %q", tokenType, claims.Type)
+	}
+	return claims, nil
+}
+
+func loadKey(path string) (*signingKey, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	block, _ := pem.Decode(data)
+	if block == nil {
+		return nil, errors.New("no PEM block found")
+	}
+
+	var parsed any
+	switch block.Type {
+	case "PRIVATE KEY":
+		parsed, err = x509.ParsePKCS8PrivateKey(block.Bytes)
+	case "RSA PRIVATE KEY":
+		parsed, err = x509.ParsePKCS1PrivateKey(block.Bytes)
+	case "PUBLIC KEY":
+		parsed, err = x509.ParsePKIXPublicKey(block.Bytes)
+	default:
+		return nil, fmt.Errorf("unsupported PEM block %q", block.Type)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	key := &signingKey{}
+	switch k := parsed.(type) {
+	case *rsa.PrivateKey:
+		key.method, key.private, key.public = jwt.SigningMethodRS256, k, &k.PublicKey
+	case *rsa.PublicKey:
+		key.method, key.public = jwt.SigningMethodRS256, k
+	case ed25519.PrivateKey:
+		key.method, key.private, key.public = jwt.SigningMethodEdDSA, k, k.Public()
+	case ed25519.PublicKey:
+		key.method, key.public = jwt.SigningMethodEdDSA, k
+	default:
+		return nil, fmt.Errorf("unsupported key type %T", parsed)
+	}
+
+	key.id = thumbprint(toJWK(key))
+	return key, nil
+}
+
+func toJWK(key *signingKey) JWK {
+	jwk := JWK{Kid: key.id, Use: "sig", Alg: key.method.Alg()}
+	switch public := key.public.(type) {
+	case *rsa.PublicKey:
+		jwk.Kty = "RSA"
+		jwk.N = base64.RawURLEncoding.EncodeToString(public.N.Bytes())
+		jwk.E = base64.RawURLEncoding.EncodeToString(big.NewInt(int64(public.E)).Bytes())
+	case ed25519.PublicKey:
+		jwk.Kty = "OKP"
+		jwk.Crv = "Ed25519"
+		jwk.X = base64.RawURLEncoding.EncodeToString(public)
+	}
+	return jwk
+}
+
+// thumbprint returns the RFC 7638 thumbprint of a key, which identifies it
+// as its kid without having to be configured.
+func thumbprint(jwk JWK) string {
+	var members string
+	switch jwk.Kty {
+	case "RSA":
+		members = fmt.Sprintf(`{"e":"%s","kty":"RSA","n":"%s"}`, jwk.E, jwk.N)
+	case "OKP":
+		members = fmt.Sprintf(`{"crv":"%s","kty":"OKP","x":"%s"}`, jwk.Crv, jwk.X)
+	}
+	sum := sha256.Sum256([]byte(members))
+	return base64.RawURLEncoding.EncodeToString(sum[:])
+}